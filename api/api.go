@@ -1,17 +1,23 @@
 package api
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bmizerany/pat"
 	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/api/robeaux"
+	"golang.org/x/net/websocket"
 )
 
 // API represents an API server
@@ -23,28 +29,69 @@ type API struct {
 	Cert     string
 	Key      string
 	handlers []func(http.ResponseWriter, *http.Request)
-	start    func(*API)
+	start    func(*API) error
+	routes   sync.Once
+
+	// Socket is the path of a Unix socket the api listens on instead of
+	// Host and Port
+	Socket string
+	// ShutdownTimeout is how long Stop waits for the requests being served
+	// to complete before closing their connections
+	ShutdownTimeout time.Duration
+	server          *http.Server
+	listener        net.Listener
+	serverMutex     sync.Mutex
+	conns           map[net.Conn]http.ConnState
+	connsMutex      sync.Mutex
+	closing         chan bool
+	stop            sync.Once
+
+	authenticators []Authenticator
+	users          map[*http.Request]*User
+	usersMutex     sync.Mutex
+	// Audit records every command executed through the api, see AuditLog
+	Audit func(AuditEntry)
+
+	// WebSocketHeartbeat is the interval of the pings sent to websocket
+	// clients, which are disconnected after two heartbeats of silence
+	WebSocketHeartbeat time.Duration
+	// WebSocketBuffer is the number of outgoing messages buffered for each
+	// websocket client, events beyond it are dropped
+	WebSocketBuffer int
 }
 
 // NewAPI returns a new api instance
 func NewAPI(g *gobot.Gobot) *API {
 	return &API{
-		gobot:  g,
-		router: pat.New(),
-		Port:   "3000",
-		start: func(a *API) {
-			log.Println("Initializing API on " + a.Host + ":" + a.Port + "...")
-			http.Handle("/", a)
-
-			go func() {
-				if a.Cert != "" && a.Key != "" {
-					http.ListenAndServeTLS(a.Host+":"+a.Port, a.Cert, a.Key, nil)
-				} else {
-					log.Println("WARNING: API using insecure connection. " +
-						"We recommend using an SSL certificate with Gobot.")
-					http.ListenAndServe(a.Host+":"+a.Port, nil)
+		gobot:              g,
+		router:             pat.New(),
+		Port:               "3000",
+		WebSocketHeartbeat: 30 * time.Second,
+		WebSocketBuffer:    64,
+		users:              make(map[*http.Request]*User),
+		conns:              make(map[net.Conn]http.ConnState),
+		ShutdownTimeout:    5 * time.Second,
+		closing:            make(chan bool),
+		start: func(a *API) error {
+			listener, err := a.listen()
+			if err != nil {
+				return err
+			}
+			a.gobot.Logger().Log(gobot.InfoLevel, "Initializing API", gobot.Fields{"addr": listener.Addr().String()})
+
+			a.serverMutex.Lock()
+			defer a.serverMutex.Unlock()
+			a.listener = listener
+			a.server = &http.Server{Handler: a, ConnState: a.trackConn}
+			go func(server *http.Server) {
+				err := server.Serve(listener)
+				select {
+				case <-a.closing:
+				default:
+					a.gobot.Logger().Log(gobot.ErrorLevel, "API stopped", gobot.Fields{"error": err})
 				}
-			}()
+			}(a.server)
+			return nil
 		},
 	}
 }
@@ -62,6 +109,18 @@ func (a *API) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 			return
 		}
 	}
+	if len(a.authenticators) > 0 && req.Method != "OPTIONS" {
+		user := a.authenticate(req)
+		if user == nil {
+			res.Header().Set("WWW-Authenticate", "Bearer realm=\"Authorization Required\"")
+			http.Error(res, "Not Authorized", http.StatusUnauthorized)
+			return
+		}
+		// the user is forgotten once the request is served, even when a
+		// handler panics
+		a.setUser(req, user)
+		defer a.setUser(req, nil)
+	}
 	a.router.ServeHTTP(res, req)
 }
 
@@ -100,8 +159,119 @@ func (a *API) AddHandler(f func(http.ResponseWriter, *http.Request)) {
 	a.handlers = append(a.handlers, f)
 }
 
-// Start initializes the api by setting up c3pio routes and robeaux
-func (a *API) Start() {
+// Start initializes the api by setting up c3pio routes and robeaux, and
+// serves them with its own http.Server. Returns the error of listening on
+// Host and Port, or Socket. The api is stopped along with its Gobot.
+func (a *API) Start() (err error) {
+	a.Handler()
+	if err = a.start(a); err != nil {
+		a.gobot.Logger().Log(gobot.ErrorLevel, err.Error(), nil)
+		return
+	}
+	a.gobot.AddStopHandler(a.Stop)
+	return
+}
+
+// Stop stops serving the api. It closes the event streams and websockets, and
+// waits up to ShutdownTimeout for the other requests to complete. A stopped
+// api can not be started again.
+func (a *API) Stop() (err error) {
+	a.stop.Do(func() { close(a.closing) })
+
+	a.serverMutex.Lock()
+	defer a.serverMutex.Unlock()
+	if a.server == nil {
+		return
+	}
+	a.server.SetKeepAlivesEnabled(false)
+	err = a.listener.Close()
+	deadline := time.Now().Add(a.ShutdownTimeout)
+	for a.closeConns(false) > 0 && time.Now().Before(deadline) {
+		<-time.After(10 * time.Millisecond)
+	}
+	a.closeConns(true)
+	a.server = nil
+	return
+}
+
+// trackConn records the state of the connections of the server, which are
+// closed by Stop
+func (a *API) trackConn(conn net.Conn, state http.ConnState) {
+	a.connsMutex.Lock()
+	defer a.connsMutex.Unlock()
+	switch state {
+	case http.StateHijacked, http.StateClosed:
+		delete(a.conns, conn)
+	default:
+		a.conns[conn] = state
+	}
+}
+
+// closeConns closes the idle connections, or every connection when all is
+// true, and returns the number of connections left serving a request
+func (a *API) closeConns(all bool) (active int) {
+	a.connsMutex.Lock()
+	defer a.connsMutex.Unlock()
+	for conn, state := range a.conns {
+		if state == http.StateActive && !all {
+			active++
+			continue
+		}
+		conn.Close()
+		delete(a.conns, conn)
+	}
+	return
+}
+
+// Addr returns the address the api is listening on, or nil when it is not
+// started
+func (a *API) Addr() net.Addr {
+	a.serverMutex.Lock()
+	defer a.serverMutex.Unlock()
+	if a.server == nil {
+		return nil
+	}
+	return a.listener.Addr()
+}
+
+// listen returns a listener of Socket, or of Host and Port, which accepts TLS
+// connections when Cert and Key are set
+func (a *API) listen() (listener net.Listener, err error) {
+	if a.Socket != "" {
+		// remove the socket left by a previous process
+		if info, e := os.Stat(a.Socket); e == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(a.Socket)
+		}
+		listener, err = net.Listen("unix", a.Socket)
+	} else {
+		listener, err = net.Listen("tcp", net.JoinHostPort(a.Host, a.Port))
+	}
+	if err != nil {
+		return
+	}
+
+	if a.Cert != "" && a.Key != "" {
+		cert, e := tls.LoadX509KeyPair(a.Cert, a.Key)
+		if e != nil {
+			listener.Close()
+			return nil, e
+		}
+		listener = tls.NewListener(listener, &tls.Config{Certificates: []tls.Certificate{cert}})
+	} else {
+		a.gobot.Logger().Log(gobot.WarnLevel, "API using insecure connection. "+
+			"We recommend using an SSL certificate with Gobot.", nil)
+	}
+	return
+}
+
+// Handler sets up the c3pio routes and robeaux without starting the api, so it
+// can be served by another server such as an httptest.Server
+func (a *API) Handler() http.Handler {
+	a.routes.Do(a.setupRoutes)
+	return a
+}
+
+func (a *API) setupRoutes() {
 	mcpCommandRoute := "/api/commands/:command"
 	robotDeviceCommandRoute := "/api/robots/:robot/devices/:device/commands/:command"
 	robotCommandRoute := "/api/robots/:robot/commands/:command"
@@ -109,19 +279,27 @@ func (a *API) Start() {
 	a.Get("/api/commands", a.mcpCommands)
 	a.Get(mcpCommandRoute, a.executeMcpCommand)
 	a.Post(mcpCommandRoute, a.executeMcpCommand)
+	a.Get("/api/events", a.mcpEvents)
+	a.Get("/api/events/:event", a.mcpEvent)
 	a.Get("/api/robots", a.robots)
 	a.Get("/api/robots/:robot", a.robot)
 	a.Get("/api/robots/:robot/commands", a.robotCommands)
 	a.Get(robotCommandRoute, a.executeRobotCommand)
 	a.Post(robotCommandRoute, a.executeRobotCommand)
+	a.Get("/api/robots/:robot/events", a.robotEvents)
+	a.Get("/api/robots/:robot/events/:event", a.robotEvent)
 	a.Get("/api/robots/:robot/devices", a.robotDevices)
 	a.Get("/api/robots/:robot/devices/:device", a.robotDevice)
+	a.Get("/api/robots/:robot/devices/:device/events", a.robotDeviceEvents)
 	a.Get("/api/robots/:robot/devices/:device/events/:event", a.robotDeviceEvent)
 	a.Get("/api/robots/:robot/devices/:device/commands", a.robotDeviceCommands)
 	a.Get(robotDeviceCommandRoute, a.executeRobotDeviceCommand)
 	a.Post(robotDeviceCommandRoute, a.executeRobotDeviceCommand)
 	a.Get("/api/robots/:robot/connections", a.robotConnections)
 	a.Get("/api/robots/:robot/connections/:connection", a.robotConnection)
+	a.Get("/api/websocket", websocket.Handler(a.webSocket).ServeHTTP)
+	a.Get("/api/openapi.json", a.openAPI)
+	a.Get("/api/metrics", a.metrics)
 	a.Get("/api/", a.mcp)
 
 	a.Get("/", func(res http.ResponseWriter, req *http.Request) {
@@ -136,8 +314,6 @@ func (a *API) Start() {
 	a.Get("/css/:a/", a.robeaux)
 	a.Get("/css/:a/:b", a.robeaux)
 	a.Get("/partials/:a", a.robeaux)
-
-	a.start(a)
 }
 
 // robeaux returns handler for robeaux routes.
@@ -167,9 +343,22 @@ func (a *API) mcp(res http.ResponseWriter, req *http.Request) {
 }
 
 // mcpCommands returns commands route handler.
-// Writes JSON with global commands representation
+// Writes JSON with global commands and their schemas representation
 func (a *API) mcpCommands(res http.ResponseWriter, req *http.Request) {
-	a.writeJSON(map[string]interface{}{"commands": gobot.NewJSONGobot(a.gobot).Commands}, res)
+	jsonGobot := gobot.NewJSONGobot(a.gobot)
+	a.writeJSON(map[string]interface{}{"commands": jsonGobot.Commands, "schemas": jsonGobot.Schemas}, res)
+}
+
+// mcpEvents returns events route handler.
+// Writes JSON with global events representation
+func (a *API) mcpEvents(res http.ResponseWriter, req *http.Request) {
+	a.writeEvents("", "", res)
+}
+
+// mcpEvent returns event route handler.
+// Streams the global event data as server sent events
+func (a *API) mcpEvent(res http.ResponseWriter, req *http.Request) {
+	a.streamEvent("", "", req.URL.Query().Get(":event"), res)
 }
 
 // robots returns route handler.
@@ -193,15 +382,27 @@ func (a *API) robot(res http.ResponseWriter, req *http.Request) {
 }
 
 // robotCommands returns commands route handler
-// Writes JSON with robot commands representation
+// Writes JSON with robot commands and their schemas representation
 func (a *API) robotCommands(res http.ResponseWriter, req *http.Request) {
 	if robot, err := a.jsonRobotFor(req.URL.Query().Get(":robot")); err != nil {
 		a.writeJSON(map[string]interface{}{"error": err.Error()}, res)
 	} else {
-		a.writeJSON(map[string]interface{}{"commands": robot.Commands}, res)
+		a.writeJSON(map[string]interface{}{"commands": robot.Commands, "schemas": robot.Schemas}, res)
 	}
 }
 
+// robotEvents returns events route handler.
+// Writes JSON with robot events representation
+func (a *API) robotEvents(res http.ResponseWriter, req *http.Request) {
+	a.writeEvents(req.URL.Query().Get(":robot"), "", res)
+}
+
+// robotEvent returns event route handler.
+// Streams the robot event data as server sent events
+func (a *API) robotEvent(res http.ResponseWriter, req *http.Request) {
+	a.streamEvent(req.URL.Query().Get(":robot"), "", req.URL.Query().Get(":event"), res)
+}
+
 // robotDevices returns devices route handler.
 // Writes JSON with robot devices representation
 func (a *API) robotDevices(res http.ResponseWriter, req *http.Request) {
@@ -226,7 +427,42 @@ func (a *API) robotDevice(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// robotDeviceEvents returns device events route handler.
+// Writes JSON with robot device events representation
+func (a *API) robotDeviceEvents(res http.ResponseWriter, req *http.Request) {
+	a.writeEvents(req.URL.Query().Get(":robot"), req.URL.Query().Get(":device"), res)
+}
+
+// robotDeviceEvent returns device event route handler.
+// Streams the robot device event data as server sent events
 func (a *API) robotDeviceEvent(res http.ResponseWriter, req *http.Request) {
+	a.streamEvent(req.URL.Query().Get(":robot"), req.URL.Query().Get(":device"), req.URL.Query().Get(":event"), res)
+}
+
+// writeEvents writes JSON with the names of the events of a device of a
+// robot, of a robot when device is empty, or of the gobot when robot is empty
+func (a *API) writeEvents(robot string, device string, res http.ResponseWriter) {
+	if eventer, err := a.eventerFor(robot, device); err != nil {
+		a.writeJSON(map[string]interface{}{"error": err.Error()}, res)
+	} else {
+		events := []string{}
+		for name := range eventer.Events() {
+			events = append(events, name)
+		}
+		sort.Strings(events)
+		a.writeJSON(map[string]interface{}{"events": events}, res)
+	}
+}
+
+// streamEvent streams the data of an event as server sent events until the
+// client disconnects
+func (a *API) streamEvent(robot string, device string, name string, res http.ResponseWriter) {
+	event, err := a.eventFor(robot, device, name)
+	if err != nil {
+		a.writeJSON(map[string]interface{}{"error": err.Error()}, res)
+		return
+	}
+
 	f, _ := res.(http.Flusher)
 	c, _ := res.(http.CloseNotifier)
 
@@ -237,50 +473,49 @@ func (a *API) robotDeviceEvent(res http.ResponseWriter, req *http.Request) {
 	res.Header().Set("Cache-Control", "no-cache")
 	res.Header().Set("Connection", "keep-alive")
 
-	if event := a.gobot.Robot(req.URL.Query().Get(":robot")).
-		Device(req.URL.Query().Get(":device")).(gobot.Eventer).
-		Event(req.URL.Query().Get(":event")); event != nil {
-		gobot.On(event, func(data interface{}) {
-			d, _ := json.Marshal(data)
-			dataChan <- string(d)
-		})
-
-		for {
-			select {
-			case data := <-dataChan:
-				fmt.Fprintf(res, "data: %v\n\n", data)
-				f.Flush()
-			case <-closer:
-				log.Println("Closing connection")
-				return
-			}
+	done := make(chan bool)
+	defer close(done)
+	// send the headers before the first event
+	f.Flush()
+	unsubscribe, _ := gobot.Subscribe(event, func(data interface{}) {
+		d, _ := json.Marshal(data)
+		select {
+		case dataChan <- string(d):
+		case <-done:
+		}
+	})
+	defer unsubscribe()
+
+	for {
+		select {
+		case data := <-dataChan:
+			fmt.Fprintf(res, "data: %v\n\n", data)
+			f.Flush()
+		case <-closer:
+			a.gobot.Logger().Log(gobot.DebugLevel, "Closing event stream", gobot.Fields{
+				"robot": robot, "device": device, "event": name})
+			return
+		case <-a.closing:
+			return
 		}
-	} else {
-		a.writeJSON(map[string]interface{}{
-			"error": "No Event found with the name " + req.URL.Query().Get(":event"),
-		}, res)
 	}
 }
 
 // robotDeviceCommands returns device commands route handler
-// writes JSON with robot device commands representation
+// writes JSON with robot device commands and their schemas representation
 func (a *API) robotDeviceCommands(res http.ResponseWriter, req *http.Request) {
 	if device, err := a.jsonDeviceFor(req.URL.Query().Get(":robot"), req.URL.Query().Get(":device")); err != nil {
 		a.writeJSON(map[string]interface{}{"error": err.Error()}, res)
 	} else {
-		a.writeJSON(map[string]interface{}{"commands": device.Commands}, res)
+		a.writeJSON(map[string]interface{}{"commands": device.Commands, "schemas": device.Schemas}, res)
 	}
 }
 
 // robotConnections returns connections route handler
 // writes JSON with robot connections representation
 func (a *API) robotConnections(res http.ResponseWriter, req *http.Request) {
-	jsonConnections := []*gobot.JSONConnection{}
 	if robot := a.gobot.Robot(req.URL.Query().Get(":robot")); robot != nil {
-		robot.Connections().Each(func(c gobot.Connection) {
-			jsonConnections = append(jsonConnections, gobot.NewJSONConnection(c))
-		})
-		a.writeJSON(map[string]interface{}{"connections": jsonConnections}, res)
+		a.writeJSON(map[string]interface{}{"connections": gobot.NewJSONRobot(robot).Connections}, res)
 	} else {
 		a.writeJSON(map[string]interface{}{"error": "No Robot found with the name " + req.URL.Query().Get(":robot")}, res)
 	}
@@ -299,44 +534,34 @@ func (a *API) robotConnection(res http.ResponseWriter, req *http.Request) {
 
 // executeMcpCommand calls a global command associated to requested route
 func (a *API) executeMcpCommand(res http.ResponseWriter, req *http.Request) {
-	a.executeCommand(a.gobot.Command(req.URL.Query().Get(":command")),
-		res,
-		req,
-	)
+	a.executeCommand("", "", req.URL.Query().Get(":command"), res, req)
 }
 
 // executeRobotDeviceCommand calls a device command associated to requested route
 func (a *API) executeRobotDeviceCommand(res http.ResponseWriter, req *http.Request) {
-	if _, err := a.jsonDeviceFor(req.URL.Query().Get(":robot"),
-		req.URL.Query().Get(":device")); err != nil {
-		a.writeJSON(map[string]interface{}{"error": err.Error()}, res)
-	} else {
-		a.executeCommand(
-			a.gobot.Robot(req.URL.Query().Get(":robot")).
-				Device(req.URL.Query().Get(":device")).(gobot.Commander).
-				Command(req.URL.Query().Get(":command")),
-			res,
-			req,
-		)
-	}
+	a.executeCommand(req.URL.Query().Get(":robot"),
+		req.URL.Query().Get(":device"),
+		req.URL.Query().Get(":command"),
+		res,
+		req,
+	)
 }
 
 // executeRobotCommand calls a robot command associated to requested route
 func (a *API) executeRobotCommand(res http.ResponseWriter, req *http.Request) {
-	if _, err := a.jsonRobotFor(req.URL.Query().Get(":robot")); err != nil {
-		a.writeJSON(map[string]interface{}{"error": err.Error()}, res)
-	} else {
-		a.executeCommand(
-			a.gobot.Robot(req.URL.Query().Get(":robot")).
-				Command(req.URL.Query().Get(":command")),
-			res,
-			req,
-		)
-	}
+	a.executeCommand(req.URL.Query().Get(":robot"),
+		"",
+		req.URL.Query().Get(":command"),
+		res,
+		req,
+	)
 }
 
-// executeCommand writes JSON response with `f` returned value.
-func (a *API) executeCommand(f func(map[string]interface{}) interface{},
+// executeCommand writes JSON response with the command returned value.
+// Invalid params are reported as a structured error with a 400 status,
+// commands the user is not allowed to execute with a 403 status, and the
+// errors returned by commands with a 500 status.
+func (a *API) executeCommand(robot string, device string, name string,
 	res http.ResponseWriter,
 	req *http.Request,
 ) {
@@ -344,10 +569,76 @@ func (a *API) executeCommand(f func(map[string]interface{}) interface{},
 	body := make(map[string]interface{})
 	json.NewDecoder(req.Body).Decode(&body)
 
-	if f != nil {
-		a.writeJSON(map[string]interface{}{"result": f(body)}, res)
-	} else {
-		a.writeJSON(map[string]interface{}{"error": "Unknown Command"}, res)
+	result, err := a.invoke(a.user(req), robot, device, name, body)
+	switch err.(type) {
+	case nil:
+		a.writeJSON(map[string]interface{}{"result": result}, res)
+	case *gobot.CommandError:
+		a.writeJSONStatus(map[string]interface{}{"error": err}, http.StatusBadRequest, res)
+	case commandFailure:
+		a.writeJSONStatus(map[string]interface{}{"error": err.Error()}, http.StatusInternalServerError, res)
+	default:
+		if err == ErrForbidden {
+			a.writeJSONStatus(map[string]interface{}{"error": err.Error()}, http.StatusForbidden, res)
+			return
+		}
+		a.writeJSON(map[string]interface{}{"error": err.Error()}, res)
+	}
+}
+
+// commandFailure is the error returned by a command, as opposed to an error
+// finding or authorizing it
+type commandFailure struct {
+	error
+}
+
+// invoke executes a command of a device of a robot, of a robot when device is
+// empty, or of the gobot when robot is empty on behalf of user, and records it
+// with Audit. Returns ErrForbidden when the role of user does not allow it,
+// the *gobot.CommandError of invalid params, or a commandFailure when the
+// command returns an error.
+func (a *API) invoke(user *User, robot string, device string, name string,
+	params map[string]interface{},
+) (result interface{}, err error) {
+	f, err := a.commandFor(robot, device, name)
+	if err == nil && user != nil && !user.CanExecute() {
+		err = ErrForbidden
+	}
+	if err == nil {
+		result = f(params)
+		switch e := result.(type) {
+		case *gobot.CommandError:
+			result, err = nil, e
+		case error:
+			result, err = nil, commandFailure{e}
+		}
+	}
+
+	if a.Audit != nil {
+		entry := AuditEntry{
+			Time:    time.Now(),
+			Robot:   robot,
+			Device:  device,
+			Command: name,
+			Params:  params,
+		}
+		if user != nil {
+			entry.User, entry.Role = user.Name, user.Role
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		a.Audit(entry)
+	}
+	return
+}
+
+// metrics returns metrics route handler.
+// Writes the metrics of the gobot in the Prometheus text exposition format
+func (a *API) metrics(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := a.gobot.WriteMetrics(res); err != nil {
+		a.gobot.Logger().Log(gobot.ErrorLevel, "Writing metrics failed", gobot.Fields{"error": err})
 	}
 }
 
@@ -358,10 +649,22 @@ func (a *API) writeJSON(j interface{}, res http.ResponseWriter) {
 	res.Write(data)
 }
 
-// Debug add handler to api that prints each request
+// writeJSONStatus writes `j` as JSON in response with status
+func (a *API) writeJSONStatus(j interface{}, status int, res http.ResponseWriter) {
+	data, _ := json.Marshal(j)
+	res.Header().Set("Content-Type", "application/json; charset=utf-8")
+	res.WriteHeader(status)
+	res.Write(data)
+}
+
+// Debug add handler to api that logs each request to the Logger of the gobot
 func (a *API) Debug() {
 	a.AddHandler(func(res http.ResponseWriter, req *http.Request) {
-		log.Println(req)
+		a.gobot.Logger().Log(gobot.InfoLevel, "API request", gobot.Fields{
+			"method": req.Method,
+			"path":   req.URL.Path,
+			"remote": req.RemoteAddr,
+		})
 	})
 }
 
@@ -384,10 +687,68 @@ func (a *API) jsonDeviceFor(robot string, name string) (jdevice *gobot.JSONDevic
 }
 
 func (a *API) jsonConnectionFor(robot string, name string) (jconnection *gobot.JSONConnection, err error) {
-	if connection := a.gobot.Robot(robot).Connection(name); connection != nil {
-		jconnection = gobot.NewJSONConnection(connection)
-	} else {
-		err = errors.New("No Connection found with the name " + name)
+	if r := a.gobot.Robot(robot); r != nil {
+		for _, connection := range gobot.NewJSONRobot(r).Connections {
+			if connection.Name == name {
+				return connection, nil
+			}
+		}
+	}
+	return nil, errors.New("No Connection found with the name " + name)
+}
+
+// eventerFor returns a device of a robot given their names, a robot when
+// device is empty, or the gobot when robot is empty
+func (a *API) eventerFor(robot string, device string) (gobot.Eventer, error) {
+	if robot == "" {
+		return a.gobot, nil
+	}
+	r := a.gobot.Robot(robot)
+	if r == nil {
+		return nil, errors.New("No Robot found with the name " + robot)
+	}
+	if device == "" {
+		return r, nil
+	}
+	d := r.Device(device)
+	if d == nil {
+		return nil, errors.New("No Device found with the name " + device)
+	}
+	if eventer, ok := d.(gobot.Eventer); ok {
+		return eventer, nil
+	}
+	return gobot.NewEventer(), nil
+}
+
+// eventFor returns an event of a device of a robot, of a robot when device is
+// empty, or of the gobot when robot is empty
+func (a *API) eventFor(robot string, device string, name string) (*gobot.Event, error) {
+	eventer, err := a.eventerFor(robot, device)
+	if err != nil {
+		return nil, err
+	}
+	if event := eventer.Event(name); event != nil {
+		return event, nil
+	}
+	return nil, errors.New("No Event found with the name " + name)
+}
+
+// commandFor returns a command of a device of a robot, of a robot when device
+// is empty, or of the gobot when robot is empty
+func (a *API) commandFor(robot string, device string, name string) (f func(map[string]interface{}) interface{}, err error) {
+	if robot == "" {
+		f = a.gobot.Command(name)
+	} else if r := a.gobot.Robot(robot); r == nil {
+		return nil, errors.New("No Robot found with the name " + robot)
+	} else if device == "" {
+		f = r.Command(name)
+	} else if d := r.Device(device); d == nil {
+		return nil, errors.New("No Device found with the name " + device)
+	} else if commander, ok := d.(gobot.Commander); ok {
+		f = commander.Command(name)
+	}
+	if f == nil {
+		err = errors.New("Unknown Command")
 	}
 	return
 }
@@ -4,10 +4,16 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,7 +25,7 @@ func initTestAPI() *API {
 	log.SetOutput(NullReadWriteCloser{})
 	g := gobot.NewGobot()
 	a := NewAPI(g)
-	a.start = func(m *API) {}
+	a.start = func(m *API) error { return nil }
 	a.Start()
 	a.Debug()
 
@@ -90,6 +96,72 @@ func TestMcpCommands(t *testing.T) {
 	var body map[string]interface{}
 	json.NewDecoder(response.Body).Decode(&body)
 	gobottest.Assert(t, body["commands"], []interface{}{"TestFunction"})
+	gobottest.Assert(t, body["schemas"], []interface{}{
+		map[string]interface{}{"name": "TestFunction", "params": []interface{}{}},
+	})
+}
+
+func TestExecuteTypedCommand(t *testing.T) {
+	var body map[string]interface{}
+	a := initTestAPI()
+	a.gobot.AddCommandSchema(gobot.CommandSchema{
+		Name: "TypedFunction",
+		Params: []gobot.CommandParam{
+			{Name: "level", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 10}},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return params["level"].(int) * 2
+	})
+
+	// schema
+	request, _ := http.NewRequest("GET", "/api/commands", nil)
+	response := httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, body["schemas"].([]interface{})[1], map[string]interface{}{
+		"name": "TypedFunction",
+		"params": []interface{}{
+			map[string]interface{}{
+				"name":     "level",
+				"type":     "integer",
+				"required": true,
+				"range":    map[string]interface{}{"min": 0.0, "max": 10.0},
+			},
+		},
+	})
+
+	// valid params
+	request, _ = http.NewRequest("POST",
+		"/api/commands/TypedFunction",
+		bytes.NewBufferString(`{"level":4}`),
+	)
+	request.Header.Add("Content-Type", "application/json")
+	response = httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+
+	body = nil
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, response.Code, http.StatusOK)
+	gobottest.Assert(t, body["result"], 8.0)
+
+	// invalid params
+	request, _ = http.NewRequest("POST",
+		"/api/commands/TypedFunction",
+		bytes.NewBufferString(`{"level":"loud"}`),
+	)
+	request.Header.Add("Content-Type", "application/json")
+	response = httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+
+	body = nil
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, response.Code, http.StatusBadRequest)
+	gobottest.Assert(t, body["error"], map[string]interface{}{
+		"command": "TypedFunction",
+		"param":   "level",
+		"message": "must be of type integer, got loud",
+	})
 }
 
 func TestExecuteMcpCommand(t *testing.T) {
@@ -231,6 +303,29 @@ func TestExecuteRobotCommand(t *testing.T) {
 	gobottest.Assert(t, body.(map[string]interface{})["error"], "No Robot found with the name UnknownRobot1")
 }
 
+func TestExecuteFailingCommand(t *testing.T) {
+	var body map[string]interface{}
+	audit := &bytes.Buffer{}
+	a := initTestAPI()
+	a.Audit = AuditLog(audit)
+	a.gobot.Robot("Robot1").AddCommand("fail", func(params map[string]interface{}) interface{} {
+		return errors.New("command failed")
+	})
+
+	// the errors returned by commands are not results
+	request, _ := http.NewRequest("POST", "/api/robots/Robot1/commands/fail", bytes.NewBufferString("{}"))
+	response := httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 500)
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, body, map[string]interface{}{"error": "command failed"})
+
+	entry := AuditEntry{}
+	json.Unmarshal(audit.Bytes(), &entry)
+	gobottest.Assert(t, entry.Command, "fail")
+	gobottest.Assert(t, entry.Error, "command failed")
+}
+
 func TestRobotDevice(t *testing.T) {
 	a := initTestAPI()
 
@@ -354,7 +449,13 @@ func TestRobotConnection(t *testing.T) {
 
 	var body map[string]interface{}
 	json.NewDecoder(response.Body).Decode(&body)
-	gobottest.Assert(t, body["connection"].(map[string]interface{})["name"].(string), "Connection1")
+	gobottest.Assert(t, body["connection"], map[string]interface{}{
+		"name":      "Connection1",
+		"adaptor":   "*api.testAdaptor",
+		"port":      "/dev/null",
+		"connected": false,
+		"pins":      []interface{}{"0"},
+	})
 
 	// unknown connection
 	request, _ = http.NewRequest("GET",
@@ -415,6 +516,70 @@ func TestRobotDeviceEvent(t *testing.T) {
 	gobottest.Assert(t, body["error"], "No Event found with the name UnknownEvent")
 }
 
+func TestEvents(t *testing.T) {
+	a := initTestAPI()
+	a.gobot.AddEvent("GobotEvent")
+	a.gobot.Robot("Robot1").AddEvent("RobotEvent")
+
+	var body map[string]interface{}
+	for url, events := range map[string]interface{}{
+		"/api/events":                               []interface{}{"GobotEvent"},
+		"/api/robots/Robot1/events":                 []interface{}{"RobotEvent"},
+		"/api/robots/Robot2/events":                 []interface{}{},
+		"/api/robots/Robot1/devices/Device1/events": []interface{}{"TestEvent"},
+	} {
+		request, _ := http.NewRequest("GET", url, nil)
+		response := httptest.NewRecorder()
+		a.ServeHTTP(response, request)
+		body = map[string]interface{}{}
+		json.NewDecoder(response.Body).Decode(&body)
+		gobottest.Assert(t, body["events"], events)
+	}
+
+	for url, err := range map[string]string{
+		"/api/robots/UnknownRobot1/events":                 "No Robot found with the name UnknownRobot1",
+		"/api/robots/Robot1/devices/UnknownDevice1/events": "No Device found with the name UnknownDevice1",
+	} {
+		request, _ := http.NewRequest("GET", url, nil)
+		response := httptest.NewRecorder()
+		a.ServeHTTP(response, request)
+		body = map[string]interface{}{}
+		json.NewDecoder(response.Body).Decode(&body)
+		gobottest.Assert(t, body["error"], err)
+	}
+}
+
+func TestRobotAndMcpEvent(t *testing.T) {
+	a := initTestAPI()
+	a.gobot.AddEvent("GobotEvent")
+	a.gobot.Robot("Robot1").AddEvent("RobotEvent")
+	server := httptest.NewServer(a)
+	defer server.Close()
+
+	for url, event := range map[string]*gobot.Event{
+		"/api/events/GobotEvent":               a.gobot.Event("GobotEvent"),
+		"/api/robots/Robot1/events/RobotEvent": a.gobot.Robot("Robot1").Event("RobotEvent"),
+	} {
+		resp, err := http.Get(server.URL + url)
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, resp.Header.Get("Content-Type"), "text/event-stream")
+
+		gobot.Publish(event, "event-data")
+		data, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		gobottest.Assert(t, data, "data: \"event-data\"\n")
+		resp.Body.Close()
+	}
+
+	response, _ := http.Get(server.URL + "/api/robots/UnknownRobot1/events/RobotEvent")
+	var body map[string]interface{}
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, body["error"], "No Robot found with the name UnknownRobot1")
+
+	response, _ = http.Get(server.URL + "/api/events/UnknownEvent")
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, body["error"], "No Event found with the name UnknownEvent")
+}
+
 func TestAPIRouter(t *testing.T) {
 	a := initTestAPI()
 
@@ -454,3 +619,124 @@ func TestAPIRouter(t *testing.T) {
 	a.ServeHTTP(response, request)
 	gobottest.Assert(t, response.Code, 200)
 }
+
+func TestAPIStartStop(t *testing.T) {
+	log.SetOutput(NullReadWriteCloser{})
+	g := gobot.NewGobot()
+	g.AddRobot(newTestRobot("Robot1"))
+	a := NewAPI(g)
+	a.Host = "127.0.0.1"
+	a.Port = "0"
+	gobottest.Assert(t, a.Addr(), nil)
+	gobottest.Assert(t, a.Start(), nil)
+
+	url := "http://" + a.Addr().String()
+	response, err := http.Get(url + "/api/robots")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, response.StatusCode, 200)
+
+	// event streams are closed by Stop
+	stream, err := http.Get(url + "/api/robots/Robot1/devices/Device1/events/TestEvent")
+	gobottest.Assert(t, err, nil)
+
+	// another api can not listen on the same address
+	b := NewAPI(g)
+	b.Host = "127.0.0.1"
+	b.Port = strings.Split(a.Addr().String(), ":")[1]
+	gobottest.Refute(t, b.Start(), nil)
+
+	// the api is stopped with the gobot
+	gobottest.Assert(t, len(g.Stop()), 0)
+	gobottest.Assert(t, a.Addr(), nil)
+	_, err = ioutil.ReadAll(stream.Body)
+	gobottest.Assert(t, err, nil)
+	_, err = http.Get(url + "/api/robots")
+	gobottest.Refute(t, err, nil)
+	gobottest.Assert(t, a.Stop(), nil)
+}
+
+func TestAPIStopWaitsForRequests(t *testing.T) {
+	log.SetOutput(NullReadWriteCloser{})
+	a := NewAPI(gobot.NewGobot())
+	a.Host = "127.0.0.1"
+	a.Port = "0"
+	a.ShutdownTimeout = 100 * time.Millisecond
+	a.Get("/slow", func(res http.ResponseWriter, req *http.Request) {
+		<-time.After(50 * time.Millisecond)
+		res.Write([]byte("done"))
+	})
+	a.Get("/stuck", func(res http.ResponseWriter, req *http.Request) {
+		<-time.After(1 * time.Second)
+	})
+	gobottest.Assert(t, a.Start(), nil)
+	url := "http://" + a.Addr().String()
+
+	slow := make(chan error, 1)
+	stuck := make(chan error, 1)
+	get := func(path string, ret chan error) {
+		response, err := http.Get(url + path)
+		if err == nil {
+			_, err = ioutil.ReadAll(response.Body)
+			response.Body.Close()
+		}
+		ret <- err
+	}
+	go get("/slow", slow)
+	go get("/stuck", stuck)
+	<-time.After(20 * time.Millisecond)
+
+	start := time.Now()
+	gobottest.Assert(t, a.Stop(), nil)
+	gobottest.Assert(t, time.Since(start) < 1*time.Second, true)
+
+	// the requests completing within ShutdownTimeout are served, the others
+	// are cut
+	gobottest.Assert(t, <-slow, nil)
+	gobottest.Refute(t, <-stuck, nil)
+}
+
+func TestAPIUnixSocket(t *testing.T) {
+	log.SetOutput(NullReadWriteCloser{})
+	dir, _ := ioutil.TempDir("", "gobot")
+	defer os.RemoveAll(dir)
+
+	a := NewAPI(gobot.NewGobot())
+	a.Socket = filepath.Join(dir, "api.sock")
+	gobottest.Assert(t, a.Start(), nil)
+
+	client := &http.Client{Transport: &http.Transport{
+		Dial: func(network, addr string) (net.Conn, error) {
+			return net.Dial("unix", a.Socket)
+		},
+	}}
+	response, err := client.Get("http://gobot/api/robots")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, response.StatusCode, 200)
+	response.Body.Close()
+	gobottest.Assert(t, a.Stop(), nil)
+}
+
+func TestMetrics(t *testing.T) {
+	a := initTestAPI()
+	a.gobot.Robot("Robot1").AddEvent("moved")
+	gobot.Publish(a.gobot.Robot("Robot1").Event("moved"), nil)
+
+	request, _ := http.NewRequest("POST", "/api/robots/Robot1/commands/robotTestFunction",
+		bytes.NewBufferString(`{"message":"Beep Boop", "robot":"Robot1"}`))
+	request.Header.Add("Content-Type", "application/json")
+	a.ServeHTTP(httptest.NewRecorder(), request)
+
+	request, _ = http.NewRequest("GET", "/api/metrics", nil)
+	response := httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 200)
+	gobottest.Assert(t, response.HeaderMap.Get("Content-Type"), "text/plain; version=0.0.4; charset=utf-8")
+
+	body := response.Body.String()
+	gobottest.Assert(t, strings.Contains(body,
+		`gobot_events_total{robot="Robot1",device="",event="moved"} 1`+"\n"), true)
+	gobottest.Assert(t, strings.Contains(body,
+		`gobot_commands_total{robot="Robot1",device="",command="robotTestFunction"} 1`+"\n"), true)
+	// commands which were never invoked have no metrics
+	gobottest.Assert(t, strings.Contains(body, `command="TestFunction"`), false)
+}
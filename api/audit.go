@@ -0,0 +1,35 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditEntry records a command executed through the api
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// User is the name of the user who executed the command, it is empty when
+	// the api does not authenticate requests
+	User    string                 `json:"user"`
+	Role    Role                   `json:"role,omitempty"`
+	Robot   string                 `json:"robot,omitempty"`
+	Device  string                 `json:"device,omitempty"`
+	Command string                 `json:"command"`
+	Params  map[string]interface{} `json:"params"`
+	// Error is the reason the command failed or was refused
+	Error string `json:"error,omitempty"`
+}
+
+// AuditLog returns an audit function which writes every entry to w as a line
+// of JSON, to be set as the Audit of an API.
+func AuditLog(w io.Writer) func(AuditEntry) {
+	var mutex sync.Mutex
+	return func(entry AuditEntry) {
+		data, _ := json.Marshal(entry)
+		mutex.Lock()
+		defer mutex.Unlock()
+		w.Write(append(data, '\n'))
+	}
+}
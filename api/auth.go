@@ -0,0 +1,188 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrForbidden is the error of a command executed by a user whose role does
+// not allow it
+var ErrForbidden = errors.New("Forbidden")
+
+// Role is the role of an api user, which controls what the user can do
+type Role string
+
+const (
+	// ReaderRole users can read the state of the robots and stream events
+	ReaderRole Role = "reader"
+	// OperatorRole users can also execute commands
+	OperatorRole Role = "operator"
+)
+
+// User is an authenticated user of the api
+type User struct {
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// CanExecute returns true if the role of the user allows executing commands
+func (u *User) CanExecute() bool {
+	return u.Role == OperatorRole
+}
+
+// Authenticator returns the user who made a request, or nil if the request
+// is not authenticated by it
+type Authenticator func(req *http.Request) *User
+
+// Authenticate requires every request, except CORS preflight requests, to be
+// authenticated by one of the authenticators, and only allows the users with
+// the OperatorRole to execute commands.
+func (a *API) Authenticate(authenticators ...Authenticator) {
+	a.authenticators = append(a.authenticators, authenticators...)
+}
+
+// authenticate returns the user who made req
+func (a *API) authenticate(req *http.Request) *User {
+	for _, authenticator := range a.authenticators {
+		if user := authenticator(req); user != nil {
+			return user
+		}
+	}
+	return nil
+}
+
+// user returns the user who made the request being served, or nil when the
+// api does not authenticate requests
+func (a *API) user(req *http.Request) *User {
+	a.usersMutex.Lock()
+	defer a.usersMutex.Unlock()
+	return a.users[req]
+}
+
+// setUser sets the user who made the request being served, or forgets it
+// when user is nil
+func (a *API) setUser(req *http.Request, user *User) {
+	a.usersMutex.Lock()
+	defer a.usersMutex.Unlock()
+	if user == nil {
+		delete(a.users, req)
+		return
+	}
+	a.users[req] = user
+}
+
+// TokenAuth returns an Authenticator of the users given their api token,
+// sent as a bearer token in the Authorization header or as the access_token
+// query parameter by clients which can not set headers, such as browsers
+// streaming events.
+func TokenAuth(tokens map[string]User) Authenticator {
+	return func(req *http.Request) *User {
+		given := bearerToken(req)
+		if given == "" {
+			return nil
+		}
+		for token, user := range tokens {
+			if secureCompare(given, token) {
+				u := user
+				return &u
+			}
+		}
+		return nil
+	}
+}
+
+// JWTAuth returns an Authenticator of the users given a JSON Web Token signed
+// with secret using HS256, sent like the tokens of TokenAuth. The name of the
+// user is the "sub" claim and its role the "role" claim, the token is refused
+// after the time of its "exp" claim.
+func JWTAuth(secret []byte) Authenticator {
+	return func(req *http.Request) *User {
+		user, err := ParseJWT(secret, bearerToken(req))
+		if err != nil {
+			return nil
+		}
+		return user
+	}
+}
+
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	Role      Role   `json:"role"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// SignJWT returns a JSON Web Token of the user signed with secret using HS256,
+// which expires at expires unless it is zero.
+func SignJWT(secret []byte, user User, expires time.Time) string {
+	claims := jwtClaims{Subject: user.Name, Role: user.Role}
+	if !expires.IsZero() {
+		claims.ExpiresAt = expires.Unix()
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	token := jwtEncode(header) + "." + jwtEncode(payload)
+	return token + "." + jwtEncode(jwtSignature(secret, token))
+}
+
+// ParseJWT returns the user of a JSON Web Token signed with secret using
+// HS256, or an error if the token is invalid or expired.
+func ParseJWT(secret []byte, token string) (*User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("Invalid token")
+	}
+
+	header := map[string]string{}
+	if data, err := jwtDecode(parts[0]); err != nil || json.Unmarshal(data, &header) != nil {
+		return nil, errors.New("Invalid token header")
+	}
+	if header["alg"] != "HS256" {
+		return nil, errors.New("Unsupported token algorithm " + header["alg"])
+	}
+
+	signature, err := jwtDecode(parts[2])
+	if err != nil || !hmac.Equal(signature, jwtSignature(secret, parts[0]+"."+parts[1])) {
+		return nil, errors.New("Invalid token signature")
+	}
+
+	claims := jwtClaims{}
+	if data, err := jwtDecode(parts[1]); err != nil || json.Unmarshal(data, &claims) != nil {
+		return nil, errors.New("Invalid token claims")
+	}
+	if claims.ExpiresAt != 0 && time.Now().Unix() >= claims.ExpiresAt {
+		return nil, errors.New("Token expired")
+	}
+	return &User{Name: claims.Subject, Role: claims.Role}, nil
+}
+
+func jwtSignature(secret []byte, data string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func jwtEncode(data []byte) string {
+	return strings.TrimRight(base64.URLEncoding.EncodeToString(data), "=")
+}
+
+func jwtDecode(s string) ([]byte, error) {
+	if m := len(s) % 4; m != 0 {
+		s += strings.Repeat("=", 4-m)
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
+
+// bearerToken returns the bearer token of the Authorization header, or of
+// the access_token query parameter
+func bearerToken(req *http.Request) string {
+	if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(auth[len("Bearer "):])
+	}
+	return req.URL.Query().Get("access_token")
+}
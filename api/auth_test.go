@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot/gobottest"
+	"golang.org/x/net/websocket"
+)
+
+func TestTokenAuth(t *testing.T) {
+	a := initTestAPI()
+	a.Authenticate(TokenAuth(map[string]User{
+		"reader-token":   {Name: "alice", Role: ReaderRole},
+		"operator-token": {Name: "bob", Role: OperatorRole},
+	}))
+
+	request, _ := http.NewRequest("GET", "/api/", nil)
+	response := httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 401)
+	gobottest.Assert(t, response.Header().Get("WWW-Authenticate"), "Bearer realm=\"Authorization Required\"")
+
+	request, _ = http.NewRequest("GET", "/api/", nil)
+	request.Header.Set("Authorization", "Bearer wrong-token")
+	response = httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 401)
+
+	request, _ = http.NewRequest("GET", "/api/", nil)
+	request.Header.Set("Authorization", "Bearer reader-token")
+	response = httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 200)
+
+	request, _ = http.NewRequest("GET", "/api/robots?access_token=reader-token", nil)
+	response = httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 200)
+
+	// preflight requests are not authenticated
+	request, _ = http.NewRequest("OPTIONS", "/api/", nil)
+	response = httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Refute(t, response.Code, 401)
+	gobottest.Assert(t, len(a.users), 0)
+}
+
+func TestAuthUserForgottenOnPanic(t *testing.T) {
+	a := initTestAPI()
+	a.Authenticate(TokenAuth(map[string]User{
+		"reader-token": {Name: "alice", Role: ReaderRole},
+	}))
+	var user *User
+	a.Get("/panic", func(res http.ResponseWriter, req *http.Request) {
+		user = a.user(req)
+		panic("handler panic")
+	})
+
+	request, _ := http.NewRequest("GET", "/panic", nil)
+	request.Header.Set("Authorization", "Bearer reader-token")
+	func() {
+		defer func() { recover() }()
+		a.ServeHTTP(httptest.NewRecorder(), request)
+	}()
+	gobottest.Assert(t, user.Name, "alice")
+	gobottest.Assert(t, len(a.users), 0)
+}
+
+func TestAuthRoles(t *testing.T) {
+	var body map[string]interface{}
+	audit := &bytes.Buffer{}
+	a := initTestAPI()
+	a.Audit = AuditLog(audit)
+	a.Authenticate(TokenAuth(map[string]User{
+		"reader-token":   {Name: "alice", Role: ReaderRole},
+		"operator-token": {Name: "bob", Role: OperatorRole},
+	}))
+
+	request, _ := http.NewRequest("POST",
+		"/api/robots/Robot1/commands/robotTestFunction",
+		bytes.NewBufferString(`{"message":"Beep Boop", "robot":"Robot1"}`),
+	)
+	request.Header.Set("Authorization", "Bearer reader-token")
+	response := httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 403)
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, body["error"], "Forbidden")
+
+	request, _ = http.NewRequest("POST",
+		"/api/robots/Robot1/commands/robotTestFunction",
+		bytes.NewBufferString(`{"message":"Beep Boop", "robot":"Robot1"}`),
+	)
+	request.Header.Set("Authorization", "Bearer operator-token")
+	response = httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 200)
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, body["result"], "hey Robot1, Beep Boop")
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	gobottest.Assert(t, len(lines), 2)
+
+	entry := AuditEntry{}
+	json.Unmarshal([]byte(lines[0]), &entry)
+	gobottest.Assert(t, entry.User, "alice")
+	gobottest.Assert(t, entry.Role, ReaderRole)
+	gobottest.Assert(t, entry.Robot, "Robot1")
+	gobottest.Assert(t, entry.Command, "robotTestFunction")
+	gobottest.Assert(t, entry.Error, "Forbidden")
+
+	entry = AuditEntry{}
+	json.Unmarshal([]byte(lines[1]), &entry)
+	gobottest.Assert(t, entry.User, "bob")
+	gobottest.Assert(t, entry.Params["message"], "Beep Boop")
+	gobottest.Assert(t, entry.Error, "")
+}
+
+func TestAuthWebSocket(t *testing.T) {
+	a := initTestAPI()
+	secret := []byte("secret")
+	a.Authenticate(JWTAuth(secret))
+	server := httptest.NewServer(a)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/websocket"
+	_, err := websocket.Dial(url, "", server.URL)
+	gobottest.Refute(t, err, nil)
+
+	token := SignJWT(secret, User{Name: "alice", Role: ReaderRole}, time.Time{})
+	ws, err := websocket.Dial(url+"?access_token="+token, "", server.URL)
+	gobottest.Assert(t, err, nil)
+	defer ws.Close()
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:      "1",
+		Type:    "command",
+		Command: "TestFunction",
+		Params:  map[string]interface{}{"message": "Beep Boop"},
+	})
+	msg := receive(t, ws)
+	gobottest.Assert(t, msg.Error, "Forbidden")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "2",
+		Type:   "subscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "TestEvent",
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Result, true)
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("secret")
+	user := User{Name: "bob", Role: OperatorRole}
+
+	token := SignJWT(secret, user, time.Now().Add(1*time.Minute))
+	parsed, err := ParseJWT(secret, token)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, *parsed, user)
+
+	_, err = ParseJWT([]byte("wrong"), token)
+	gobottest.Assert(t, err.Error(), "Invalid token signature")
+
+	_, err = ParseJWT(secret, SignJWT(secret, user, time.Now().Add(-1*time.Minute)))
+	gobottest.Assert(t, err.Error(), "Token expired")
+
+	_, err = ParseJWT(secret, "not-a-token")
+	gobottest.Assert(t, err.Error(), "Invalid token")
+
+	// unsigned tokens are refused
+	parts := strings.Split(token, ".")
+	_, err = ParseJWT(secret, jwtEncode([]byte(`{"alg":"none"}`))+"."+parts[1]+".")
+	gobottest.Assert(t, err.Error(), "Unsupported token algorithm none")
+
+	// a token signed by another implementation
+	parsed, err = ParseJWT([]byte("your-256-bit-secret"),
+		"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9."+
+			"eyJzdWIiOiIxMjM0NTY3ODkwIiwibmFtZSI6IkpvaG4gRG9lIiwiaWF0IjoxNTE2MjM5MDIyfQ."+
+			"SflKxwRJSMeKKF2QT4fwpMeJf36POk6yJV_adQssw5c",
+	)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, parsed.Name, "1234567890")
+	gobottest.Assert(t, parsed.CanExecute(), false)
+
+	request, _ := http.NewRequest("GET", "/api/", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	gobottest.Assert(t, *JWTAuth(secret)(request), user)
+	gobottest.Assert(t, JWTAuth([]byte("wrong"))(request), (*User)(nil))
+}
@@ -0,0 +1,327 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hybridgroup/gobot"
+)
+
+// Error is an error reported by the api
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api error %v: %v", e.StatusCode, e.Message)
+}
+
+// Client is a client of the Gobot api
+type Client struct {
+	// URL is the base URL of the api, such as http://localhost:3000
+	URL string
+	// HTTPClient is the client the requests are made with
+	HTTPClient *http.Client
+	// Header is sent with every request, such as an Authorization header
+	Header http.Header
+}
+
+// NewClient returns a new Client of the api served at url
+func NewClient(url string) *Client {
+	return &Client{
+		URL:        strings.TrimRight(url, "/"),
+		HTTPClient: http.DefaultClient,
+		Header:     http.Header{},
+	}
+}
+
+// SetBasicAuth sets the credentials of an api protected by api.BasicAuth
+func (c *Client) SetBasicAuth(username, password string) {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(username, password)
+	c.Header.Set("Authorization", req.Header.Get("Authorization"))
+}
+
+// SetToken sets the api token, or JSON Web Token, of an api protected by
+// api.TokenAuth or api.JWTAuth
+func (c *Client) SetToken(token string) {
+	c.Header.Set("Authorization", "Bearer "+token)
+}
+
+// MCP returns the Gobot with its robots and commands
+func (c *Client) MCP() (mcp *gobot.JSONGobot, err error) {
+	err = c.get("MCP", &mcp, "")
+	return
+}
+
+// OpenAPI returns the OpenAPI document of the api
+func (c *Client) OpenAPI() (doc map[string]interface{}, err error) {
+	err = c.get("", &doc, "openapi.json")
+	return
+}
+
+// Commands returns the schemas of the commands of the Gobot
+func (c *Client) Commands() (schemas []gobot.CommandSchema, err error) {
+	err = c.get("schemas", &schemas, "commands")
+	return
+}
+
+// Command executes a command of the Gobot and returns its result
+func (c *Client) Command(name string, params map[string]interface{}) (result interface{}, err error) {
+	return c.execute(params, "commands", name)
+}
+
+// Events returns the names of the events of the Gobot
+func (c *Client) Events() (events []string, err error) {
+	err = c.get("events", &events, "events")
+	return
+}
+
+// Event subscribes to an event of the Gobot, which is streamed as server sent
+// events
+func (c *Client) Event(event string) (s *Subscription, err error) {
+	return c.stream("events", event)
+}
+
+// Robots returns the robots
+func (c *Client) Robots() (robots []*gobot.JSONRobot, err error) {
+	err = c.get("robots", &robots, "robots")
+	return
+}
+
+// Robot returns a robot given a name
+func (c *Client) Robot(robot string) (r *gobot.JSONRobot, err error) {
+	err = c.get("robot", &r, "robots", robot)
+	return
+}
+
+// RobotCommands returns the schemas of the commands of a robot
+func (c *Client) RobotCommands(robot string) (schemas []gobot.CommandSchema, err error) {
+	err = c.get("schemas", &schemas, "robots", robot, "commands")
+	return
+}
+
+// RobotCommand executes a command of a robot and returns its result
+func (c *Client) RobotCommand(robot string, name string, params map[string]interface{}) (result interface{}, err error) {
+	return c.execute(params, "robots", robot, "commands", name)
+}
+
+// RobotEvents returns the names of the events of a robot
+func (c *Client) RobotEvents(robot string) (events []string, err error) {
+	err = c.get("events", &events, "robots", robot, "events")
+	return
+}
+
+// RobotEvent subscribes to an event of a robot, which is streamed as server
+// sent events
+func (c *Client) RobotEvent(robot string, event string) (s *Subscription, err error) {
+	return c.stream("robots", robot, "events", event)
+}
+
+// Devices returns the devices of a robot
+func (c *Client) Devices(robot string) (devices []*gobot.JSONDevice, err error) {
+	err = c.get("devices", &devices, "robots", robot, "devices")
+	return
+}
+
+// Device returns a device of a robot given a name
+func (c *Client) Device(robot string, device string) (d *gobot.JSONDevice, err error) {
+	err = c.get("device", &d, "robots", robot, "devices", device)
+	return
+}
+
+// DeviceCommands returns the schemas of the commands of a device
+func (c *Client) DeviceCommands(robot string, device string) (schemas []gobot.CommandSchema, err error) {
+	err = c.get("schemas", &schemas, "robots", robot, "devices", device, "commands")
+	return
+}
+
+// DeviceCommand executes a command of a device and returns its result
+func (c *Client) DeviceCommand(robot string, device string, name string, params map[string]interface{}) (result interface{}, err error) {
+	return c.execute(params, "robots", robot, "devices", device, "commands", name)
+}
+
+// DeviceEvents returns the names of the events of a device
+func (c *Client) DeviceEvents(robot string, device string) (events []string, err error) {
+	err = c.get("events", &events, "robots", robot, "devices", device, "events")
+	return
+}
+
+// Connections returns the connections of a robot
+func (c *Client) Connections(robot string) (connections []*gobot.JSONConnection, err error) {
+	err = c.get("connections", &connections, "robots", robot, "connections")
+	return
+}
+
+// Connection returns a connection of a robot given a name
+func (c *Client) Connection(robot string, connection string) (conn *gobot.JSONConnection, err error) {
+	err = c.get("connection", &conn, "robots", robot, "connections", connection)
+	return
+}
+
+// Subscription is a stream of the data of an event
+type Subscription struct {
+	// Data receives the data of every event, decoded from JSON. It is closed
+	// when the stream ends.
+	Data <-chan interface{}
+	body io.ReadCloser
+	done chan bool
+	once sync.Once
+	err  error
+}
+
+// Close ends the stream
+func (s *Subscription) Close() (err error) {
+	s.once.Do(func() {
+		close(s.done)
+		s.err = s.body.Close()
+	})
+	return s.err
+}
+
+// DeviceEvent subscribes to an event of a device, which is streamed as
+// server sent events
+func (c *Client) DeviceEvent(robot string, device string, event string) (s *Subscription, err error) {
+	return c.stream("robots", robot, "devices", device, "events", event)
+}
+
+// stream subscribes to the server sent events of the api route made of path
+func (c *Client) stream(path ...string) (s *Subscription, err error) {
+	req, err := c.request("GET", nil, path...)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return
+	}
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		defer res.Body.Close()
+		return nil, decode(res, "", nil)
+	}
+
+	data := make(chan interface{})
+	s = &Subscription{Data: data, body: res.Body, done: make(chan bool)}
+	go func() {
+		defer close(data)
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			var d interface{}
+			if json.Unmarshal([]byte(strings.TrimSpace(line[len("data:"):])), &d) != nil {
+				continue
+			}
+			select {
+			case data <- d:
+			case <-s.done:
+				return
+			}
+		}
+	}()
+	return
+}
+
+func (c *Client) get(key string, v interface{}, path ...string) (err error) {
+	req, err := c.request("GET", nil, path...)
+	if err != nil {
+		return
+	}
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	return decode(res, key, v)
+}
+
+func (c *Client) execute(params map[string]interface{}, path ...string) (result interface{}, err error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	req, err := c.request("POST", bytes.NewReader(body), path...)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+	err = decode(res, "result", &result)
+	return
+}
+
+// request returns a request of the api route made of the escaped path
+// segments
+func (c *Client) request(method string, body io.Reader, path ...string) (req *http.Request, err error) {
+	segments := []string{}
+	for _, segment := range path {
+		segments = append(segments, strings.Replace(url.QueryEscape(segment), "+", "%20", -1))
+	}
+	req, err = http.NewRequest(method, c.URL+"/api/"+strings.Join(segments, "/"), body)
+	if err != nil {
+		return
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	return
+}
+
+// decode decodes the value under key of the JSON response into v, or the
+// whole response when key is empty. An "error" in the response is returned as
+// a *gobot.CommandError when it is structured, or an *Error.
+func decode(res *http.Response, key string, v interface{}) (err error) {
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+
+	fields := map[string]json.RawMessage{}
+	if e := json.Unmarshal(data, &fields); e != nil {
+		return &Error{StatusCode: res.StatusCode, Message: strings.TrimSpace(string(data))}
+	}
+	if raw, ok := fields["error"]; ok {
+		commandErr := &gobot.CommandError{}
+		if json.Unmarshal(raw, commandErr) == nil {
+			return commandErr
+		}
+		message := ""
+		json.Unmarshal(raw, &message)
+		return &Error{StatusCode: res.StatusCode, Message: message}
+	}
+	if res.StatusCode != http.StatusOK {
+		return &Error{StatusCode: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+	}
+
+	if v == nil {
+		return
+	}
+	if key == "" {
+		return json.Unmarshal(data, v)
+	}
+	raw, ok := fields[key]
+	if !ok {
+		return errors.New("Response is missing " + key)
+	}
+	return json.Unmarshal(raw, v)
+}
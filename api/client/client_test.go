@@ -0,0 +1,378 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/api"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+type testAdaptor struct {
+	name string
+}
+
+func (t *testAdaptor) Name() string             { return t.name }
+func (t *testAdaptor) Connect() (errs []error)  { return }
+func (t *testAdaptor) Finalize() (errs []error) { return }
+
+type testDriver struct {
+	name       string
+	connection gobot.Connection
+	gobot.Commander
+	gobot.Eventer
+}
+
+func (t *testDriver) Name() string                 { return t.name }
+func (t *testDriver) Connection() gobot.Connection { return t.connection }
+func (t *testDriver) Start() (errs []error)        { return }
+func (t *testDriver) Halt() (errs []error)         { return }
+
+func newTestDriver(adaptor *testAdaptor, name string) *testDriver {
+	d := &testDriver{
+		name:       name,
+		connection: adaptor,
+		Commander:  gobot.NewCommander(),
+		Eventer:    gobot.NewEventer(),
+	}
+	d.AddEvent("data")
+	d.AddCommandSchema(gobot.CommandSchema{
+		Name: "Double",
+		Params: []gobot.CommandParam{
+			{Name: "value", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 100}},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return params["value"].(int) * 2
+	})
+	return d
+}
+
+func initTestClient() (*Client, *gobot.Gobot, *httptest.Server) {
+	g := gobot.NewGobot()
+	g.AddCommand("Hello", func(params map[string]interface{}) interface{} {
+		return "hello " + params["name"].(string)
+	})
+
+	adaptor := &testAdaptor{name: "adaptor"}
+	r := gobot.NewRobot("bot",
+		[]gobot.Connection{adaptor},
+		[]gobot.Device{newTestDriver(adaptor, "device")},
+	)
+	r.AddCommand("Ping", func(params map[string]interface{}) interface{} {
+		return "pong"
+	})
+	g.AddRobot(r)
+
+	server := httptest.NewServer(api.NewAPI(g).Handler())
+	return NewClient(server.URL + "/"), g, server
+}
+
+func TestClientMCP(t *testing.T) {
+	c, _, server := initTestClient()
+	defer server.Close()
+
+	mcp, err := c.MCP()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, mcp.Commands, []string{"Hello"})
+	gobottest.Assert(t, mcp.Robots[0].Name, "bot")
+
+	schemas, err := c.Commands()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, schemas, []gobot.CommandSchema{{Name: "Hello", Params: []gobot.CommandParam{}}})
+
+	result, err := c.Command("Hello", map[string]interface{}{"name": "human"})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, "hello human")
+
+	_, err = c.Command("Unknown", nil)
+	gobottest.Assert(t, err, &Error{StatusCode: http.StatusOK, Message: "Unknown Command"})
+
+	doc, err := c.OpenAPI()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, doc["openapi"], "3.0.0")
+}
+
+func TestClientRobots(t *testing.T) {
+	c, _, server := initTestClient()
+	defer server.Close()
+
+	robots, err := c.Robots()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(robots), 1)
+
+	robot, err := c.Robot("bot")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, robot.Devices[0].Name, "device")
+
+	_, err = c.Robot("unknown")
+	gobottest.Assert(t, err.Error(), "api error 200: No Robot found with the name unknown")
+
+	schemas, err := c.RobotCommands("bot")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, schemas[0].Name, "Ping")
+
+	result, err := c.RobotCommand("bot", "Ping", nil)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, "pong")
+
+	connections, err := c.Connections("bot")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, connections[0].Name, "adaptor")
+
+	connection, err := c.Connection("bot", "adaptor")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, connection.Adaptor, "*client.testAdaptor")
+}
+
+func TestClientDevices(t *testing.T) {
+	c, _, server := initTestClient()
+	defer server.Close()
+
+	devices, err := c.Devices("bot")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, devices[0].Name, "device")
+
+	device, err := c.Device("bot", "device")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, device.Connection, "adaptor")
+
+	schemas, err := c.DeviceCommands("bot", "device")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, schemas[0].Params[0].Type, gobot.IntegerParam)
+
+	result, err := c.DeviceCommand("bot", "device", "Double", map[string]interface{}{"value": 21})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, 42.0)
+
+	_, err = c.DeviceCommand("bot", "device", "Double", map[string]interface{}{"value": 210})
+	gobottest.Assert(t, err, &gobot.CommandError{
+		Command: "Double",
+		Param:   "value",
+		Message: "must be between 0 and 100, got 210",
+	})
+}
+
+func TestClientDeviceEvent(t *testing.T) {
+	c, g, server := initTestClient()
+	defer server.Close()
+
+	s, err := c.DeviceEvent("bot", "device", "data")
+	gobottest.Assert(t, err, nil)
+
+	device := g.Robot("bot").Device("device").(*testDriver)
+	gobot.Publish(device.Event("data"), map[string]interface{}{"value": 1})
+
+	select {
+	case data := <-s.Data:
+		gobottest.Assert(t, data, map[string]interface{}{"value": 1.0})
+	case <-time.After(1 * time.Second):
+		t.Errorf("Event data was not received")
+	}
+	gobottest.Assert(t, s.Close(), nil)
+
+	_, err = c.DeviceEvent("bot", "device", "unknown")
+	gobottest.Assert(t, err.Error(), "api error 200: No Event found with the name unknown")
+}
+
+func TestClientEvents(t *testing.T) {
+	c, g, server := initTestClient()
+	defer server.Close()
+	g.AddEvent("started")
+	g.Robot("bot").AddEvent("moved")
+
+	events, err := c.Events()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, events, []string{"started"})
+
+	events, err = c.RobotEvents("bot")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, events, []string{"moved"})
+
+	events, err = c.DeviceEvents("bot", "device")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, events, []string{"data"})
+
+	_, err = c.RobotEvents("unknown")
+	gobottest.Assert(t, err.Error(), "api error 200: No Robot found with the name unknown")
+
+	s, err := c.RobotEvent("bot", "moved")
+	gobottest.Assert(t, err, nil)
+	defer s.Close()
+	gobot.Publish(g.Robot("bot").Event("moved"), 1)
+
+	select {
+	case data := <-s.Data:
+		gobottest.Assert(t, data, 1.0)
+	case <-time.After(1 * time.Second):
+		t.Errorf("Event data was not received")
+	}
+
+	s, err = c.Event("started")
+	gobottest.Assert(t, err, nil)
+	defer s.Close()
+	gobot.Publish(g.Event("started"), true)
+
+	select {
+	case data := <-s.Data:
+		gobottest.Assert(t, data, true)
+	case <-time.After(1 * time.Second):
+		t.Errorf("Event data was not received")
+	}
+}
+
+func TestClientBasicAuth(t *testing.T) {
+	g := gobot.NewGobot()
+	a := api.NewAPI(g)
+	a.AddHandler(api.BasicAuth("admin", "password"))
+	server := httptest.NewServer(a.Handler())
+	defer server.Close()
+
+	c := NewClient(server.URL)
+	_, err := c.Robots()
+	gobottest.Assert(t, err, &Error{StatusCode: http.StatusUnauthorized, Message: "Not Authorized"})
+
+	c.SetBasicAuth("admin", "password")
+	robots, err := c.Robots()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(robots), 0)
+}
+
+func TestClientWebSocket(t *testing.T) {
+	c, g, server := initTestClient()
+	defer server.Close()
+
+	w, err := c.WebSocket()
+	gobottest.Assert(t, err, nil)
+	defer w.Close()
+
+	gobottest.Assert(t, w.Ping(), nil)
+
+	result, err := w.Command("", "", "Hello", map[string]interface{}{"name": "gobot"})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, "hello gobot")
+
+	result, err = w.Command("bot", "", "Ping", nil)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, "pong")
+
+	result, err = w.Command("bot", "device", "Double", map[string]interface{}{"value": 21})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, 42.0)
+
+	_, err = w.Command("bot", "device", "Double", map[string]interface{}{"value": 101})
+	gobottest.Assert(t, err, &gobot.CommandError{
+		Command: "Double",
+		Param:   "value",
+		Message: "must be between 0 and 100, got 101",
+	})
+
+	_, err = w.Command("bot", "device", "Unknown", nil)
+	gobottest.Assert(t, err, &Error{Message: "Unknown Command"})
+
+	gobottest.Assert(t, w.Subscribe("bot", "device", "data"), nil)
+	gobottest.Assert(t, w.Subscribe("bot", "device", "unknown"),
+		&Error{Message: "No Event found with the name unknown"})
+
+	device := g.Robot("bot").Device("device").(*testDriver)
+	gobot.Publish(device.Event("data"), 1)
+
+	select {
+	case event := <-w.Events:
+		gobottest.Assert(t, event, &Event{Robot: "bot", Device: "device", Event: "data", Data: 1.0})
+	case <-time.After(1 * time.Second):
+		t.Errorf("Event was not received")
+	}
+
+	gobottest.Assert(t, w.Unsubscribe("bot", "device", "data"), nil)
+	gobottest.Assert(t, w.Close(), nil)
+	_, ok := <-w.Events
+	gobottest.Assert(t, ok, false)
+	gobottest.Assert(t, w.Ping(), ErrWebSocketClosed)
+}
+
+func TestClientWebSocketSlowEvents(t *testing.T) {
+	c, g, server := initTestClient()
+	defer server.Close()
+
+	w, err := c.WebSocket()
+	gobottest.Assert(t, err, nil)
+	defer w.Close()
+
+	gobottest.Assert(t, w.Subscribe("bot", "device", "data"), nil)
+	device := g.Robot("bot").Device("device").(*testDriver)
+	for i := 0; i < 2*webSocketEventBuffer; i++ {
+		gobot.Publish(device.Event("data"), i)
+	}
+
+	timeout := time.After(1 * time.Second)
+	for len(w.Events) < webSocketEventBuffer {
+		select {
+		case <-timeout:
+			t.Fatalf("Events were not received")
+		case <-time.After(1 * time.Millisecond):
+		}
+	}
+
+	// the replies of the api are not blocked behind the events which were
+	// not received
+	result, err := w.Command("bot", "device", "Double", map[string]interface{}{"value": 21})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, 42.0)
+
+	// the events dropped meanwhile are counted in the next ones
+	dropped := 0
+	for i := 0; i < webSocketEventBuffer; i++ {
+		dropped += (<-w.Events).Dropped
+	}
+	timeout = time.After(1 * time.Second)
+	for last := false; !last; {
+		select {
+		case event := <-w.Events:
+			dropped += event.Dropped
+			last = event.Data == "last"
+		case <-time.After(10 * time.Millisecond):
+			gobot.Publish(device.Event("data"), "last")
+		case <-timeout:
+			t.Fatalf("Event was not received")
+		}
+	}
+	gobottest.Assert(t, dropped > 0, true)
+}
+
+func TestClientTokenAuth(t *testing.T) {
+	g := gobot.NewGobot()
+	g.AddCommand("Hello", func(params map[string]interface{}) interface{} {
+		return "hello"
+	})
+	a := api.NewAPI(g)
+	a.Authenticate(api.TokenAuth(map[string]api.User{
+		"reader":   {Name: "alice", Role: api.ReaderRole},
+		"operator": {Name: "bob", Role: api.OperatorRole},
+	}))
+	server := httptest.NewServer(a.Handler())
+	defer server.Close()
+
+	c := NewClient(server.URL)
+	_, err := c.Robots()
+	gobottest.Assert(t, err, &Error{StatusCode: http.StatusUnauthorized, Message: "Not Authorized"})
+
+	c.SetToken("reader")
+	_, err = c.Robots()
+	gobottest.Assert(t, err, nil)
+	_, err = c.Command("Hello", nil)
+	gobottest.Assert(t, err, &Error{StatusCode: http.StatusForbidden, Message: "Forbidden"})
+
+	w, err := c.WebSocket()
+	gobottest.Assert(t, err, nil)
+	_, err = w.Command("", "", "Hello", nil)
+	gobottest.Assert(t, err, &Error{Message: "Forbidden"})
+	w.Close()
+
+	c.SetToken("operator")
+	result, err := c.Command("Hello", nil)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, result, "hello")
+}
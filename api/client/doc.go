@@ -0,0 +1,61 @@
+/*
+Package client provides a client of the Gobot api, to list robots, devices
+and connections, execute commands and subscribe to device events.
+
+Example:
+
+    package main
+
+    import (
+    	"fmt"
+
+    	"github.com/hybridgroup/gobot/api/client"
+    )
+
+    func main() {
+    	c := client.NewClient("http://localhost:3000")
+
+    	robots, err := c.Robots()
+    	if err != nil {
+    		fmt.Println(err)
+    		return
+    	}
+    	for _, robot := range robots {
+    		fmt.Println(robot.Name)
+    	}
+
+    	result, err := c.DeviceCommand("bot", "led", "Brightness", map[string]interface{}{"level": 128})
+    	fmt.Println(result, err)
+
+    	sub, err := c.DeviceEvent("bot", "button", "push")
+    	if err != nil {
+    		fmt.Println(err)
+    		return
+    	}
+    	defer sub.Close()
+    	for data := range sub.Data {
+    		fmt.Println("push", data)
+    	}
+    }
+
+A WebSocket multiplexes event subscriptions and commands over a single
+connection:
+
+    w, err := c.WebSocket()
+    if err != nil {
+    	fmt.Println(err)
+    	return
+    }
+    defer w.Close()
+    w.Subscribe("bot", "joystick", "left_x")
+    for event := range w.Events {
+    	w.Command("bot", "sphero", "Roll", map[string]interface{}{"speed": 100, "heading": event.Data})
+    }
+
+Events are dropped rather than delaying the replies of the api when they are
+not received fast enough, and counted in the Dropped field of the next event.
+
+Commands executed with invalid params return a *gobot.CommandError, other
+errors reported by the api are returned as an *Error.
+*/
+package client
@@ -0,0 +1,221 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/api"
+	"golang.org/x/net/websocket"
+)
+
+// ErrWebSocketClosed is the error of the requests of a closed WebSocket
+var ErrWebSocketClosed = errors.New("WebSocket is closed")
+
+// webSocketEventBuffer is the number of events buffered in Events
+const webSocketEventBuffer = 64
+
+// Event is an event of a device received by a WebSocket
+type Event struct {
+	Robot  string
+	Device string
+	Event  string
+	Data   interface{}
+	// Dropped is the number of events the api, or the WebSocket, dropped
+	// before this one because they were not received fast enough
+	Dropped int
+}
+
+// WebSocket is a connection to the websocket route of the api, which
+// multiplexes event subscriptions and commands
+type WebSocket struct {
+	// Events receives the events of every subscription. Events are dropped
+	// rather than delaying the replies of the api when more than 64 of them
+	// are waiting to be received. It is closed when the connection ends.
+	Events  <-chan *Event
+	ws      *websocket.Conn
+	events  chan *Event
+	dropped int
+	done    chan bool
+	closed  chan bool
+	mutex   sync.Mutex
+	lastID  int
+	pending map[string]chan *api.WebSocketMessage
+	once    sync.Once
+}
+
+// WebSocket connects to the websocket route of the api
+func (c *Client) WebSocket() (w *WebSocket, err error) {
+	config, err := websocket.NewConfig(
+		"ws"+strings.TrimPrefix(c.URL, "http")+"/api/websocket",
+		c.URL,
+	)
+	if err != nil {
+		return
+	}
+	for k, v := range c.Header {
+		config.Header[k] = v
+	}
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return
+	}
+
+	events := make(chan *Event, webSocketEventBuffer)
+	w = &WebSocket{
+		Events:  events,
+		ws:      ws,
+		events:  events,
+		done:    make(chan bool),
+		closed:  make(chan bool),
+		pending: make(map[string]chan *api.WebSocketMessage),
+	}
+	go w.read()
+	return
+}
+
+// Subscribe streams an event of a device to Events
+func (w *WebSocket) Subscribe(robot string, device string, event string) (err error) {
+	_, err = w.request(&api.WebSocketMessage{Type: "subscribe", Robot: robot, Device: device, Event: event})
+	return
+}
+
+// Unsubscribe stops streaming an event of a device
+func (w *WebSocket) Unsubscribe(robot string, device string, event string) (err error) {
+	_, err = w.request(&api.WebSocketMessage{Type: "unsubscribe", Robot: robot, Device: device, Event: event})
+	return
+}
+
+// Command executes a command of a device of a robot, of a robot when device
+// is empty, or of the Gobot when robot is empty, and returns its result
+func (w *WebSocket) Command(robot string, device string, name string, params map[string]interface{}) (result interface{}, err error) {
+	return w.request(&api.WebSocketMessage{
+		Type:    "command",
+		Robot:   robot,
+		Device:  device,
+		Command: name,
+		Params:  params,
+	})
+}
+
+// Ping waits for a pong from the api
+func (w *WebSocket) Ping() (err error) {
+	_, err = w.request(&api.WebSocketMessage{Type: "ping"})
+	return
+}
+
+// Close ends the connection
+func (w *WebSocket) Close() (err error) {
+	w.once.Do(func() {
+		close(w.closed)
+		err = w.ws.Close()
+	})
+	return
+}
+
+// request sends msg with a new id and waits for its reply
+func (w *WebSocket) request(msg *api.WebSocketMessage) (result interface{}, err error) {
+	select {
+	case <-w.closed:
+		return nil, ErrWebSocketClosed
+	default:
+	}
+	reply := make(chan *api.WebSocketMessage, 1)
+
+	w.mutex.Lock()
+	w.lastID++
+	id := fmt.Sprint(w.lastID)
+	w.pending[id] = reply
+	w.mutex.Unlock()
+
+	defer func() {
+		w.mutex.Lock()
+		delete(w.pending, id)
+		w.mutex.Unlock()
+	}()
+
+	msg.ID = id
+	if err = w.send(msg); err != nil {
+		return
+	}
+
+	select {
+	case msg = <-reply:
+	case <-w.done:
+		return nil, ErrWebSocketClosed
+	}
+	if msg.Type == "error" {
+		return nil, webSocketError(msg.Error)
+	}
+	return msg.Result, nil
+}
+
+func (w *WebSocket) send(msg *api.WebSocketMessage) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return websocket.JSON.Send(w.ws, msg)
+}
+
+// read dispatches the messages of the api until the connection ends
+func (w *WebSocket) read() {
+	defer func() {
+		close(w.done)
+		close(w.events)
+	}()
+
+	for {
+		msg := &api.WebSocketMessage{}
+		if err := websocket.JSON.Receive(w.ws, msg); err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case "ping":
+			w.send(&api.WebSocketMessage{Type: "pong"})
+		case "event":
+			w.publish(&Event{
+				Robot:   msg.Robot,
+				Device:  msg.Device,
+				Event:   msg.Event,
+				Data:    msg.Data,
+				Dropped: msg.Dropped,
+			})
+		default:
+			w.mutex.Lock()
+			reply, ok := w.pending[fmt.Sprint(msg.ID)]
+			w.mutex.Unlock()
+			if ok {
+				reply <- msg
+			}
+		}
+	}
+}
+
+// publish queues an event unless Events is full, in which case it is dropped
+// and counted in the next event
+func (w *WebSocket) publish(event *Event) {
+	event.Dropped += w.dropped
+	select {
+	case w.events <- event:
+		w.dropped = 0
+	default:
+		w.dropped = event.Dropped + 1
+	}
+}
+
+// webSocketError returns a *gobot.CommandError when e is structured, or an
+// *Error
+func webSocketError(e interface{}) error {
+	if message, ok := e.(string); ok {
+		return &Error{Message: message}
+	}
+	data, _ := json.Marshal(e)
+	commandErr := &gobot.CommandError{}
+	if json.Unmarshal(data, commandErr) != nil {
+		return &Error{Message: string(data)}
+	}
+	return commandErr
+}
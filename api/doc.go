@@ -7,6 +7,7 @@ Example:
 
     import (
     	"fmt"
+    	"strings"
 
     	"github.com/hybridgroup/gobot"
     	"github.com/hybridgroup/gobot/api"
@@ -30,9 +31,68 @@ Example:
     		return fmt.Sprintf("%v says hello!", hello.Name)
     	})
 
+      // Accessible via http://localhost:3000/robots/Eve/commands/say_hello_to,
+      // its params are validated against the schema listed by
+      // http://localhost:3000/robots/Eve/commands
+    	hello.AddCommandSchema(gobot.CommandSchema{
+    		Name:        "say_hello_to",
+    		Description: "Says hello a number of times",
+    		Params: []gobot.CommandParam{
+    			{Name: "name", Type: gobot.StringParam, Required: true},
+    			{Name: "times", Type: gobot.IntegerParam, Default: 1, Range: &gobot.ParamRange{Min: 1, Max: 10}},
+    		},
+    	}, func(params map[string]interface{}) interface{} {
+    		return strings.Repeat(fmt.Sprintf("%v says hello to %v! ", hello.Name, params["name"]), params["times"].(int))
+    	})
+
     	gbot.Start()
     }
 
+Requests can be authenticated with per user api tokens, or JSON Web Tokens
+signed with HS256, sent as a bearer token or as the access_token query
+parameter. Users with the ReaderRole can read the state of the robots and
+stream events, users with the OperatorRole can also execute commands, which
+are recorded by the Audit function of the api:
+
+    server := api.NewAPI(gbot)
+    server.Authenticate(
+    	api.TokenAuth(map[string]api.User{
+    		"f3ec7a2c": {Name: "dashboard", Role: api.ReaderRole},
+    	}),
+    	api.JWTAuth([]byte("secret")),
+    )
+    server.Audit = api.AuditLog(os.Stdout)
+    server.Start()
+
+Every API serves its routes with its own http.Server, so several of them can
+be started in a program. Start returns the error of listening on Host and
+Port, or on the Unix socket at Socket, and the server is shut down by Stop,
+which is called when the Gobot is stopped.
+
+Commands added with a schema return a *gobot.CommandError when their params are
+invalid, which is written with a 400 Bad Request status. Other errors returned
+by commands are written with a 500 Internal Server Error status, and are
+replies of type "error" over the websocket route.
+
+Events of the Gobot, of robots and of devices are listed by /api/events,
+/api/robots/:robot/events and /api/robots/:robot/devices/:device/events, and
+streamed as server sent events by the same routes followed by the name of the
+event. Connections are described with their port, whether they are connected
+and the pins of their devices.
+
+An OpenAPI 3 document of the routes, and of the commands and events of the
+robots and devices, is served at /api/openapi.json. The api/client package
+provides a Go client of the api.
+
+The /api/websocket route multiplexes event subscriptions and commands over a
+websocket, see WebSocketMessage. A ping is sent every WebSocketHeartbeat, and
+events are dropped when more than WebSocketBuffer messages are waiting to be
+sent to a client.
+
+The /api/metrics route serves the metrics of the events, commands and devices
+of the gobot, and the gobot.DefaultMetrics of the i2c and serial connections,
+in the Prometheus text exposition format.
+
 It follows Common Protocol for Programming Physical Input and Output (CPPP-IO) spec:
 https://github.com/hybridgroup/cppp-io
 */
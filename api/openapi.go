@@ -0,0 +1,376 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/hybridgroup/gobot"
+)
+
+// OpenAPI returns an OpenAPI 3 document describing the api routes, with a
+// path for every command and event of the robots and devices of g, whose
+// request bodies are described by the command schemas.
+func OpenAPI(g *gobot.Gobot) map[string]interface{} {
+	paths := map[string]interface{}{
+		"/api/": map[string]interface{}{
+			"get": openAPIOperation("Gobot with its robots and commands", nil, "MCP", ref("Gobot")),
+		},
+		"/api/commands": map[string]interface{}{
+			"get": openAPIOperation("Commands of the Gobot", nil, "", ref("Commands")),
+		},
+		"/api/commands/{command}": map[string]interface{}{
+			"post": openAPICommand("Executes a command of the Gobot", openAPIParams("command"), nil),
+		},
+		"/api/events": map[string]interface{}{
+			"get": openAPIOperation("Events of the Gobot", nil, "", ref("Events")),
+		},
+		"/api/events/{event}": map[string]interface{}{
+			"get": openAPIEvent("Streams an event of the Gobot", openAPIParams("event")),
+		},
+		"/api/robots": map[string]interface{}{
+			"get": openAPIOperation("Robots", nil, "robots", array(ref("Robot"))),
+		},
+		"/api/robots/{robot}": map[string]interface{}{
+			"get": openAPIOperation("Robot", openAPIParams("robot"), "robot", ref("Robot")),
+		},
+		"/api/robots/{robot}/commands": map[string]interface{}{
+			"get": openAPIOperation("Commands of a robot", openAPIParams("robot"), "", ref("Commands")),
+		},
+		"/api/robots/{robot}/commands/{command}": map[string]interface{}{
+			"post": openAPICommand("Executes a command of a robot", openAPIParams("robot", "command"), nil),
+		},
+		"/api/robots/{robot}/events": map[string]interface{}{
+			"get": openAPIOperation("Events of a robot", openAPIParams("robot"), "", ref("Events")),
+		},
+		"/api/robots/{robot}/events/{event}": map[string]interface{}{
+			"get": openAPIEvent("Streams an event of a robot", openAPIParams("robot", "event")),
+		},
+		"/api/robots/{robot}/devices": map[string]interface{}{
+			"get": openAPIOperation("Devices of a robot", openAPIParams("robot"), "devices", array(ref("Device"))),
+		},
+		"/api/robots/{robot}/devices/{device}": map[string]interface{}{
+			"get": openAPIOperation("Device of a robot", openAPIParams("robot", "device"), "device", ref("Device")),
+		},
+		"/api/robots/{robot}/devices/{device}/commands": map[string]interface{}{
+			"get": openAPIOperation("Commands of a device", openAPIParams("robot", "device"), "", ref("Commands")),
+		},
+		"/api/robots/{robot}/devices/{device}/commands/{command}": map[string]interface{}{
+			"post": openAPICommand("Executes a command of a device", openAPIParams("robot", "device", "command"), nil),
+		},
+		"/api/robots/{robot}/devices/{device}/events": map[string]interface{}{
+			"get": openAPIOperation("Events of a device", openAPIParams("robot", "device"), "", ref("Events")),
+		},
+		"/api/robots/{robot}/devices/{device}/events/{event}": map[string]interface{}{
+			"get": openAPIEvent("Streams an event of a device", openAPIParams("robot", "device", "event")),
+		},
+		"/api/websocket": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Multiplexes event subscriptions and commands over a websocket",
+				"responses": map[string]interface{}{
+					"101": map[string]interface{}{"description": "Switching Protocols"},
+				},
+			},
+		},
+		"/api/metrics": map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary": "Metrics of the events, commands, devices and connections in the Prometheus text exposition format",
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "OK",
+						"content": map[string]interface{}{
+							"text/plain": map[string]interface{}{"schema": str()},
+						},
+					},
+				},
+			},
+		},
+		"/api/robots/{robot}/connections": map[string]interface{}{
+			"get": openAPIOperation("Connections of a robot", openAPIParams("robot"), "connections", array(ref("Connection"))),
+		},
+		"/api/robots/{robot}/connections/{connection}": map[string]interface{}{
+			"get": openAPIOperation("Connection of a robot", openAPIParams("robot", "connection"), "connection", ref("Connection")),
+		},
+	}
+
+	for _, schema := range g.Schemas() {
+		paths["/api/commands/"+schema.Name] = map[string]interface{}{
+			"post": openAPICommand(openAPIDescription(schema), nil, schema.Params),
+		}
+	}
+	for _, event := range sortedEvents(g) {
+		paths["/api/events/"+event] = map[string]interface{}{
+			"get": openAPIEvent("Streams the "+event+" event of the Gobot", nil),
+		}
+	}
+
+	g.Robots().Each(func(r *gobot.Robot) {
+		robotPath := "/api/robots/" + r.Name
+		for _, schema := range r.Schemas() {
+			paths[robotPath+"/commands/"+schema.Name] = map[string]interface{}{
+				"post": openAPICommand(openAPIDescription(schema), nil, schema.Params),
+			}
+		}
+		for _, event := range sortedEvents(r) {
+			paths[robotPath+"/events/"+event] = map[string]interface{}{
+				"get": openAPIEvent("Streams the "+event+" event of "+r.Name, nil),
+			}
+		}
+		r.Devices().Each(func(d gobot.Device) {
+			devicePath := robotPath + "/devices/" + d.Name()
+			if commander, ok := d.(gobot.Commander); ok {
+				for _, schema := range commander.Schemas() {
+					paths[devicePath+"/commands/"+schema.Name] = map[string]interface{}{
+						"post": openAPICommand(openAPIDescription(schema), nil, schema.Params),
+					}
+				}
+			}
+			if eventer, ok := d.(gobot.Eventer); ok {
+				for _, event := range sortedEvents(eventer) {
+					paths[devicePath+"/events/"+event] = map[string]interface{}{
+						"get": openAPIEvent("Streams the "+event+" event of "+d.Name(), nil),
+					}
+				}
+			}
+		})
+	})
+
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   "Gobot API",
+			"version": gobot.Version(),
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": openAPIComponents,
+		},
+	}
+}
+
+// openAPI returns the OpenAPI document route handler.
+// Writes JSON with the OpenAPI document of the api
+func (a *API) openAPI(res http.ResponseWriter, req *http.Request) {
+	a.writeJSON(OpenAPI(a.gobot), res)
+}
+
+var openAPIComponents = map[string]interface{}{
+	"Param": object(map[string]interface{}{
+		"name": str(),
+		"type": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"number", "integer", "boolean", "string", "array"},
+			"description": "an array param is a JSON array, whose items may be of any type",
+		},
+		"description": str(),
+		"required":    map[string]interface{}{"type": "boolean"},
+		"range": object(map[string]interface{}{
+			"min": map[string]interface{}{"type": "number"},
+			"max": map[string]interface{}{"type": "number"},
+		}),
+		"default": map[string]interface{}{},
+	}),
+	"Schema": object(map[string]interface{}{
+		"name":        str(),
+		"description": str(),
+		"params":      array(ref("Param")),
+	}),
+	"Commands": object(map[string]interface{}{
+		"commands": array(str()),
+		"schemas":  array(ref("Schema")),
+	}),
+	"Events": object(map[string]interface{}{
+		"events": array(str()),
+	}),
+	"Connection": object(map[string]interface{}{
+		"name":      str(),
+		"adaptor":   str(),
+		"port":      str(),
+		"connected": map[string]interface{}{"type": "boolean"},
+		"pins":      array(str()),
+	}),
+	"Device": object(map[string]interface{}{
+		"name":       str(),
+		"driver":     str(),
+		"connection": str(),
+		"commands":   array(str()),
+		"schemas":    array(ref("Schema")),
+		"events":     array(str()),
+	}),
+	"Robot": object(map[string]interface{}{
+		"name":        str(),
+		"commands":    array(str()),
+		"schemas":     array(ref("Schema")),
+		"events":      array(str()),
+		"connections": array(ref("Connection")),
+		"devices":     array(ref("Device")),
+	}),
+	"Gobot": object(map[string]interface{}{
+		"robots":   array(ref("Robot")),
+		"commands": array(str()),
+		"schemas":  array(ref("Schema")),
+		"events":   array(str()),
+	}),
+	"Error": object(map[string]interface{}{
+		"error": str(),
+	}),
+	"CommandError": object(map[string]interface{}{
+		"error": object(map[string]interface{}{
+			"command": str(),
+			"param":   str(),
+			"message": str(),
+		}),
+	}),
+}
+
+// openAPIOperation returns a GET operation whose response is wrapped in an
+// object under key, or is schema itself when key is empty
+func openAPIOperation(summary string, params []interface{}, key string, schema map[string]interface{}) map[string]interface{} {
+	if key != "" {
+		schema = object(map[string]interface{}{key: schema})
+	}
+	op := map[string]interface{}{
+		"summary": summary,
+		"responses": map[string]interface{}{
+			"200": openAPIResponse("OK", map[string]interface{}{"oneOf": []interface{}{schema, ref("Error")}}),
+		},
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return op
+}
+
+// openAPICommand returns a POST operation executing a command, whose request
+// body is described by params when they are known
+func openAPICommand(summary string, pathParams []interface{}, params []gobot.CommandParam) map[string]interface{} {
+	body := map[string]interface{}{"type": "object"}
+	if params != nil {
+		body = openAPIParamsSchema(params)
+	}
+	op := map[string]interface{}{
+		"summary": summary,
+		"requestBody": map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": body},
+			},
+		},
+		"responses": map[string]interface{}{
+			"200": openAPIResponse("Result of the command", map[string]interface{}{"oneOf": []interface{}{
+				object(map[string]interface{}{"result": map[string]interface{}{}}),
+				ref("Error"),
+			}}),
+			"400": openAPIResponse("Invalid params", ref("CommandError")),
+			"403": openAPIResponse("The role of the user does not allow executing commands", ref("Error")),
+		},
+	}
+	if len(pathParams) > 0 {
+		op["parameters"] = pathParams
+	}
+	return op
+}
+
+// openAPIEvent returns a GET operation streaming an event as server sent
+// events
+func openAPIEvent(summary string, params []interface{}) map[string]interface{} {
+	op := map[string]interface{}{
+		"summary": summary,
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "Stream of the event data as JSON",
+				"content": map[string]interface{}{
+					"text/event-stream": map[string]interface{}{"schema": str()},
+					"application/json":  map[string]interface{}{"schema": ref("Error")},
+				},
+			},
+		},
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+	return op
+}
+
+func openAPIResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": schema},
+		},
+	}
+}
+
+// openAPIParams returns the path parameters of names
+func openAPIParams(names ...string) (params []interface{}) {
+	for _, name := range names {
+		params = append(params, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   str(),
+		})
+	}
+	return
+}
+
+// openAPIParamsSchema returns the JSON schema of an object of command params
+func openAPIParamsSchema(params []gobot.CommandParam) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, param := range params {
+		property := map[string]interface{}{"type": string(param.Type)}
+		if param.Type == gobot.ArrayParam {
+			// the items of an array param may be of any type
+			property["items"] = map[string]interface{}{"description": "any JSON value"}
+		}
+		if param.Description != "" {
+			property["description"] = param.Description
+		}
+		if param.Range != nil {
+			property["minimum"] = param.Range.Min
+			property["maximum"] = param.Range.Max
+		}
+		if param.Default != nil {
+			property["default"] = param.Default
+		}
+		properties[param.Name] = property
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+	schema := object(properties)
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func openAPIDescription(schema gobot.CommandSchema) string {
+	if schema.Description != "" {
+		return schema.Description
+	}
+	return "Executes " + schema.Name
+}
+
+func sortedEvents(eventer gobot.Eventer) (events []string) {
+	for name := range eventer.Events() {
+		events = append(events, name)
+	}
+	sort.Strings(events)
+	return
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func array(items map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "array", "items": items}
+}
+
+func object(properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+func str() map[string]interface{} {
+	return map[string]interface{}{"type": "string"}
+}
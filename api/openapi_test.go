@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func TestOpenAPI(t *testing.T) {
+	a := initTestAPI()
+	d := a.gobot.Robot("Robot1").Device("Device1").(*testDriver)
+	d.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Brightness",
+		Description: "Sets the brightness",
+		Params: []gobot.CommandParam{
+			{Name: "level", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 255}},
+			{Name: "rate", Type: gobot.NumberParam, Default: 1.0, Description: "fade rate"},
+			{Name: "steps", Type: gobot.ArrayParam},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return nil
+	})
+
+	request, _ := http.NewRequest("GET", "/api/openapi.json", nil)
+	response := httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+
+	var doc map[string]interface{}
+	json.NewDecoder(response.Body).Decode(&doc)
+	gobottest.Assert(t, doc["openapi"], "3.0.0")
+	gobottest.Assert(t, doc["info"].(map[string]interface{})["version"], gobot.Version())
+
+	paths := doc["paths"].(map[string]interface{})
+	gobottest.Refute(t, paths["/api/robots/{robot}/devices/{device}/commands/{command}"], nil)
+	gobottest.Refute(t, paths["/api/commands/TestFunction"], nil)
+	gobottest.Refute(t, paths["/api/robots/Robot2/commands/robotTestFunction"], nil)
+	gobottest.Refute(t, paths["/api/robots/Robot1/devices/Device1/events/TestEvent"], nil)
+
+	brightness := paths["/api/robots/Robot1/devices/Device1/commands/Brightness"].(map[string]interface{})["post"].(map[string]interface{})
+	gobottest.Assert(t, brightness["summary"], "Sets the brightness")
+	schema := brightness["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"]
+	gobottest.Assert(t, schema, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"level": map[string]interface{}{"type": "integer", "minimum": 0.0, "maximum": 255.0},
+			"rate":  map[string]interface{}{"type": "number", "default": 1.0, "description": "fade rate"},
+			"steps": map[string]interface{}{"type": "array", "items": map[string]interface{}{"description": "any JSON value"}},
+		},
+		"required": []interface{}{"level"},
+	})
+
+	untyped := paths["/api/robots/Robot1/devices/Device1/commands/TestDriverCommand"].(map[string]interface{})["post"].(map[string]interface{})
+	gobottest.Assert(t, untyped["summary"], "Executes TestDriverCommand")
+
+	components := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	gobottest.Refute(t, components["Robot"], nil)
+	gobottest.Refute(t, components["CommandError"], nil)
+	paramType := components["Param"].(map[string]interface{})["properties"].(map[string]interface{})["type"].(map[string]interface{})
+	gobottest.Assert(t, paramType["enum"], []interface{}{"number", "integer", "boolean", "string", "array"})
+}
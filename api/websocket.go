@@ -0,0 +1,240 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketMessage is a JSON message of the websocket route.
+//
+// Clients send messages of the types:
+//	"subscribe" - streams the Event of the Device of the Robot, of the Robot
+//	when Device is empty, or of the Gobot when Robot is empty
+//	"unsubscribe" - stops streaming the Event
+//	"command" - executes the Command of the Device of the Robot, of the Robot
+//	when Device is empty, or of the Gobot when Robot is empty, with Params
+//	"ping" - answered with a "pong"
+//
+// Every message sent by a client is answered with a message of type "result",
+// "error" or "pong" with the same ID. The api sends messages of type "event"
+// with the Data of the subscribed events, and of type "ping" as a heartbeat
+// which clients answer with a "pong".
+type WebSocketMessage struct {
+	ID      interface{}            `json:"id,omitempty"`
+	Type    string                 `json:"type"`
+	Robot   string                 `json:"robot,omitempty"`
+	Device  string                 `json:"device,omitempty"`
+	Event   string                 `json:"event,omitempty"`
+	Command string                 `json:"command,omitempty"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Result  interface{}            `json:"result,omitempty"`
+	Error   interface{}            `json:"error,omitempty"`
+	Data    interface{}            `json:"data,omitempty"`
+	// Dropped is the number of events which were dropped before this one
+	// because the client did not keep up with them
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// webSocketConn is a client connected to the websocket route
+type webSocketConn struct {
+	api           *API
+	user          *User
+	ws            *websocket.Conn
+	out           chan *WebSocketMessage
+	done          chan bool
+	closed        sync.Once
+	mutex         sync.Mutex
+	subscriptions map[string]func()
+	dropped       int
+}
+
+// webSocket returns the websocket route handler.
+// Multiplexes event subscriptions and commands over a websocket
+func (a *API) webSocket(ws *websocket.Conn) {
+	c := &webSocketConn{
+		api:           a,
+		user:          a.user(ws.Request()),
+		ws:            ws,
+		out:           make(chan *WebSocketMessage, a.WebSocketBuffer),
+		done:          make(chan bool),
+		subscriptions: make(map[string]func()),
+	}
+	go c.write()
+	c.read()
+	c.close()
+
+	c.mutex.Lock()
+	for _, unsubscribe := range c.subscriptions {
+		unsubscribe()
+	}
+	c.mutex.Unlock()
+}
+
+// read handles the messages of the client until it disconnects, or does not
+// send anything for two heartbeats
+func (c *webSocketConn) read() {
+	for {
+		c.ws.SetReadDeadline(time.Now().Add(2 * c.api.WebSocketHeartbeat))
+		var data []byte
+		if err := websocket.Message.Receive(c.ws, &data); err != nil {
+			return
+		}
+		msg := &WebSocketMessage{}
+		if err := json.Unmarshal(data, msg); err != nil {
+			c.send(&WebSocketMessage{Type: "error", Error: err.Error()})
+			continue
+		}
+
+		switch msg.Type {
+		case "ping":
+			c.send(&WebSocketMessage{ID: msg.ID, Type: "pong"})
+		case "pong":
+		case "subscribe":
+			c.reply(msg, true, c.subscribe(msg))
+		case "unsubscribe":
+			c.reply(msg, true, c.unsubscribe(msg))
+		case "command":
+			go c.command(msg)
+		default:
+			c.reply(msg, nil, errors.New("Unknown message type "+msg.Type))
+		}
+	}
+}
+
+// write sends the outgoing messages, and a ping every heartbeat
+func (c *webSocketConn) write() {
+	heartbeat := time.NewTicker(c.api.WebSocketHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		msg := &WebSocketMessage{Type: "ping"}
+		select {
+		case msg = <-c.out:
+		case <-heartbeat.C:
+		case <-c.done:
+			return
+		case <-c.api.closing:
+			c.close()
+			return
+		}
+		c.ws.SetWriteDeadline(time.Now().Add(c.api.WebSocketHeartbeat))
+		if err := websocket.JSON.Send(c.ws, msg); err != nil {
+			c.api.gobot.Logger().Log(gobot.WarnLevel, "Closing websocket", gobot.Fields{"error": err})
+			c.close()
+			return
+		}
+	}
+}
+
+// close closes the websocket and releases the reader and the commands waiting
+// to send, whichever of the reader or the writer stops first
+func (c *webSocketConn) close() {
+	c.closed.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
+
+// send queues msg, waiting for room in the buffer
+func (c *webSocketConn) send(msg *WebSocketMessage) {
+	select {
+	case c.out <- msg:
+	case <-c.done:
+	}
+}
+
+// publish queues an event unless the buffer is full, in which case it is
+// dropped and counted in the next event
+func (c *webSocketConn) publish(msg *WebSocketMessage) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	msg.Dropped = c.dropped
+	select {
+	case c.out <- msg:
+		c.dropped = 0
+	default:
+		c.dropped++
+	}
+}
+
+// reply sends the result of msg, or err
+func (c *webSocketConn) reply(msg *WebSocketMessage, result interface{}, err error) {
+	if err != nil {
+		c.send(&WebSocketMessage{ID: msg.ID, Type: "error", Error: err.Error()})
+		return
+	}
+	c.send(&WebSocketMessage{ID: msg.ID, Type: "result", Result: result})
+}
+
+func (c *webSocketConn) subscribe(msg *WebSocketMessage) (err error) {
+	event, err := c.api.eventFor(msg.Robot, msg.Device, msg.Event)
+	if err != nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := msg.Robot + "/" + msg.Device + "/" + msg.Event
+	if _, ok := c.subscriptions[key]; ok {
+		return
+	}
+	robot, device, name := msg.Robot, msg.Device, msg.Event
+	c.subscriptions[key], err = gobot.Subscribe(event, func(data interface{}) {
+		c.publish(&WebSocketMessage{
+			Type:   "event",
+			Robot:  robot,
+			Device: device,
+			Event:  name,
+			Data:   data,
+		})
+	})
+	return
+}
+
+func (c *webSocketConn) unsubscribe(msg *WebSocketMessage) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := msg.Robot + "/" + msg.Device + "/" + msg.Event
+	unsubscribe, ok := c.subscriptions[key]
+	if !ok {
+		return errors.New("Not subscribed to the Event " + msg.Event)
+	}
+	unsubscribe()
+	delete(c.subscriptions, key)
+	return nil
+}
+
+func (c *webSocketConn) command(msg *WebSocketMessage) {
+	// report a panicking command as the http server would, without taking the
+	// api down
+	defer func() {
+		if r := recover(); r != nil {
+			c.reply(msg, nil, fmt.Errorf("Command %v: %v", msg.Command, r))
+		}
+	}()
+
+	if msg.Params == nil {
+		msg.Params = make(map[string]interface{})
+	}
+
+	result, err := c.api.invoke(c.user, msg.Robot, msg.Device, msg.Command, msg.Params)
+	if commandErr, ok := err.(*gobot.CommandError); ok {
+		c.send(&WebSocketMessage{ID: msg.ID, Type: "error", Error: commandErr})
+		return
+	}
+	c.reply(msg, result, err)
+}
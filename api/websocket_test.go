@@ -0,0 +1,355 @@
+package api
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+	"golang.org/x/net/websocket"
+)
+
+// --------- HELPERS
+func initTestWebSocket(t *testing.T, a *API) (*websocket.Conn, func()) {
+	server := httptest.NewServer(a)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/websocket"
+	ws, err := websocket.Dial(url, "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ws, func() {
+		ws.Close()
+		server.Close()
+	}
+}
+
+// receive returns the next message which is not a heartbeat
+func receive(t *testing.T, ws *websocket.Conn) *WebSocketMessage {
+	ws.SetReadDeadline(time.Now().Add(1 * time.Second))
+	for {
+		msg := &WebSocketMessage{}
+		if err := websocket.JSON.Receive(ws, msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Type != "ping" {
+			return msg
+		}
+	}
+}
+
+// --------- TESTS
+func TestWebSocketCommand(t *testing.T) {
+	ws, closer := initTestWebSocket(t, initTestAPI())
+	defer closer()
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:      "1",
+		Type:    "command",
+		Command: "TestFunction",
+		Params:  map[string]interface{}{"message": "Beep Boop"},
+	})
+	msg := receive(t, ws)
+	gobottest.Assert(t, msg.ID, "1")
+	gobottest.Assert(t, msg.Type, "result")
+	gobottest.Assert(t, msg.Result, "hey Beep Boop")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:      "2",
+		Type:    "command",
+		Robot:   "Robot1",
+		Command: "robotTestFunction",
+		Params:  map[string]interface{}{"message": "Beep Boop", "robot": "Robot1"},
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.ID, "2")
+	gobottest.Assert(t, msg.Result, "hey Robot1, Beep Boop")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:      "3",
+		Type:    "command",
+		Robot:   "Robot1",
+		Device:  "Device1",
+		Command: "TestDriverCommand",
+		Params:  map[string]interface{}{"name": "human"},
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.ID, "3")
+	gobottest.Assert(t, msg.Result, "hello human")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:      "4",
+		Type:    "command",
+		Robot:   "Robot1",
+		Device:  "Device1",
+		Command: "UnknownCommand",
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.ID, "4")
+	gobottest.Assert(t, msg.Type, "error")
+	gobottest.Assert(t, msg.Error, "Unknown Command")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:      "5",
+		Type:    "command",
+		Robot:   "UnknownRobot1",
+		Command: "robotTestFunction",
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Error, "No Robot found with the name UnknownRobot1")
+
+	// missing params
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:      "6",
+		Type:    "command",
+		Command: "TestFunction",
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.ID, "6")
+	gobottest.Assert(t, msg.Type, "error")
+}
+
+func TestWebSocketFailingCommand(t *testing.T) {
+	a := initTestAPI()
+	a.gobot.AddCommand("fail", func(params map[string]interface{}) interface{} {
+		return errors.New("command failed")
+	})
+	ws, closer := initTestWebSocket(t, a)
+	defer closer()
+
+	websocket.JSON.Send(ws, &WebSocketMessage{ID: "1", Type: "command", Command: "fail"})
+	msg := receive(t, ws)
+	gobottest.Assert(t, msg.ID, "1")
+	gobottest.Assert(t, msg.Type, "error")
+	gobottest.Assert(t, msg.Error, "command failed")
+}
+
+func TestWebSocketTypedCommand(t *testing.T) {
+	a := initTestAPI()
+	a.gobot.AddCommandSchema(gobot.CommandSchema{
+		Name: "Speed",
+		Params: []gobot.CommandParam{
+			{Name: "speed", Type: gobot.IntegerParam, Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return params["speed"].(int)
+	})
+	ws, closer := initTestWebSocket(t, a)
+	defer closer()
+
+	websocket.JSON.Send(ws, &WebSocketMessage{ID: 1, Type: "command", Command: "Speed"})
+	msg := receive(t, ws)
+	gobottest.Assert(t, msg.ID, 1.0)
+	gobottest.Assert(t, msg.Error, map[string]interface{}{
+		"command": "Speed",
+		"param":   "speed",
+		"message": "is required",
+	})
+}
+
+func TestWebSocketSubscribe(t *testing.T) {
+	a := initTestAPI()
+	ws, closer := initTestWebSocket(t, a)
+	defer closer()
+
+	event := a.gobot.Robot("Robot1").
+		Device("Device1").(gobot.Eventer).
+		Event("TestEvent")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "1",
+		Type:   "subscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "TestEvent",
+	})
+	msg := receive(t, ws)
+	gobottest.Assert(t, msg.ID, "1")
+	gobottest.Assert(t, msg.Result, true)
+	gobottest.Assert(t, len(event.Callbacks), 1)
+
+	// subscribing twice does not duplicate events
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "2",
+		Type:   "subscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "TestEvent",
+	})
+	receive(t, ws)
+	gobottest.Assert(t, len(event.Callbacks), 1)
+
+	gobot.Publish(event, "event-data")
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Type, "event")
+	gobottest.Assert(t, msg.Robot, "Robot1")
+	gobottest.Assert(t, msg.Device, "Device1")
+	gobottest.Assert(t, msg.Event, "TestEvent")
+	gobottest.Assert(t, msg.Data, "event-data")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "3",
+		Type:   "unsubscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "TestEvent",
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Result, true)
+	gobottest.Assert(t, len(event.Callbacks), 0)
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "4",
+		Type:   "unsubscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "TestEvent",
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Error, "Not subscribed to the Event TestEvent")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "5",
+		Type:   "subscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "UnknownEvent",
+	})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Error, "No Event found with the name UnknownEvent")
+}
+
+func TestWebSocketUnsubscribeOnClose(t *testing.T) {
+	a := initTestAPI()
+	ws, closer := initTestWebSocket(t, a)
+
+	event := a.gobot.Robot("Robot1").
+		Device("Device1").(gobot.Eventer).
+		Event("TestEvent")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "1",
+		Type:   "subscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "TestEvent",
+	})
+	receive(t, ws)
+	gobottest.Assert(t, callbacks(event), 1)
+
+	closer()
+	timeout := time.After(1 * time.Second)
+	for callbacks(event) != 0 {
+		select {
+		case <-timeout:
+			t.Fatalf("Event \"TestEvent\" was not unsubscribed on close")
+		case <-time.After(1 * time.Millisecond):
+		}
+	}
+}
+
+// callbacks returns the number of callbacks subscribed to event
+func callbacks(event *gobot.Event) int {
+	event.Lock()
+	defer event.Unlock()
+	return len(event.Callbacks)
+}
+
+func TestWebSocketPing(t *testing.T) {
+	a := initTestAPI()
+	a.WebSocketHeartbeat = 10 * time.Millisecond
+	ws, closer := initTestWebSocket(t, a)
+	defer closer()
+
+	websocket.JSON.Send(ws, &WebSocketMessage{ID: "1", Type: "ping"})
+	msg := receive(t, ws)
+	gobottest.Assert(t, msg.ID, "1")
+	gobottest.Assert(t, msg.Type, "pong")
+
+	// heartbeat
+	ws.SetReadDeadline(time.Now().Add(1 * time.Second))
+	msg = &WebSocketMessage{}
+	gobottest.Assert(t, websocket.JSON.Receive(ws, msg), nil)
+	gobottest.Assert(t, msg.Type, "ping")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{ID: "2", Type: "unknown"})
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Error, "Unknown message type unknown")
+
+	websocket.Message.Send(ws, "{")
+	msg = receive(t, ws)
+	gobottest.Assert(t, msg.Type, "error")
+
+	// a silent client is disconnected after two heartbeats
+	<-time.After(50 * time.Millisecond)
+	ws.SetReadDeadline(time.Now().Add(1 * time.Second))
+	for {
+		if err := websocket.JSON.Receive(ws, msg); err != nil {
+			break
+		}
+	}
+}
+
+func TestWebSocketSlowClient(t *testing.T) {
+	a := initTestAPI()
+	a.WebSocketHeartbeat = 50 * time.Millisecond
+	a.WebSocketBuffer = 1
+	ws, closer := initTestWebSocket(t, a)
+	defer closer()
+
+	event := a.gobot.Robot("Robot1").
+		Device("Device1").(gobot.Eventer).
+		Event("TestEvent")
+
+	websocket.JSON.Send(ws, &WebSocketMessage{
+		ID:     "1",
+		Type:   "subscribe",
+		Robot:  "Robot1",
+		Device: "Device1",
+		Event:  "TestEvent",
+	})
+	receive(t, ws)
+
+	// the client stops reading, but keeps pinging while events fill the
+	// connection until the api gives up writing to it
+	data := strings.Repeat("x", 64*1024)
+	timeout := time.After(2 * time.Second)
+	for callbacks(event) != 0 {
+		select {
+		case <-timeout:
+			t.Fatalf("Event \"TestEvent\" was not unsubscribed from a slow client")
+		case <-time.After(1 * time.Millisecond):
+		}
+		gobot.Publish(event, data)
+		ws.SetWriteDeadline(time.Now().Add(10 * time.Millisecond))
+		websocket.JSON.Send(ws, &WebSocketMessage{Type: "ping"})
+	}
+}
+
+func TestWebSocketBackpressure(t *testing.T) {
+	c := &webSocketConn{
+		out:  make(chan *WebSocketMessage, 1),
+		done: make(chan bool),
+	}
+
+	c.publish(&WebSocketMessage{Type: "event", Data: 1})
+	c.publish(&WebSocketMessage{Type: "event", Data: 2})
+	c.publish(&WebSocketMessage{Type: "event", Data: 3})
+	gobottest.Assert(t, c.dropped, 2)
+
+	msg := <-c.out
+	gobottest.Assert(t, msg.Data, 1)
+	gobottest.Assert(t, msg.Dropped, 0)
+
+	c.publish(&WebSocketMessage{Type: "event", Data: 4})
+	msg = <-c.out
+	gobottest.Assert(t, msg.Data, 4)
+	gobottest.Assert(t, msg.Dropped, 2)
+	gobottest.Assert(t, c.dropped, 0)
+
+	close(c.done)
+	c.publish(&WebSocketMessage{Type: "event", Data: 5})
+	gobottest.Assert(t, len(c.out), 0)
+}
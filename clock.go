@@ -0,0 +1,150 @@
+package gobot
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of Every and After.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// Tick returns a channel receiving the time every d duration
+	Tick(d time.Duration) <-chan time.Time
+	// AfterFunc calls f after d duration
+	AfterFunc(d time.Duration, f func())
+}
+
+// DefaultClock is the Clock of Every and After, it is the system clock
+// unless it is set to a VirtualClock to replay recorded events.
+var DefaultClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                        { return time.Now() }
+func (systemClock) Tick(d time.Duration) <-chan time.Time { return time.Tick(d) }
+func (systemClock) AfterFunc(d time.Duration, f func())   { time.AfterFunc(d, f) }
+
+// VirtualClock is a Clock whose time only changes when it is advanced, firing
+// the tickers and timers which are due in order.
+type VirtualClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+}
+
+type virtualTimer struct {
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+	f      func()
+}
+
+// NewVirtualClock returns a new VirtualClock set to now
+func NewVirtualClock(now time.Time) *VirtualClock {
+	return &VirtualClock{now: now}
+}
+
+// Now returns the time of the clock
+func (c *VirtualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Tick returns a channel receiving the time of the clock every d duration it
+// is advanced by. Like a time.Ticker, ticks are dropped when the receiver
+// does not keep up.
+func (c *VirtualClock) Tick(d time.Duration) <-chan time.Time {
+	if d <= 0 {
+		return nil
+	}
+	t := &virtualTimer{period: d, c: make(chan time.Time, 1)}
+	c.add(t, d)
+	return t.c
+}
+
+// AfterFunc calls f once the clock is advanced by d duration
+func (c *VirtualClock) AfterFunc(d time.Duration, f func()) {
+	c.add(&virtualTimer{f: f}, d)
+}
+
+func (c *VirtualClock) add(t *virtualTimer, d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	t.at = c.now.Add(d)
+	c.timers = append(c.timers, t)
+}
+
+// start sets the time of a clock which was never set to now, delaying its
+// tickers and timers accordingly
+func (c *VirtualClock) start(now time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, t := range c.timers {
+		t.at = now.Add(t.at.Sub(c.now))
+	}
+	c.now = now
+}
+
+// Advance advances the clock by d duration, see Set.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set advances the clock to now, firing the tickers and timers which are due
+// in order. The functions of timers are called before Set returns, with the
+// clock set to the time they were due. The clock never goes back in time.
+func (c *VirtualClock) Set(now time.Time) {
+	for {
+		c.mutex.Lock()
+		t := c.next(now)
+		if t == nil {
+			if now.After(c.now) {
+				c.now = now
+			}
+			c.mutex.Unlock()
+			return
+		}
+		c.now = t.at
+		c.mutex.Unlock()
+
+		if t.f != nil {
+			t.f()
+			continue
+		}
+		select {
+		case t.c <- t.at:
+		default:
+		}
+	}
+}
+
+// byDueTime sorts timers by the time they are due
+type byDueTime []*virtualTimer
+
+func (t byDueTime) Len() int           { return len(t) }
+func (t byDueTime) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t byDueTime) Less(i, j int) bool { return t[i].at.Before(t[j].at) }
+
+// next removes the first timer due at or before now, rescheduling it if it
+// is a ticker
+func (c *VirtualClock) next(now time.Time) *virtualTimer {
+	if len(c.timers) == 0 {
+		return nil
+	}
+	sort.Stable(byDueTime(c.timers))
+	t := c.timers[0]
+	if t.at.After(now) {
+		return nil
+	}
+	if t.period > 0 {
+		next := *t
+		next.at = t.at.Add(t.period)
+		c.timers[0] = &next
+	} else {
+		c.timers = c.timers[1:]
+	}
+	return t
+}
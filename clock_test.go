@@ -0,0 +1,79 @@
+package gobot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func TestVirtualClock(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewVirtualClock(start)
+	gobottest.Assert(t, c.Now(), start)
+
+	fired := []time.Time{}
+	c.AfterFunc(3*time.Second, func() { fired = append(fired, c.Now()) })
+	c.AfterFunc(1*time.Second, func() {
+		fired = append(fired, c.Now())
+		c.AfterFunc(1*time.Second, func() { fired = append(fired, c.Now()) })
+	})
+
+	c.Advance(500 * time.Millisecond)
+	gobottest.Assert(t, len(fired), 0)
+	gobottest.Assert(t, c.Now(), start.Add(500*time.Millisecond))
+
+	c.Advance(5 * time.Second)
+	gobottest.Assert(t, fired, []time.Time{
+		start.Add(1 * time.Second),
+		start.Add(2 * time.Second),
+		start.Add(3 * time.Second),
+	})
+	gobottest.Assert(t, c.Now(), start.Add(5500*time.Millisecond))
+
+	// never goes back in time
+	c.Set(start)
+	gobottest.Assert(t, c.Now(), start.Add(5500*time.Millisecond))
+}
+
+func TestVirtualClockTick(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewVirtualClock(start)
+	tick := c.Tick(time.Second)
+	gobottest.Assert(t, c.Tick(0), (<-chan time.Time)(nil))
+
+	c.Advance(time.Second)
+	gobottest.Assert(t, <-tick, start.Add(time.Second))
+
+	// ticks are dropped when they are not received
+	c.Advance(3 * time.Second)
+	gobottest.Assert(t, <-tick, start.Add(2*time.Second))
+	select {
+	case <-tick:
+		t.Errorf("Tick should have been dropped")
+	default:
+	}
+}
+
+func TestVirtualClockEveryAndAfter(t *testing.T) {
+	c := NewVirtualClock(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC))
+	DefaultClock = c
+	defer func() { DefaultClock = systemClock{} }()
+
+	after := make(chan bool, 1)
+	every := make(chan bool, 1)
+	After(time.Minute, func() { after <- true })
+	Every(time.Minute, func() { every <- true })
+
+	c.Advance(time.Minute)
+	select {
+	case <-after:
+	default:
+		t.Errorf("After was not triggered")
+	}
+	select {
+	case <-every:
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("Every was not triggered")
+	}
+}
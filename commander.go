@@ -1,7 +1,81 @@
 package gobot
 
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ParamType is the type of a command parameter
+type ParamType string
+
+const (
+	// NumberParam is a float64 parameter
+	NumberParam ParamType = "number"
+	// IntegerParam is an int parameter
+	IntegerParam ParamType = "integer"
+	// BooleanParam is a bool parameter
+	BooleanParam ParamType = "boolean"
+	// StringParam is a string parameter
+	StringParam ParamType = "string"
+	// ArrayParam is a slice parameter, such as a []interface{} decoded from
+	// a JSON array
+	ArrayParam ParamType = "array"
+)
+
+// ParamRange is the inclusive range of a NumberParam or IntegerParam
+type ParamRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// CommandParam describes a parameter of a command.
+type CommandParam struct {
+	Name        string      `json:"name"`
+	Type        ParamType   `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required"`
+	Range       *ParamRange `json:"range,omitempty"`
+	// Default is the value of the parameter when it is omitted
+	Default interface{} `json:"default,omitempty"`
+}
+
+// CommandSchema describes a command and its parameters.
+type CommandSchema struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Params      []CommandParam `json:"params"`
+}
+
+// CommandError is the result of a command invoked with invalid parameters.
+type CommandError struct {
+	Command string `json:"command"`
+	Param   string `json:"param"`
+	Message string `json:"message"`
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("Command %q: param %q %v", e.Command, e.Param, e.Message)
+}
+
+// CommandStats counts the invocations of a command.
+type CommandStats struct {
+	Count uint64
+	// Errors is the number of invocations which returned an error
+	Errors uint64
+	// Seconds is the total duration of the invocations
+	Seconds float64
+}
+
 type commander struct {
 	commands map[string]func(map[string]interface{}) interface{}
+	schemas  map[string]CommandSchema
+	stats    map[string]*CommandStats
+	mutex    sync.Mutex
 }
 
 // Commander is the interface which describes the behaviour for a Driver or Adaptor
@@ -13,12 +87,23 @@ type Commander interface {
 	Commands() (commands map[string]func(map[string]interface{}) interface{})
 	// AddCommand adds a command given a name.
 	AddCommand(name string, command func(map[string]interface{}) interface{})
+	// AddCommandSchema adds a command described by schema. Its parameters are
+	// validated and coerced to their types before the command is invoked.
+	AddCommandSchema(schema CommandSchema, command func(map[string]interface{}) interface{})
+	// Schema returns the schema of a command given a name.
+	Schema(name string) (schema CommandSchema, ok bool)
+	// Schemas returns the schemas of every command sorted by name.
+	Schemas() (schemas []CommandSchema)
+	// CommandStats returns the invocation counts of every command invoked.
+	CommandStats() (stats map[string]CommandStats)
 }
 
 // NewCommander returns a new Commander.
 func NewCommander() Commander {
 	return &commander{
 		commands: make(map[string]func(map[string]interface{}) interface{}),
+		schemas:  make(map[string]CommandSchema),
+		stats:    make(map[string]*CommandStats),
 	}
 }
 
@@ -32,5 +117,190 @@ func (c *commander) Commands() map[string]func(map[string]interface{}) interface
 }
 
 func (c *commander) AddCommand(name string, command func(map[string]interface{}) interface{}) {
-	c.commands[name] = command
+	c.commands[name] = c.measure(name, command)
+	delete(c.schemas, name)
+}
+
+func (c *commander) AddCommandSchema(schema CommandSchema, command func(map[string]interface{}) interface{}) {
+	c.commands[schema.Name] = c.measure(schema.Name, func(params map[string]interface{}) interface{} {
+		coerced, err := schema.Coerce(params)
+		if err != nil {
+			return err
+		}
+		return command(coerced)
+	})
+	c.schemas[schema.Name] = schema
+}
+
+func (c *commander) CommandStats() map[string]CommandStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := make(map[string]CommandStats)
+	for name, s := range c.stats {
+		stats[name] = *s
+	}
+	return stats
+}
+
+// measure returns command counting its invocations, their errors and their
+// duration in the stats of name
+func (c *commander) measure(name string, command func(map[string]interface{}) interface{}) func(map[string]interface{}) interface{} {
+	return func(params map[string]interface{}) interface{} {
+		start := time.Now()
+		result := command(params)
+		_, failed := result.(error)
+
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		s, ok := c.stats[name]
+		if !ok {
+			s = &CommandStats{}
+			c.stats[name] = s
+		}
+		s.Count++
+		s.Seconds += time.Since(start).Seconds()
+		if failed {
+			s.Errors++
+		}
+		return result
+	}
+}
+
+// Schema returns the schema of a command given a name, a command added
+// without a schema has one without params.
+func (c *commander) Schema(name string) (schema CommandSchema, ok bool) {
+	if _, ok = c.commands[name]; !ok {
+		return
+	}
+	if schema, ok = c.schemas[name]; !ok {
+		schema, ok = CommandSchema{Name: name, Params: []CommandParam{}}, true
+	}
+	return
+}
+
+func (c *commander) Schemas() (schemas []CommandSchema) {
+	names := []string{}
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schemas = []CommandSchema{}
+	for _, name := range names {
+		schema, _ := c.Schema(name)
+		schemas = append(schemas, schema)
+	}
+	return
+}
+
+// Coerce validates params against the schema and returns a copy of them with
+// every declared parameter converted to its type, numbers to float64,
+// integers to int, and omitted parameters set to their default. Parameters
+// which are not declared are passed through unchanged. The error is a
+// *CommandError naming the invalid parameter.
+func (s CommandSchema) Coerce(params map[string]interface{}) (coerced map[string]interface{}, err error) {
+	coerced = make(map[string]interface{})
+	for name, val := range params {
+		coerced[name] = val
+	}
+
+	for _, param := range s.Params {
+		val, ok := params[param.Name]
+		if !ok || val == nil {
+			if param.Required {
+				return nil, &CommandError{Command: s.Name, Param: param.Name, Message: "is required"}
+			}
+			if param.Default == nil {
+				delete(coerced, param.Name)
+				continue
+			}
+			val = param.Default
+		}
+
+		v, e := param.coerce(val)
+		if e != nil {
+			return nil, &CommandError{Command: s.Name, Param: param.Name, Message: e.Error()}
+		}
+		coerced[param.Name] = v
+	}
+	return
+}
+
+// coerce converts val to the type of the parameter and checks its range
+func (p CommandParam) coerce(val interface{}) (interface{}, error) {
+	switch p.Type {
+	case BooleanParam:
+		switch v := val.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("must be of type %v, got %v", p.Type, val)
+	case StringParam:
+		if v, ok := val.(string); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("must be of type %v, got %v", p.Type, val)
+	case ArrayParam:
+		if reflect.ValueOf(val).Kind() == reflect.Slice {
+			return val, nil
+		}
+		return nil, fmt.Errorf("must be of type %v, got %v", p.Type, val)
+	case NumberParam, IntegerParam:
+		f, ok := toFloat64(val)
+		if !ok {
+			return nil, fmt.Errorf("must be of type %v, got %v", p.Type, val)
+		}
+		if p.Range != nil && (f < p.Range.Min || f > p.Range.Max) {
+			return nil, fmt.Errorf("must be between %v and %v, got %v", p.Range.Min, p.Range.Max, f)
+		}
+		if p.Type == NumberParam {
+			return f, nil
+		}
+		if f != math.Trunc(f) {
+			return nil, fmt.Errorf("must be of type %v, got %v", p.Type, f)
+		}
+		return int(f), nil
+	}
+	return val, nil
+}
+
+// toFloat64 converts a JSON number, a go number or a numeric string to a
+// float64
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
 }
@@ -22,3 +22,136 @@ func TestCommaner(t *testing.T) {
 	command = c.Command("booyeah")
 	gobottest.Assert(t, command, (func(map[string]interface{}) interface{})(nil))
 }
+
+func TestCommanderSchema(t *testing.T) {
+	c := NewCommander()
+	c.AddCommand("untyped", func(map[string]interface{}) interface{} {
+		return nil
+	})
+	c.AddCommandSchema(CommandSchema{
+		Name:        "typed",
+		Description: "a typed command",
+		Params: []CommandParam{
+			{Name: "level", Type: IntegerParam, Required: true, Range: &ParamRange{Min: 0, Max: 255}},
+			{Name: "rate", Type: NumberParam, Default: 1.5},
+			{Name: "enabled", Type: BooleanParam},
+			{Name: "label", Type: StringParam},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return params
+	})
+
+	schema, ok := c.Schema("typed")
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, schema.Description, "a typed command")
+	gobottest.Assert(t, len(schema.Params), 4)
+
+	schema, ok = c.Schema("untyped")
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, schema, CommandSchema{Name: "untyped", Params: []CommandParam{}})
+
+	_, ok = c.Schema("booyeah")
+	gobottest.Assert(t, ok, false)
+
+	schemas := c.Schemas()
+	gobottest.Assert(t, len(schemas), 2)
+	gobottest.Assert(t, schemas[0].Name, "typed")
+	gobottest.Assert(t, schemas[1].Name, "untyped")
+
+	// replacing a typed command with an untyped one drops its schema
+	c.AddCommand("typed", func(map[string]interface{}) interface{} {
+		return nil
+	})
+	schema, _ = c.Schema("typed")
+	gobottest.Assert(t, len(schema.Params), 0)
+}
+
+func TestCommanderSchemaCoercion(t *testing.T) {
+	c := NewCommander()
+	c.AddCommandSchema(CommandSchema{
+		Name: "typed",
+		Params: []CommandParam{
+			{Name: "level", Type: IntegerParam, Required: true, Range: &ParamRange{Min: 0, Max: 255}},
+			{Name: "rate", Type: NumberParam, Default: 1.5},
+			{Name: "enabled", Type: BooleanParam},
+			{Name: "label", Type: StringParam},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return params
+	})
+	command := c.Command("typed")
+
+	result := command(map[string]interface{}{"level": 100.0, "enabled": "true", "label": "a", "other": 1})
+	gobottest.Assert(t, result, map[string]interface{}{
+		"level":   100,
+		"rate":    1.5,
+		"enabled": true,
+		"label":   "a",
+		"other":   1,
+	})
+
+	result = command(map[string]interface{}{"level": "7", "rate": uint8(2)})
+	gobottest.Assert(t, result, map[string]interface{}{"level": 7, "rate": 2.0})
+
+	result = command(map[string]interface{}{})
+	gobottest.Assert(t, result, &CommandError{Command: "typed", Param: "level", Message: "is required"})
+	gobottest.Assert(t, result.(error).Error(), `Command "typed": param "level" is required`)
+
+	result = command(nil)
+	gobottest.Assert(t, result.(*CommandError).Param, "level")
+
+	result = command(map[string]interface{}{"level": 256.0})
+	gobottest.Assert(t, result, &CommandError{Command: "typed", Param: "level", Message: "must be between 0 and 255, got 256"})
+
+	result = command(map[string]interface{}{"level": 1.5})
+	gobottest.Assert(t, result, &CommandError{Command: "typed", Param: "level", Message: "must be of type integer, got 1.5"})
+
+	result = command(map[string]interface{}{"level": "high"})
+	gobottest.Assert(t, result, &CommandError{Command: "typed", Param: "level", Message: "must be of type integer, got high"})
+
+	result = command(map[string]interface{}{"level": 1.0, "enabled": 2.0})
+	gobottest.Assert(t, result.(*CommandError).Param, "enabled")
+
+	result = command(map[string]interface{}{"level": 1.0, "label": 2.0})
+	gobottest.Assert(t, result.(*CommandError).Param, "label")
+
+	c.AddCommandSchema(CommandSchema{
+		Name:   "array",
+		Params: []CommandParam{{Name: "items", Type: ArrayParam, Required: true}},
+	}, func(params map[string]interface{}) interface{} {
+		return params
+	})
+	command = c.Command("array")
+
+	result = command(map[string]interface{}{"items": []interface{}{1.0, "a"}})
+	gobottest.Assert(t, result, map[string]interface{}{"items": []interface{}{1.0, "a"}})
+
+	result = command(map[string]interface{}{"items": []int{}})
+	gobottest.Assert(t, result, map[string]interface{}{"items": []int{}})
+
+	result = command(map[string]interface{}{"items": nil})
+	gobottest.Assert(t, result, &CommandError{Command: "array", Param: "items", Message: "is required"})
+
+	result = command(map[string]interface{}{"items": "a"})
+	gobottest.Assert(t, result, &CommandError{Command: "array", Param: "items", Message: "must be of type array, got a"})
+}
+
+func TestCommanderStats(t *testing.T) {
+	c := NewCommander()
+	c.AddCommand("ok", func(map[string]interface{}) interface{} { return nil })
+	c.AddCommandSchema(CommandSchema{
+		Name:   "typed",
+		Params: []CommandParam{{Name: "speed", Type: IntegerParam, Required: true}},
+	}, func(map[string]interface{}) interface{} { return nil })
+
+	c.Command("ok")(nil)
+	c.Command("ok")(nil)
+	c.Command("typed")(map[string]interface{}{})
+
+	stats := c.CommandStats()
+	gobottest.Assert(t, len(stats), 2)
+	gobottest.Assert(t, stats["ok"].Count, uint64(2))
+	gobottest.Assert(t, stats["ok"].Errors, uint64(0))
+	gobottest.Assert(t, stats["typed"].Count, uint64(1))
+	gobottest.Assert(t, stats["typed"].Errors, uint64(1))
+}
@@ -2,22 +2,30 @@ package gobot
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 )
 
 // JSONConnection is a JSON representation of a Connection.
 type JSONConnection struct {
-	Name    string `json:"name"`
-	Adaptor string `json:"adaptor"`
+	Name      string   `json:"name"`
+	Adaptor   string   `json:"adaptor"`
+	Port      string   `json:"port"`
+	Connected bool     `json:"connected"`
+	Pins      []string `json:"pins"`
 }
 
-// NewJSONConnection returns a JSONConnection given a Connection.
+// NewJSONConnection returns a JSONConnection given a Connection. Its status
+// and pins are known by the robot of the connection, see NewJSONRobot.
 func NewJSONConnection(connection Connection) *JSONConnection {
-	return &JSONConnection{
+	jsonConnection := &JSONConnection{
 		Name:    connection.Name(),
 		Adaptor: reflect.TypeOf(connection).String(),
+		Pins:    []string{},
 	}
+	if porter, ok := connection.(Porter); ok {
+		jsonConnection.Port = porter.Port()
+	}
+	return jsonConnection
 }
 
 // A Connection is an instance of an Adaptor
@@ -40,15 +48,22 @@ func (c *Connections) Each(f func(Connection)) {
 
 // Start calls Connect on each Connection in c
 func (c *Connections) Start() (errs []error) {
-	log.Println("Starting connections...")
-	for _, connection := range *c {
-		info := "Starting connection " + connection.Name()
+	return c.start("", DefaultLogger, func(Connection) {})
+}
 
-		if porter, ok := connection.(Porter); ok {
-			info = info + " on port " + porter.Port()
+// start calls Connect on each Connection of the robot in c, logging to log,
+// and connected with each Connection which connected successfully
+func (c *Connections) start(robot string, log Logger, connected func(Connection)) (errs []error) {
+	for _, connection := range *c {
+		fields := Fields{"connection": connection.Name()}
+		if robot != "" {
+			fields["robot"] = robot
+		}
+		if porter, ok := connection.(Porter); ok && porter.Port() != "" {
+			fields["port"] = porter.Port()
 		}
 
-		log.Println(info + "...")
+		log.Log(InfoLevel, "Starting connection", fields)
 
 		if errs = connection.Connect(); len(errs) > 0 {
 			for i, err := range errs {
@@ -56,6 +71,7 @@ func (c *Connections) Start() (errs []error) {
 			}
 			return
 		}
+		connected(connection)
 	}
 	return
 }
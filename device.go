@@ -2,16 +2,17 @@ package gobot
 
 import (
 	"fmt"
-	"log"
 	"reflect"
 )
 
 // JSONDevice is a JSON representation of a Device.
 type JSONDevice struct {
-	Name       string   `json:"name"`
-	Driver     string   `json:"driver"`
-	Connection string   `json:"connection"`
-	Commands   []string `json:"commands"`
+	Name       string          `json:"name"`
+	Driver     string          `json:"driver"`
+	Connection string          `json:"connection"`
+	Commands   []string        `json:"commands"`
+	Schemas    []CommandSchema `json:"schemas"`
+	Events     []string        `json:"events"`
 }
 
 // NewJSONDevice returns a JSONDevice given a Device.
@@ -20,6 +21,8 @@ func NewJSONDevice(device Device) *JSONDevice {
 		Name:       device.Name(),
 		Driver:     reflect.TypeOf(device).String(),
 		Commands:   []string{},
+		Schemas:    []CommandSchema{},
+		Events:     []string{},
 		Connection: "",
 	}
 	if device.Connection() != nil {
@@ -29,6 +32,10 @@ func NewJSONDevice(device Device) *JSONDevice {
 		for command := range commander.Commands() {
 			jsonDevice.Commands = append(jsonDevice.Commands, command)
 		}
+		jsonDevice.Schemas = commander.Schemas()
+	}
+	if eventer, ok := device.(Eventer); ok {
+		jsonDevice.Events = eventNames(eventer)
 	}
 	return jsonDevice
 }
@@ -53,16 +60,17 @@ func (d *Devices) Each(f func(Device)) {
 
 // Start calls Start on each Device in d
 func (d *Devices) Start() (errs []error) {
-	log.Println("Starting devices...")
-	for _, device := range *d {
-		info := "Starting device " + device.Name()
-
-		if pinner, ok := device.(Pinner); ok {
-			info = info + " on pin " + pinner.Pin()
-		}
+	return d.start("", DefaultLogger)
+}
 
-		log.Println(info + "...")
+// start calls Start on each Device of the robot in d, logging to log and
+// counting the errors in DefaultMetrics
+func (d *Devices) start(robot string, log Logger) (errs []error) {
+	for _, device := range *d {
+		log.Log(InfoLevel, "Starting device", deviceFields(robot, device))
 		if errs = device.Start(); len(errs) > 0 {
+			DefaultMetrics.Add("gobot_device_errors_total", float64(len(errs)),
+				"robot", robot, "device", device.Name(), "operation", "start")
 			for i, err := range errs {
 				errs[i] = fmt.Errorf("Device %q: %v", device.Name(), err)
 			}
@@ -72,10 +80,33 @@ func (d *Devices) Start() (errs []error) {
 	return
 }
 
+// deviceFields returns the log fields of a device of robot
+func deviceFields(robot string, device Device) Fields {
+	fields := Fields{"device": device.Name()}
+	if robot != "" {
+		fields["robot"] = robot
+	}
+	if pinner, ok := device.(Pinner); ok && pinner.Pin() != "" {
+		fields["pin"] = pinner.Pin()
+	}
+	if device.Connection() != nil {
+		fields["connection"] = device.Connection().Name()
+	}
+	return fields
+}
+
 // Halt calls Halt on each Device in d
 func (d *Devices) Halt() (errs []error) {
+	return d.halt("")
+}
+
+// halt calls Halt on each Device of the robot in d, counting the errors in
+// DefaultMetrics
+func (d *Devices) halt(robot string) (errs []error) {
 	for _, device := range *d {
 		if derrs := device.Halt(); len(derrs) > 0 {
+			DefaultMetrics.Add("gobot_device_errors_total", float64(len(derrs)),
+				"robot", robot, "device", device.Name(), "operation", "halt")
 			for i, err := range derrs {
 				derrs[i] = fmt.Errorf("Device %q: %v", device.Name(), err)
 			}
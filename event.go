@@ -5,12 +5,16 @@ import "sync"
 type callback struct {
 	f    func(interface{})
 	once bool
+	// id identifies the callbacks added with Subscribe, it is 0 otherwise
+	id uint64
 }
 
 // Event executes the list of Callbacks when Chan is written to.
 type Event struct {
 	sync.Mutex
 	Callbacks []callback
+	lastID    uint64
+	writes    uint64
 }
 
 // NewEvent returns a new Event which is now listening for data.
@@ -24,6 +28,7 @@ func (e *Event) Write(data interface{}) {
 	e.Lock()
 	defer e.Unlock()
 
+	e.writes++
 	tmp := []callback{}
 	for _, cb := range e.Callbacks {
 		go cb.f(data)
@@ -33,3 +38,37 @@ func (e *Event) Write(data interface{}) {
 	}
 	e.Callbacks = tmp
 }
+
+// Writes returns the number of times the Event was written to.
+func (e *Event) Writes() uint64 {
+	e.Lock()
+	defer e.Unlock()
+	return e.writes
+}
+
+// add appends a callback, returning its id when it can be removed
+func (e *Event) add(f func(interface{}), once bool, removable bool) (id uint64) {
+	e.Lock()
+	defer e.Unlock()
+
+	if removable {
+		e.lastID++
+		id = e.lastID
+	}
+	e.Callbacks = append(e.Callbacks, callback{f, once, id})
+	return
+}
+
+// remove removes the callback identified by id
+func (e *Event) remove(id uint64) {
+	e.Lock()
+	defer e.Unlock()
+
+	tmp := []callback{}
+	for _, cb := range e.Callbacks {
+		if cb.id != id {
+			tmp = append(tmp, cb)
+		}
+	}
+	e.Callbacks = tmp
+}
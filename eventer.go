@@ -1,5 +1,7 @@
 package gobot
 
+import "sort"
+
 type eventer struct {
 	events map[string]*Event
 }
@@ -34,3 +36,13 @@ func (e *eventer) Event(name string) (event *Event) {
 func (e *eventer) AddEvent(name string) {
 	e.events[name] = NewEvent()
 }
+
+// eventNames returns the names of the events of e sorted by name
+func eventNames(e Eventer) (names []string) {
+	names = []string{}
+	for name := range e.Events() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
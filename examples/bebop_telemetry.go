@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/arcommands"
+	"github.com/hybridgroup/gobot/platforms/bebop"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	bebopAdaptor := bebop.NewBebopAdaptor("Drone")
+	drone := bebop.NewBebopDriver(bebopAdaptor, "Drone")
+
+	work := func() {
+		gobot.On(drone.Event("battery"), func(data interface{}) {
+			fmt.Printf("battery: %d%%\n", data.(arcommands.BatteryState).Percent)
+		})
+
+		gobot.On(drone.Event("flyingstate"), func(data interface{}) {
+			fmt.Println("flying state:", data.(arcommands.FlyingState).State)
+		})
+
+		gobot.On(drone.Event("position"), func(data interface{}) {
+			p := data.(arcommands.Position)
+			fmt.Printf("position: %.6f, %.6f at %.1fm\n", p.Latitude, p.Longitude, p.Altitude)
+		})
+
+		gobot.On(drone.Event("attitude"), func(data interface{}) {
+			a := data.(arcommands.Attitude)
+			fmt.Printf("attitude: roll %.2f pitch %.2f yaw %.2f\n", a.Roll, a.Pitch, a.Yaw)
+		})
+	}
+
+	robot := gobot.NewRobot("drone",
+		[]gobot.Connection{bebopAdaptor},
+		[]gobot.Device{drone},
+		work,
+	)
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
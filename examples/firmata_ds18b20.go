@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/firmata"
+	"github.com/hybridgroup/gobot/platforms/onewire"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	firmataAdaptor := firmata.NewFirmataAdaptor("firmata", "/dev/ttyACM0")
+	ds18b20 := onewire.NewDS18B20Driver(firmataAdaptor, "ds18b20", "2", 5*time.Second)
+
+	work := func() {
+		ds18b20.SetResolution(10)
+
+		gobot.Every(5*time.Second, func() {
+			for id, celsius := range ds18b20.Temperatures() {
+				fmt.Println(id, celsius)
+			}
+		})
+	}
+
+	robot := gobot.NewRobot("thermometerBot",
+		[]gobot.Connection{firmataAdaptor},
+		[]gobot.Device{ds18b20},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
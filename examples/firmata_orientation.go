@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/firmata"
+	"github.com/hybridgroup/gobot/platforms/i2c"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	firmataAdaptor := firmata.NewFirmataAdaptor("firmata", "/dev/ttyACM0")
+	mpu6050 := i2c.NewMPU6050Driver(firmataAdaptor, "mpu6050")
+	hmc6352 := i2c.NewHMC6352Driver(firmataAdaptor, "hmc6352")
+	orientation := i2c.NewOrientationDriver(mpu6050, "orientation")
+	orientation.SetFilter(i2c.NewMadgwickFilter(0.1))
+	orientation.SetCompass(hmc6352)
+
+	work := func() {
+		mpu6050.SetDLPF(i2c.MPU6050_DLPF_BW_42)
+		mpu6050.SetGyroRange(i2c.MPU6050_GYRO_FS_500)
+
+		gobot.On(orientation.Event(i2c.Calibrated), func(data interface{}) {
+			fmt.Println("Gyroscope bias", data)
+		})
+		gobot.On(orientation.Event(i2c.Orientation), func(data interface{}) {
+			o := data.(i2c.EulerAngles)
+			fmt.Printf("Roll %.1f Pitch %.1f Yaw %.1f\n", o.Roll, o.Pitch, o.Yaw)
+		})
+	}
+
+	robot := gobot.NewRobot("orientationBot",
+		[]gobot.Connection{firmataAdaptor},
+		[]gobot.Device{mpu6050, hmc6352, orientation},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/firmata"
+	"github.com/hybridgroup/gobot/platforms/i2c"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	firmataAdaptor := firmata.NewFirmataAdaptor("firmata", "/dev/ttyACM0")
+	sht3x := i2c.NewSHT3xDriver(firmataAdaptor, "sht3x", i2c.SHT3xAddress)
+
+	work := func() {
+		gobot.Every(1*time.Second, func() {
+			fmt.Println("Temperature", sht3x.Temperature())
+			fmt.Println("Humidity", sht3x.Humidity())
+		})
+	}
+
+	robot := gobot.NewRobot("sht3xBot",
+		[]gobot.Connection{firmataAdaptor},
+		[]gobot.Device{sht3x},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/firmata"
+	"github.com/hybridgroup/gobot/platforms/ledstrip"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	firmataAdaptor := firmata.NewFirmataAdaptor("arduino", "/dev/ttyACM0")
+	strip := ledstrip.NewWS2812Driver(firmataAdaptor, "strip", "6", 8)
+
+	work := func() {
+		strip.SetGamma(2.5)
+		colors := []color.RGBA{
+			{R: 255, A: 255},
+			{G: 255, A: 255},
+			{B: 255, A: 255},
+		}
+		i := 0
+		gobot.Every(2*time.Second, func() {
+			strip.Fade(colors[i%len(colors)], 1*time.Second)
+			i++
+		})
+	}
+
+	robot := gobot.NewRobot("fadeBot",
+		[]gobot.Connection{firmataAdaptor},
+		[]gobot.Device{strip},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
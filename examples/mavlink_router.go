@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/mavlink"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	// a SITL simulator sending to port 14550, routed to a ground station
+	// listening to port 14551
+	sitl := mavlink.NewMavlinkAdaptor("sitl", "udp:0.0.0.0:14550")
+	gcs := mavlink.NewMavlinkAdaptor("gcs", "udpout:127.0.0.1:14551")
+	router := mavlink.NewMavlinkRouter("router", sitl, gcs)
+
+	work := func() {
+		gobot.On(router.Event("packet"), func(data interface{}) {
+			packet := data.(*common.MAVLinkPacket)
+			fmt.Println("packet", packet.SystemID, packet.ComponentID, packet.FullMessageID())
+		})
+	}
+
+	robot := gobot.NewRobot("mavRouter",
+		[]gobot.Connection{sitl, gcs},
+		[]gobot.Device{router},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/api"
+	"github.com/hybridgroup/gobot/platforms/mavlink"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+	api.NewAPI(gbot).Start()
+
+	adaptor := mavlink.NewMavlinkAdaptor("sitl", "udp:0.0.0.0:14550")
+	driver := mavlink.NewMavlinkDriver(adaptor, "sitl")
+	copter := mavlink.NewMavlinkVehicle(driver, "copter", 1, 1)
+
+	work := func() {
+		err := copter.UploadMission([]mavlink.MissionItem{
+			{Command: common.MAV_CMD_NAV_TAKEOFF, Frame: common.MAV_FRAME_GLOBAL_RELATIVE_ALT, Z: 10, Autocontinue: true},
+			{Command: common.MAV_CMD_NAV_WAYPOINT, Frame: common.MAV_FRAME_GLOBAL_RELATIVE_ALT, X: -35.36, Y: 149.16, Z: 20, Autocontinue: true},
+			{Command: common.MAV_CMD_NAV_RETURN_TO_LAUNCH, Autocontinue: true},
+		})
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+
+		// takes off in GUIDED mode, then flies the mission in AUTO mode
+		for _, request := range []func() error{
+			func() error { return copter.SetMode(4) },
+			copter.Arm,
+			func() error { return copter.Takeoff(10) },
+		} {
+			if err := request(); err != nil {
+				fmt.Println(err)
+				return
+			}
+		}
+		gobot.On(copter.Event("heartbeat"), func(data interface{}) {
+			fmt.Println("mode", data.(*common.Heartbeat).CUSTOM_MODE, "armed", copter.Armed())
+		})
+		gobot.After(10*time.Second, func() { copter.SetMode(3) })
+	}
+
+	robot := gobot.NewRobot("mavBot",
+		[]gobot.Connection{adaptor},
+		[]gobot.Device{driver, copter},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
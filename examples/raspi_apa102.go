@@ -0,0 +1,31 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/ledstrip"
+	"github.com/hybridgroup/gobot/platforms/raspi"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	r := raspi.NewRaspiAdaptor("raspi")
+	strip := ledstrip.NewAPA102Driver(r, "strip", "0.0", 60)
+
+	work := func() {
+		strip.SetBrightness(64)
+		strip.Rainbow(20 * time.Millisecond)
+	}
+
+	robot := gobot.NewRobot("rainbowBot",
+		[]gobot.Connection{r},
+		[]gobot.Device{strip},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/i2c"
+	"github.com/hybridgroup/gobot/platforms/raspi"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	r := raspi.NewRaspiAdaptor("raspi")
+	bme280 := i2c.NewBME280Driver(r, "bme280", i2c.BMP280Address)
+
+	work := func() {
+		gobot.On(bme280.Event(i2c.Data), func(data interface{}) {
+			d := data.(map[string]float32)
+			fmt.Println("Temperature", d["temperature"])
+			fmt.Println("Pressure", d["pressure"])
+			fmt.Println("Humidity", d["humidity"])
+		})
+	}
+
+	robot := gobot.NewRobot("weatherBot",
+		[]gobot.Connection{r},
+		[]gobot.Device{bme280},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/onewire"
+	"github.com/hybridgroup/gobot/platforms/raspi"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	r := raspi.NewRaspiAdaptor("raspi")
+	ds18b20 := onewire.NewDS18B20Driver(r, "ds18b20", "w1_bus_master1")
+
+	work := func() {
+		gobot.On(ds18b20.Event(onewire.Data), func(data interface{}) {
+			t := data.(onewire.DS18B20Temperature)
+			fmt.Println(t.ID, t.Celsius)
+		})
+	}
+
+	robot := gobot.NewRobot("thermometerBot",
+		[]gobot.Connection{r},
+		[]gobot.Device{ds18b20},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/gpio"
+	"github.com/hybridgroup/gobot/platforms/i2c"
+	"github.com/hybridgroup/gobot/platforms/raspi"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	r := raspi.NewRaspiAdaptor("raspi")
+	// INTA and INTB are both routed to INTA, which is wired to pin 7
+	mcp := i2c.NewMCP23017Driver(r, "mcp23017", i2c.MCP23017Config{Mirror: 1}, 0x20)
+	mcp.SetInterruptPin(r, "7")
+	led := gpio.NewLedDriver(mcp, "led", "A0")
+	button := gpio.NewButtonDriver(mcp, "button", "B7")
+
+	work := func() {
+		if err := mcp.EnableInterrupt("B7"); err != nil {
+			fmt.Println(err)
+		}
+
+		gobot.On(button.Event("push"), func(data interface{}) {
+			led.On()
+		})
+		gobot.On(button.Event("release"), func(data interface{}) {
+			led.Off()
+		})
+	}
+
+	robot := gobot.NewRobot("expanderBot",
+		[]gobot.Connection{r},
+		[]gobot.Device{mcp, led, button},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/gpio"
+	"github.com/hybridgroup/gobot/platforms/i2c"
+	"github.com/hybridgroup/gobot/platforms/raspi"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	r := raspi.NewRaspiAdaptor("raspi")
+	pca9685 := i2c.NewPCA9685Driver(r, "pca9685", i2c.PCA9685Address)
+	shoulder := gpio.NewServoDriver(pca9685, "shoulder", "0")
+	elbow := gpio.NewServoDriver(pca9685, "elbow", "1")
+	led := gpio.NewLedDriver(pca9685, "led", "15")
+
+	work := func() {
+		angle := uint8(0)
+		brightness := uint8(0)
+
+		gobot.Every(100*time.Millisecond, func() {
+			shoulder.Move(angle)
+			elbow.Move(180 - angle)
+			led.Brightness(brightness)
+
+			angle = (angle + 10) % 190
+			brightness += 15
+		})
+	}
+
+	robot := gobot.NewRobot("armBot",
+		[]gobot.Connection{r},
+		[]gobot.Device{pca9685, shoulder, elbow, led},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
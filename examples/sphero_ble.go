@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/ble"
+	"github.com/hybridgroup/gobot/platforms/sphero"
+)
+
+func main() {
+	gbot := gobot.NewGobot()
+
+	adaptor := ble.NewSpheroBLEAdaptor("bb8", os.Args[1])
+	driver := sphero.NewSpheroDriver(adaptor, "bb8")
+
+	work := func() {
+		gobot.On(driver.Event(sphero.Collision), func(data interface{}) {
+			fmt.Printf("Collision Detected! %+v\n", data)
+		})
+
+		gobot.Every(3*time.Second, func() {
+			driver.Roll(40, uint16(gobot.Rand(360)))
+		})
+
+		gobot.Every(1*time.Second, func() {
+			r := uint8(gobot.Rand(255))
+			g := uint8(gobot.Rand(255))
+			b := uint8(gobot.Rand(255))
+			driver.SetRGB(r, g, b)
+		})
+	}
+
+	robot := gobot.NewRobot("bb8",
+		[]gobot.Connection{adaptor},
+		[]gobot.Device{driver},
+		work,
+	)
+
+	gbot.AddRobot(robot)
+
+	gbot.Start()
+}
@@ -1,15 +1,16 @@
 package gobot
 
 import (
-	"log"
 	"os"
 	"os/signal"
 )
 
 // JSONGobot is a JSON representation of a Gobot.
 type JSONGobot struct {
-	Robots   []*JSONRobot `json:"robots"`
-	Commands []string     `json:"commands"`
+	Robots   []*JSONRobot    `json:"robots"`
+	Commands []string        `json:"commands"`
+	Schemas  []CommandSchema `json:"schemas"`
+	Events   []string        `json:"events"`
 }
 
 // NewJSONGobot returns a JSONGobt given a Gobot.
@@ -17,6 +18,8 @@ func NewJSONGobot(gobot *Gobot) *JSONGobot {
 	jsonGobot := &JSONGobot{
 		Robots:   []*JSONRobot{},
 		Commands: []string{},
+		Schemas:  gobot.Schemas(),
+		Events:   eventNames(gobot),
 	}
 
 	for command := range gobot.Commands() {
@@ -32,9 +35,11 @@ func NewJSONGobot(gobot *Gobot) *JSONGobot {
 // Gobot is the main type of your Gobot application and contains a collection of
 // Robots, API commands and Events.
 type Gobot struct {
-	robots   *Robots
-	trap     func(chan os.Signal)
-	AutoStop bool
+	robots       *Robots
+	trap         func(chan os.Signal)
+	stopHandlers []func() error
+	logger       Logger
+	AutoStop     bool
 	Commander
 	Eventer
 }
@@ -58,7 +63,7 @@ func NewGobot() *Gobot {
 func (g *Gobot) Start() (errs []error) {
 	if rerrs := g.robots.Start(); len(rerrs) > 0 {
 		for _, err := range rerrs {
-			log.Println("Error:", err)
+			g.Logger().Log(ErrorLevel, err.Error(), nil)
 			errs = append(errs, err)
 		}
 	}
@@ -82,11 +87,19 @@ func (g *Gobot) Start() (errs []error) {
 	return errs
 }
 
-// Stop calls the Stop method on each robot in its collection of robots.
+// Stop calls the Stop method on each robot in its collection of robots, and
+// then the stop handlers.
 func (g *Gobot) Stop() (errs []error) {
 	if rerrs := g.robots.Stop(); len(rerrs) > 0 {
 		for _, err := range rerrs {
-			log.Println("Error:", err)
+			g.Logger().Log(ErrorLevel, err.Error(), nil)
+			errs = append(errs, err)
+		}
+	}
+
+	for _, handler := range g.stopHandlers {
+		if err := handler(); err != nil {
+			g.Logger().Log(ErrorLevel, err.Error(), nil)
 			errs = append(errs, err)
 		}
 	}
@@ -94,15 +107,36 @@ func (g *Gobot) Stop() (errs []error) {
 	return errs
 }
 
+// AddStopHandler adds a function called by Stop after the robots are stopped,
+// such as stopping the api server.
+func (g *Gobot) AddStopHandler(f func() error) {
+	g.stopHandlers = append(g.stopHandlers, f)
+}
+
 // Robots returns all robots associated with this Gobot.
 func (g *Gobot) Robots() *Robots {
 	return g.robots
 }
 
+// Logger returns the Logger of the Gobot, DefaultLogger unless it was set
+func (g *Gobot) Logger() Logger {
+	if g.logger == nil {
+		return DefaultLogger
+	}
+	return g.logger
+}
+
+// SetLogger sets the Logger of the Gobot, which is also the Logger of its
+// robots unless they were given another one.
+func (g *Gobot) SetLogger(l Logger) {
+	g.logger = l
+}
+
 // AddRobot adds a new robot to the internal collection of robots. Returns the
 // added robot
 func (g *Gobot) AddRobot(r *Robot) *Robot {
 	*g.robots = append(*g.robots, r)
+	r.gobot = g
 	return r
 }
 
@@ -12,6 +12,7 @@ CLI tool for generating new Gobot projects.
 
 	COMMANDS:
 		 generate     Generate new Gobot skeleton project
+		 mavlink      Generate MAVLink dialect packages for the mavlink platform
 		 help, h      Shows a list of commands or help for one command
 
 	GLOBAL OPTIONS:
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/hybridgroup/gobot/platforms/mavlink/generator"
+)
+
+func Mavlink() cli.Command {
+	return cli.Command{
+		Name:  "mavlink",
+		Usage: "Generate MAVLink dialect packages for the mavlink platform",
+		Action: func(c *cli.Context) {
+			if c.Args().First() != "generate" {
+				fmt.Println("Invalid/no subcommand supplied.")
+				fmt.Println()
+				fmt.Println("Usage:")
+				fmt.Println(" gobot mavlink generate <dialect.xml> [package] # generate a MAVLink dialect package")
+				return
+			}
+
+			args := c.Args()[1:]
+
+			if len(args) < 1 {
+				fmt.Println("Please provide the XML definitions of a dialect.")
+				return
+			}
+
+			file := args[0]
+			packageName := strings.ToLower(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+			if len(args) > 1 {
+				packageName = strings.ToLower(args[1])
+			}
+
+			if err := generateMavlink(file, packageName); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		},
+	}
+}
+
+// generateMavlink generates the package of a dialect in the current
+// directory
+func generateMavlink(file string, packageName string) error {
+	dialect, err := generator.Load(file)
+	if err != nil {
+		return err
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	dir := pwd + "/" + packageName
+	fmt.Println("Creating", dir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	fileLocation := dir + "/" + packageName + ".go"
+	fmt.Println("Creating", fileLocation)
+	f, err := os.Create(fileLocation)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return dialect.Generate(f, packageName)
+}
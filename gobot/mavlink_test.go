@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// inTempDir runs f in a new temporary directory, given the absolute path of
+// a file of the generator testdata
+func inTempDir(t *testing.T, f func(testdata func(string) string)) {
+	testdata, err := filepath.Abs("../platforms/mavlink/generator/testdata")
+	gobottest.Assert(t, err, nil)
+	dir, err := ioutil.TempDir("", "gobot-mavlink")
+	gobottest.Assert(t, err, nil)
+	defer os.RemoveAll(dir)
+	pwd, err := os.Getwd()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, os.Chdir(dir), nil)
+	defer os.Chdir(pwd)
+
+	f(func(name string) string { return filepath.Join(testdata, name) })
+}
+
+func TestGenerateMavlink(t *testing.T) {
+	inTempDir(t, func(testdata func(string) string) {
+		gobottest.Assert(t, generateMavlink(testdata("common.xml"), "common"), nil)
+		data, err := ioutil.ReadFile("common/common.go")
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, strings.Contains(string(data), "package common"), true)
+	})
+}
+
+func TestGenerateMavlinkMAVLink2Messages(t *testing.T) {
+	inTempDir(t, func(testdata func(string) string) {
+		gobottest.Assert(t, generateMavlink(testdata("custom.xml"), "winch"), nil)
+		data, err := ioutil.ReadFile("winch/winch.go")
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, strings.Contains(string(data), "package winch"), true)
+		gobottest.Assert(t, strings.Contains(string(data), "WinchStatus"), true)
+		gobottest.Assert(t, strings.Contains(string(data), "func (*WinchConfig) FullId() uint32"), true)
+	})
+}
+
+func TestGenerateMavlinkError(t *testing.T) {
+	inTempDir(t, func(testdata func(string) string) {
+		gobottest.Refute(t, generateMavlink(testdata("missing.xml"), "missing"), nil)
+	})
+}
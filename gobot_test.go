@@ -67,12 +67,53 @@ func TestGobotToJSON(t *testing.T) {
 	gobottest.Assert(t, len(json.Commands), len(g.Commands()))
 }
 
+func TestRobotToJSON(t *testing.T) {
+	r := newTestRobot("Robot1")
+	r.AddEvent("RobotEvent")
+
+	json := NewJSONRobot(r)
+	gobottest.Assert(t, json.Events, []string{"RobotEvent"})
+	gobottest.Assert(t, len(json.Connections), r.Connections().Len())
+	gobottest.Assert(t, json.Connections[0], &JSONConnection{
+		Name:      "Connection1",
+		Adaptor:   "*gobot.testAdaptor",
+		Port:      "/dev/null",
+		Connected: false,
+		Pins:      []string{"0"},
+	})
+	gobottest.Assert(t, json.Devices[0].Events, []string{})
+
+	gobottest.Assert(t, len(r.Start()), 0)
+	gobottest.Assert(t, r.Connected("Connection1"), true)
+	gobottest.Assert(t, NewJSONRobot(r).Connections[0].Connected, true)
+
+	gobottest.Assert(t, len(r.Stop()), 0)
+	gobottest.Assert(t, r.Connected("Connection1"), false)
+	gobottest.Assert(t, r.Connected("Connection4"), false)
+}
+
 func TestGobotStart(t *testing.T) {
 	g := initTestGobot()
 	gobottest.Assert(t, len(g.Start()), 0)
 	gobottest.Assert(t, len(g.Stop()), 0)
 }
 
+func TestGobotStopHandlers(t *testing.T) {
+	g := initTestGobot()
+	stopped := 0
+	g.AddStopHandler(func() error {
+		stopped++
+		return nil
+	})
+	g.AddStopHandler(func() error {
+		stopped++
+		return errors.New("stop handler error")
+	})
+	errs := g.Stop()
+	gobottest.Assert(t, stopped, 2)
+	gobottest.Assert(t, errs, []error{errors.New("stop handler error")})
+}
+
 func TestGobotStartErrors(t *testing.T) {
 	log.SetOutput(&NullReadWriteCloser{})
 	g := NewGobot()
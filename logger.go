@@ -0,0 +1,180 @@
+package gobot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log entry
+type Level int
+
+const (
+	// DebugLevel entries help diagnosing problems, such as requests and
+	// transactions
+	DebugLevel Level = iota
+	// InfoLevel entries report the progress of robots, such as starting them
+	InfoLevel
+	// WarnLevel entries report unexpected but handled problems
+	WarnLevel
+	// ErrorLevel entries report failures
+	ErrorLevel
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// Fields is the context of a log entry, such as the "robot", "device",
+// "connection" and "pin" it is about.
+type Fields map[string]interface{}
+
+// Logger logs entries of a level with fields. It may be implemented to route
+// entries to any logging library.
+type Logger interface {
+	Log(level Level, msg string, fields Fields)
+}
+
+// LevelLogger is a Logger which tells whether it logs the entries of a level,
+// which saves building the fields of entries it would discard
+type LevelLogger interface {
+	Logger
+	Enabled(level Level) bool
+}
+
+// Enabled returns false when l is a LevelLogger discarding the entries of
+// level. Loggers which are not LevelLoggers are assumed to log every level.
+func Enabled(l Logger, level Level) bool {
+	if ll, ok := l.(LevelLogger); ok {
+		return ll.Enabled(level)
+	}
+	return true
+}
+
+// levelLogger logs the entries of level and above to log
+type levelLogger struct {
+	level Level
+	log   LoggerFunc
+}
+
+func (l *levelLogger) Log(level Level, msg string, fields Fields) {
+	if l.Enabled(level) {
+		l.log(level, msg, fields)
+	}
+}
+
+func (l *levelLogger) Enabled(level Level) bool { return level >= l.level }
+
+// LoggerFunc is a function used as a Logger
+type LoggerFunc func(level Level, msg string, fields Fields)
+
+// Log calls f
+func (f LoggerFunc) Log(level Level, msg string, fields Fields) {
+	f(level, msg, fields)
+}
+
+// DefaultLogger is the Logger of a Gobot and its robots unless they are given
+// another one, and of the adaptors and drivers. It writes InfoLevel entries
+// and above to the standard logger of the log package.
+var DefaultLogger Logger = NewLogger(nil, InfoLevel)
+
+// DiscardLogger is a Logger which discards every entry
+var DiscardLogger Logger = &levelLogger{level: ErrorLevel + 1, log: func(Level, string, Fields) {}}
+
+// NewLogger returns a Logger writing entries of level and above as lines of
+// text to w, prefixed by the date and time, or to the standard logger of the
+// log package when w is nil:
+//
+//	2016/01/02 15:04:05 INFO Starting device robot=bot device=led pin=13
+func NewLogger(w io.Writer, level Level) Logger {
+	output := log.Println
+	if w != nil {
+		output = log.New(w, "", log.LstdFlags).Println
+	}
+	return &levelLogger{level: level, log: func(l Level, msg string, fields Fields) {
+		buf := &bytes.Buffer{}
+		fmt.Fprintf(buf, "%v %v", strings.ToUpper(l.String()), msg)
+		for _, key := range sortedKeys(fields) {
+			fmt.Fprintf(buf, " %v=%v", key, fields[key])
+		}
+		output(buf.String())
+	}}
+}
+
+// NewJSONLogger returns a Logger writing entries of level and above to w as
+// lines of JSON objects with their "time", "level", "msg" and fields.
+func NewJSONLogger(w io.Writer, level Level) Logger {
+	var mutex sync.Mutex
+	return &levelLogger{level: level, log: func(l Level, msg string, fields Fields) {
+		entry := map[string]interface{}{}
+		for key, value := range fields {
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			entry[key] = value
+		}
+		entry["time"] = time.Now().Format(time.RFC3339Nano)
+		entry["level"] = l.String()
+		entry["msg"] = msg
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			data, _ = json.Marshal(map[string]interface{}{
+				"time": entry["time"], "level": entry["level"], "msg": msg,
+				"error": "Invalid log fields: " + err.Error(),
+			})
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		w.Write(append(data, '\n'))
+	}}
+}
+
+// WithFields returns a Logger adding fields to the entries it logs to l,
+// such as the robot and device of a driver.
+func WithFields(l Logger, fields Fields) Logger {
+	return &fieldsLogger{logger: l, fields: fields}
+}
+
+// fieldsLogger adds fields to the entries it logs to logger
+type fieldsLogger struct {
+	logger Logger
+	fields Fields
+}
+
+func (f *fieldsLogger) Enabled(level Level) bool { return Enabled(f.logger, level) }
+
+func (f *fieldsLogger) Log(level Level, msg string, entryFields Fields) {
+	merged := Fields{}
+	for key, value := range f.fields {
+		merged[key] = value
+	}
+	for key, value := range entryFields {
+		merged[key] = value
+	}
+	f.logger.Log(level, msg, merged)
+}
+
+func sortedKeys(fields Fields) (keys []string) {
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return
+}
@@ -0,0 +1,127 @@
+package gobot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+type testLogEntry struct {
+	level  Level
+	msg    string
+	fields Fields
+}
+
+func newTestLogger() (Logger, *[]testLogEntry) {
+	entries := &[]testLogEntry{}
+	return LoggerFunc(func(level Level, msg string, fields Fields) {
+		*entries = append(*entries, testLogEntry{level, msg, fields})
+	}), entries
+}
+
+func TestLevelString(t *testing.T) {
+	gobottest.Assert(t, DebugLevel.String(), "debug")
+	gobottest.Assert(t, InfoLevel.String(), "info")
+	gobottest.Assert(t, WarnLevel.String(), "warn")
+	gobottest.Assert(t, ErrorLevel.String(), "error")
+	gobottest.Assert(t, Level(7).String(), "level(7)")
+}
+
+func TestNewLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewLogger(buf, InfoLevel)
+	logger.Log(DebugLevel, "Hidden", nil)
+	logger.Log(InfoLevel, "Starting device", Fields{"robot": "bot", "device": "led", "pin": "13"})
+	logger.Log(ErrorLevel, "Failed", Fields{"error": errors.New("oops")})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	gobottest.Assert(t, len(lines), 2)
+	// lines are prefixed by the date and time
+	gobottest.Assert(t, strings.HasSuffix(lines[0], " INFO Starting device device=led pin=13 robot=bot"), true)
+	gobottest.Assert(t, strings.HasSuffix(lines[1], " ERROR Failed error=oops"), true)
+}
+
+func TestNewJSONLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := NewJSONLogger(buf, WarnLevel)
+	logger.Log(InfoLevel, "Hidden", nil)
+	logger.Log(WarnLevel, "Closing websocket", Fields{"robot": "bot", "error": errors.New("EOF")})
+	logger.Log(ErrorLevel, "Invalid", Fields{"data": make(chan bool)})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	gobottest.Assert(t, len(lines), 2)
+
+	entry := map[string]interface{}{}
+	gobottest.Assert(t, json.Unmarshal([]byte(lines[0]), &entry), nil)
+	gobottest.Refute(t, entry["time"], nil)
+	delete(entry, "time")
+	gobottest.Assert(t, entry, map[string]interface{}{
+		"level": "warn", "msg": "Closing websocket", "robot": "bot", "error": "EOF",
+	})
+
+	entry = map[string]interface{}{}
+	gobottest.Assert(t, json.Unmarshal([]byte(lines[1]), &entry), nil)
+	gobottest.Assert(t, entry["msg"], "Invalid")
+	gobottest.Assert(t, strings.HasPrefix(entry["error"].(string), "Invalid log fields: "), true)
+}
+
+func TestEnabled(t *testing.T) {
+	gobottest.Assert(t, Enabled(NewLogger(nil, InfoLevel), DebugLevel), false)
+	gobottest.Assert(t, Enabled(NewLogger(nil, InfoLevel), InfoLevel), true)
+	gobottest.Assert(t, Enabled(NewJSONLogger(nil, ErrorLevel), WarnLevel), false)
+	gobottest.Assert(t, Enabled(WithFields(NewLogger(nil, WarnLevel), Fields{}), InfoLevel), false)
+	gobottest.Assert(t, Enabled(DiscardLogger, ErrorLevel), false)
+
+	// other loggers are assumed to log every level
+	logger, _ := newTestLogger()
+	gobottest.Assert(t, Enabled(logger, DebugLevel), true)
+	gobottest.Assert(t, Enabled(WithFields(logger, Fields{}), DebugLevel), true)
+}
+
+func TestWithFields(t *testing.T) {
+	logger, entries := newTestLogger()
+	WithFields(logger, Fields{"robot": "bot", "device": "led"}).Log(InfoLevel, "On", Fields{"device": "button"})
+	gobottest.Assert(t, *entries, []testLogEntry{
+		{InfoLevel, "On", Fields{"robot": "bot", "device": "button"}},
+	})
+
+	// discards everything
+	DiscardLogger.Log(ErrorLevel, "Failed", nil)
+}
+
+func TestRobotLogger(t *testing.T) {
+	g := NewGobot()
+	r := newTestRobot("Robot1")
+
+	defaultLogger := DefaultLogger
+	logger, entries := newTestLogger()
+	DefaultLogger = logger
+	g.Logger().Log(InfoLevel, "Gobot", nil)
+	r.Logger().Log(InfoLevel, "Robot", nil)
+	DefaultLogger = defaultLogger
+	gobottest.Assert(t, *entries, []testLogEntry{{InfoLevel, "Gobot", nil}, {InfoLevel, "Robot", nil}})
+
+	logger, entries = newTestLogger()
+	g.SetLogger(logger)
+	g.AddRobot(r)
+
+	// robots log to the logger of their gobot unless they have their own
+	gobottest.Assert(t, len(r.Start()), 0)
+	gobottest.Assert(t, (*entries)[0], testLogEntry{InfoLevel, "Starting robot", Fields{"robot": "Robot1"}})
+	gobottest.Assert(t, (*entries)[1], testLogEntry{InfoLevel, "Starting connection",
+		Fields{"robot": "Robot1", "connection": "Connection1", "port": "/dev/null"}})
+	gobottest.Assert(t, (*entries)[4], testLogEntry{InfoLevel, "Starting device",
+		Fields{"robot": "Robot1", "device": "Device1", "pin": "0", "connection": "Connection1"}})
+	gobottest.Assert(t, (*entries)[len(*entries)-1], testLogEntry{InfoLevel, "Starting work", Fields{"robot": "Robot1"}})
+
+	robotLogger, robotEntries := newTestLogger()
+	r.SetLogger(robotLogger)
+	count := len(*entries)
+	r.Stop()
+	gobottest.Assert(t, len(*entries), count)
+	gobottest.Assert(t, *robotEntries, []testLogEntry{{InfoLevel, "Stopping robot", Fields{"robot": "Robot1"}}})
+}
@@ -0,0 +1,268 @@
+package gobot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMetrics is the registry of the metrics of the connections and
+// devices, such as the errors of devices and the i2c and serial transactions
+// of adaptors.
+var DefaultMetrics = NewMetrics()
+
+func init() {
+	DefaultMetrics.Help("gobot_device_errors_total", "Number of errors returned by devices, by operation.")
+	DefaultMetrics.Help("gobot_serial_operations_total", "Number of serial port reads and writes.")
+	DefaultMetrics.Help("gobot_serial_bytes_total", "Number of bytes read from and written to serial ports.")
+	DefaultMetrics.Help("gobot_serial_errors_total", "Number of serial port reads and writes which failed.")
+	DefaultMetrics.Help("gobot_i2c_transactions_total", "Number of i2c reads and writes.")
+	DefaultMetrics.Help("gobot_i2c_errors_total", "Number of i2c reads and writes which failed.")
+	DefaultMetrics.Help("gobot_i2c_duration_seconds", "Duration of i2c reads and writes.")
+}
+
+// Metrics is a registry of counters and summaries, written in the Prometheus
+// text exposition format.
+type Metrics struct {
+	mutex   sync.Mutex
+	metrics map[string]*metric
+}
+
+type metric struct {
+	kind   string
+	help   string
+	series map[string]*series
+}
+
+type series struct {
+	value float64
+	count uint64
+}
+
+// NewMetrics returns a new Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{metrics: make(map[string]*metric)}
+}
+
+// Help sets the description of the metric of name.
+func (m *Metrics) Help(name string, help string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.metric(name, "").help = help
+}
+
+// Inc adds 1 to the counter of name with labels, which are given as pairs of
+// label names and values.
+func (m *Metrics) Inc(name string, labels ...string) {
+	m.Add(name, 1, labels...)
+}
+
+// Add adds v to the counter of name with labels.
+func (m *Metrics) Add(name string, v float64, labels ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.series(name, "counter", labels).value += v
+}
+
+// Observe adds an observation of v, such as a duration in seconds, to the
+// summary of name with labels.
+func (m *Metrics) Observe(name string, v float64, labels ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s := m.series(name, "summary", labels)
+	s.value += v
+	s.count++
+}
+
+// Value returns the value of the counter, or the sum of the summary, of name
+// with labels.
+func (m *Metrics) Value(name string, labels ...string) float64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if metric, ok := m.metrics[name]; ok {
+		if s, ok := metric.series[formatLabels(labels)]; ok {
+			return s.value
+		}
+	}
+	return 0
+}
+
+// WriteTo writes the metrics sorted by name in the Prometheus text exposition
+// format.
+func (m *Metrics) WriteTo(w io.Writer) (n int64, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	names := []string{}
+	for name, metric := range m.metrics {
+		if len(metric.series) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	for _, name := range names {
+		metric := m.metrics[name]
+		if metric.help != "" {
+			fmt.Fprintf(buf, "# HELP %v %v\n", name, metric.help)
+		}
+		fmt.Fprintf(buf, "# TYPE %v %v\n", name, metric.kind)
+
+		keys := []string{}
+		for key := range metric.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := metric.series[key]
+			if metric.kind == "summary" {
+				fmt.Fprintf(buf, "%v_sum%v %v\n", name, key, formatValue(s.value))
+				fmt.Fprintf(buf, "%v_count%v %v\n", name, key, s.count)
+				continue
+			}
+			fmt.Fprintf(buf, "%v%v %v\n", name, key, formatValue(s.value))
+		}
+	}
+
+	written, err := io.WriteString(w, buf.String())
+	return int64(written), err
+}
+
+func (m *Metrics) metric(name string, kind string) *metric {
+	mt, ok := m.metrics[name]
+	if !ok {
+		mt = &metric{series: make(map[string]*series)}
+		m.metrics[name] = mt
+	}
+	if mt.kind == "" {
+		mt.kind = kind
+	}
+	return mt
+}
+
+func (m *Metrics) series(name string, kind string, labels []string) *series {
+	mt := m.metric(name, kind)
+	key := formatLabels(labels)
+	s, ok := mt.series[key]
+	if !ok {
+		s = &series{}
+		mt.series[key] = s
+	}
+	return s
+}
+
+// formatLabels returns the label pairs as {name="value",...}
+func formatLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+	pairs := []string{}
+	for i := 0; i+1 < len(labels); i += 2 {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[i+1])
+		pairs = append(pairs, labels[i]+`="`+value+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// WriteMetrics writes the number of times the events of the Gobot, its robots
+// and their devices were written, and the invocations of their commands,
+// followed by DefaultMetrics, in the Prometheus text exposition format.
+func (g *Gobot) WriteMetrics(w io.Writer) (err error) {
+	m := NewMetrics()
+	m.Help("gobot_events_total", "Number of times events were written.")
+	m.Help("gobot_commands_total", "Number of command invocations.")
+	m.Help("gobot_command_errors_total", "Number of command invocations which returned an error.")
+	m.Help("gobot_command_duration_seconds", "Duration of command invocations.")
+
+	collect := func(robot string, device string, v interface{}) {
+		if eventer, ok := v.(Eventer); ok {
+			for name, event := range eventer.Events() {
+				m.Add("gobot_events_total", float64(event.Writes()),
+					"robot", robot, "device", device, "event", name)
+			}
+		}
+		if commander, ok := v.(Commander); ok {
+			for name, stats := range commander.CommandStats() {
+				labels := []string{"robot", robot, "device", device, "command", name}
+				m.Add("gobot_commands_total", float64(stats.Count), labels...)
+				m.Add("gobot_command_errors_total", float64(stats.Errors), labels...)
+				m.mutex.Lock()
+				s := m.series("gobot_command_duration_seconds", "summary", labels)
+				s.value, s.count = stats.Seconds, stats.Count
+				m.mutex.Unlock()
+			}
+		}
+	}
+
+	collect("", "", g)
+	g.Robots().Each(func(r *Robot) {
+		collect(r.Name, "", r)
+		r.Devices().Each(func(d Device) {
+			collect(r.Name, d.Name(), d)
+		})
+	})
+
+	if _, err = m.WriteTo(w); err != nil {
+		return
+	}
+	_, err = DefaultMetrics.WriteTo(w)
+	return
+}
+
+// MeterI2c counts an i2c read or write of adaptor with the device at address,
+// which started at start and failed if err points to an error, in
+// DefaultMetrics. It is meant to be deferred by the I2cRead and I2cWrite of
+// adaptors:
+//
+//	defer gobot.MeterI2c(a.Name(), address, "read", time.Now(), &err)
+func MeterI2c(adaptor string, address int, operation string, start time.Time, err *error) {
+	labels := []string{"adaptor", adaptor, "address", fmt.Sprintf("0x%02x", address), "operation", operation}
+	DefaultMetrics.Inc("gobot_i2c_transactions_total", labels...)
+	DefaultMetrics.Observe("gobot_i2c_duration_seconds", time.Since(start).Seconds(), labels...)
+	if err != nil && *err != nil {
+		DefaultMetrics.Inc("gobot_i2c_errors_total", labels...)
+	}
+}
+
+// MeteredReadWriteCloser counts the reads and writes of a serial port, or any
+// other io.ReadWriteCloser, in DefaultMetrics.
+type MeteredReadWriteCloser struct {
+	io.ReadWriteCloser
+	Port string
+}
+
+// NewMeteredReadWriteCloser returns a MeteredReadWriteCloser of the
+// connection to port.
+func NewMeteredReadWriteCloser(rwc io.ReadWriteCloser, port string) *MeteredReadWriteCloser {
+	return &MeteredReadWriteCloser{ReadWriteCloser: rwc, Port: port}
+}
+
+func (m *MeteredReadWriteCloser) Read(b []byte) (n int, err error) {
+	n, err = m.ReadWriteCloser.Read(b)
+	m.record("read", n, err)
+	return
+}
+
+func (m *MeteredReadWriteCloser) Write(b []byte) (n int, err error) {
+	n, err = m.ReadWriteCloser.Write(b)
+	m.record("write", n, err)
+	return
+}
+
+func (m *MeteredReadWriteCloser) record(op string, n int, err error) {
+	DefaultMetrics.Inc("gobot_serial_operations_total", "port", m.Port, "operation", op)
+	DefaultMetrics.Add("gobot_serial_bytes_total", float64(n), "port", m.Port, "operation", op)
+	if err != nil && err != io.EOF {
+		DefaultMetrics.Inc("gobot_serial_errors_total", "port", m.Port, "operation", op)
+	}
+}
@@ -0,0 +1,134 @@
+package gobot
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	m.Help("test_total", "Test counter.")
+	m.Inc("test_total", "name", "b")
+	m.Inc("test_total", "name", "a")
+	m.Add("test_total", 2, "name", "a")
+	m.Observe("test_seconds", 0.5)
+	m.Observe("test_seconds", 0.25)
+	m.Help("unused_total", "Never written.")
+
+	gobottest.Assert(t, m.Value("test_total", "name", "a"), 3.0)
+	gobottest.Assert(t, m.Value("test_total", "name", "c"), 0.0)
+	gobottest.Assert(t, m.Value("test_seconds"), 0.75)
+
+	buf := &bytes.Buffer{}
+	n, err := m.WriteTo(buf)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, int64(buf.Len()))
+	gobottest.Assert(t, buf.String(), `# TYPE test_seconds summary
+test_seconds_sum 0.75
+test_seconds_count 2
+# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{name="a"} 3
+test_total{name="b"} 1
+`)
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	m := NewMetrics()
+	m.Inc("test_total", "name", "a \"b\"\\c\nd", "other", "e")
+
+	buf := &bytes.Buffer{}
+	m.WriteTo(buf)
+	gobottest.Assert(t, strings.Contains(buf.String(),
+		`test_total{name="a \"b\"\\c\nd",other="e"} 1`), true)
+}
+
+func TestGobotWriteMetrics(t *testing.T) {
+	g := NewGobot()
+	r := newTestRobot("Robot1")
+	g.AddRobot(r)
+	r.AddEvent("moved")
+	Publish(r.Event("moved"), nil)
+	Publish(r.Event("moved"), nil)
+	r.AddCommand("Fail", func(params map[string]interface{}) interface{} {
+		return errors.New("failed")
+	})
+	r.Command("Fail")(nil)
+	r.Device("Device1").(Commander).Command("DriverCommand")(nil)
+
+	buf := &bytes.Buffer{}
+	gobottest.Assert(t, g.WriteMetrics(buf), nil)
+	metrics := buf.String()
+
+	for _, line := range []string{
+		"# HELP gobot_events_total Number of times events were written.",
+		"# TYPE gobot_events_total counter",
+		`gobot_events_total{robot="Robot1",device="",event="moved"} 2`,
+		`gobot_commands_total{robot="Robot1",device="",command="Fail"} 1`,
+		`gobot_command_errors_total{robot="Robot1",device="",command="Fail"} 1`,
+		`gobot_command_duration_seconds_count{robot="Robot1",device="",command="Fail"} 1`,
+		`gobot_commands_total{robot="Robot1",device="Device1",command="DriverCommand"} 1`,
+		`gobot_command_errors_total{robot="Robot1",device="Device1",command="DriverCommand"} 0`,
+	} {
+		if !strings.Contains(metrics, line+"\n") {
+			t.Errorf("Metrics do not contain %q:\n%v", line, metrics)
+		}
+	}
+}
+
+func TestDeviceErrorMetrics(t *testing.T) {
+	r := newTestRobot("DeviceErrorsRobot")
+	testDriverHalt = func() (errs []error) {
+		return []error{errors.New("driver halt error")}
+	}
+	defer func() { testDriverHalt = func() (errs []error) { return } }()
+
+	gobottest.Assert(t, len(r.Devices().halt(r.Name)), 3)
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_device_errors_total",
+		"robot", "DeviceErrorsRobot", "device", "Device1", "operation", "halt"), 1.0)
+}
+
+type testReadWriteCloser struct {
+	err error
+}
+
+func (t *testReadWriteCloser) Read(b []byte) (int, error)  { return len(b), t.err }
+func (t *testReadWriteCloser) Write(b []byte) (int, error) { return len(b), t.err }
+func (t *testReadWriteCloser) Close() error                { return nil }
+
+func TestMeteredReadWriteCloser(t *testing.T) {
+	rwc := &testReadWriteCloser{}
+	m := NewMeteredReadWriteCloser(rwc, "/dev/metered")
+	m.Write([]byte{1, 2, 3})
+	m.Read(make([]byte, 2))
+	rwc.err = io.EOF
+	m.Read(make([]byte, 2))
+	rwc.err = errors.New("write error")
+	m.Write([]byte{4})
+
+	labels := func(op string) []string { return []string{"port", "/dev/metered", "operation", op} }
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_serial_operations_total", labels("write")...), 2.0)
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_serial_bytes_total", labels("write")...), 4.0)
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_serial_errors_total", labels("write")...), 1.0)
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_serial_operations_total", labels("read")...), 2.0)
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_serial_bytes_total", labels("read")...), 4.0)
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_serial_errors_total", labels("read")...), 0.0)
+}
+
+func TestMeterI2c(t *testing.T) {
+	labels := []string{"adaptor", "metered", "address", "0x1d", "operation", "read"}
+
+	var err error
+	MeterI2c("metered", 0x1d, "read", time.Now(), &err)
+	err = errors.New("read error")
+	MeterI2c("metered", 0x1d, "read", time.Now(), &err)
+
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_i2c_transactions_total", labels...), 2.0)
+	gobottest.Assert(t, DefaultMetrics.Value("gobot_i2c_errors_total", labels...), 1.0)
+}
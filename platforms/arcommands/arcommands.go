@@ -0,0 +1,132 @@
+// Package arcommands decodes the ARCommands protocol of the Parrot drones,
+// shared by the bebop and ble platforms.
+package arcommands
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ErrShortARCommand is returned when a buffer is too short to be an ARCommand
+var ErrShortARCommand = errors.New("ARCommand is too short")
+
+// ARCommand is a message of the ARCommands protocol, sent in the network
+// frames of the Bebop or in the BLE notifications of the Minidrone
+type ARCommand struct {
+	Project byte
+	Class   byte
+	Command uint16
+	Args    []byte
+}
+
+// NewARCommand decodes the ARCommand of a buffer
+func NewARCommand(buf []byte) (ARCommand, error) {
+	//
+	// ARCOMMANDS_Decoder_DecodeBuffer
+	//
+	// uint8  project
+	// uint8  class
+	// uint16 command
+	// ...    arguments
+	//
+	if len(buf) < 4 {
+		return ARCommand{}, ErrShortARCommand
+	}
+
+	cmd := ARCommand{
+		Project: buf[0],
+		Class:   buf[1],
+		Args:    buf[4:],
+	}
+	binary.Read(bytes.NewReader(buf[2:4]), binary.LittleEndian, &cmd.Command)
+
+	return cmd, nil
+}
+
+// BatteryState is the charge of the battery of the drone
+type BatteryState struct {
+	Percent uint8
+}
+
+// FlyingState is the flying state of the drone, one of the
+// ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE values for the
+// Bebop, or of the ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE
+// values for the Minidrone
+type FlyingState struct {
+	State byte
+}
+
+// Position is the GPS position of the drone, in degrees and meters. Its values
+// are 500 while the drone has no GPS fix.
+type Position struct {
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+}
+
+// Speed is the speed of the drone in m/s, relative to the north, east and
+// ground
+type Speed struct {
+	X float32
+	Y float32
+	Z float32
+}
+
+// Attitude is the attitude of the drone in radians
+type Attitude struct {
+	Roll  float32
+	Pitch float32
+	Yaw   float32
+}
+
+// Altitude is the altitude of the drone above its take off point in meters
+type Altitude struct {
+	Altitude float64
+}
+
+// Telemetry returns the state of the drone the command reports, a
+// BatteryState, FlyingState, Position, Speed, Attitude or Altitude, or nil if
+// the command reports none of them
+func (c ARCommand) Telemetry() interface{} {
+	r := bytes.NewReader(c.Args)
+	switch {
+	case c.is(ARCOMMANDS_ID_PROJECT_COMMON, ARCOMMANDS_ID_COMMON_CLASS_COMMONSTATE, ARCOMMANDS_ID_COMMON_COMMONSTATE_CMD_BATTERYSTATECHANGED):
+		s := BatteryState{}
+		if binary.Read(r, binary.LittleEndian, &s) == nil {
+			return s
+		}
+	case c.is(ARCOMMANDS_ID_PROJECT_ARDRONE3, ARCOMMANDS_ID_ARDRONE3_CLASS_PILOTINGSTATE, ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_FLYINGSTATECHANGED),
+		c.is(ARCOMMANDS_ID_PROJECT_MINIDRONE, ARCOMMANDS_ID_MINIDRONE_CLASS_PILOTINGSTATE, ARCOMMANDS_ID_MINIDRONE_PILOTINGSTATE_CMD_FLYINGSTATECHANGED):
+		// the state is a 32 bits enum
+		var s uint32
+		if binary.Read(r, binary.LittleEndian, &s) == nil {
+			return FlyingState{State: byte(s)}
+		}
+	case c.is(ARCOMMANDS_ID_PROJECT_ARDRONE3, ARCOMMANDS_ID_ARDRONE3_CLASS_PILOTINGSTATE, ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_POSITIONCHANGED):
+		s := Position{}
+		if binary.Read(r, binary.LittleEndian, &s) == nil {
+			return s
+		}
+	case c.is(ARCOMMANDS_ID_PROJECT_ARDRONE3, ARCOMMANDS_ID_ARDRONE3_CLASS_PILOTINGSTATE, ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_SPEEDCHANGED):
+		s := Speed{}
+		if binary.Read(r, binary.LittleEndian, &s) == nil {
+			return s
+		}
+	case c.is(ARCOMMANDS_ID_PROJECT_ARDRONE3, ARCOMMANDS_ID_ARDRONE3_CLASS_PILOTINGSTATE, ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_ATTITUDECHANGED):
+		s := Attitude{}
+		if binary.Read(r, binary.LittleEndian, &s) == nil {
+			return s
+		}
+	case c.is(ARCOMMANDS_ID_PROJECT_ARDRONE3, ARCOMMANDS_ID_ARDRONE3_CLASS_PILOTINGSTATE, ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_ALTITUDECHANGED):
+		s := Altitude{}
+		if binary.Read(r, binary.LittleEndian, &s) == nil {
+			return s
+		}
+	}
+	return nil
+}
+
+func (c ARCommand) is(project byte, class byte, command byte) bool {
+	return c.Project == project && c.Class == class && c.Command == uint16(command)
+}
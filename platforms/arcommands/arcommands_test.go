@@ -0,0 +1,40 @@
+package arcommands
+
+import (
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func TestNewARCommand(t *testing.T) {
+	cmd, err := NewARCommand([]byte{0x01, 0x04, 0x08, 0x00, 0xAA})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, cmd, ARCommand{Project: 1, Class: 4, Command: 8, Args: []byte{0xAA}})
+
+	_, err = NewARCommand([]byte{0x01, 0x04, 0x08})
+	gobottest.Assert(t, err, ErrShortARCommand)
+}
+
+func TestARCommandTelemetry(t *testing.T) {
+	for _, test := range []struct {
+		buf   []byte
+		state interface{}
+	}{
+		{[]byte{0x00, 0x05, 0x01, 0x00, 0x57}, BatteryState{Percent: 87}},
+		{[]byte{0x01, 0x04, 0x01, 0x00, 0x03, 0x00, 0x00, 0x00}, FlyingState{State: ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_FLYING}},
+		{[]byte{0x02, 0x03, 0x01, 0x00, 0x06, 0x00, 0x00, 0x00}, FlyingState{State: ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_ROLLING}},
+		{[]byte{0x01, 0x04, 0x04, 0x00, 0xAA, 0xF1, 0xD2, 0x4D, 0x62, 0x70, 0x48, 0x40, 0x56, 0x0E, 0x2D, 0xB2, 0x9D, 0xEF, 0x02, 0x40,
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x7F, 0x40}, Position{Latitude: 48.878, Longitude: 2.367, Altitude: 500}},
+		{[]byte{0x01, 0x04, 0x05, 0x00, 0x00, 0x00, 0x80, 0x3F, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0}, Speed{X: 1, Y: 0, Z: -2}},
+		{[]byte{0x01, 0x04, 0x06, 0x00, 0x00, 0x00, 0x00, 0x3F, 0x00, 0x00, 0x80, 0xBE, 0x00, 0x00, 0xC0, 0x3F}, Attitude{Roll: 0.5, Pitch: -0.25, Yaw: 1.5}},
+		{[]byte{0x01, 0x04, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x24, 0x40}, Altitude{Altitude: 10}},
+		// too short
+		{[]byte{0x01, 0x04, 0x06, 0x00, 0x00, 0x00, 0x00, 0x3F}, nil},
+		// flat trim changed
+		{[]byte{0x01, 0x04, 0x00, 0x00}, nil},
+	} {
+		cmd, err := NewARCommand(test.buf)
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, cmd.Telemetry(), test.state)
+	}
+}
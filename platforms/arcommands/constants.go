@@ -0,0 +1,55 @@
+package arcommands
+
+const (
+	// eARCOMMANDS_ID_PROJECT
+	ARCOMMANDS_ID_PROJECT_COMMON    byte = 0
+	ARCOMMANDS_ID_PROJECT_ARDRONE3  byte = 1
+	ARCOMMANDS_ID_PROJECT_MINIDRONE byte = 2
+
+	// eARCOMMANDS_ID_COMMON_CLASS
+	ARCOMMANDS_ID_COMMON_CLASS_COMMONSTATE byte = 5
+
+	// eARCOMMANDS_ID_COMMON_COMMONSTATE_CMD
+	ARCOMMANDS_ID_COMMON_COMMONSTATE_CMD_BATTERYSTATECHANGED byte = 1
+
+	// eARCOMMANDS_ID_ARDRONE3_CLASS
+	ARCOMMANDS_ID_ARDRONE3_CLASS_PILOTINGSTATE byte = 4
+
+	// eARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_FLATTRIMCHANGED          byte = 0
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_FLYINGSTATECHANGED       byte = 1
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_ALERTSTATECHANGED        byte = 2
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_NAVIGATEHOMESTATECHANGED byte = 3
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_POSITIONCHANGED          byte = 4
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_SPEEDCHANGED             byte = 5
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_ATTITUDECHANGED          byte = 6
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_AUTOTAKEOFFMODECHANGED   byte = 7
+	ARCOMMANDS_ID_ARDRONE3_PILOTINGSTATE_CMD_ALTITUDECHANGED          byte = 8
+
+	// eARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE
+	ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_LANDED    byte = 0
+	ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_TAKINGOFF byte = 1
+	ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_HOVERING  byte = 2
+	ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_FLYING    byte = 3
+	ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_LANDING   byte = 4
+	ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_EMERGENCY byte = 5
+
+	// eARCOMMANDS_ID_MINIDRONE_CLASS
+	ARCOMMANDS_ID_MINIDRONE_CLASS_PILOTINGSTATE byte = 3
+
+	// eARCOMMANDS_ID_MINIDRONE_PILOTINGSTATE_CMD
+	ARCOMMANDS_ID_MINIDRONE_PILOTINGSTATE_CMD_FLATTRIMCHANGED        byte = 0
+	ARCOMMANDS_ID_MINIDRONE_PILOTINGSTATE_CMD_FLYINGSTATECHANGED     byte = 1
+	ARCOMMANDS_ID_MINIDRONE_PILOTINGSTATE_CMD_ALERTSTATECHANGED      byte = 2
+	ARCOMMANDS_ID_MINIDRONE_PILOTINGSTATE_CMD_AUTOTAKEOFFMODECHANGED byte = 3
+
+	// eARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_LANDED    byte = 0
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_TAKINGOFF byte = 1
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_HOVERING  byte = 2
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_FLYING    byte = 3
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_LANDING   byte = 4
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_EMERGENCY byte = 5
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_ROLLING   byte = 6
+	ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_INIT      byte = 7
+)
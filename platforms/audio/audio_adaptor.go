@@ -3,7 +3,6 @@ package audio
 
 import (
 	"errors"
-	"log"
 	"os"
 	"os/exec"
 	"path"
@@ -29,18 +28,24 @@ func (a *AudioAdaptor) Connect() []error { return nil }
 
 func (a *AudioAdaptor) Finalize() []error { return nil }
 
+// logError logs err to gobot.DefaultLogger
+func (a *AudioAdaptor) logError(err error) {
+	gobot.DefaultLogger.Log(gobot.ErrorLevel, err.Error(), gobot.Fields{"connection": a.Name()})
+}
+
 func (a *AudioAdaptor) Sound(fileName string) []error {
 	var errorsList []error
 
 	if fileName == "" {
-		log.Println("Requires filename for audio file.")
-		errorsList = append(errorsList, errors.New("Requires filename for audio file."))
+		err := errors.New("Requires filename for audio file.")
+		a.logError(err)
+		errorsList = append(errorsList, err)
 		return errorsList
 	}
 
 	_, err := os.Stat(fileName)
 	if err != nil {
-		log.Println(err)
+		a.logError(err)
 		errorsList = append(errorsList, err)
 		return errorsList
 	}
@@ -48,14 +53,14 @@ func (a *AudioAdaptor) Sound(fileName string) []error {
 	// command to play audio file based on file type
 	commandName, err := CommandName(fileName)
 	if err != nil {
-		log.Println(err)
+		a.logError(err)
 		errorsList = append(errorsList, err)
 		return errorsList
 	}
 
 	err = RunCommand(commandName, fileName)
 	if err != nil {
-		log.Println(err)
+		a.logError(err)
 		errorsList = append(errorsList, err)
 		return errorsList
 	}
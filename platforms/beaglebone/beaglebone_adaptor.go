@@ -8,10 +8,12 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/platforms/gpio"
 	"github.com/hybridgroup/gobot/platforms/i2c"
+	"github.com/hybridgroup/gobot/platforms/onewire"
 	"github.com/hybridgroup/gobot/sysfs"
 )
 
@@ -25,6 +27,8 @@ var _ gpio.ServoWriter = (*BeagleboneAdaptor)(nil)
 
 var _ i2c.I2c = (*BeagleboneAdaptor)(nil)
 
+var _ onewire.OneWire = (*BeagleboneAdaptor)(nil)
+
 var slots = "/sys/devices/bone_capemgr.*"
 var ocp = "/sys/devices/ocp.*"
 var usrLed = "/sys/devices/ocp.3/gpio-leds.8/leds/beaglebone:green:"
@@ -125,21 +129,23 @@ var analogPins = map[string]string{
 
 // BeagleboneAdaptor is the gobot.Adaptor representation for the Beaglebone
 type BeagleboneAdaptor struct {
-	name        string
-	digitalPins []sysfs.DigitalPin
-	pwmPins     map[string]*pwmPin
-	i2cDevice   sysfs.I2cDevice
-	ocp         string
-	helper      string
-	slots       string
+	name           string
+	digitalPins    []sysfs.DigitalPin
+	pwmPins        map[string]*pwmPin
+	i2cDevice      sysfs.I2cDevice
+	oneWireDevices map[string]sysfs.OneWireDevice
+	ocp            string
+	helper         string
+	slots          string
 }
 
 // NewBeagleboneAdaptor returns a new BeagleboneAdaptor with specified name
 func NewBeagleboneAdaptor(name string) *BeagleboneAdaptor {
 	b := &BeagleboneAdaptor{
-		name:        name,
-		digitalPins: make([]sysfs.DigitalPin, 120),
-		pwmPins:     make(map[string]*pwmPin),
+		name:           name,
+		digitalPins:    make([]sysfs.DigitalPin, 120),
+		oneWireDevices: make(map[string]sysfs.OneWireDevice),
+		pwmPins:        make(map[string]*pwmPin),
 	}
 
 	g, _ := glob(ocp)
@@ -188,6 +194,11 @@ func (b *BeagleboneAdaptor) Finalize() (errs []error) {
 			}
 		}
 	}
+	for _, device := range b.oneWireDevices {
+		if err := device.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if b.i2cDevice != nil {
 		if err := b.i2cDevice.Close(); err != nil {
 			errs = append(errs, err)
@@ -273,6 +284,8 @@ func (b *BeagleboneAdaptor) I2cStart(address int) (err error) {
 
 // I2cWrite writes data to i2c device
 func (b *BeagleboneAdaptor) I2cWrite(address int, data []byte) (err error) {
+	defer gobot.MeterI2c(b.Name(), address, "write", time.Now(), &err)
+
 	if err = b.i2cDevice.SetAddress(address); err != nil {
 		return
 	}
@@ -282,6 +295,8 @@ func (b *BeagleboneAdaptor) I2cWrite(address int, data []byte) (err error) {
 
 // I2cRead returns size bytes from the i2c device
 func (b *BeagleboneAdaptor) I2cRead(address int, size int) (data []byte, err error) {
+	defer gobot.MeterI2c(b.Name(), address, "read", time.Now(), &err)
+
 	if err = b.i2cDevice.SetAddress(address); err != nil {
 		return
 	}
@@ -404,3 +419,49 @@ func ensureSlot(slots, item string) (err error) {
 	}
 	return
 }
+
+// OneWireStart checks that the linux 1-wire bus master bus, eg.
+// "w1_bus_master1", is available. An empty bus defaults to w1_bus_master1.
+// The Beaglebone requires a w1-gpio device tree overlay for the chosen pin.
+func (b *BeagleboneAdaptor) OneWireStart(bus string) (err error) {
+	_, err = sysfs.OneWireSlaves(bus)
+	return
+}
+
+// OneWireSearch returns the ROM IDs of the devices found by the 1-wire bus master
+func (b *BeagleboneAdaptor) OneWireSearch(bus string) (ids []string, err error) {
+	return sysfs.OneWireSlaves(bus)
+}
+
+// OneWireWrite resets the 1-wire bus, selects device id and writes data to it
+func (b *BeagleboneAdaptor) OneWireWrite(bus string, id string, data []byte) (err error) {
+	device, err := b.oneWireDevice(id)
+	if err != nil {
+		return
+	}
+	_, err = device.Write(data)
+	return
+}
+
+// OneWireRead returns size bytes from 1-wire device id
+func (b *BeagleboneAdaptor) OneWireRead(bus string, id string, size int) (data []byte, err error) {
+	device, err := b.oneWireDevice(id)
+	if err != nil {
+		return
+	}
+	data = make([]byte, size)
+	_, err = device.Read(data)
+	return
+}
+
+// oneWireDevice returns the sysfs 1-wire device for id, opening it on first use
+func (b *BeagleboneAdaptor) oneWireDevice(id string) (device sysfs.OneWireDevice, err error) {
+	if device, ok := b.oneWireDevices[id]; ok {
+		return device, nil
+	}
+	if device, err = sysfs.NewOneWireDevice(id); err != nil {
+		return nil, err
+	}
+	b.oneWireDevices[id] = device
+	return
+}
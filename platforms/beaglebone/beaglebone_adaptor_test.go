@@ -57,6 +57,8 @@ func TestBeagleboneAdaptor(t *testing.T) {
 		"/sys/class/gpio/gpio60/direction",
 		"/sys/class/gpio/gpio10/value",
 		"/sys/class/gpio/gpio10/direction",
+		"/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves",
+		"/sys/bus/w1/devices/28-0000054c2ec2/rw",
 	})
 
 	sysfs.SetFilesystem(fs)
@@ -133,5 +135,15 @@ func TestBeagleboneAdaptor(t *testing.T) {
 	data, _ := a.I2cRead(0xff, 2)
 	gobottest.Assert(t, data, []byte{0x00, 0x01})
 
+	// OneWire
+	fs.Files["/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves"].Contents = "28-0000054c2ec2\n"
+	gobottest.Assert(t, a.OneWireStart(""), nil)
+	ids, _ := a.OneWireSearch("")
+	gobottest.Assert(t, ids, []string{"28-0000054c2ec2"})
+
+	a.OneWireWrite("", "28-0000054c2ec2", []byte{0xBE})
+	data, _ = a.OneWireRead("", "28-0000054c2ec2", 1)
+	gobottest.Assert(t, data, []byte{0xBE})
+
 	gobottest.Assert(t, len(a.Finalize()), 0)
 }
@@ -1,6 +1,8 @@
 package bebop
 
 import (
+	"io"
+
 	"github.com/hybridgroup/gobot/platforms/bebop/client"
 )
 
@@ -19,6 +21,10 @@ type drone interface {
 	Stop() error
 	Connect() error
 	Video() chan []byte
+	VideoReader() io.ReadCloser
+	StartVideoRTP(addr string) error
+	StopVideoRTP() error
+	Telemetry() chan interface{}
 	StartRecording() error
 	StopRecording() error
 	HullProtection(protect bool) error
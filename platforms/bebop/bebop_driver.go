@@ -1,17 +1,32 @@
 package bebop
 
 import (
+	"io"
+	"sync"
+
 	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/arcommands"
 )
 
 // BebopDriver is gobot.Driver representation for the Bebop
 type BebopDriver struct {
 	name       string
 	connection gobot.Connection
+	mutex      sync.Mutex
+	halt       chan bool
 	gobot.Eventer
 }
 
 // NewBebopDriver creates an BebopDriver with specified name.
+//
+// It adds the following events:
+//	"flying" - triggered when the drone takes off
+//	"battery" - triggered with the arcommands.BatteryState of the drone
+//	"flyingstate" - triggered with the arcommands.FlyingState of the drone
+//	"position" - triggered with the arcommands.Position of the drone
+//	"attitude" - triggered with the arcommands.Attitude of the drone
+//	"altitude" - triggered with the arcommands.Altitude of the drone
+//	"speed" - triggered with the arcommands.Speed of the drone
 func NewBebopDriver(connection *BebopAdaptor, name string) *BebopDriver {
 	d := &BebopDriver{
 		name:       name,
@@ -19,6 +34,12 @@ func NewBebopDriver(connection *BebopAdaptor, name string) *BebopDriver {
 		Eventer:    gobot.NewEventer(),
 	}
 	d.AddEvent("flying")
+	d.AddEvent("battery")
+	d.AddEvent("flyingstate")
+	d.AddEvent("position")
+	d.AddEvent("attitude")
+	d.AddEvent("altitude")
+	d.AddEvent("speed")
 	return d
 }
 
@@ -33,16 +54,56 @@ func (a *BebopDriver) adaptor() *BebopAdaptor {
 	return a.Connection().(*BebopAdaptor)
 }
 
-// Start starts the BebopDriver
+// Start starts the BebopDriver, which publishes the telemetry of the drone
+// as events
 func (a *BebopDriver) Start() (errs []error) {
+	telemetry := a.adaptor().drone.Telemetry()
+	a.mutex.Lock()
+	halt := make(chan bool)
+	a.halt = halt
+	a.mutex.Unlock()
+	go func() {
+		for {
+			select {
+			case <-halt:
+				return
+			case state := <-telemetry:
+				a.publishTelemetry(state)
+			}
+		}
+	}()
 	return
 }
 
 // Halt halts the BebopDriver
 func (a *BebopDriver) Halt() (errs []error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.halt != nil {
+		close(a.halt)
+		a.halt = nil
+	}
 	return
 }
 
+// publishTelemetry publishes a state reported by the drone to its event
+func (a *BebopDriver) publishTelemetry(state interface{}) {
+	switch state.(type) {
+	case arcommands.BatteryState:
+		gobot.Publish(a.Event("battery"), state)
+	case arcommands.FlyingState:
+		gobot.Publish(a.Event("flyingstate"), state)
+	case arcommands.Position:
+		gobot.Publish(a.Event("position"), state)
+	case arcommands.Attitude:
+		gobot.Publish(a.Event("attitude"), state)
+	case arcommands.Altitude:
+		gobot.Publish(a.Event("altitude"), state)
+	case arcommands.Speed:
+		gobot.Publish(a.Event("speed"), state)
+	}
+}
+
 // TakeOff makes the drone start flying
 func (a *BebopDriver) TakeOff() {
 	gobot.Publish(a.Event("flying"), a.adaptor().drone.TakeOff())
@@ -113,6 +174,23 @@ func (a *BebopDriver) Video() chan []byte {
 	return a.adaptor().drone.Video()
 }
 
+// VideoReader returns a reader of the H.264 video stream of the drone, which
+// starts with an iframe. Close stops the reader.
+func (a *BebopDriver) VideoReader() io.ReadCloser {
+	return a.adaptor().drone.VideoReader()
+}
+
+// StartVideoRTP sends the video stream of the drone as RTP packets to the UDP
+// address addr, such as "127.0.0.1:5004"
+func (a *BebopDriver) StartVideoRTP(addr string) error {
+	return a.adaptor().drone.StartVideoRTP(addr)
+}
+
+// StopVideoRTP stops sending the video stream of the drone as RTP packets
+func (a *BebopDriver) StopVideoRTP() error {
+	return a.adaptor().drone.StopVideoRTP()
+}
+
 // StartRecording starts the recording video to the drones interal storage
 func (a *BebopDriver) StartRecording() error {
 	return a.adaptor().drone.StartRecording()
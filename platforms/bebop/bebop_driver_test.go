@@ -1,5 +1,76 @@
 package bebop
 
-import "github.com/hybridgroup/gobot"
+import (
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+	"github.com/hybridgroup/gobot/platforms/arcommands"
+)
 
 var _ gobot.Driver = (*BebopDriver)(nil)
+
+func TestBebopDriverTelemetry(t *testing.T) {
+	a := initTestBebopAdaptor()
+	a.Connect()
+	drone := &testDrone{telemetry: make(chan interface{})}
+	a.drone = drone
+	d := NewBebopDriver(a, "bot")
+	gobottest.Assert(t, len(d.Start()), 0)
+	defer d.Halt()
+
+	for _, test := range []struct {
+		event string
+		state interface{}
+	}{
+		{"battery", arcommands.BatteryState{Percent: 87}},
+		{"flyingstate", arcommands.FlyingState{State: arcommands.ARCOMMANDS_ARDRONE3_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_HOVERING}},
+		{"position", arcommands.Position{Latitude: 48.878, Longitude: 2.367, Altitude: 500}},
+		{"attitude", arcommands.Attitude{Roll: 0.5}},
+		{"altitude", arcommands.Altitude{Altitude: 10}},
+		{"speed", arcommands.Speed{Z: -2}},
+	} {
+		sem := make(chan interface{}, 1)
+		unsubscribe, _ := gobot.Subscribe(d.Event(test.event), func(data interface{}) {
+			sem <- data
+		})
+		drone.telemetry <- test.state
+		select {
+		case data := <-sem:
+			gobottest.Assert(t, data, test.state)
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("%v was not published", test.event)
+		}
+		unsubscribe()
+	}
+}
+
+func TestBebopDriverRestart(t *testing.T) {
+	a := initTestBebopAdaptor()
+	a.Connect()
+	drone := &testDrone{telemetry: make(chan interface{})}
+	a.drone = drone
+	d := NewBebopDriver(a, "bot")
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+	gobottest.Assert(t, len(d.Start()), 0)
+	gobottest.Assert(t, len(d.Halt()), 0)
+	gobottest.Assert(t, len(d.Halt()), 0)
+
+	// the telemetry is published again once restarted
+	gobottest.Assert(t, len(d.Start()), 0)
+	defer d.Halt()
+	sem := make(chan interface{}, 1)
+	unsubscribe, _ := gobot.Subscribe(d.Event("battery"), func(data interface{}) {
+		sem <- data
+	})
+	defer unsubscribe()
+	drone.telemetry <- arcommands.BatteryState{Percent: 50}
+	select {
+	case data := <-sem:
+		gobottest.Assert(t, data, arcommands.BatteryState{Percent: 50})
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("battery was not published")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"sync"
 	"time"
 )
 
@@ -18,14 +19,6 @@ func validatePitch(val int) int {
 	return val
 }
 
-type tmpFrame struct {
-	arstreamACK   ARStreamACK
-	fragments     map[int][]byte
-	frame         []byte
-	waitForIframe bool
-	frameFlags    int
-}
-
 type ARStreamACK struct {
 	FrameNumber    int
 	HighPacketsAck uint64
@@ -158,7 +151,8 @@ type Bebop struct {
 	IP                    string
 	NavData               map[string]string
 	Pcmd                  Pcmd
-	tmpFrame              tmpFrame
+	arstreamACK           ARStreamACK
+	videoAssembler        *videoAssembler
 	C2dPort               int
 	D2cPort               int
 	DiscoveryPort         int
@@ -167,6 +161,10 @@ type Bebop struct {
 	discoveryClient       *net.TCPConn
 	networkFrameGenerator func(*bytes.Buffer, byte, byte) *bytes.Buffer
 	video                 chan []byte
+	videoMutex            sync.Mutex
+	videoSinks            []*videoSink
+	rtpDone               chan struct{}
+	telemetry             chan interface{}
 	writeChan             chan []byte
 }
 
@@ -186,9 +184,10 @@ func New() *Bebop {
 			Gaz:   0,
 			Psi:   0,
 		},
-		tmpFrame:  tmpFrame{},
-		video:     make(chan []byte),
-		writeChan: make(chan []byte),
+		videoAssembler: newVideoAssembler(),
+		video:          make(chan []byte),
+		telemetry:      make(chan interface{}, telemetrySize),
+		writeChan:      make(chan []byte),
 	}
 }
 
@@ -260,6 +259,9 @@ func (b *Bebop) Connect() error {
 		return err
 	}
 
+	// the video stream starts over on each connection
+	b.videoAssembler.reset()
+
 	go func() {
 		for {
 			_, err := b.c2dClient.Write(<-b.writeChan)
@@ -539,6 +541,12 @@ func (b *Bebop) packetReceiver(buf []byte) {
 		if err != nil {
 			fmt.Println("ARNETWORKAL_FRAME_TYPE_DATA_LOW_LATENCY", err)
 		}
+
+		b.receiveVideo(arstreamFrame)
+	}
+
+	if frame.Id == int(BD_NET_DC_NAVDATA_ID) || frame.Id == int(BD_NET_DC_EVENT_ID) {
+		b.receiveTelemetry(frame)
 	}
 
 	//
@@ -595,6 +603,8 @@ func (b *Bebop) videoRecord(state byte) *bytes.Buffer {
 	return cmd
 }
 
+// Video returns a channel of the complete H.264 access units of the video of
+// the drone, which are dropped unless the channel is ready to receive them
 func (b *Bebop) Video() chan []byte {
 	return b.video
 }
@@ -674,69 +684,28 @@ func (b *Bebop) createARStreamACK(frame ARStreamFrame) *bytes.Buffer {
 	// fragmentsPerFrame which have been received per frameNumber, so time to
 	// flip some bits!
 	//
-	if frame.FrameNumber != b.tmpFrame.arstreamACK.FrameNumber {
-		if len(b.tmpFrame.fragments) > 0 {
-			emit := false
-
-			// if we missed some frames, wait for the next iframe
-			if frame.FrameNumber != b.tmpFrame.arstreamACK.FrameNumber+1 {
-				b.tmpFrame.waitForIframe = true
-			}
-
-			// if it's an iframe
-			if b.tmpFrame.frameFlags == 1 {
-				b.tmpFrame.waitForIframe = false
-				emit = true
-			} else if !b.tmpFrame.waitForIframe {
-				emit = true
-			}
-
-			if emit {
-				skip := false
-
-				for i := 0; i < len(b.tmpFrame.fragments); i++ {
-					// check if any fragments are missing
-					if len(b.tmpFrame.fragments[i]) == 0 {
-						skip = true
-						break
-					}
-					b.tmpFrame.frame = append(b.tmpFrame.frame, b.tmpFrame.fragments[i]...)
-				}
-
-				if !skip {
-					select {
-					case b.video <- b.tmpFrame.frame:
-					default:
-					}
-				}
-			}
-		}
-
-		b.tmpFrame.fragments = make(map[int][]byte)
-		b.tmpFrame.frame = []byte{}
-		b.tmpFrame.arstreamACK.FrameNumber = frame.FrameNumber
-		b.tmpFrame.frameFlags = frame.FrameFlags
+	if frame.FrameNumber != b.arstreamACK.FrameNumber {
+		b.arstreamACK = ARStreamACK{FrameNumber: frame.FrameNumber}
 	}
-	b.tmpFrame.fragments[frame.FragmentNumber] = frame.Frame
 
 	if frame.FragmentNumber < 64 {
-		b.tmpFrame.arstreamACK.LowPacketsAck |= uint64(1) << uint64(frame.FragmentNumber)
+		b.arstreamACK.LowPacketsAck |= uint64(1) << uint64(frame.FragmentNumber)
 	} else {
-		b.tmpFrame.arstreamACK.HighPacketsAck |= uint64(1) << uint64(frame.FragmentNumber-64)
+		b.arstreamACK.HighPacketsAck |= uint64(1) << uint64(frame.FragmentNumber-64)
 	}
 
 	ackPacket := &bytes.Buffer{}
 	tmp := &bytes.Buffer{}
 
-	binary.Write(tmp, binary.LittleEndian, uint16(b.tmpFrame.arstreamACK.FrameNumber))
+	binary.Write(tmp, binary.LittleEndian, uint16(b.arstreamACK.FrameNumber))
 	ackPacket.Write(tmp.Bytes())
 
 	tmp = &bytes.Buffer{}
-	binary.Write(tmp, binary.LittleEndian, uint64(b.tmpFrame.arstreamACK.HighPacketsAck))
+	binary.Write(tmp, binary.LittleEndian, uint64(b.arstreamACK.HighPacketsAck))
 	ackPacket.Write(tmp.Bytes())
 
 	tmp = &bytes.Buffer{}
-	binary.Write(tmp, binary.LittleEndian, uint64(b.tmpFrame.arstreamACK.LowPacketsAck))
+	binary.Write(tmp, binary.LittleEndian, uint64(b.arstreamACK.LowPacketsAck))
 	ackPacket.Write(tmp.Bytes())
 
 	return b.networkFrameGenerator(ackPacket, ARNETWORKAL_FRAME_TYPE_DATA, BD_NET_CD_VIDEO_ACK_ID)
@@ -0,0 +1,49 @@
+/*
+	This example will connect to the Bebop and stream its video as RTP packets
+	to a local UDP port, which standard players such as ffplay or VLC can play.
+
+	Run this program with:
+		$ go run rtp.go
+
+	It writes the session description of the stream to bebop.sdp, which you
+	can then open with:
+		$ ffplay -protocol_whitelist file,udp,rtp bebop.sdp
+*/
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/hybridgroup/gobot/platforms/bebop/client"
+)
+
+func main() {
+	bebop := client.New()
+
+	if err := bebop.Connect(); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	addr := "127.0.0.1:5004"
+
+	sdp, err := client.VideoSDP(addr)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := ioutil.WriteFile("bebop.sdp", []byte(sdp), 0644); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	if err := bebop.StartVideoRTP(addr); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	<-time.After(99 * time.Second)
+}
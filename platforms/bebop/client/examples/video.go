@@ -17,6 +17,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os/exec"
 	"time"
@@ -66,10 +67,8 @@ func main() {
 	}()
 
 	go func() {
-		for {
-			if _, err := ffmpegIn.Write(<-bebop.Video()); err != nil {
-				fmt.Println(err)
-			}
+		if _, err := io.Copy(ffmpegIn, bebop.VideoReader()); err != nil {
+			fmt.Println(err)
 		}
 	}()
 
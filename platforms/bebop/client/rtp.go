@@ -0,0 +1,208 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+const (
+	// RTPPayloadType is the dynamic RTP payload type of the H.264 video
+	RTPPayloadType = 96
+	// RTPMTU is the default maximum size of the RTP packets
+	RTPMTU = 1400
+
+	rtpHeaderSize = 12
+	rtpClockRate  = 90000
+	// rtpWarningInterval is the shortest interval between the warnings
+	// logged when the RTP packets can not be sent
+	rtpWarningInterval = 10 * time.Second
+	// nalFUA is the NAL unit type of the fragmentation units of RFC 6184
+	nalFUA = 28
+)
+
+// RTPPacketizer packetizes H.264 access units into RTP packets, as described
+// by RFC 6184. NAL units which fit in a packet are sent as single NAL unit
+// packets, and larger ones as FU-A fragmentation units.
+type RTPPacketizer struct {
+	SSRC        uint32
+	PayloadType uint8
+	MTU         int
+	sequence    uint16
+}
+
+// NewRTPPacketizer returns a new RTPPacketizer with a random SSRC
+func NewRTPPacketizer() *RTPPacketizer {
+	return &RTPPacketizer{
+		SSRC:        rand.Uint32(),
+		PayloadType: RTPPayloadType,
+		MTU:         RTPMTU,
+		sequence:    uint16(rand.Uint32()),
+	}
+}
+
+// Packetize returns the RTP packets of an access unit in the H.264 byte
+// stream format, with the 90kHz timestamp of the access unit. The last packet
+// has the marker bit set.
+func (p *RTPPacketizer) Packetize(au []byte, timestamp uint32) (packets [][]byte) {
+	max := p.MTU - rtpHeaderSize
+	for _, nal := range nalUnits(au) {
+		if len(nal) <= max {
+			packets = append(packets, p.packet(timestamp, nal))
+			continue
+		}
+
+		indicator := nal[0]&0xE0 | nalFUA
+		for data := nal[1:]; len(data) > 0; {
+			header := nal[0] & 0x1F
+			if len(data) == len(nal)-1 {
+				header |= 0x80 // start
+			}
+			n := len(data)
+			if n > max-2 {
+				n = max - 2
+			} else {
+				header |= 0x40 // end
+			}
+			packets = append(packets, p.packet(timestamp, append([]byte{indicator, header}, data[:n]...)))
+			data = data[n:]
+		}
+	}
+	if len(packets) > 0 {
+		packets[len(packets)-1][1] |= 0x80
+	}
+	return
+}
+
+// packet returns an RTP packet with the next sequence number
+func (p *RTPPacketizer) packet(timestamp uint32, payload []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteByte(0x80) // version 2
+	buf.WriteByte(p.PayloadType & 0x7F)
+	binary.Write(buf, binary.BigEndian, p.sequence)
+	binary.Write(buf, binary.BigEndian, timestamp)
+	binary.Write(buf, binary.BigEndian, p.SSRC)
+	buf.Write(payload)
+	p.sequence++
+	return buf.Bytes()
+}
+
+// nalUnits splits the H.264 byte stream of an access unit into its NAL
+// units, without their start codes
+func nalUnits(au []byte) (nals [][]byte) {
+	start := -1
+	for i := 0; i+2 < len(au); i++ {
+		if au[i] != 0 || au[i+1] != 0 || au[i+2] != 1 {
+			continue
+		}
+		if start >= 0 {
+			nals = appendNAL(nals, au[start:i])
+		}
+		start = i + 3
+		i += 2
+	}
+	if start < 0 {
+		// not a byte stream, but a single NAL unit
+		return appendNAL(nals, au)
+	}
+	return appendNAL(nals, au[start:])
+}
+
+// appendNAL appends a NAL unit without the trailing zeros which belong to
+// the start code of the next one
+func appendNAL(nals [][]byte, nal []byte) [][]byte {
+	nal = bytes.TrimRight(nal, "\x00")
+	if len(nal) == 0 {
+		return nals
+	}
+	return append(nals, nal)
+}
+
+// VideoSDP returns the session description of the RTP stream sent to addr
+// by StartVideoRTP, which players such as ffplay or VLC open to play it
+func VideoSDP(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("v=0\r\n"+
+		"o=- 0 0 IN IP4 %v\r\n"+
+		"s=Bebop\r\n"+
+		"c=IN IP4 %v\r\n"+
+		"t=0 0\r\n"+
+		"m=video %v RTP/AVP %v\r\n"+
+		"a=rtpmap:%v H264/%v\r\n"+
+		"a=fmtp:%v packetization-mode=1\r\n",
+		host, host, port, RTPPayloadType, RTPPayloadType, rtpClockRate, RTPPayloadType), nil
+}
+
+// StartVideoRTP sends the video of the drone as RTP packets to the UDP
+// address addr, such as "127.0.0.1:5004", until StopVideoRTP is called. The
+// access units which can not be sent, for instance while no player listens
+// at addr, are dropped, and a warning with their number is logged at most
+// every 10 seconds.
+func (b *Bebop) StartVideoRTP(addr string) error {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return err
+	}
+
+	b.StopVideoRTP()
+	sink := b.addVideoSink()
+	done := make(chan struct{})
+	b.videoMutex.Lock()
+	b.rtpDone = done
+	b.videoMutex.Unlock()
+
+	go func() {
+		defer conn.Close()
+		defer b.removeVideoSink(sink)
+		packetizer := NewRTPPacketizer()
+		start := time.Now()
+		var warned time.Time
+		dropped := 0
+		for {
+			select {
+			case <-done:
+				return
+			case au := <-sink.units:
+				timestamp := uint32(time.Since(start).Seconds() * rtpClockRate)
+				if err := sendRTP(conn, packetizer.Packetize(au.data, timestamp)); err != nil {
+					dropped++
+					if time.Since(warned) >= rtpWarningInterval {
+						gobot.DefaultLogger.Log(gobot.WarnLevel, "Failed to send RTP video",
+							gobot.Fields{"addr": addr, "error": err, "dropped": dropped})
+						warned, dropped = time.Now(), 0
+					}
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// sendRTP writes RTP packets to conn, stopping at the first error
+func sendRTP(conn net.Conn, packets [][]byte) error {
+	for _, packet := range packets {
+		if _, err := conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StopVideoRTP stops sending the video of the drone as RTP packets
+func (b *Bebop) StopVideoRTP() error {
+	b.videoMutex.Lock()
+	defer b.videoMutex.Unlock()
+	if b.rtpDone != nil {
+		close(b.rtpDone)
+		b.rtpDone = nil
+	}
+	return nil
+}
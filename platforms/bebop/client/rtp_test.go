@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// testLogger records the messages of the entries logged
+type testLogger struct {
+	mutex    sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) Log(level gobot.Level, msg string, fields gobot.Fields) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.messages = append(l.messages, level.String()+" "+msg)
+}
+
+func (l *testLogger) Messages() []string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]string{}, l.messages...)
+}
+
+func TestNALUnits(t *testing.T) {
+	nals := nalUnits([]byte{0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x00, 0x01, 0x68, 0xCE, 0x00, 0x00, 0x00, 0x01, 0x65, 0x88})
+	gobottest.Assert(t, nals, [][]byte{{0x67, 0x42}, {0x68, 0xCE}, {0x65, 0x88}})
+
+	gobottest.Assert(t, nalUnits([]byte{0x65, 0x88}), [][]byte{{0x65, 0x88}})
+	gobottest.Assert(t, len(nalUnits([]byte{})), 0)
+}
+
+func TestRTPPacketizer(t *testing.T) {
+	p := NewRTPPacketizer()
+	p.SSRC = 0x01020304
+	p.MTU = 20
+	p.sequence = 65535
+
+	idr := append([]byte{0x65}, bytes.Repeat([]byte{0xAB}, 10)...)
+	au := append([]byte{0x00, 0x00, 0x00, 0x01, 0x67, 0x42, 0x00, 0x00, 0x01}, idr...)
+	packets := p.Packetize(au, 90000)
+	gobottest.Assert(t, len(packets), 3)
+
+	gobottest.Assert(t, packets[0][:12], []byte{0x80, 96, 0xFF, 0xFF, 0x00, 0x01, 0x5F, 0x90, 0x01, 0x02, 0x03, 0x04})
+	gobottest.Assert(t, packets[0][12:], []byte{0x67, 0x42})
+
+	// FU-A fragments of the IDR slice
+	gobottest.Assert(t, binary.BigEndian.Uint16(packets[1][2:4]), uint16(0))
+	gobottest.Assert(t, packets[1][1], uint8(96))
+	gobottest.Assert(t, packets[1][12:], append([]byte{0x7C, 0x85}, idr[1:7]...))
+	gobottest.Assert(t, binary.BigEndian.Uint16(packets[2][2:4]), uint16(1))
+	gobottest.Assert(t, packets[2][1], uint8(0x80|96))
+	gobottest.Assert(t, packets[2][12:], append([]byte{0x7C, 0x45}, idr[7:]...))
+}
+
+func TestVideoSDP(t *testing.T) {
+	sdp, err := VideoSDP("127.0.0.1:5004")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, strings.Contains(sdp, "m=video 5004 RTP/AVP 96\r\n"), true)
+	gobottest.Assert(t, strings.Contains(sdp, "a=rtpmap:96 H264/90000\r\n"), true)
+
+	_, err = VideoSDP("127.0.0.1")
+	gobottest.Refute(t, err, nil)
+}
+
+func TestBebopVideoRTP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	gobottest.Assert(t, err, nil)
+	defer conn.Close()
+
+	b := New()
+	gobottest.Assert(t, b.StartVideoRTP(conn.LocalAddr().String()), nil)
+	b.receiveVideo(fragment(1, 1, 0, 1, "\x00\x00\x01\x65\xAA"))
+
+	buf := make([]byte, 1500)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, buf[1:2], []byte{0x80 | 96})
+	gobottest.Assert(t, buf[12:n], []byte{0x65, 0xAA})
+
+	gobottest.Assert(t, b.StopVideoRTP(), nil)
+	for i := 0; i < 10 && videoSinks(b) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	gobottest.Assert(t, videoSinks(b), 0)
+}
+
+func TestBebopVideoRTPErrors(t *testing.T) {
+	logger := &testLogger{}
+	defaultLogger := gobot.DefaultLogger
+	gobot.DefaultLogger = logger
+	defer func() { gobot.DefaultLogger = defaultLogger }()
+
+	// nothing listens at the address
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	gobottest.Assert(t, err, nil)
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	b := New()
+	gobottest.Assert(t, b.StartVideoRTP(addr), nil)
+	defer b.StopVideoRTP()
+	for i := 1; i < 100 && len(logger.Messages()) == 0; i++ {
+		b.receiveVideo(fragment(i, 1, 0, 1, "\x00\x00\x01\x65\xAA"))
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i := 100; i < 110; i++ {
+		b.receiveVideo(fragment(i, 1, 0, 1, "\x00\x00\x01\x65\xAA"))
+		time.Sleep(time.Millisecond)
+	}
+
+	// the failures are logged once every rtpWarningInterval
+	gobottest.Assert(t, logger.Messages(), []string{"warn Failed to send RTP video"})
+}
@@ -0,0 +1,34 @@
+package client
+
+import "github.com/hybridgroup/gobot/platforms/arcommands"
+
+// telemetrySize is the number of telemetry states buffered for the reader of
+// Telemetry, beyond which states are dropped
+const telemetrySize = 100
+
+// receiveTelemetry decodes the ARCommand of a navdata or event frame, and
+// sends the state it reports to the reader of Telemetry
+func (b *Bebop) receiveTelemetry(frame NetworkFrame) {
+	cmd, err := arcommands.NewARCommand(frame.Data)
+	if err != nil {
+		return
+	}
+
+	state := cmd.Telemetry()
+	if state == nil {
+		return
+	}
+
+	select {
+	case b.telemetry <- state:
+	default:
+	}
+}
+
+// Telemetry returns a channel of the states reported by the drone: its
+// arcommands.BatteryState, FlyingState, Position, Speed, Attitude and
+// Altitude. States
+// are dropped when the channel is full.
+func (b *Bebop) Telemetry() chan interface{} {
+	return b.telemetry
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+	"github.com/hybridgroup/gobot/platforms/arcommands"
+)
+
+func TestBebopTelemetry(t *testing.T) {
+	b := New()
+
+	b.packetReceiver([]byte{0x02, 0x7F, 0x01, 0x0C, 0x00, 0x00, 0x00, 0x00, 0x05, 0x01, 0x00, 0x32})
+	gobottest.Assert(t, <-b.Telemetry(), arcommands.BatteryState{Percent: 50})
+
+	// states are dropped when the channel is full
+	for i := 0; i < telemetrySize+1; i++ {
+		b.packetReceiver([]byte{0x02, 0x7F, 0x01, 0x0C, 0x00, 0x00, 0x00, 0x00, 0x05, 0x01, 0x00, 0x32})
+	}
+	gobottest.Assert(t, len(b.Telemetry()), telemetrySize)
+}
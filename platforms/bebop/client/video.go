@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// videoSinkSize is the number of access units buffered for each reader of
+// the video stream, beyond which access units are dropped
+const videoSinkSize = 30
+
+// videoMaxLateFrames is the number of frames behind the current one whose
+// fragments are still received late, while a frame further behind restarts
+// the stream, as when the drone enables its video again
+const videoMaxLateFrames = 30
+
+// accessUnit is a complete H.264 access unit of the video stream
+type accessUnit struct {
+	data   []byte
+	iframe bool
+}
+
+// videoAssembler reassembles the fragments of the ARStream frames into H.264
+// access units.
+//
+// A frame is complete once all of its fragments are received, in any order.
+// A frame which misses fragments when the next frame starts is dropped, as
+// are the following frames until an iframe, since they can not be decoded
+// without it. The stream starts over at an iframe when its frame numbers
+// restart.
+type videoAssembler struct {
+	started       bool
+	number        int
+	flags         int
+	fragments     [][]byte
+	received      int
+	waitForIframe bool
+	// dropped is the number of frames which could not be reassembled
+	dropped int
+}
+
+func newVideoAssembler() *videoAssembler {
+	return &videoAssembler{waitForIframe: true}
+}
+
+// reset starts the stream over, keeping the count of dropped frames
+func (v *videoAssembler) reset() {
+	*v = videoAssembler{waitForIframe: true, dropped: v.dropped}
+}
+
+// add adds a fragment, and returns the access unit of its frame once the
+// fragment completes it
+func (v *videoAssembler) add(frame ARStreamFrame) (au accessUnit, ok bool) {
+	if v.started && frame.FrameNumber != v.number {
+		behind := -int(int16(uint16(frame.FrameNumber) - uint16(v.number)))
+		if behind > videoMaxLateFrames {
+			v.reset()
+		} else if behind > 0 {
+			// late fragments of previous frames are ignored
+			return
+		}
+	}
+	if !v.started || frame.FrameNumber != v.number {
+		if v.started {
+			if v.received < len(v.fragments) {
+				v.dropped++
+				v.waitForIframe = true
+			}
+			if lost := int(uint16(frame.FrameNumber)-uint16(v.number)) - 1; lost > 0 {
+				v.dropped += lost
+				v.waitForIframe = true
+			}
+		}
+		v.started = true
+		v.number = frame.FrameNumber
+		v.flags = frame.FrameFlags
+		v.fragments = make([][]byte, frame.FragmentsPerFrame)
+		v.received = 0
+	}
+
+	// fragments are sent again until acknowledged
+	if frame.FragmentNumber >= len(v.fragments) || v.fragments[frame.FragmentNumber] != nil {
+		return
+	}
+	v.fragments[frame.FragmentNumber] = frame.Frame
+	v.received++
+	if v.received < len(v.fragments) {
+		return
+	}
+
+	iframe := v.flags&1 == 1
+	if v.waitForIframe && !iframe {
+		v.dropped++
+		return
+	}
+	v.waitForIframe = false
+	return accessUnit{data: bytes.Join(v.fragments, nil), iframe: iframe}, true
+}
+
+// videoSink is a reader of the access units of the video stream, which
+// starts with an iframe, and waits for the next iframe whenever it falls
+// behind and access units are dropped
+type videoSink struct {
+	units         chan accessUnit
+	waitForIframe bool
+}
+
+// receiveVideo reassembles a fragment of the video stream, and sends the
+// access unit it completes to the readers of the stream
+func (b *Bebop) receiveVideo(frame ARStreamFrame) {
+	au, ok := b.videoAssembler.add(frame)
+	if !ok {
+		return
+	}
+
+	select {
+	case b.video <- au.data:
+	default:
+	}
+
+	b.videoMutex.Lock()
+	defer b.videoMutex.Unlock()
+	for _, sink := range b.videoSinks {
+		if sink.waitForIframe && !au.iframe {
+			continue
+		}
+		select {
+		case sink.units <- au:
+			sink.waitForIframe = false
+		default:
+			sink.waitForIframe = true
+		}
+	}
+}
+
+// addVideoSink returns a new reader of the access units of the video stream
+func (b *Bebop) addVideoSink() *videoSink {
+	b.videoMutex.Lock()
+	defer b.videoMutex.Unlock()
+	sink := &videoSink{
+		units:         make(chan accessUnit, videoSinkSize),
+		waitForIframe: true,
+	}
+	b.videoSinks = append(b.videoSinks, sink)
+	return sink
+}
+
+// removeVideoSink stops sending access units to a reader
+func (b *Bebop) removeVideoSink(sink *videoSink) {
+	b.videoMutex.Lock()
+	defer b.videoMutex.Unlock()
+	for i, s := range b.videoSinks {
+		if s == sink {
+			b.videoSinks = append(b.videoSinks[:i], b.videoSinks[i+1:]...)
+			return
+		}
+	}
+}
+
+// videoReader reads the H.264 byte stream of the access units of a video sink
+type videoReader struct {
+	bebop *Bebop
+	sink  *videoSink
+	buf   []byte
+	once  sync.Once
+	done  chan struct{}
+}
+
+// VideoReader returns a reader of the H.264 byte stream of the video of the
+// drone, made of complete access units starting with an iframe. When the
+// reader falls behind, access units are dropped until the next iframe. Close
+// stops the reader.
+func (b *Bebop) VideoReader() io.ReadCloser {
+	return &videoReader{
+		bebop: b,
+		sink:  b.addVideoSink(),
+		done:  make(chan struct{}),
+	}
+}
+
+func (r *videoReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.done:
+		return 0, io.EOF
+	default:
+	}
+	for len(r.buf) == 0 {
+		select {
+		case <-r.done:
+			return 0, io.EOF
+		case au := <-r.sink.units:
+			r.buf = au.data
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *videoReader) Close() error {
+	r.once.Do(func() {
+		r.bebop.removeVideoSink(r.sink)
+		close(r.done)
+	})
+	return nil
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func fragment(number, flags, n, count int, data string) ARStreamFrame {
+	return ARStreamFrame{
+		FrameNumber:       number,
+		FrameFlags:        flags,
+		FragmentNumber:    n,
+		FragmentsPerFrame: count,
+		Frame:             []byte(data),
+	}
+}
+
+func videoSinks(b *Bebop) int {
+	b.videoMutex.Lock()
+	defer b.videoMutex.Unlock()
+	return len(b.videoSinks)
+}
+
+func TestNewARStreamFrame(t *testing.T) {
+	frame := NewARStreamFrame([]byte{0x02, 0x01, 0x01, 0x03, 0x04, 0xAA, 0xBB})
+	gobottest.Assert(t, frame.FrameNumber, 258)
+	gobottest.Assert(t, frame.FrameFlags, 1)
+	gobottest.Assert(t, frame.FragmentNumber, 3)
+	gobottest.Assert(t, frame.FragmentsPerFrame, 4)
+	gobottest.Assert(t, frame.Frame, []byte{0xAA, 0xBB})
+}
+
+func TestVideoAssembler(t *testing.T) {
+	v := newVideoAssembler()
+
+	// frames before the first iframe are dropped
+	_, ok := v.add(fragment(1, 0, 0, 1, "p"))
+	gobottest.Assert(t, ok, false)
+	gobottest.Assert(t, v.dropped, 1)
+
+	// fragments are reassembled in any order, duplicates are ignored
+	_, ok = v.add(fragment(2, 1, 1, 3, "b"))
+	gobottest.Assert(t, ok, false)
+	_, ok = v.add(fragment(2, 1, 1, 3, "b"))
+	gobottest.Assert(t, ok, false)
+	_, ok = v.add(fragment(2, 1, 0, 3, "a"))
+	gobottest.Assert(t, ok, false)
+	au, ok := v.add(fragment(2, 1, 2, 3, "c"))
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, au.iframe, true)
+	gobottest.Assert(t, string(au.data), "abc")
+
+	au, ok = v.add(fragment(3, 0, 0, 1, "d"))
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, au.iframe, false)
+
+	// late fragments are ignored
+	_, ok = v.add(fragment(2, 1, 0, 3, "a"))
+	gobottest.Assert(t, ok, false)
+	gobottest.Assert(t, v.number, 3)
+}
+
+func TestVideoAssemblerMissingFragments(t *testing.T) {
+	v := newVideoAssembler()
+	v.add(fragment(1, 1, 0, 1, "i"))
+
+	// frame 2 misses a fragment, so frames wait for the next iframe
+	v.add(fragment(2, 0, 0, 2, "a"))
+	_, ok := v.add(fragment(3, 0, 0, 1, "p"))
+	gobottest.Assert(t, ok, false)
+	gobottest.Assert(t, v.dropped, 2)
+
+	au, ok := v.add(fragment(4, 1, 0, 1, "i"))
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, string(au.data), "i")
+
+	// frames 5 and 6 are lost
+	_, ok = v.add(fragment(7, 0, 0, 1, "p"))
+	gobottest.Assert(t, ok, false)
+	gobottest.Assert(t, v.dropped, 5)
+}
+
+func TestVideoAssemblerFrameNumberWraps(t *testing.T) {
+	v := newVideoAssembler()
+	v.add(fragment(65535, 1, 0, 1, "i"))
+	au, ok := v.add(fragment(0, 0, 0, 1, "p"))
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, string(au.data), "p")
+	gobottest.Assert(t, v.dropped, 0)
+}
+
+func TestVideoAssemblerStreamRestarts(t *testing.T) {
+	v := newVideoAssembler()
+	v.add(fragment(1000, 1, 0, 1, "i"))
+	v.add(fragment(1001, 0, 0, 2, "a"))
+
+	// the stream restarts at frame 0 and waits for its first iframe
+	_, ok := v.add(fragment(0, 0, 0, 1, "p"))
+	gobottest.Assert(t, ok, false)
+	au, ok := v.add(fragment(1, 1, 0, 1, "i"))
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, string(au.data), "i")
+	au, ok = v.add(fragment(2, 0, 0, 1, "p"))
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, string(au.data), "p")
+	gobottest.Assert(t, v.dropped, 1)
+
+	v.reset()
+	gobottest.Assert(t, v.started, false)
+	gobottest.Assert(t, v.waitForIframe, true)
+	gobottest.Assert(t, v.dropped, 1)
+}
+
+func TestBebopVideo(t *testing.T) {
+	b := New()
+	r := b.VideoReader()
+	received := make(chan []byte, 1)
+	go func() {
+		received <- <-b.Video()
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	b.receiveVideo(fragment(1, 1, 0, 2, "\x00\x00\x01\x65"))
+	b.receiveVideo(fragment(1, 1, 1, 2, "\xAA"))
+	b.receiveVideo(fragment(2, 0, 0, 1, "\x00\x00\x01\x41\xBB"))
+
+	select {
+	case data := <-received:
+		gobottest.Assert(t, data, []byte("\x00\x00\x01\x65\xAA"))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("access unit was not received")
+	}
+
+	buf := make([]byte, 3)
+	n, err := r.Read(buf)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, buf[:n], []byte("\x00\x00\x01"))
+	n, _ = r.Read(buf)
+	gobottest.Assert(t, buf[:n], []byte("\x65\xAA"))
+	n, _ = r.Read(buf)
+	gobottest.Assert(t, buf[:n], []byte("\x00\x00\x01"))
+
+	gobottest.Assert(t, r.Close(), nil)
+	gobottest.Assert(t, videoSinks(b), 0)
+	data, err := ioutil.ReadAll(r)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(data), 0)
+}
+
+func TestBebopVideoReaderFallsBehind(t *testing.T) {
+	b := New()
+	r := b.VideoReader()
+	defer r.Close()
+
+	b.receiveVideo(fragment(0, 1, 0, 1, "i"))
+	for i := 1; i <= videoSinkSize; i++ {
+		b.receiveVideo(fragment(i, 0, 0, 1, "p"))
+	}
+	// the last frame was dropped, so the reader waits for the next iframe
+	data, err := ioutil.ReadAll(io.LimitReader(r, videoSinkSize))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, string(data), "i"+strings.Repeat("p", videoSinkSize-1))
+
+	b.receiveVideo(fragment(videoSinkSize+1, 0, 0, 1, "x"))
+	b.receiveVideo(fragment(videoSinkSize+2, 1, 0, 1, "I"))
+	buf := make([]byte, 1)
+	r.Read(buf)
+	gobottest.Assert(t, string(buf), "I")
+}
+
+func TestBebopCreateARStreamACK(t *testing.T) {
+	b := New()
+	b.createARStreamACK(fragment(1, 0, 0, 66, ""))
+	b.createARStreamACK(fragment(1, 0, 65, 66, ""))
+	gobottest.Assert(t, b.arstreamACK, ARStreamACK{FrameNumber: 1, LowPacketsAck: 1, HighPacketsAck: 2})
+
+	ack := NewNetworkFrame(b.createARStreamACK(fragment(2, 0, 1, 2, "")).Bytes())
+	gobottest.Assert(t, ack.Id, int(BD_NET_CD_VIDEO_ACK_ID))
+	gobottest.Assert(t, ack.Data, []byte{0x02, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00})
+}
@@ -1,6 +1,10 @@
 package bebop
 
-type testDrone struct{}
+import "io"
+
+type testDrone struct {
+	telemetry chan interface{}
+}
 
 //func (t testDrone) Close() {}
 func (t testDrone) TakeOff() error { return nil }
@@ -16,6 +20,10 @@ func (t testDrone) CounterClockwise(n int) error { return nil }
 func (t testDrone) Stop() error { return nil }
 func (t testDrone) Connect() error { return nil }
 func (t testDrone) Video() chan []byte { return nil }
+func (t testDrone) VideoReader() io.ReadCloser { return nil }
+func (t testDrone) StartVideoRTP(addr string) error { return nil }
+func (t testDrone) StopVideoRTP() error { return nil }
+func (t testDrone) Telemetry() chan interface{} { return t.telemetry }
 func (t testDrone) StartRecording() error { return nil }
 func (t testDrone) StopRecording() error { return nil }
 func (t testDrone) HullProtection(protect bool) error { return nil }
@@ -1,7 +1,6 @@
 package ble
 
 import (
-	"fmt"
 	"log"
 	"strings"
 
@@ -20,6 +19,8 @@ type BLEClientAdaptor struct {
 	services   map[string]*BLEService
 	connected  bool
 	ready      chan struct{}
+	// disconnected is called when the peripheral disconnects
+	disconnected func(err error)
 }
 
 // NewBLEClientAdaptor returns a new BLEClientAdaptor given a name and uuid
@@ -92,13 +93,13 @@ func (b *BLEClientAdaptor) ReadCharacteristic(sUUID string, cUUID string) (data
 
 	characteristic := b.lookupCharacteristic(sUUID, cUUID)
 	if characteristic == nil {
-		log.Println("Cannot read from unknown characteristic")
+		b.logger().Log(gobot.ErrorLevel, "Cannot read from unknown characteristic", nil)
 		return
 	}
 
 	val, err := b.peripheral.ReadCharacteristic(characteristic)
 	if err != nil {
-		fmt.Printf("Failed to read characteristic, err: %s\n", err)
+		b.logger().Log(gobot.ErrorLevel, "Failed to read characteristic", gobot.Fields{"error": err})
 		return nil, err
 	}
 
@@ -115,13 +116,13 @@ func (b *BLEClientAdaptor) WriteCharacteristic(sUUID string, cUUID string, data
 
 	characteristic := b.lookupCharacteristic(sUUID, cUUID)
 	if characteristic == nil {
-		log.Println("Cannot write to unknown characteristic")
+		b.logger().Log(gobot.ErrorLevel, "Cannot write to unknown characteristic", nil)
 		return
 	}
 
 	err = b.peripheral.WriteCharacteristic(characteristic, data, true)
 	if err != nil {
-		fmt.Printf("Failed to write characteristic, err: %s\n", err)
+		b.logger().Log(gobot.ErrorLevel, "Failed to write characteristic", gobot.Fields{"error": err})
 		return err
 	}
 
@@ -138,7 +139,7 @@ func (b *BLEClientAdaptor) Subscribe(sUUID string, cUUID string, f func([]byte,
 
 	characteristic := b.lookupCharacteristic(sUUID, cUUID)
 	if characteristic == nil {
-		log.Println("Cannot subscribe to unknown characteristic")
+		b.logger().Log(gobot.ErrorLevel, "Cannot subscribe to unknown characteristic", nil)
 		return
 	}
 
@@ -148,7 +149,7 @@ func (b *BLEClientAdaptor) Subscribe(sUUID string, cUUID string, f func([]byte,
 
 	err = b.peripheral.SetNotifyValue(characteristic, fn)
 	if err != nil {
-		fmt.Printf("Failed to subscribe to characteristic, err: %s\n", err)
+		b.logger().Log(gobot.ErrorLevel, "Failed to subscribe to characteristic", gobot.Fields{"error": err})
 		return err
 	}
 
@@ -156,10 +157,10 @@ func (b *BLEClientAdaptor) Subscribe(sUUID string, cUUID string, f func([]byte,
 }
 
 func (b *BLEClientAdaptor) StateChangeHandler(d gatt.Device, s gatt.State) {
-	fmt.Println("State:", s)
+	b.logger().Log(gobot.DebugLevel, "BLE device state changed", gobot.Fields{"state": s})
 	switch s {
 	case gatt.StatePoweredOn:
-		fmt.Println("scanning...")
+		b.logger().Log(gobot.InfoLevel, "Scanning for BLE peripheral", nil)
 		d.Scan([]gatt.UUID{}, false)
 		return
 	default:
@@ -187,17 +188,17 @@ func (b *BLEClientAdaptor) DiscoveryHandler(p gatt.Peripheral, a *gatt.Advertise
 }
 
 func (b *BLEClientAdaptor) ConnectHandler(p gatt.Peripheral, err error) {
-	fmt.Printf("\nConnected Peripheral ID:%s, NAME:(%s)\n", p.ID(), p.Name())
+	b.logger().Log(gobot.InfoLevel, "Connected BLE peripheral", gobot.Fields{"id": p.ID(), "peripheral": p.Name()})
 
 	b.peripheral = p
 
 	if err := p.SetMTU(250); err != nil {
-		fmt.Printf("Failed to set MTU, err: %s\n", err)
+		b.logger().Log(gobot.WarnLevel, "Failed to set MTU", gobot.Fields{"error": err})
 	}
 
 	ss, err := p.DiscoverServices(nil)
 	if err != nil {
-		fmt.Printf("Failed to discover services, err: %s\n", err)
+		b.logger().Log(gobot.ErrorLevel, "Failed to discover services", gobot.Fields{"error": err})
 		return
 	}
 
@@ -207,14 +208,14 @@ outer:
 
 		cs, err := p.DiscoverCharacteristics(nil, s)
 		if err != nil {
-			fmt.Printf("Failed to discover characteristics, err: %s\n", err)
+			b.logger().Log(gobot.ErrorLevel, "Failed to discover characteristics", gobot.Fields{"error": err})
 			continue
 		}
 
 		for _, c := range cs {
 			_, err := p.DiscoverDescriptors(nil, c)
 			if err != nil {
-				fmt.Printf("Failed to discover descriptors: %v\n", err)
+				b.logger().Log(gobot.ErrorLevel, "Failed to discover descriptors", gobot.Fields{"error": err})
 				continue outer
 			}
 			b.services[s.UUID().String()].characteristics[c.UUID().String()] = c
@@ -226,20 +227,29 @@ outer:
 }
 
 func (b *BLEClientAdaptor) DisconnectHandler(p gatt.Peripheral, err error) {
-	fmt.Println("Disconnected")
+	b.logger().Log(gobot.InfoLevel, "Disconnected BLE peripheral", nil)
+	b.connected = false
+	if b.disconnected != nil {
+		b.disconnected(err)
+	}
+}
+
+// logger returns gobot.DefaultLogger with the connection of the adaptor
+func (b *BLEClientAdaptor) logger() gobot.Logger {
+	return gobot.WithFields(gobot.DefaultLogger, gobot.Fields{"connection": b.Name()})
 }
 
 // Finalize finalizes the BLEAdaptor
 func (b *BLEClientAdaptor) lookupCharacteristic(sUUID string, cUUID string) *gatt.Characteristic {
 	service := b.services[sUUID]
 	if service == nil {
-		log.Printf("Unknown service ID: %s\n", sUUID)
+		b.logger().Log(gobot.WarnLevel, "Unknown service ID", gobot.Fields{"service": sUUID})
 		return nil
 	}
 
 	characteristic := service.characteristics[cUUID]
 	if characteristic == nil {
-		log.Printf("Unknown characteristic ID: %s\n", cUUID)
+		b.logger().Log(gobot.WarnLevel, "Unknown characteristic ID", gobot.Fields{"characteristic": cUUID})
 		return nil
 	}
 
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/arcommands"
 )
 
 var _ gobot.Driver = (*BLEMinidroneDriver)(nil)
@@ -35,9 +36,15 @@ const (
 	// Battery event
 	Battery = "battery"
 
+	// battery state event
+	BatteryState = "batterystate"
+
 	// flight status event
 	Status = "status"
 
+	// flying state event
+	FlyingState = "flyingstate"
+
 	// flying event
 	Flying = "flying"
 
@@ -65,6 +72,14 @@ func validatePitch(val int) int {
 }
 
 // NewBLEMinidroneDriver creates a BLEMinidroneDriver by name
+//
+// It adds the following events:
+//	"battery" - triggered with the battery percentage of the drone as a byte
+//	"batterystate" - triggered with the arcommands.BatteryState of the drone
+//	"flyingstate" - triggered with the arcommands.FlyingState of the drone
+//	"status" - triggered with the flying state of the drone as a byte
+//	"flying" - triggered when the drone takes off
+//	"landed" - triggered when the drone lands
 func NewBLEMinidroneDriver(a *BLEClientAdaptor, name string) *BLEMinidroneDriver {
 	n := &BLEMinidroneDriver{
 		name:       name,
@@ -81,7 +96,9 @@ func NewBLEMinidroneDriver(a *BLEClientAdaptor, name string) *BLEMinidroneDriver
 	}
 
 	n.AddEvent(Battery)
+	n.AddEvent(BatteryState)
 	n.AddEvent(Status)
+	n.AddEvent(FlyingState)
 	n.AddEvent(Flying)
 	n.AddEvent(Landed)
 
@@ -118,26 +135,44 @@ func (b *BLEMinidroneDriver) Init() (err error) {
 
 	// subscribe to battery notifications
 	b.adaptor().Subscribe(DroneNotificationService, BatteryCharacteristic, func(data []byte, e error) {
-		gobot.Publish(b.Event(Battery), data[len(data)-1])
+		b.receive(data)
 	})
 
 	// subscribe to flying status notifications
 	b.adaptor().Subscribe(DroneNotificationService, FlightStatusCharacteristic, func(data []byte, e error) {
-		if len(data) < 7 || data[2] != 2 {
-			fmt.Println(data)
-			return
-		}
-		gobot.Publish(b.Event(Status), data[6])
-		if (data[6] == 1 || data[6] == 2) && !b.flying {
+		b.receive(data)
+	})
+
+	return
+}
+
+// receive decodes the ARCommand of a notification, which follows its frame
+// type and sequence number, and publishes the state it reports
+func (b *BLEMinidroneDriver) receive(data []byte) {
+	if len(data) < 2 {
+		return
+	}
+	cmd, err := arcommands.NewARCommand(data[2:])
+	if err != nil {
+		return
+	}
+
+	switch state := cmd.Telemetry().(type) {
+	case arcommands.BatteryState:
+		gobot.Publish(b.Event(BatteryState), state)
+		gobot.Publish(b.Event(Battery), state.Percent)
+	case arcommands.FlyingState:
+		gobot.Publish(b.Event(FlyingState), state)
+		gobot.Publish(b.Event(Status), state.State)
+		if (state.State == arcommands.ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_TAKINGOFF ||
+			state.State == arcommands.ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_HOVERING) && !b.flying {
 			b.flying = true
 			gobot.Publish(b.Event(Flying), true)
-		} else if (data[6] == 0) && b.flying {
+		} else if state.State == arcommands.ARCOMMANDS_MINIDRONE_PILOTINGSTATE_FLYINGSTATECHANGED_STATE_LANDED && b.flying {
 			b.flying = false
 			gobot.Publish(b.Event(Landed), true)
 		}
-	})
-
-	return
+	}
 }
 
 func (b *BLEMinidroneDriver) GenerateAllStates() (err error) {
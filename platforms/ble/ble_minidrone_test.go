@@ -0,0 +1,77 @@
+package ble
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+	"github.com/hybridgroup/gobot/platforms/arcommands"
+)
+
+func initTestBLEMinidroneDriver() *BLEMinidroneDriver {
+	return NewBLEMinidroneDriver(initTestBLEClientAdaptor(), "drone")
+}
+
+func TestBLEMinidroneDriverBattery(t *testing.T) {
+	d := initTestBLEMinidroneDriver()
+	battery := make(chan interface{}, 1)
+	state := make(chan interface{}, 1)
+	gobot.Once(d.Event(Battery), func(data interface{}) {
+		battery <- data
+	})
+	gobot.Once(d.Event(BatteryState), func(data interface{}) {
+		state <- data
+	})
+
+	d.receive([]byte{0x04, 0x01, 0x00, 0x05, 0x01, 0x00, 0x57})
+	select {
+	case data := <-battery:
+		gobottest.Assert(t, data, uint8(87))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("battery was not published")
+	}
+	select {
+	case data := <-state:
+		gobottest.Assert(t, data, arcommands.BatteryState{Percent: 87})
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("batterystate was not published")
+	}
+}
+
+func TestBLEMinidroneDriverFlyingState(t *testing.T) {
+	d := initTestBLEMinidroneDriver()
+	states := make(chan interface{}, 1)
+	status := make(chan interface{}, 1)
+	flying := make(chan interface{}, 1)
+	landed := make(chan interface{}, 1)
+	gobot.On(d.Event(FlyingState), func(data interface{}) { states <- data })
+	gobot.On(d.Event(Status), func(data interface{}) { status <- data })
+	gobot.On(d.Event(Flying), func(data interface{}) { flying <- data })
+	gobot.On(d.Event(Landed), func(data interface{}) { landed <- data })
+
+	receive := func(c chan interface{}) interface{} {
+		select {
+		case data := <-c:
+			return data
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		}
+	}
+
+	// hovering
+	d.receive([]byte{0x04, 0x02, 0x02, 0x03, 0x01, 0x00, 0x02, 0x00, 0x00, 0x00})
+	gobottest.Assert(t, receive(states), arcommands.FlyingState{State: 2})
+	gobottest.Assert(t, receive(status), uint8(2))
+	gobottest.Assert(t, receive(flying), true)
+
+	// landed
+	d.receive([]byte{0x04, 0x03, 0x02, 0x03, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00})
+	gobottest.Assert(t, receive(states), arcommands.FlyingState{State: 0})
+	gobottest.Assert(t, receive(status), uint8(0))
+	gobottest.Assert(t, receive(landed), true)
+
+	// alert state changed
+	d.receive([]byte{0x04, 0x04, 0x02, 0x03, 0x02, 0x00, 0x01, 0x00, 0x00, 0x00})
+	gobottest.Assert(t, receive(status), nil)
+}
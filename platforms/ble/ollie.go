@@ -10,6 +10,10 @@ import (
 
 var _ gobot.Driver = (*SpheroOllieDriver)(nil)
 
+// SpheroOllieDriver represents an Ollie.
+//
+// Deprecated: use a sphero.SpheroDriver with a SpheroBLEAdaptor, which runs
+// the full Sphero command set over BLE.
 type SpheroOllieDriver struct {
 	name          string
 	connection    gobot.Connection
@@ -44,6 +48,8 @@ type packet struct {
 }
 
 // NewSpheroOllieDriver creates a SpheroOllieDriver by name
+//
+// Deprecated: use sphero.NewSpheroDriver with a SpheroBLEAdaptor.
 func NewSpheroOllieDriver(a *BLEClientAdaptor, name string) *SpheroOllieDriver {
 	n := &SpheroOllieDriver{
 		name:          name,
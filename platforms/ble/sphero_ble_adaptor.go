@@ -0,0 +1,169 @@
+package ble
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/sphero"
+)
+
+var _ gobot.Adaptor = (*SpheroBLEAdaptor)(nil)
+var _ sphero.Transport = (*SpheroBLEAdaptor)(nil)
+
+// ErrSpheroDisconnected is returned by the writes to a disconnected Sphero
+var ErrSpheroDisconnected = errors.New("Sphero is disconnected")
+
+// spheroBLEMTU is the largest number of bytes written to the commands
+// characteristic at once
+const spheroBLEMTU = 20
+
+// SpheroBLEAdaptor represents a connection to a Sphero SPRK+, BB-8 or Ollie
+// over BLE. It is the Transport of a sphero.SpheroDriver, which runs its full
+// command set over the commands and response characteristics of the robot
+// control service:
+//
+//	adaptor := ble.NewSpheroBLEAdaptor("bb8", uuid)
+//	driver := sphero.NewSpheroDriver(adaptor, "bb8")
+type SpheroBLEAdaptor struct {
+	*BLEClientAdaptor
+	mutex     sync.Mutex
+	cond      *sync.Cond
+	responses []byte
+	closed    bool
+	write     func(data []byte) error
+}
+
+// NewSpheroBLEAdaptor returns a new SpheroBLEAdaptor given a name and uuid
+func NewSpheroBLEAdaptor(name string, uuid string) *SpheroBLEAdaptor {
+	a := &SpheroBLEAdaptor{
+		BLEClientAdaptor: NewBLEClientAdaptor(name, uuid),
+		closed:           true,
+	}
+	a.cond = sync.NewCond(&a.mutex)
+	a.write = func(data []byte) error {
+		return a.WriteCharacteristic(RobotControlService, CommandsCharacteristic, data)
+	}
+	a.disconnected = a.close
+	return a
+}
+
+// Connect connects to the Sphero, turns off its anti-DoS protection, sets
+// its transmit power, wakes it up and subscribes to its responses.
+func (a *SpheroBLEAdaptor) Connect() (errs []error) {
+	if errs = a.BLEClientAdaptor.Connect(); len(errs) > 0 {
+		return
+	}
+
+	a.mutex.Lock()
+	a.responses = nil
+	a.closed = false
+	a.mutex.Unlock()
+
+	for _, w := range []struct {
+		service        string
+		characteristic string
+		data           []byte
+	}{
+		{SpheroBLEService, AntiDosCharacteristic, []byte("011i3")},
+		{SpheroBLEService, TXPowerCharacteristic, []byte{0x07}},
+		{SpheroBLEService, WakeCharacteristic, []byte{0x01}},
+	} {
+		if err := a.WriteCharacteristic(w.service, w.characteristic, w.data); err != nil {
+			return []error{err}
+		}
+	}
+
+	if err := a.Subscribe(RobotControlService, ResponseCharacteristic, a.notify); err != nil {
+		return []error{err}
+	}
+	return
+}
+
+// Reconnect attempts to reconnect to the Sphero. If it has an active
+// connection it will first close that connection and then establish a new
+// connection.
+func (a *SpheroBLEAdaptor) Reconnect() (errs []error) {
+	if a.Connected() {
+		a.Disconnect()
+	}
+	return a.Connect()
+}
+
+// Disconnect terminates the connection to the Sphero
+func (a *SpheroBLEAdaptor) Disconnect() (errs []error) {
+	a.close(nil)
+	return a.BLEClientAdaptor.Disconnect()
+}
+
+// Finalize finalizes the SpheroBLEAdaptor
+func (a *SpheroBLEAdaptor) Finalize() (errs []error) {
+	return a.Disconnect()
+}
+
+// Connected returns whether the Sphero is connected
+func (a *SpheroBLEAdaptor) Connected() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return !a.closed
+}
+
+// Read reads the packets notified by the response characteristic, blocking
+// until there are some. It returns io.EOF once the Sphero is disconnected.
+func (a *SpheroBLEAdaptor) Read(b []byte) (int, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for len(a.responses) == 0 && !a.closed {
+		a.cond.Wait()
+	}
+	if a.closed {
+		return 0, io.EOF
+	}
+	n := copy(b, a.responses)
+	a.responses = a.responses[n:]
+	return n, nil
+}
+
+// Write writes a packet to the commands characteristic, in chunks of at
+// most 20 bytes. It returns ErrSpheroDisconnected once the Sphero is
+// disconnected.
+func (a *SpheroBLEAdaptor) Write(b []byte) (n int, err error) {
+	for n < len(b) {
+		if !a.Connected() {
+			return n, ErrSpheroDisconnected
+		}
+		end := n + spheroBLEMTU
+		if end > len(b) {
+			end = len(b)
+		}
+		if err = a.write(b[n:end]); err != nil {
+			return
+		}
+		n = end
+	}
+	return
+}
+
+// close wakes up the pending reads once the Sphero is disconnected
+func (a *SpheroBLEAdaptor) close(err error) {
+	if err != nil {
+		a.logger().Log(gobot.WarnLevel, "Sphero disconnected", gobot.Fields{"error": err})
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.closed = true
+	a.cond.Broadcast()
+}
+
+// notify buffers the data notified by the response characteristic
+func (a *SpheroBLEAdaptor) notify(data []byte, err error) {
+	if err != nil {
+		a.logger().Log(gobot.WarnLevel, "Failed to receive Sphero response", gobot.Fields{"error": err})
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.responses = append(a.responses, data...)
+	a.cond.Broadcast()
+}
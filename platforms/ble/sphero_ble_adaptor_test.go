@@ -0,0 +1,79 @@
+package ble
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func TestSpheroBLEAdaptorRead(t *testing.T) {
+	a := NewSpheroBLEAdaptor("bb8", "D7:99:5A:26:EC:38")
+	gobottest.Assert(t, a.Name(), "bb8")
+	gobottest.Assert(t, a.Connected(), false)
+
+	a.closed = false
+	a.notify([]byte{0xFF, 0xFF, 0x00}, nil)
+	a.notify([]byte{0x01, 0x01, 0xFD}, nil)
+	a.notify([]byte{0xFF}, errors.New("notification error"))
+
+	buf := make([]byte, 4)
+	n, err := a.Read(buf)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, buf[:n], []byte{0xFF, 0xFF, 0x00, 0x01})
+	n, err = a.Read(buf)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, buf[:n], []byte{0x01, 0xFD})
+}
+
+func TestSpheroBLEAdaptorReadDisconnected(t *testing.T) {
+	a := NewSpheroBLEAdaptor("bb8", "D7:99:5A:26:EC:38")
+	a.closed = false
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := a.Read(make([]byte, 4))
+		result <- err
+	}()
+
+	// the peripheral disconnects while the read is pending
+	a.DisconnectHandler(nil, errors.New("connection lost"))
+	select {
+	case err := <-result:
+		gobottest.Assert(t, err, io.EOF)
+	case <-time.After(time.Second):
+		t.Error("Read was not woken up by the disconnection")
+	}
+	gobottest.Assert(t, a.Connected(), false)
+
+	_, err := a.Write([]byte{0xFF})
+	gobottest.Assert(t, err, ErrSpheroDisconnected)
+}
+
+func TestSpheroBLEAdaptorWrite(t *testing.T) {
+	a := NewSpheroBLEAdaptor("bb8", "D7:99:5A:26:EC:38")
+	a.closed = false
+	var writes [][]byte
+	a.write = func(data []byte) error {
+		writes = append(writes, data)
+		return nil
+	}
+
+	data := make([]byte, 45)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	n, err := a.Write(data)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, n, 45)
+	gobottest.Assert(t, writes, [][]byte{data[:20], data[20:40], data[40:]})
+
+	a.write = func(data []byte) error {
+		return errors.New("write error")
+	}
+	n, err = a.Write(data)
+	gobottest.Assert(t, err, errors.New("write error"))
+	gobottest.Assert(t, n, 0)
+}
@@ -2,10 +2,12 @@ package chip
 
 import (
 	"errors"
+	"time"
 
 	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/platforms/gpio"
 	"github.com/hybridgroup/gobot/platforms/i2c"
+	"github.com/hybridgroup/gobot/platforms/onewire"
 	"github.com/hybridgroup/gobot/sysfs"
 )
 
@@ -16,10 +18,13 @@ var _ gpio.DigitalWriter = (*ChipAdaptor)(nil)
 
 var _ i2c.I2c = (*ChipAdaptor)(nil)
 
+var _ onewire.OneWire = (*ChipAdaptor)(nil)
+
 type ChipAdaptor struct {
-	name        string
-	digitalPins map[int]sysfs.DigitalPin
-	i2cDevice   sysfs.I2cDevice
+	name           string
+	digitalPins    map[int]sysfs.DigitalPin
+	i2cDevice      sysfs.I2cDevice
+	oneWireDevices map[string]sysfs.OneWireDevice
 }
 
 var pins = map[string]int{
@@ -36,8 +41,9 @@ var pins = map[string]int{
 // NewChipAdaptor creates a ChipAdaptor with the specified name
 func NewChipAdaptor(name string) *ChipAdaptor {
 	c := &ChipAdaptor{
-		name:        name,
-		digitalPins: make(map[int]sysfs.DigitalPin),
+		name:           name,
+		digitalPins:    make(map[int]sysfs.DigitalPin),
+		oneWireDevices: make(map[string]sysfs.OneWireDevice),
 	}
 	return c
 }
@@ -59,6 +65,11 @@ func (c *ChipAdaptor) Finalize() (errs []error) {
 			}
 		}
 	}
+	for _, device := range c.oneWireDevices {
+		if err := device.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if c.i2cDevice != nil {
 		if err := c.i2cDevice.Close(); err != nil {
 			errs = append(errs, err)
@@ -130,6 +141,8 @@ func (c *ChipAdaptor) I2cStart(address int) (err error) {
 
 // I2cWrite writes data to i2c device
 func (c *ChipAdaptor) I2cWrite(address int, data []byte) (err error) {
+	defer gobot.MeterI2c(c.Name(), address, "write", time.Now(), &err)
+
 	if err = c.i2cDevice.SetAddress(address); err != nil {
 		return
 	}
@@ -139,6 +152,8 @@ func (c *ChipAdaptor) I2cWrite(address int, data []byte) (err error) {
 
 // I2cRead returns value from i2c device using specified size
 func (c *ChipAdaptor) I2cRead(address int, size int) (data []byte, err error) {
+	defer gobot.MeterI2c(c.Name(), address, "read", time.Now(), &err)
+
 	if err = c.i2cDevice.SetAddress(address); err != nil {
 		return
 	}
@@ -146,3 +161,49 @@ func (c *ChipAdaptor) I2cRead(address int, size int) (data []byte, err error) {
 	_, err = c.i2cDevice.Read(data)
 	return
 }
+
+// OneWireStart checks that the linux 1-wire bus master bus, eg.
+// "w1_bus_master1", is available. An empty bus defaults to w1_bus_master1.
+// The C.H.I.P. requires the w1-gpio device tree overlay which uses pin LCD-D2.
+func (c *ChipAdaptor) OneWireStart(bus string) (err error) {
+	_, err = sysfs.OneWireSlaves(bus)
+	return
+}
+
+// OneWireSearch returns the ROM IDs of the devices found by the 1-wire bus master
+func (c *ChipAdaptor) OneWireSearch(bus string) (ids []string, err error) {
+	return sysfs.OneWireSlaves(bus)
+}
+
+// OneWireWrite resets the 1-wire bus, selects device id and writes data to it
+func (c *ChipAdaptor) OneWireWrite(bus string, id string, data []byte) (err error) {
+	device, err := c.oneWireDevice(id)
+	if err != nil {
+		return
+	}
+	_, err = device.Write(data)
+	return
+}
+
+// OneWireRead returns size bytes from 1-wire device id
+func (c *ChipAdaptor) OneWireRead(bus string, id string, size int) (data []byte, err error) {
+	device, err := c.oneWireDevice(id)
+	if err != nil {
+		return
+	}
+	data = make([]byte, size)
+	_, err = device.Read(data)
+	return
+}
+
+// oneWireDevice returns the sysfs 1-wire device for id, opening it on first use
+func (c *ChipAdaptor) oneWireDevice(id string) (device sysfs.OneWireDevice, err error) {
+	if device, ok := c.oneWireDevices[id]; ok {
+		return device, nil
+	}
+	if device, err = sysfs.NewOneWireDevice(id); err != nil {
+		return nil, err
+	}
+	c.oneWireDevices[id] = device
+	return
+}
@@ -79,3 +79,23 @@ func TestChipAdaptorI2c(t *testing.T) {
 
 	gobottest.Assert(t, len(a.Finalize()), 0)
 }
+
+func TestChipAdaptorOneWire(t *testing.T) {
+	a := initTestChipAdaptor()
+	fs := sysfs.NewMockFilesystem([]string{
+		"/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves",
+		"/sys/bus/w1/devices/28-0000054c2ec2/rw",
+	})
+	sysfs.SetFilesystem(fs)
+	fs.Files["/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves"].Contents = "28-0000054c2ec2\n"
+
+	gobottest.Assert(t, a.OneWireStart(""), nil)
+	ids, _ := a.OneWireSearch("")
+	gobottest.Assert(t, ids, []string{"28-0000054c2ec2"})
+
+	a.OneWireWrite("", "28-0000054c2ec2", []byte{0xBE})
+	data, _ := a.OneWireRead("", "28-0000054c2ec2", 1)
+	gobottest.Assert(t, data, []byte{0xBE})
+
+	gobottest.Assert(t, len(a.Finalize()), 0)
+}
@@ -14,11 +14,12 @@ import (
 
 // Pin Modes
 const (
-	Input  = 0x00
-	Output = 0x01
-	Analog = 0x02
-	Pwm    = 0x03
-	Servo  = 0x04
+	Input   = 0x00
+	Output  = 0x01
+	Analog  = 0x02
+	Pwm     = 0x03
+	Servo   = 0x04
+	OneWire = 0x07
 )
 
 // Sysex Codes
@@ -52,6 +53,31 @@ const (
 	I2CModeContinuousRead    byte = 0x02
 	I2CModeStopReading       byte = 0x03
 	ServoConfig              byte = 0x70
+	OneWireData              byte = 0x73
+	PixelCommand             byte = 0x51
+)
+
+// OneWire Sysex Subcommands
+const (
+	OneWireSearchRequest       byte = 0x40
+	OneWireConfigRequest       byte = 0x41
+	OneWireSearchReply         byte = 0x42
+	OneWireReadReply           byte = 0x43
+	OneWireSearchAlarmsRequest byte = 0x44
+	OneWireSearchAlarmsReply   byte = 0x45
+	OneWireResetRequestBit     byte = 0x01
+	OneWireSkipRequestBit      byte = 0x02
+	OneWireSelectRequestBit    byte = 0x04
+	OneWireReadRequestBit      byte = 0x08
+	OneWireDelayRequestBit     byte = 0x10
+	OneWireWriteRequestBit     byte = 0x20
+)
+
+// NeoPixel Sysex Subcommands, as implemented by the node-pixel firmware
+const (
+	PixelConfig byte = 0x01
+	PixelShow   byte = 0x02
+	PixelSet    byte = 0x03
 )
 
 // Errors
@@ -87,6 +113,20 @@ type I2cReply struct {
 	Data     []byte
 }
 
+// OneWireSearchResponse represents the response from a OneWireSearchRequest or
+// OneWireSearchAlarmsRequest message
+type OneWireSearchResponse struct {
+	Pin       int
+	Addresses [][]byte
+}
+
+// OneWireReadResponse represents the response from a OneWire read request
+type OneWireReadResponse struct {
+	Pin           int
+	CorrelationID int
+	Data          []byte
+}
+
 // New returns a new Client
 func New() *Client {
 	c := &Client{
@@ -105,6 +145,8 @@ func New() *Client {
 		"AnalogMappingQuery",
 		"ProtocolVersion",
 		"I2cReply",
+		"OneWireSearchReply",
+		"OneWireReadReply",
 		"StringData",
 		"Error",
 	} {
@@ -291,6 +333,104 @@ func (b *Client) I2cConfig(delay int) error {
 	return b.writeSysex([]byte{I2CConfig, byte(delay & 0xFF), byte((delay >> 8) & 0xFF)})
 }
 
+// OneWireConfig configures pin as a 1-Wire bus. When power is true the pin is
+// left high after a write to supply parasitically powered devices.
+func (b *Client) OneWireConfig(pin int, power bool) error {
+	p := byte(0)
+	if power {
+		p = 1
+	}
+	return b.writeSysex([]byte{OneWireData, OneWireConfigRequest, byte(pin), p})
+}
+
+// OneWireSearch searches the 1-Wire bus on pin for the addresses of all
+// attached devices.
+func (b *Client) OneWireSearch(pin int) error {
+	return b.writeSysex([]byte{OneWireData, OneWireSearchRequest, byte(pin)})
+}
+
+// OneWireWrite resets the 1-Wire bus on pin, selects the device at address and
+// writes data to it.
+func (b *Client) OneWireWrite(pin int, address []byte, data []byte) error {
+	payload := append(append([]byte{}, address...), data...)
+	return b.oneWireRequest(pin,
+		OneWireResetRequestBit|OneWireSelectRequestBit|OneWireWriteRequestBit,
+		payload,
+	)
+}
+
+// OneWireRead reads numBytes from the 1-Wire bus on pin without resetting it.
+// The reply is published on the "OneWireReadReply" event with correlationID.
+func (b *Client) OneWireRead(pin int, numBytes int, correlationID int) error {
+	return b.oneWireRequest(pin, OneWireReadRequestBit, []byte{
+		byte(numBytes), byte(numBytes >> 8),
+		byte(correlationID), byte(correlationID >> 8),
+	})
+}
+
+func (b *Client) oneWireRequest(pin int, command byte, payload []byte) error {
+	return b.writeSysex(append([]byte{OneWireData, command, byte(pin)}, encode7Bit(payload)...))
+}
+
+// NeoPixelConfig configures a strip of length WS2812 pixels on pin
+func (b *Client) NeoPixelConfig(pin int, length int) error {
+	return b.writeSysex([]byte{PixelCommand, PixelConfig, byte(pin),
+		byte(length & 0x7F), byte((length >> 7) & 0x7F)})
+}
+
+// NeoPixelSetPixel sets the pixel at index to the 0xRRGGBB color. The pixel
+// is not updated until NeoPixelShow is called.
+func (b *Client) NeoPixelSetPixel(index int, color uint32) error {
+	return b.writeSysex([]byte{PixelCommand, PixelSet,
+		byte(index & 0x7F), byte((index >> 7) & 0x7F),
+		byte(color & 0x7F), byte((color >> 7) & 0x7F),
+		byte((color >> 14) & 0x7F), byte((color >> 21) & 0x7F),
+	})
+}
+
+// NeoPixelShow sends the pixels set by NeoPixelSetPixel to the strip
+func (b *Client) NeoPixelShow() error {
+	return b.writeSysex([]byte{PixelCommand, PixelShow})
+}
+
+// encode7Bit packs 8-bit data into 7-bit bytes as required by the OneWire sysex
+func encode7Bit(data []byte) (encoded []byte) {
+	shift := uint(0)
+	previous := byte(0)
+	for _, val := range data {
+		if shift == 0 {
+			encoded = append(encoded, val&0x7F)
+			shift++
+			previous = val >> 7
+		} else {
+			encoded = append(encoded, ((val<<shift)&0x7F)|previous)
+			if shift == 6 {
+				encoded = append(encoded, val>>1)
+				shift = 0
+			} else {
+				shift++
+				previous = val >> (8 - shift)
+			}
+		}
+	}
+	if shift > 0 {
+		encoded = append(encoded, previous)
+	}
+	return
+}
+
+// decode7Bit unpacks 7-bit bytes encoded by encode7Bit into 8-bit data
+func decode7Bit(encoded []byte) (data []byte) {
+	length := len(encoded) * 7 / 8
+	for i := 0; i < length; i++ {
+		j := i << 3
+		pos := j / 7
+		shift := uint(j % 7)
+		data = append(data, (encoded[pos]>>shift)|(encoded[pos+1]<<(7-shift)))
+	}
+	return
+}
+
 func (b *Client) togglePinReporting(pin int, state int, mode byte) error {
 	if state != 0 {
 		state = 1
@@ -459,6 +599,26 @@ func (b *Client) process() (err error) {
 				)
 			}
 			gobot.Publish(b.Event("I2cReply"), reply)
+		case OneWireData:
+			pin := int(currentBuffer[3])
+			data := decode7Bit(currentBuffer[4 : len(currentBuffer)-1])
+			switch currentBuffer[2] {
+			case OneWireSearchReply, OneWireSearchAlarmsReply:
+				reply := OneWireSearchResponse{Pin: pin, Addresses: [][]byte{}}
+				for i := 0; i+8 <= len(data); i = i + 8 {
+					reply.Addresses = append(reply.Addresses, data[i:i+8])
+				}
+				gobot.Publish(b.Event("OneWireSearchReply"), reply)
+			case OneWireReadReply:
+				if len(data) < 2 {
+					break
+				}
+				gobot.Publish(b.Event("OneWireReadReply"), OneWireReadResponse{
+					Pin:           pin,
+					CorrelationID: int(data[0]) | int(data[1])<<8,
+					Data:          data[2:],
+				})
+			}
 		case FirmwareQuery:
 			name := []byte{}
 			for _, val := range currentBuffer[4:(len(currentBuffer) - 1)] {
@@ -156,6 +156,29 @@ func TestProcess(t *testing.T) {
 			},
 			init: func() {},
 		},
+		{
+			event: "OneWireSearchReply",
+			data: []byte{240, 0x73, 0x42, 2, 0x28, 0x04, 0x3B, 0x61, 0x54, 0x00, 0x00,
+				0x00, 0x72, 0x50, 0x08, 0x76, 0x42, 0x29, 0x01, 0x00, 0x00, 0x64, 0x01, 247},
+			expected: OneWireSearchResponse{
+				Pin: 2,
+				Addresses: [][]byte{
+					{0x28, 0xC2, 0x2E, 0x4C, 0x05, 0x00, 0x00, 0x72},
+					{0x28, 0xC2, 0x2E, 0x4C, 0x05, 0x00, 0x00, 0x72},
+				},
+			},
+			init: func() {},
+		},
+		{
+			event: "OneWireReadReply",
+			data:  []byte{240, 0x73, 0x43, 2, 0x01, 0x00, 0x44, 0x0C, 0x30, 0x09, 247},
+			expected: OneWireReadResponse{
+				Pin:           2,
+				CorrelationID: 1,
+				Data:          []byte{0x91, 0x01, 0x4B},
+			},
+			init: func() {},
+		},
 		{
 			event: "FirmwareQuery",
 			data: []byte{240, 121, 2, 3, 83, 0, 116, 0, 97, 0, 110, 0, 100, 0, 97,
@@ -255,3 +278,52 @@ func TestServoConfig(t *testing.T) {
 		gobottest.Assert(t, err, test.result)
 	}
 }
+
+func TestOneWire(t *testing.T) {
+	b := New()
+	b.connection = readWriteCloser{}
+	address := []byte{0x28, 0xC2, 0x2E, 0x4C, 0x05, 0x00, 0x00, 0x72}
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.OneWireConfig(2, true), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x73, 0x41, 2, 1, 0xF7})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.OneWireSearch(2), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x73, 0x40, 2, 0xF7})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.OneWireWrite(2, address, []byte{0x44}), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x73, 0x25, 2,
+		0x28, 0x04, 0x3B, 0x61, 0x54, 0x00, 0x00, 0x00, 0x72, 0x08, 0x01, 0xF7})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.OneWireRead(2, 9, 1), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x73, 0x08, 2,
+		0x09, 0x00, 0x04, 0x00, 0x00, 0xF7})
+}
+
+func TestNeoPixel(t *testing.T) {
+	b := New()
+	b.connection = readWriteCloser{}
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.NeoPixelConfig(6, 300), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x51, 0x01, 6, 0x2C, 0x02, 0xF7})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.NeoPixelSetPixel(130, 0xFF8001), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x51, 0x03, 0x02, 0x01,
+		0x01, 0x00, 0x7E, 0x07, 0xF7})
+
+	testWriteData.Reset()
+	gobottest.Assert(t, b.NeoPixelShow(), nil)
+	gobottest.Assert(t, testWriteData.Bytes(), []byte{0xF0, 0x51, 0x02, 0xF7})
+}
+
+func TestEncode7Bit(t *testing.T) {
+	data := []byte{0x00, 0xFF, 0x80, 0x7F, 0x55, 0xAA, 0x01, 0xFE, 0x10}
+	for i := range data {
+		gobottest.Assert(t, decode7Bit(encode7Bit(data[:i+1])), data[:i+1])
+	}
+}
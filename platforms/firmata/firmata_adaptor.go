@@ -1,19 +1,27 @@
 package firmata
 
 import (
+	"errors"
 	"io"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/platforms/firmata/client"
 	"github.com/hybridgroup/gobot/platforms/gpio"
 	"github.com/hybridgroup/gobot/platforms/i2c"
+	"github.com/hybridgroup/gobot/platforms/ledstrip"
+	"github.com/hybridgroup/gobot/platforms/onewire"
 	"github.com/tarm/goserial"
 )
 
 var _ gobot.Adaptor = (*FirmataAdaptor)(nil)
 
+// ErrOneWireTimeout is returned when the board does not reply to a 1-Wire
+// search or read
+var ErrOneWireTimeout = errors.New("1-Wire reply timed out")
+
 var _ gpio.DigitalReader = (*FirmataAdaptor)(nil)
 var _ gpio.DigitalWriter = (*FirmataAdaptor)(nil)
 var _ gpio.AnalogReader = (*FirmataAdaptor)(nil)
@@ -22,6 +30,10 @@ var _ gpio.ServoWriter = (*FirmataAdaptor)(nil)
 
 var _ i2c.I2c = (*FirmataAdaptor)(nil)
 
+var _ onewire.OneWire = (*FirmataAdaptor)(nil)
+
+var _ ledstrip.NeoPixelWriter = (*FirmataAdaptor)(nil)
+
 type firmataBoard interface {
 	Connect(io.ReadWriteCloser) error
 	Disconnect() error
@@ -35,6 +47,13 @@ type firmataBoard interface {
 	I2cWrite(int, []byte) error
 	I2cConfig(int) error
 	ServoConfig(int, int, int) error
+	OneWireConfig(int, bool) error
+	OneWireSearch(int) error
+	OneWireWrite(int, []byte, []byte) error
+	OneWireRead(int, int, int) error
+	NeoPixelConfig(int, int) error
+	NeoPixelSetPixel(int, uint32) error
+	NeoPixelShow() error
 	Event(string) *gobot.Event
 }
 
@@ -45,6 +64,10 @@ type FirmataAdaptor struct {
 	board  firmataBoard
 	conn   io.ReadWriteCloser
 	openSP func(port string) (io.ReadWriteCloser, error)
+
+	mutex                sync.Mutex
+	oneWireCorrelationID int
+	oneWireTimeout       time.Duration
 }
 
 // NewFirmataAdaptor returns a new FirmataAdaptor with specified name and optionally accepts:
@@ -63,8 +86,13 @@ func NewFirmataAdaptor(name string, args ...interface{}) *FirmataAdaptor {
 		conn:  nil,
 		board: client.New(),
 		openSP: func(port string) (io.ReadWriteCloser, error) {
-			return serial.OpenPort(&serial.Config{Name: port, Baud: 57600})
+			sp, err := serial.OpenPort(&serial.Config{Name: port, Baud: 57600})
+			if err != nil {
+				return nil, err
+			}
+			return gobot.NewMeteredReadWriteCloser(sp, port), nil
 		},
+		oneWireTimeout: time.Second,
 	}
 
 	for _, arg := range args {
@@ -236,6 +264,8 @@ func (f *FirmataAdaptor) I2cStart(address int) (err error) {
 // I2cRead returns size bytes from the i2c device
 // Returns an empty array if the response from the board has timed out
 func (f *FirmataAdaptor) I2cRead(address int, size int) (data []byte, err error) {
+	defer gobot.MeterI2c(f.Name(), address, "read", time.Now(), &err)
+
 	ret := make(chan []byte)
 
 	if err = f.board.I2cRead(address, size); err != nil {
@@ -253,5 +283,136 @@ func (f *FirmataAdaptor) I2cRead(address int, size int) (data []byte, err error)
 
 // I2cWrite writes data to i2c device
 func (f *FirmataAdaptor) I2cWrite(address int, data []byte) (err error) {
+	defer gobot.MeterI2c(f.Name(), address, "write", time.Now(), &err)
+
 	return f.board.I2cWrite(address, data)
 }
+
+// OneWireStart configures the specified pin as a 1-Wire bus, leaving it
+// powered for parasitically powered devices
+func (f *FirmataAdaptor) OneWireStart(bus string) (err error) {
+	p, err := strconv.Atoi(bus)
+	if err != nil {
+		return
+	}
+	return f.board.OneWireConfig(p, true)
+}
+
+// OneWireSearch returns the ROM IDs of the devices on the 1-Wire bus.
+// Blocks until the board replies, or returns ErrOneWireTimeout.
+func (f *FirmataAdaptor) OneWireSearch(bus string) (ids []string, err error) {
+	p, err := strconv.Atoi(bus)
+	if err != nil {
+		return
+	}
+
+	ret := make(chan client.OneWireSearchResponse, 1)
+	unsubscribe, err := gobot.Subscribe(f.board.Event("OneWireSearchReply"), func(data interface{}) {
+		reply := data.(client.OneWireSearchResponse)
+		if reply.Pin != p {
+			return
+		}
+		select {
+		case ret <- reply:
+		default:
+		}
+	})
+	if err != nil {
+		return
+	}
+	defer unsubscribe()
+
+	if err = f.board.OneWireSearch(p); err != nil {
+		return
+	}
+
+	var reply client.OneWireSearchResponse
+	select {
+	case reply = <-ret:
+	case <-time.After(f.oneWireTimeout):
+		err = ErrOneWireTimeout
+		return
+	}
+
+	ids = []string{}
+	for _, address := range reply.Addresses {
+		var rom onewire.ROM
+		copy(rom[:], address)
+		ids = append(ids, rom.String())
+	}
+	return
+}
+
+// OneWireWrite resets the 1-Wire bus, selects device id and writes data to it
+func (f *FirmataAdaptor) OneWireWrite(bus string, id string, data []byte) (err error) {
+	p, err := strconv.Atoi(bus)
+	if err != nil {
+		return
+	}
+	rom, err := onewire.ParseROM(id)
+	if err != nil {
+		return
+	}
+	return f.board.OneWireWrite(p, rom[:], data)
+}
+
+// OneWireRead returns size bytes from the device selected by the last
+// OneWireWrite. Blocks until the board replies, or returns ErrOneWireTimeout.
+func (f *FirmataAdaptor) OneWireRead(bus string, id string, size int) (data []byte, err error) {
+	p, err := strconv.Atoi(bus)
+	if err != nil {
+		return
+	}
+
+	f.mutex.Lock()
+	f.oneWireCorrelationID = (f.oneWireCorrelationID + 1) & 0xFFFF
+	correlationID := f.oneWireCorrelationID
+	f.mutex.Unlock()
+	ret := make(chan []byte, 1)
+	unsubscribe, err := gobot.Subscribe(f.board.Event("OneWireReadReply"), func(data interface{}) {
+		reply := data.(client.OneWireReadResponse)
+		if reply.CorrelationID != correlationID {
+			return
+		}
+		select {
+		case ret <- reply.Data:
+		default:
+		}
+	})
+	if err != nil {
+		return
+	}
+	defer unsubscribe()
+
+	if err = f.board.OneWireRead(p, size, correlationID); err != nil {
+		return
+	}
+
+	select {
+	case data = <-ret:
+	case <-time.After(f.oneWireTimeout):
+		err = ErrOneWireTimeout
+	}
+	return
+}
+
+// NeoPixelStart configures a strip of length WS2812 pixels on the specified
+// pin. Requires the node-pixel firmware.
+func (f *FirmataAdaptor) NeoPixelStart(pin string, length int) (err error) {
+	p, err := strconv.Atoi(pin)
+	if err != nil {
+		return
+	}
+	return f.board.NeoPixelConfig(p, length)
+}
+
+// NeoPixelWrite sets every pixel of the strip to the 0xRRGGBB colors and
+// shows them
+func (f *FirmataAdaptor) NeoPixelWrite(pin string, colors []uint32) (err error) {
+	for i, c := range colors {
+		if err = f.board.NeoPixelSetPixel(i, c); err != nil {
+			return
+		}
+	}
+	return f.board.NeoPixelShow()
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/gobottest"
 	"github.com/hybridgroup/gobot/platforms/firmata/client"
+	"github.com/hybridgroup/gobot/platforms/onewire"
 )
 
 type readWriteCloser struct{}
@@ -55,6 +56,8 @@ func newMockFirmataBoard() *mockFirmataBoard {
 	m.pins[15].Value = 133
 
 	m.AddEvent("I2cReply")
+	m.AddEvent("OneWireSearchReply")
+	m.AddEvent("OneWireReadReply")
 	return m
 }
 
@@ -65,15 +68,22 @@ func (m mockFirmataBoard) Disconnect() error {
 func (m mockFirmataBoard) Pins() []client.Pin {
 	return m.pins
 }
-func (mockFirmataBoard) AnalogWrite(int, int) error      { return nil }
-func (mockFirmataBoard) SetPinMode(int, int) error       { return nil }
-func (mockFirmataBoard) ReportAnalog(int, int) error     { return nil }
-func (mockFirmataBoard) ReportDigital(int, int) error    { return nil }
-func (mockFirmataBoard) DigitalWrite(int, int) error     { return nil }
-func (mockFirmataBoard) I2cRead(int, int) error          { return nil }
-func (mockFirmataBoard) I2cWrite(int, []byte) error      { return nil }
-func (mockFirmataBoard) I2cConfig(int) error             { return nil }
-func (mockFirmataBoard) ServoConfig(int, int, int) error { return nil }
+func (mockFirmataBoard) AnalogWrite(int, int) error             { return nil }
+func (mockFirmataBoard) SetPinMode(int, int) error              { return nil }
+func (mockFirmataBoard) ReportAnalog(int, int) error            { return nil }
+func (mockFirmataBoard) ReportDigital(int, int) error           { return nil }
+func (mockFirmataBoard) DigitalWrite(int, int) error            { return nil }
+func (mockFirmataBoard) I2cRead(int, int) error                 { return nil }
+func (mockFirmataBoard) I2cWrite(int, []byte) error             { return nil }
+func (mockFirmataBoard) I2cConfig(int) error                    { return nil }
+func (mockFirmataBoard) ServoConfig(int, int, int) error        { return nil }
+func (mockFirmataBoard) OneWireConfig(int, bool) error          { return nil }
+func (mockFirmataBoard) OneWireSearch(int) error                { return nil }
+func (mockFirmataBoard) OneWireWrite(int, []byte, []byte) error { return nil }
+func (mockFirmataBoard) OneWireRead(int, int, int) error        { return nil }
+func (mockFirmataBoard) NeoPixelConfig(int, int) error          { return nil }
+func (mockFirmataBoard) NeoPixelSetPixel(int, uint32) error     { return nil }
+func (mockFirmataBoard) NeoPixelShow() error                    { return nil }
 
 func initTestFirmataAdaptor() *FirmataAdaptor {
 	a := NewFirmataAdaptor("board", "/dev/null")
@@ -181,3 +191,76 @@ func TestServoConfig(t *testing.T) {
 	err = a.ServoConfig("a", 0, 0)
 	gobottest.Assert(t, true, strings.Contains(fmt.Sprintf("%v", err), "invalid syntax"))
 }
+
+func TestFirmataAdaptorOneWireStart(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	gobottest.Assert(t, a.OneWireStart("2"), nil)
+	gobottest.Refute(t, a.OneWireStart("w1_bus_master1"), nil)
+}
+
+func TestFirmataAdaptorOneWireSearch(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	reply := client.OneWireSearchResponse{
+		Pin: 2,
+		Addresses: [][]byte{
+			{0x28, 0xC2, 0x2E, 0x4C, 0x05, 0x00, 0x00, 0x72},
+		},
+	}
+	go func() {
+		<-time.After(10 * time.Millisecond)
+		// the reply to a search on another pin is ignored
+		gobot.Publish(a.board.Event("OneWireSearchReply"), client.OneWireSearchResponse{Pin: 3})
+		<-time.After(10 * time.Millisecond)
+		gobot.Publish(a.board.Event("OneWireSearchReply"), reply)
+	}()
+	ids, err := a.OneWireSearch("2")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, ids, []string{"28-0000054c2ec2"})
+}
+
+func TestFirmataAdaptorOneWireSearchTimeout(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	a.oneWireTimeout = 10 * time.Millisecond
+	_, err := a.OneWireSearch("2")
+	gobottest.Assert(t, err, ErrOneWireTimeout)
+}
+
+func TestFirmataAdaptorOneWireWrite(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	gobottest.Assert(t, a.OneWireWrite("2", "28-0000054c2ec2", []byte{0x44}), nil)
+	gobottest.Assert(t, a.OneWireWrite("2", "invalid", []byte{0x44}), onewire.ErrInvalidROM)
+}
+
+func TestFirmataAdaptorOneWireRead(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	i := []byte{0x91, 0x01}
+	go func() {
+		<-time.After(10 * time.Millisecond)
+		// the reply to another read is ignored
+		gobot.Publish(a.board.Event("OneWireReadReply"), client.OneWireReadResponse{CorrelationID: 7, Data: []byte{0x00}})
+		<-time.After(10 * time.Millisecond)
+		gobot.Publish(a.board.Event("OneWireReadReply"), client.OneWireReadResponse{CorrelationID: 1, Data: i})
+	}()
+	data, err := a.OneWireRead("2", "28-0000054c2ec2", 2)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, data, i)
+	gobottest.Assert(t, a.oneWireCorrelationID, 1)
+}
+
+func TestFirmataAdaptorOneWireReadTimeout(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	a.oneWireTimeout = 10 * time.Millisecond
+	_, err := a.OneWireRead("2", "28-0000054c2ec2", 2)
+	gobottest.Assert(t, err, ErrOneWireTimeout)
+}
+
+func TestFirmataAdaptorNeoPixelStart(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	gobottest.Assert(t, a.NeoPixelStart("6", 8), nil)
+	gobottest.Refute(t, a.NeoPixelStart("D6", 8), nil)
+}
+
+func TestFirmataAdaptorNeoPixelWrite(t *testing.T) {
+	a := initTestFirmataAdaptor()
+	gobottest.Assert(t, a.NeoPixelWrite("6", []uint32{0xFF0000, 0x00FF00}), nil)
+}
@@ -29,8 +29,14 @@ func NewLedDriver(a DigitalWriter, name string, pin string) *LedDriver {
 		Commander:  gobot.NewCommander(),
 	}
 
-	l.AddCommand("Brightness", func(params map[string]interface{}) interface{} {
-		level := byte(params["level"].(float64))
+	l.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Brightness",
+		Description: "Sets the brightness of the led",
+		Params: []gobot.CommandParam{
+			{Name: "level", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 255}},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		level := byte(params["level"].(int))
 		return l.Brightness(level)
 	})
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/gobottest"
 )
 
@@ -45,6 +46,9 @@ func TestLedDriver(t *testing.T) {
 	err = d.Command("Brightness")(map[string]interface{}{"level": 100.0})
 	gobottest.Assert(t, err.(error), errors.New("pwm error"))
 
+	err = d.Command("Brightness")(map[string]interface{}{"level": 300.0})
+	gobottest.Assert(t, err.(*gobot.CommandError).Param, "level")
+
 }
 
 func TestLedDriverStart(t *testing.T) {
@@ -29,17 +29,32 @@ func NewServoDriver(a ServoWriter, name string, pin string) *ServoDriver {
 		CurrentAngle: 0,
 	}
 
-	s.AddCommand("Move", func(params map[string]interface{}) interface{} {
-		angle := byte(params["angle"].(float64))
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Move",
+		Description: "Moves the servo to an angle in degrees",
+		Params: []gobot.CommandParam{
+			{Name: "angle", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 180}},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		angle := byte(params["angle"].(int))
 		return s.Move(angle)
 	})
-	s.AddCommand("Min", func(params map[string]interface{}) interface{} {
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Min",
+		Description: "Moves the servo to 0 degrees",
+	}, func(params map[string]interface{}) interface{} {
 		return s.Min()
 	})
-	s.AddCommand("Center", func(params map[string]interface{}) interface{} {
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Center",
+		Description: "Moves the servo to 90 degrees",
+	}, func(params map[string]interface{}) interface{} {
 		return s.Center()
 	})
-	s.AddCommand("Max", func(params map[string]interface{}) interface{} {
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Max",
+		Description: "Moves the servo to 180 degrees",
+	}, func(params map[string]interface{}) interface{} {
 		return s.Max()
 	})
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/gobottest"
 )
 
@@ -36,6 +37,18 @@ func TestServoDriver(t *testing.T) {
 	err = d.Command("Move")(map[string]interface{}{"angle": 100.0})
 	gobottest.Assert(t, err.(error), errors.New("pwm error"))
 
+	err = d.Command("Move")(map[string]interface{}{"angle": 200.0})
+	gobottest.Assert(t, err.(*gobot.CommandError).Param, "angle")
+
+	err = d.Command("Move")(map[string]interface{}{})
+	gobottest.Assert(t, err.(*gobot.CommandError).Message, "is required")
+
+	for _, name := range []string{"Min", "Center", "Max"} {
+		schema, ok := d.Schema(name)
+		gobottest.Assert(t, ok, true)
+		gobottest.Assert(t, len(schema.Params), 0)
+	}
+
 }
 
 func TestServoDriverStart(t *testing.T) {
@@ -10,6 +10,12 @@ var _ gobot.Driver = (*BlinkMDriver)(nil)
 
 const blinkmAddress = 0x09
 
+var blinkmColorParams = []gobot.CommandParam{
+	{Name: "red", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 255}},
+	{Name: "green", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 255}},
+	{Name: "blue", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 255}},
+}
+
 type BlinkMDriver struct {
 	name       string
 	connection I2c
@@ -30,16 +36,24 @@ func NewBlinkMDriver(a I2c, name string) *BlinkMDriver {
 		Commander:  gobot.NewCommander(),
 	}
 
-	b.AddCommand("Rgb", func(params map[string]interface{}) interface{} {
-		red := byte(params["red"].(float64))
-		green := byte(params["green"].(float64))
-		blue := byte(params["blue"].(float64))
+	b.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Rgb",
+		Description: "Sets the RGB color",
+		Params:      blinkmColorParams,
+	}, func(params map[string]interface{}) interface{} {
+		red := byte(params["red"].(int))
+		green := byte(params["green"].(int))
+		blue := byte(params["blue"].(int))
 		return b.Rgb(red, green, blue)
 	})
-	b.AddCommand("Fade", func(params map[string]interface{}) interface{} {
-		red := byte(params["red"].(float64))
-		green := byte(params["green"].(float64))
-		blue := byte(params["blue"].(float64))
+	b.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Fade",
+		Description: "Fades to the RGB color",
+		Params:      blinkmColorParams,
+	}, func(params map[string]interface{}) interface{} {
+		red := byte(params["red"].(int))
+		green := byte(params["green"].(int))
+		blue := byte(params["blue"].(int))
 		return b.Fade(red, green, blue)
 	})
 	b.AddCommand("FirmwareVersion", func(params map[string]interface{}) interface{} {
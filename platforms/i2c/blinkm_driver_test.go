@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/gobottest"
 )
 
@@ -35,6 +36,9 @@ func TestNewBlinkMDriverCommands_Rgb(t *testing.T) {
 
 	result := blinkM.Command("Rgb")(rgb)
 	gobottest.Assert(t, result, nil)
+
+	result = blinkM.Command("Rgb")(map[string]interface{}{"red": 1.0, "green": 1.0})
+	gobottest.Assert(t, result.(*gobot.CommandError).Param, "blue")
 }
 
 func TestNewBlinkMDriverCommands_Fade(t *testing.T) {
@@ -0,0 +1,125 @@
+package i2c
+
+import (
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var _ gobot.Driver = (*BME280Driver)(nil)
+
+const BME280_REGISTER_CALIBRATION_H1 = 0xA1
+const BME280_REGISTER_CALIBRATION_H2 = 0xE1
+const BME280_REGISTER_CONTROL_HUMIDITY = 0xF2
+const BME280_CHIPID = 0x60
+
+type bme280Humidity struct {
+	osr      BMP280Oversampling
+	h1       uint8
+	h2       int16
+	h3       uint8
+	h4       int16
+	h5       int16
+	h6       int8
+	humidity float32
+}
+
+// BME280Driver is the gobot driver for the Bosch BME280 humidity, barometric
+// pressure and temperature sensor. It extends the BMP280Driver, which the
+// BME280 is register compatible with, with humidity.
+type BME280Driver struct {
+	*BMP280Driver
+}
+
+// NewBME280Driver creates a new driver with specified name, i2c interface and
+// device address, sampling every 100 milliseconds with 1x temperature, 4x
+// pressure and 1x humidity oversampling
+//
+// Optionally accepts:
+// 	time.Duration: Interval at which the sensor is sampled
+//
+// Adds the following API Commands:
+// 	"Temperature" - See BMP280Driver.ReadTemperature
+// 	"Pressure" - See BMP280Driver.ReadPressure
+// 	"Humidity" - See BME280Driver.ReadHumidity
+func NewBME280Driver(a I2c, name string, deviceAddress int, v ...time.Duration) *BME280Driver {
+	d := &BME280Driver{
+		BMP280Driver: NewBMP280Driver(a, name, deviceAddress, v...),
+	}
+	d.chipID = BME280_CHIPID
+	d.humidity = &bme280Humidity{osr: BMP280Oversampling1}
+
+	d.AddCommand("Humidity", func(params map[string]interface{}) interface{} {
+		val, err := d.ReadHumidity()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+
+	return d
+}
+
+// Start verifies the chip id, reads the calibration coefficients, puts the
+// sensor in normal mode and samples it at the given interval.
+// Emits the Events:
+//	Data map[string]float32 - "temperature" in celsius, "pressure" in pascals and "humidity" in %RH, emitted every sample.
+//	Error error - Event is emitted on error reading from the sensor.
+func (d *BME280Driver) Start() (errs []error) {
+	return d.BMP280Driver.Start()
+}
+
+// SetHumidityOversampling sets the humidity oversampling, it takes effect
+// when the driver is started
+func (d *BME280Driver) SetHumidityOversampling(humidity BMP280Oversampling) {
+	d.humidity.osr = humidity
+}
+
+// Humidity returns the last sampled relative humidity in %RH
+func (d *BME280Driver) Humidity() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.humidity.humidity
+}
+
+// ReadHumidity reads the latest measurement of the sensor and returns the
+// relative humidity in %RH
+func (d *BME280Driver) ReadHumidity() (humidity float32, err error) {
+	data, err := d.read()
+	return data["humidity"], err
+}
+
+// initialization reads the humidity calibration coefficients, which are
+// packed across two register blocks, and configures the humidity
+// oversampling. Changes to the humidity control only take effect after the
+// following write to the control register.
+func (h *bme280Humidity) initialization(d *BMP280Driver) (err error) {
+	ret, err := d.readRegister(BME280_REGISTER_CALIBRATION_H1, 1)
+	if err != nil {
+		return
+	}
+	h.h1 = ret[0]
+
+	if ret, err = d.readRegister(BME280_REGISTER_CALIBRATION_H2, 7); err != nil {
+		return
+	}
+	h.h2 = int16(ret[1])<<8 | int16(ret[0])
+	h.h3 = ret[2]
+	h.h4 = int16(int8(ret[3]))<<4 | int16(ret[4]&0x0F)
+	h.h5 = int16(int8(ret[5]))<<4 | int16(ret[4]>>4)
+	h.h6 = int8(ret[6])
+
+	return d.connection.I2cWrite(d.address, []byte{BME280_REGISTER_CONTROL_HUMIDITY, byte(h.osr)})
+}
+
+// compensate returns the relative humidity in %RH using the floating point
+// algorithm of the datasheet
+func (h *bme280Humidity) compensate(adcH int32, tFine float64) float64 {
+	varH := tFine - 76800.0
+	varH = (float64(adcH) - (float64(h.h4)*64.0 + float64(h.h5)/16384.0*varH)) *
+		(float64(h.h2) / 65536.0 * (1.0 + float64(h.h6)/67108864.0*varH*(1.0+float64(h.h3)/67108864.0*varH)))
+	varH = varH * (1.0 - float64(h.h1)*varH/524288.0)
+	if varH > 100.0 {
+		return 100.0
+	} else if varH < 0.0 {
+		return 0.0
+	}
+	return varH
+}
@@ -0,0 +1,98 @@
+package i2c
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// H1 = 75, H2 = 362, H3 = 0, H4 = 313, H5 = 50, H6 = 30
+var bme280CalibrationH2 = []byte{0x6A, 0x01, 0x00, 0x13, 0x29, 0x03, 0x1E}
+
+// --------- HELPERS
+func initTestBME280DriverWithStubbedAdaptor() (*BME280Driver, *i2cRegisterTestAdaptor) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	adaptor.setRegisters(BMP280_REGISTER_CHIPID, []byte{BME280_CHIPID})
+	adaptor.setRegisters(BMP280_REGISTER_CALIBRATION, bmp280Calibration)
+	adaptor.setRegisters(BME280_REGISTER_CALIBRATION_H1, []byte{0x4B})
+	adaptor.setRegisters(BME280_REGISTER_CALIBRATION_H2, bme280CalibrationH2)
+	adaptor.setRegisters(BMP280_REGISTER_PRESSURE_MSB, append(bmp280Data, 0x6A, 0x00))
+	return NewBME280Driver(adaptor, "bot", BMP280Address), adaptor
+}
+
+// --------- TESTS
+
+func TestBME280Driver(t *testing.T) {
+	d, _ := initTestBME280DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Refute(t, d.Command("Temperature"), nil)
+	gobottest.Refute(t, d.Command("Pressure"), nil)
+	gobottest.Refute(t, d.Command("Humidity"), nil)
+}
+
+func TestBME280DriverInitialization(t *testing.T) {
+	d, adaptor := initTestBME280DriverWithStubbedAdaptor()
+	d.SetHumidityOversampling(BMP280Oversampling8)
+
+	gobottest.Assert(t, d.initialization(), nil)
+	gobottest.Assert(t, d.humidity.h1, uint8(75))
+	gobottest.Assert(t, d.humidity.h2, int16(362))
+	gobottest.Assert(t, d.humidity.h4, int16(313))
+	gobottest.Assert(t, d.humidity.h5, int16(50))
+	gobottest.Assert(t, d.humidity.h6, int8(30))
+	gobottest.Assert(t, adaptor.registers[BME280_REGISTER_CONTROL_HUMIDITY], byte(0x04))
+
+	// the humidity control only takes effect after the control register is written
+	n := len(adaptor.written)
+	gobottest.Assert(t, adaptor.written[n-1][0], byte(BMP280_REGISTER_CONTROL))
+
+	adaptor.setRegisters(BMP280_REGISTER_CHIPID, []byte{BMP280_CHIPID})
+	gobottest.Assert(t, d.initialization(), ErrInvalidChipID)
+}
+
+func TestBME280DriverRead(t *testing.T) {
+	d, adaptor := initTestBME280DriverWithStubbedAdaptor()
+	d.initialization()
+
+	humidity, err := d.ReadHumidity()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, humidity, float32(39.035065))
+	gobottest.Assert(t, d.Humidity(), float32(39.035065))
+	gobottest.Assert(t, d.Temperature(), float32(25.082478))
+
+	// humidity is clamped to 0-100 %RH
+	adaptor.setRegisters(BMP280_REGISTER_PRESSURE_MSB+6, []byte{0xFF, 0xFF})
+	humidity, _ = d.ReadHumidity()
+	gobottest.Assert(t, humidity, float32(100))
+	adaptor.setRegisters(BMP280_REGISTER_PRESSURE_MSB+6, []byte{0x00, 0x00})
+	humidity, _ = d.ReadHumidity()
+	gobottest.Assert(t, humidity, float32(0))
+}
+
+func TestBME280DriverStart(t *testing.T) {
+	sem := make(chan bool)
+	d, _ := initTestBME280DriverWithStubbedAdaptor()
+
+	gobot.Once(d.Event(Data), func(data interface{}) {
+		gobottest.Assert(t, data, map[string]float32{
+			"temperature": 25.082478,
+			"pressure":    100653.266,
+			"humidity":    39.035065,
+		})
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("BME280 Event \"Data\" was not published")
+	}
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+}
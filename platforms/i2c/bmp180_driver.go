@@ -0,0 +1,313 @@
+package i2c
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var _ gobot.Driver = (*BMP180Driver)(nil)
+
+const bmp180Address = 0x77
+
+const BMP180_REGISTER_CALIBRATION = 0xAA
+const BMP180_REGISTER_CONTROL = 0xF4
+const BMP180_REGISTER_DATA = 0xF6
+const BMP180_CMD_TEMPERATURE = 0x2E
+const BMP180_CMD_PRESSURE = 0x34
+
+// BMP180OversamplingMode is the number of pressure samples averaged by the
+// BMP180, trading conversion time and power for lower noise
+type BMP180OversamplingMode uint
+
+const (
+	// BMP180UltraLowPower takes 1 sample in 4.5ms
+	BMP180UltraLowPower BMP180OversamplingMode = iota
+	// BMP180Standard takes 2 samples in 7.5ms
+	BMP180Standard
+	// BMP180HighResolution takes 4 samples in 13.5ms
+	BMP180HighResolution
+	// BMP180UltraHighResolution takes 8 samples in 25.5ms
+	BMP180UltraHighResolution
+)
+
+type bmp180CalibrationCoefficients struct {
+	ac1 int16
+	ac2 int16
+	ac3 int16
+	ac4 uint16
+	ac5 uint16
+	ac6 uint16
+	b1  int16
+	b2  int16
+	mb  int16
+	mc  int16
+	md  int16
+}
+
+// BMP180Driver is the gobot driver for the Bosch BMP180 barometric pressure
+// and temperature sensor
+type BMP180Driver struct {
+	name        string
+	connection  I2c
+	interval    time.Duration
+	mode        BMP180OversamplingMode
+	calibration bmp180CalibrationCoefficients
+	temperature float32
+	pressure    float32
+	halt        chan bool
+	mutex       sync.Mutex
+	gobot.Eventer
+	gobot.Commander
+}
+
+// NewBMP180Driver creates a new driver with specified name and i2c interface,
+// sampling in BMP180Standard mode every 100 milliseconds
+//
+// Optionally accepts:
+// 	time.Duration: Interval at which the sensor is sampled
+//
+// Adds the following API Commands:
+// 	"Temperature" - See BMP180Driver.ReadTemperature
+// 	"Pressure" - See BMP180Driver.ReadPressure
+func NewBMP180Driver(a I2c, name string, v ...time.Duration) *BMP180Driver {
+	d := &BMP180Driver{
+		name:       name,
+		connection: a,
+		interval:   100 * time.Millisecond,
+		mode:       BMP180Standard,
+		halt:       make(chan bool),
+		Eventer:    gobot.NewEventer(),
+		Commander:  gobot.NewCommander(),
+	}
+
+	if len(v) > 0 {
+		d.interval = v[0]
+	}
+
+	d.AddEvent(Data)
+	d.AddEvent(Error)
+
+	d.AddCommand("Temperature", func(params map[string]interface{}) interface{} {
+		val, err := d.ReadTemperature()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+	d.AddCommand("Pressure", func(params map[string]interface{}) interface{} {
+		val, err := d.ReadPressure()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+
+	return d
+}
+
+func (d *BMP180Driver) Name() string                 { return d.name }
+func (d *BMP180Driver) Connection() gobot.Connection { return d.connection.(gobot.Connection) }
+
+// Start reads the calibration coefficients of the sensor and samples it at
+// the given interval.
+// Emits the Events:
+//	Data map[string]float32 - "temperature" in celsius and "pressure" in pascals, emitted every sample.
+//	Error error - Event is emitted on error reading from the sensor.
+func (d *BMP180Driver) Start() (errs []error) {
+	if err := d.connection.I2cStart(bmp180Address); err != nil {
+		return []error{err}
+	}
+	if err := d.initialization(); err != nil {
+		return []error{err}
+	}
+
+	go func() {
+		for {
+			temperature, pressure, err := d.read()
+			if err != nil {
+				gobot.Publish(d.Event(Error), err)
+			} else {
+				gobot.Publish(d.Event(Data), map[string]float32{
+					"temperature": temperature,
+					"pressure":    pressure,
+				})
+			}
+			select {
+			case <-time.After(d.interval):
+			case <-d.halt:
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops sampling the sensor
+func (d *BMP180Driver) Halt() (errs []error) {
+	d.halt <- true
+	return
+}
+
+// Mode returns the pressure oversampling mode
+func (d *BMP180Driver) Mode() BMP180OversamplingMode { return d.mode }
+
+// SetMode sets the pressure oversampling mode
+func (d *BMP180Driver) SetMode(mode BMP180OversamplingMode) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.mode = mode
+}
+
+// Temperature returns the last sampled temperature in celsius
+func (d *BMP180Driver) Temperature() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.temperature
+}
+
+// Pressure returns the last sampled pressure in pascals
+func (d *BMP180Driver) Pressure() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.pressure
+}
+
+// ReadTemperature samples the sensor and returns the temperature in celsius
+func (d *BMP180Driver) ReadTemperature() (temperature float32, err error) {
+	temperature, _, err = d.read()
+	return
+}
+
+// ReadPressure samples the sensor and returns the pressure in pascals
+func (d *BMP180Driver) ReadPressure() (pressure float32, err error) {
+	_, pressure, err = d.read()
+	return
+}
+
+func (d *BMP180Driver) initialization() (err error) {
+	ret, err := d.readRegister(BMP180_REGISTER_CALIBRATION, 22)
+	if err != nil {
+		return
+	}
+	buf := bytes.NewBuffer(ret)
+	c := &d.calibration
+	binary.Read(buf, binary.BigEndian, &c.ac1)
+	binary.Read(buf, binary.BigEndian, &c.ac2)
+	binary.Read(buf, binary.BigEndian, &c.ac3)
+	binary.Read(buf, binary.BigEndian, &c.ac4)
+	binary.Read(buf, binary.BigEndian, &c.ac5)
+	binary.Read(buf, binary.BigEndian, &c.ac6)
+	binary.Read(buf, binary.BigEndian, &c.b1)
+	binary.Read(buf, binary.BigEndian, &c.b2)
+	binary.Read(buf, binary.BigEndian, &c.mb)
+	binary.Read(buf, binary.BigEndian, &c.mc)
+	binary.Read(buf, binary.BigEndian, &c.md)
+	return
+}
+
+// read converts and compensates a temperature and pressure sample
+func (d *BMP180Driver) read() (temperature float32, pressure float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	ut, err := d.rawTemperature()
+	if err != nil {
+		return
+	}
+	up, err := d.rawPressure()
+	if err != nil {
+		return
+	}
+
+	b5 := d.calculateB5(ut)
+	d.temperature = float32((b5+8)>>4) / 10.0
+	d.pressure = float32(d.calculatePressure(up, b5))
+	return d.temperature, d.pressure, nil
+}
+
+func (d *BMP180Driver) rawTemperature() (ut int32, err error) {
+	if err = d.connection.I2cWrite(bmp180Address, []byte{BMP180_REGISTER_CONTROL, BMP180_CMD_TEMPERATURE}); err != nil {
+		return
+	}
+	<-time.After(5 * time.Millisecond)
+	ret, err := d.readRegister(BMP180_REGISTER_DATA, 2)
+	if err != nil {
+		return
+	}
+	return int32(ret[0])<<8 | int32(ret[1]), nil
+}
+
+func (d *BMP180Driver) rawPressure() (up int32, err error) {
+	if err = d.connection.I2cWrite(bmp180Address, []byte{BMP180_REGISTER_CONTROL, BMP180_CMD_PRESSURE + byte(d.mode<<6)}); err != nil {
+		return
+	}
+	<-time.After(d.pressureConversionTime())
+	ret, err := d.readRegister(BMP180_REGISTER_DATA, 3)
+	if err != nil {
+		return
+	}
+	up = (int32(ret[0])<<16 | int32(ret[1])<<8 | int32(ret[2])) >> (8 - d.mode)
+	return
+}
+
+func (d *BMP180Driver) pressureConversionTime() time.Duration {
+	switch d.mode {
+	case BMP180UltraLowPower:
+		return 5 * time.Millisecond
+	case BMP180Standard:
+		return 8 * time.Millisecond
+	case BMP180HighResolution:
+		return 14 * time.Millisecond
+	default:
+		return 26 * time.Millisecond
+	}
+}
+
+func (d *BMP180Driver) readRegister(register byte, n int) (ret []byte, err error) {
+	if err = d.connection.I2cWrite(bmp180Address, []byte{register}); err != nil {
+		return
+	}
+	if ret, err = d.connection.I2cRead(bmp180Address, n); err != nil {
+		return
+	}
+	if len(ret) < n {
+		return nil, ErrNotEnoughBytes
+	}
+	return
+}
+
+// calculateB5 returns the B5 term of the compensation algorithm in the
+// datasheet, which is shared by the temperature and pressure
+func (d *BMP180Driver) calculateB5(ut int32) int32 {
+	c := d.calibration
+	x1 := (ut - int32(c.ac6)) * int32(c.ac5) >> 15
+	x2 := int32(c.mc) << 11 / (x1 + int32(c.md))
+	return x1 + x2
+}
+
+// calculatePressure returns the compensated pressure in pascals using the
+// integer algorithm of the datasheet
+func (d *BMP180Driver) calculatePressure(up int32, b5 int32) int32 {
+	c := d.calibration
+	oss := uint(d.mode)
+
+	b6 := b5 - 4000
+	x1 := (int32(c.b2) * (b6 * b6 >> 12)) >> 11
+	x2 := int32(c.ac2) * b6 >> 11
+	x3 := x1 + x2
+	b3 := (((int32(c.ac1)*4 + x3) << oss) + 2) >> 2
+	x1 = int32(c.ac3) * b6 >> 13
+	x2 = (int32(c.b1) * (b6 * b6 >> 12)) >> 16
+	x3 = ((x1 + x2) + 2) >> 2
+	b4 := uint32(c.ac4) * uint32(x3+32768) >> 15
+	b7 := uint32(up-b3) * (50000 >> oss)
+
+	var p int32
+	if b7 < 0x80000000 {
+		p = int32((b7 * 2) / b4)
+	} else {
+		p = int32((b7 / b4) * 2)
+	}
+	x1 = (p >> 8) * (p >> 8)
+	x1 = (x1 * 3038) >> 16
+	x2 = (-7357 * p) >> 16
+	return p + (x1+x2+3791)>>4
+}
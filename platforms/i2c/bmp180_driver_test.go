@@ -0,0 +1,128 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// calibration coefficients and samples of the datasheet example
+var bmp180Calibration = []byte{
+	0x01, 0x98, 0xFF, 0xB8, 0xC7, 0xD1, 0x7F, 0xE5, 0x7F, 0xF5, 0x5A, 0x71,
+	0x18, 0x2E, 0x00, 0x04, 0x80, 0x00, 0xDD, 0xF9, 0x0B, 0x34,
+}
+
+// --------- HELPERS
+func initTestBMP180Driver() (driver *BMP180Driver) {
+	driver, _ = initTestBMP180DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestBMP180DriverWithStubbedAdaptor() (*BMP180Driver, *i2cRegisterTestAdaptor) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	adaptor.setRegisters(BMP180_REGISTER_CALIBRATION, bmp180Calibration)
+	adaptor.writeHook = func(buf []byte) {
+		if len(buf) != 2 || buf[0] != BMP180_REGISTER_CONTROL {
+			return
+		}
+		if buf[1] == BMP180_CMD_TEMPERATURE {
+			// UT = 27898
+			adaptor.setRegisters(BMP180_REGISTER_DATA, []byte{0x6C, 0xFA})
+		} else {
+			// UP = 23843 shifted for the oversampling
+			oss := uint(buf[1]-BMP180_CMD_PRESSURE) >> 6
+			up := uint32(23843) << (8 - oss)
+			adaptor.setRegisters(BMP180_REGISTER_DATA, []byte{byte(up >> 16), byte(up >> 8), byte(up)})
+		}
+	}
+	return NewBMP180Driver(adaptor, "bot"), adaptor
+}
+
+// --------- TESTS
+
+func TestBMP180Driver(t *testing.T) {
+	d := initTestBMP180Driver()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.interval, 100*time.Millisecond)
+	gobottest.Assert(t, d.Mode(), BMP180Standard)
+	gobottest.Refute(t, d.Command("Temperature"), nil)
+	gobottest.Refute(t, d.Command("Pressure"), nil)
+
+	d = NewBMP180Driver(newI2cTestAdaptor("adaptor"), "bot", 10*time.Millisecond)
+	gobottest.Assert(t, d.interval, 10*time.Millisecond)
+}
+
+func TestBMP180DriverRead(t *testing.T) {
+	d, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	gobottest.Assert(t, d.initialization(), nil)
+	gobottest.Assert(t, d.calibration.ac1, int16(408))
+	gobottest.Assert(t, d.calibration.ac4, uint16(32741))
+	gobottest.Assert(t, d.calibration.mc, int16(-8711))
+
+	d.SetMode(BMP180UltraLowPower)
+	temperature, err := d.ReadTemperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temperature, float32(15.0))
+	pressure, err := d.ReadPressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(69964))
+	gobottest.Assert(t, d.Temperature(), float32(15.0))
+	gobottest.Assert(t, d.Pressure(), float32(69964))
+	gobottest.Assert(t, adaptor.written[len(adaptor.written)-2], []byte{BMP180_REGISTER_CONTROL, 0x34})
+
+	d.SetMode(BMP180UltraHighResolution)
+	d.ReadPressure()
+	gobottest.Assert(t, adaptor.written[len(adaptor.written)-2], []byte{BMP180_REGISTER_CONTROL, 0xF4})
+
+	ret := d.Command("Temperature")(map[string]interface{}{})
+	gobottest.Assert(t, ret.(map[string]interface{})["val"], float32(15.0))
+
+	adaptor.i2cReadImpl = func() ([]byte, error) {
+		return nil, errors.New("read error")
+	}
+	_, err = d.ReadPressure()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP180DriverStart(t *testing.T) {
+	sem := make(chan bool)
+	d := initTestBMP180Driver()
+	d.SetMode(BMP180UltraLowPower)
+
+	gobot.Once(d.Event(Data), func(data interface{}) {
+		gobottest.Assert(t, data, map[string]float32{
+			"temperature": 15.0,
+			"pressure":    69964,
+		})
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("BMP180 Event \"Data\" was not published")
+	}
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+}
+
+func TestBMP180DriverStartError(t *testing.T) {
+	d, adaptor := initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("start error"))
+
+	d, adaptor = initTestBMP180DriverWithStubbedAdaptor()
+	adaptor.i2cReadImpl = func() ([]byte, error) {
+		return nil, errors.New("read error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("read error"))
+}
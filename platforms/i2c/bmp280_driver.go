@@ -0,0 +1,317 @@
+package i2c
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var _ gobot.Driver = (*BMP280Driver)(nil)
+
+// BMP280Address is the i2c address of a BMP280 or BME280 with SDO pulled high,
+// the address is 0x76 with SDO pulled low
+const BMP280Address = 0x77
+
+const BMP280_REGISTER_CALIBRATION = 0x88
+const BMP280_REGISTER_CHIPID = 0xD0
+const BMP280_REGISTER_CONTROL = 0xF4
+const BMP280_REGISTER_CONFIG = 0xF5
+const BMP280_REGISTER_PRESSURE_MSB = 0xF7
+const BMP280_CHIPID = 0x58
+const BMP280_MODE_NORMAL = 0x03
+
+// BMP280Oversampling is the number of samples averaged by the BMP280 and
+// BME280 for each measurement
+type BMP280Oversampling byte
+
+const (
+	// BMP280Skipped disables the measurement
+	BMP280Skipped BMP280Oversampling = iota
+	BMP280Oversampling1
+	BMP280Oversampling2
+	BMP280Oversampling4
+	BMP280Oversampling8
+	BMP280Oversampling16
+)
+
+// ErrInvalidChipID is the error resulting when the chip id read from a sensor
+// does not match the driver
+var ErrInvalidChipID = errors.New("Invalid chip id")
+
+type bmp280CalibrationCoefficients struct {
+	t1 uint16
+	t2 int16
+	t3 int16
+	p1 uint16
+	p2 int16
+	p3 int16
+	p4 int16
+	p5 int16
+	p6 int16
+	p7 int16
+	p8 int16
+	p9 int16
+}
+
+// BMP280Driver is the gobot driver for the Bosch BMP280 barometric pressure
+// and temperature sensor
+type BMP280Driver struct {
+	name           string
+	connection     I2c
+	address        int
+	interval       time.Duration
+	chipID         byte
+	temperatureOSR BMP280Oversampling
+	pressureOSR    BMP280Oversampling
+	calibration    bmp280CalibrationCoefficients
+	temperature    float32
+	pressure       float32
+	halt           chan bool
+	mutex          sync.Mutex
+	gobot.Eventer
+	gobot.Commander
+
+	// humidity extends the driver for the BME280
+	humidity *bme280Humidity
+}
+
+// NewBMP280Driver creates a new driver with specified name, i2c interface and
+// device address, sampling every 100 milliseconds with 1x temperature and 4x
+// pressure oversampling
+//
+// Optionally accepts:
+// 	time.Duration: Interval at which the sensor is sampled
+//
+// Adds the following API Commands:
+// 	"Temperature" - See BMP280Driver.ReadTemperature
+// 	"Pressure" - See BMP280Driver.ReadPressure
+func NewBMP280Driver(a I2c, name string, deviceAddress int, v ...time.Duration) *BMP280Driver {
+	d := &BMP280Driver{
+		name:           name,
+		connection:     a,
+		address:        deviceAddress,
+		interval:       100 * time.Millisecond,
+		chipID:         BMP280_CHIPID,
+		temperatureOSR: BMP280Oversampling1,
+		pressureOSR:    BMP280Oversampling4,
+		halt:           make(chan bool),
+		Eventer:        gobot.NewEventer(),
+		Commander:      gobot.NewCommander(),
+	}
+
+	if len(v) > 0 {
+		d.interval = v[0]
+	}
+
+	d.AddEvent(Data)
+	d.AddEvent(Error)
+
+	d.AddCommand("Temperature", func(params map[string]interface{}) interface{} {
+		val, err := d.ReadTemperature()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+	d.AddCommand("Pressure", func(params map[string]interface{}) interface{} {
+		val, err := d.ReadPressure()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+
+	return d
+}
+
+func (d *BMP280Driver) Name() string                 { return d.name }
+func (d *BMP280Driver) Connection() gobot.Connection { return d.connection.(gobot.Connection) }
+
+// Start verifies the chip id, reads the calibration coefficients, puts the
+// sensor in normal mode and samples it at the given interval.
+// Emits the Events:
+//	Data map[string]float32 - "temperature" in celsius and "pressure" in pascals, emitted every sample.
+//	Error error - Event is emitted on error reading from the sensor.
+func (d *BMP280Driver) Start() (errs []error) {
+	if err := d.connection.I2cStart(d.address); err != nil {
+		return []error{err}
+	}
+	if err := d.initialization(); err != nil {
+		return []error{err}
+	}
+
+	go func() {
+		for {
+			data, err := d.read()
+			if err != nil {
+				gobot.Publish(d.Event(Error), err)
+			} else {
+				gobot.Publish(d.Event(Data), data)
+			}
+			select {
+			case <-time.After(d.interval):
+			case <-d.halt:
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops sampling the sensor
+func (d *BMP280Driver) Halt() (errs []error) {
+	d.halt <- true
+	return
+}
+
+// SetOversampling sets the temperature and pressure oversampling, it takes
+// effect when the driver is started
+func (d *BMP280Driver) SetOversampling(temperature BMP280Oversampling, pressure BMP280Oversampling) {
+	d.temperatureOSR = temperature
+	d.pressureOSR = pressure
+}
+
+// Temperature returns the last sampled temperature in celsius
+func (d *BMP280Driver) Temperature() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.temperature
+}
+
+// Pressure returns the last sampled pressure in pascals
+func (d *BMP280Driver) Pressure() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.pressure
+}
+
+// ReadTemperature reads the latest measurement of the sensor and returns the
+// temperature in celsius
+func (d *BMP280Driver) ReadTemperature() (temperature float32, err error) {
+	data, err := d.read()
+	return data["temperature"], err
+}
+
+// ReadPressure reads the latest measurement of the sensor and returns the
+// pressure in pascals
+func (d *BMP280Driver) ReadPressure() (pressure float32, err error) {
+	data, err := d.read()
+	return data["pressure"], err
+}
+
+func (d *BMP280Driver) initialization() (err error) {
+	ret, err := d.readRegister(BMP280_REGISTER_CHIPID, 1)
+	if err != nil {
+		return
+	}
+	if ret[0] != d.chipID {
+		return ErrInvalidChipID
+	}
+
+	if ret, err = d.readRegister(BMP280_REGISTER_CALIBRATION, 24); err != nil {
+		return
+	}
+	buf := bytes.NewBuffer(ret)
+	c := &d.calibration
+	binary.Read(buf, binary.LittleEndian, &c.t1)
+	binary.Read(buf, binary.LittleEndian, &c.t2)
+	binary.Read(buf, binary.LittleEndian, &c.t3)
+	binary.Read(buf, binary.LittleEndian, &c.p1)
+	binary.Read(buf, binary.LittleEndian, &c.p2)
+	binary.Read(buf, binary.LittleEndian, &c.p3)
+	binary.Read(buf, binary.LittleEndian, &c.p4)
+	binary.Read(buf, binary.LittleEndian, &c.p5)
+	binary.Read(buf, binary.LittleEndian, &c.p6)
+	binary.Read(buf, binary.LittleEndian, &c.p7)
+	binary.Read(buf, binary.LittleEndian, &c.p8)
+	binary.Read(buf, binary.LittleEndian, &c.p9)
+
+	if d.humidity != nil {
+		if err = d.humidity.initialization(d); err != nil {
+			return
+		}
+	}
+
+	// 0.5ms standby between measurements, filter off
+	if err = d.connection.I2cWrite(d.address, []byte{BMP280_REGISTER_CONFIG, 0x00}); err != nil {
+		return
+	}
+	control := byte(d.temperatureOSR)<<5 | byte(d.pressureOSR)<<2 | BMP280_MODE_NORMAL
+	return d.connection.I2cWrite(d.address, []byte{BMP280_REGISTER_CONTROL, control})
+}
+
+// read reads and compensates the latest measurement
+func (d *BMP280Driver) read() (data map[string]float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	n := 6
+	if d.humidity != nil {
+		n = 8
+	}
+	ret, err := d.readRegister(BMP280_REGISTER_PRESSURE_MSB, n)
+	if err != nil {
+		return
+	}
+	adcP := int32(ret[0])<<12 | int32(ret[1])<<4 | int32(ret[2])>>4
+	adcT := int32(ret[3])<<12 | int32(ret[4])<<4 | int32(ret[5])>>4
+
+	temperature, tFine := d.compensateTemperature(adcT)
+	d.temperature = float32(temperature)
+	d.pressure = float32(d.compensatePressure(adcP, tFine))
+	data = map[string]float32{
+		"temperature": d.temperature,
+		"pressure":    d.pressure,
+	}
+
+	if d.humidity != nil {
+		adcH := int32(ret[6])<<8 | int32(ret[7])
+		d.humidity.humidity = float32(d.humidity.compensate(adcH, tFine))
+		data["humidity"] = d.humidity.humidity
+	}
+	return
+}
+
+func (d *BMP280Driver) readRegister(register byte, n int) (ret []byte, err error) {
+	if err = d.connection.I2cWrite(d.address, []byte{register}); err != nil {
+		return
+	}
+	if ret, err = d.connection.I2cRead(d.address, n); err != nil {
+		return
+	}
+	if len(ret) < n {
+		return nil, ErrNotEnoughBytes
+	}
+	return
+}
+
+// compensateTemperature returns the temperature in celsius and the fine
+// resolution temperature used to compensate the pressure and humidity, using
+// the floating point algorithm of the datasheet
+func (d *BMP280Driver) compensateTemperature(adcT int32) (temperature float64, tFine float64) {
+	c := d.calibration
+	var1 := (float64(adcT)/16384.0 - float64(c.t1)/1024.0) * float64(c.t2)
+	var2 := float64(adcT)/131072.0 - float64(c.t1)/8192.0
+	var2 = var2 * var2 * float64(c.t3)
+	tFine = var1 + var2
+	return tFine / 5120.0, tFine
+}
+
+// compensatePressure returns the pressure in pascals using the floating point
+// algorithm of the datasheet
+func (d *BMP280Driver) compensatePressure(adcP int32, tFine float64) float64 {
+	c := d.calibration
+	var1 := tFine/2.0 - 64000.0
+	var2 := var1 * var1 * float64(c.p6) / 32768.0
+	var2 = var2 + var1*float64(c.p5)*2.0
+	var2 = var2/4.0 + float64(c.p4)*65536.0
+	var1 = (float64(c.p3)*var1*var1/524288.0 + float64(c.p2)*var1) / 524288.0
+	var1 = (1.0 + var1/32768.0) * float64(c.p1)
+	if var1 == 0 {
+		// avoid a division by zero
+		return 0
+	}
+	p := 1048576.0 - float64(adcP)
+	p = (p - var2/4096.0) * 6250.0 / var1
+	var1 = float64(c.p9) * p * p / 2147483648.0
+	var2 = p * float64(c.p8) / 32768.0
+	return p + (var1+var2+float64(c.p7))/16.0
+}
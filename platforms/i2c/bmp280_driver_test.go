@@ -0,0 +1,124 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// calibration coefficients and samples of the datasheet example
+var bmp280Calibration = []byte{
+	0x70, 0x6B, 0x43, 0x67, 0x18, 0xFC, 0x7D, 0x8E, 0x43, 0xD6, 0xD0, 0x0B,
+	0x27, 0x0B, 0x8C, 0x00, 0xF9, 0xFF, 0x8C, 0x3C, 0xF8, 0xC6, 0x70, 0x17,
+}
+
+var bmp280Data = []byte{0x65, 0x5A, 0xC0, 0x7E, 0xED, 0x00}
+
+// --------- HELPERS
+func initTestBMP280Driver() (driver *BMP280Driver) {
+	driver, _ = initTestBMP280DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestBMP280DriverWithStubbedAdaptor() (*BMP280Driver, *i2cRegisterTestAdaptor) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	adaptor.setRegisters(BMP280_REGISTER_CHIPID, []byte{BMP280_CHIPID})
+	adaptor.setRegisters(BMP280_REGISTER_CALIBRATION, bmp280Calibration)
+	adaptor.setRegisters(BMP280_REGISTER_PRESSURE_MSB, bmp280Data)
+	return NewBMP280Driver(adaptor, "bot", BMP280Address), adaptor
+}
+
+// --------- TESTS
+
+func TestBMP280Driver(t *testing.T) {
+	d := initTestBMP280Driver()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.address, 0x77)
+	gobottest.Assert(t, d.interval, 100*time.Millisecond)
+	gobottest.Refute(t, d.Command("Temperature"), nil)
+	gobottest.Refute(t, d.Command("Pressure"), nil)
+
+	d = NewBMP280Driver(newI2cTestAdaptor("adaptor"), "bot", 0x76, 10*time.Millisecond)
+	gobottest.Assert(t, d.address, 0x76)
+	gobottest.Assert(t, d.interval, 10*time.Millisecond)
+}
+
+func TestBMP280DriverInitialization(t *testing.T) {
+	d, adaptor := initTestBMP280DriverWithStubbedAdaptor()
+	d.SetOversampling(BMP280Oversampling2, BMP280Oversampling16)
+
+	gobottest.Assert(t, d.initialization(), nil)
+	gobottest.Assert(t, d.calibration.t1, uint16(27504))
+	gobottest.Assert(t, d.calibration.t3, int16(-1000))
+	gobottest.Assert(t, d.calibration.p9, int16(6000))
+	gobottest.Assert(t, adaptor.registers[BMP280_REGISTER_CONFIG], byte(0x00))
+	gobottest.Assert(t, adaptor.registers[BMP280_REGISTER_CONTROL], byte(0x57))
+
+	adaptor.setRegisters(BMP280_REGISTER_CHIPID, []byte{BME280_CHIPID})
+	gobottest.Assert(t, d.initialization(), ErrInvalidChipID)
+}
+
+func TestBMP280DriverRead(t *testing.T) {
+	d, adaptor := initTestBMP280DriverWithStubbedAdaptor()
+	d.initialization()
+
+	temperature, err := d.ReadTemperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temperature, float32(25.082478))
+	pressure, err := d.ReadPressure()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, pressure, float32(100653.266))
+	gobottest.Assert(t, d.Temperature(), float32(25.082478))
+	gobottest.Assert(t, d.Pressure(), float32(100653.266))
+
+	ret := d.Command("Pressure")(map[string]interface{}{})
+	gobottest.Assert(t, ret.(map[string]interface{})["val"], float32(100653.266))
+
+	adaptor.i2cReadImpl = func() ([]byte, error) {
+		return nil, errors.New("read error")
+	}
+	_, err = d.ReadTemperature()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestBMP280DriverStart(t *testing.T) {
+	sem := make(chan bool)
+	d := initTestBMP280Driver()
+
+	gobot.Once(d.Event(Data), func(data interface{}) {
+		gobottest.Assert(t, data, map[string]float32{
+			"temperature": 25.082478,
+			"pressure":    100653.266,
+		})
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("BMP280 Event \"Data\" was not published")
+	}
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+}
+
+func TestBMP280DriverStartError(t *testing.T) {
+	d, adaptor := initTestBMP280DriverWithStubbedAdaptor()
+	adaptor.i2cStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("start error"))
+
+	d, adaptor = initTestBMP280DriverWithStubbedAdaptor()
+	adaptor.i2cWriteImpl = func() error {
+		return errors.New("write error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("write error"))
+}
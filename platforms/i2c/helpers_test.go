@@ -1,5 +1,7 @@
 package i2c
 
+import "sync"
+
 var rgb = map[string]interface{}{
 	"red":   1.0,
 	"green": 1.0,
@@ -48,3 +50,56 @@ func newI2cTestAdaptor(name string) *i2cTestAdaptor {
 		},
 	}
 }
+
+// i2cRegisterTestAdaptor is a test adaptor backed by a register map fixture.
+// A write sets the register pointer to its first byte and stores the remaining
+// bytes from there, a read returns the registers from the pointer onwards.
+type i2cRegisterTestAdaptor struct {
+	*i2cTestAdaptor
+	mutex     sync.Mutex
+	registers map[byte]byte
+	pointer   byte
+	written   [][]byte
+	writeHook func(buf []byte)
+}
+
+func (t *i2cRegisterTestAdaptor) I2cWrite(address int, buf []byte) (err error) {
+	t.mutex.Lock()
+	t.written = append(t.written, append([]byte{}, buf...))
+	if len(buf) > 0 {
+		t.pointer = buf[0]
+		for i, b := range buf[1:] {
+			t.registers[buf[0]+byte(i)] = b
+		}
+	}
+	if t.writeHook != nil {
+		t.writeHook(buf)
+	}
+	t.mutex.Unlock()
+	return t.i2cWriteImpl()
+}
+func (t *i2cRegisterTestAdaptor) I2cRead(address int, n int) (data []byte, err error) {
+	if _, err = t.i2cReadImpl(); err != nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for i := 0; i < n; i++ {
+		data = append(data, t.registers[t.pointer+byte(i)])
+	}
+	return
+}
+
+// setRegisters stores data in the registers starting at register
+func (t *i2cRegisterTestAdaptor) setRegisters(register byte, data []byte) {
+	for i, b := range data {
+		t.registers[register+byte(i)] = b
+	}
+}
+
+func newI2cRegisterTestAdaptor(name string) *i2cRegisterTestAdaptor {
+	return &i2cRegisterTestAdaptor{
+		i2cTestAdaptor: newI2cTestAdaptor(name),
+		registers:      make(map[byte]byte),
+	}
+}
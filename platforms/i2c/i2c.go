@@ -11,13 +11,17 @@ var (
 	ErrNotEnoughBytes  = errors.New("Not enough bytes read")
 	ErrNotReady        = errors.New("Device is not ready")
 	ErrInvalidPosition = errors.New("Invalid position value")
+	ErrFIFOOverflow    = errors.New("FIFO overflow")
 )
 
 const (
-	Error    = "error"
-	Joystick = "joystick"
-	C        = "c"
-	Z        = "z"
+	Error       = "error"
+	Data        = "data"
+	Joystick    = "joystick"
+	C           = "c"
+	Z           = "z"
+	Orientation = "orientation"
+	Calibrated  = "calibrated"
 )
 
 type I2cStarter interface {
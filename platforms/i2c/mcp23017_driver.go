@@ -17,20 +17,37 @@ limitations under the License.
 package i2c
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/gpio"
 )
 
 var (
-	debug = false // Set this to true to see debugging information
 	// Register this Driver
 	_ gobot.Driver = (*MCP23017Driver)(nil)
+	// The driver is also an adaptor for gpio drivers
+	_ gpio.DigitalWriter = (*MCP23017Driver)(nil)
+	_ gpio.DigitalReader = (*MCP23017Driver)(nil)
 )
 
+// ErrInvalidPin is the error resulting when a MCP23017 pin is not named by
+// its port and bit, "A0" to "A7" or "B0" to "B7"
+var ErrInvalidPin = errors.New("Invalid pin, must be between A0-A7 or B0-B7")
+
+// ErrInvalidPort is the error resulting when a MCP23017 port is not "A" or "B"
+var ErrInvalidPort = errors.New("Invalid port, must be A or B")
+
+// mcp23017Pins are the names of the pins of both ports
+var mcp23017Pins = []string{
+	"A0", "A1", "A2", "A3", "A4", "A5", "A6", "A7",
+	"B0", "B1", "B2", "B3", "B4", "B5", "B6", "B7",
+}
+
 // Port contains all the registers for the device.
 type port struct {
 	IODIR   uint8 // I/O direction register: 0=output / 1=input
@@ -79,38 +96,86 @@ func (mc *MCP23017Config) GetUint8Value() uint8 {
 }
 
 // MCP23017Driver contains the driver configuration parameters.
+// It implements gpio.DigitalWriter and gpio.DigitalReader, so gpio drivers
+// can be bound to its pins "A0" to "A7" and "B0" to "B7".
 type MCP23017Driver struct {
 	name            string
 	connection      I2c
 	conf            MCP23017Config
 	mcp23017Address int
 	interval        time.Duration
+	// cache holds the last value written to each register, which saves
+	// reading the register before modifying one of its bits.
+	cache               map[uint8]uint8
+	interruptConnection gpio.DigitalReader
+	interruptPin        string
+	halt                chan bool
+	mutex               sync.Mutex
 	gobot.Commander
 	gobot.Eventer
 }
 
 // NewMCP23017Driver creates a new driver with specified name and i2c interface.
+//
+// Optionally accepts:
+// 	time.Duration: Interval at which the interrupt pin is polled
+//
+// Adds the following API Commands:
+// 	"WriteGPIO" - See MCP23017Driver.WriteGPIO
+// 	"ReadGPIO" - See MCP23017Driver.ReadGPIO
 func NewMCP23017Driver(a I2c, name string, conf MCP23017Config, deviceAddress int, v ...time.Duration) *MCP23017Driver {
 	m := &MCP23017Driver{
 		name:            name,
 		connection:      a,
 		conf:            conf,
 		mcp23017Address: deviceAddress,
+		interval:        10 * time.Millisecond,
+		cache:           make(map[uint8]uint8),
 		Commander:       gobot.NewCommander(),
 		Eventer:         gobot.NewEventer(),
 	}
 
-	m.AddCommand("WriteGPIO", func(params map[string]interface{}) interface{} {
-		pin := params["pin"].(uint8)
-		val := params["val"].(uint8)
+	if len(v) > 0 {
+		m.interval = v[0]
+	}
+
+	for _, pin := range mcp23017Pins {
+		m.AddEvent(pin)
+	}
+	m.AddEvent(Error)
+
+	pinParam := gobot.CommandParam{Name: "pin", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 7}}
+	portParam := gobot.CommandParam{Name: "port", Type: gobot.StringParam, Description: "A or B", Default: "A"}
+
+	m.AddCommandSchema(gobot.CommandSchema{
+		Name:        "WriteGPIO",
+		Description: "Sets a pin of a port to an output and writes a value to it",
+		Params: []gobot.CommandParam{
+			pinParam,
+			{Name: "val", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 1}},
+			portParam,
+		},
+	}, func(params map[string]interface{}) interface{} {
+		pin := uint8(params["pin"].(int))
+		val := uint8(params["val"].(int))
 		port := params["port"].(string)
+		if _, err := m.getPort(port); err != nil {
+			return &gobot.CommandError{Command: "WriteGPIO", Param: "port", Message: "must be A or B"}
+		}
 		err := m.WriteGPIO(pin, val, port)
 		return map[string]interface{}{"err": err}
 	})
 
-	m.AddCommand("ReadGPIO", func(params map[string]interface{}) interface{} {
-		pin := params["pin"].(uint8)
+	m.AddCommandSchema(gobot.CommandSchema{
+		Name:        "ReadGPIO",
+		Description: "Reads the value of a pin of a port",
+		Params:      []gobot.CommandParam{pinParam, portParam},
+	}, func(params map[string]interface{}) interface{} {
+		pin := uint8(params["pin"].(int))
 		port := params["port"].(string)
+		if _, err := m.getPort(port); err != nil {
+			return &gobot.CommandError{Command: "ReadGPIO", Param: "port", Message: "must be A or B"}
+		}
 		val, err := m.ReadGPIO(pin, port)
 		return map[string]interface{}{"val": val, "err": err}
 	})
@@ -124,26 +189,148 @@ func (m *MCP23017Driver) Name() string { return m.name }
 // Connection returns the I2c connection.
 func (m *MCP23017Driver) Connection() gobot.Connection { return m.connection.(gobot.Connection) }
 
-// Halt stops the driver.
-func (m *MCP23017Driver) Halt() (err []error) { return }
+// Connect implements the Adaptor interface, the device is configured by Start.
+func (m *MCP23017Driver) Connect() (errs []error) { return }
 
-// Start writes the device configuration.
+// Finalize implements the Adaptor interface.
+func (m *MCP23017Driver) Finalize() (errs []error) { return }
+
+// Halt stops polling the interrupt pin.
+func (m *MCP23017Driver) Halt() (err []error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.halt != nil {
+		close(m.halt)
+		m.halt = nil
+	}
+	return
+}
+
+// Start writes the device configuration, and polls the interrupt pin at the
+// given interval when one is set.
+// Emits the Events:
+//	"A0" to "B7" int - Event is emitted with the pin value on an interrupt on change of an enabled pin.
+//	Error error - Event is emitted on error reading the interrupt pin or registers.
 func (m *MCP23017Driver) Start() (errs []error) {
 	if err := m.connection.I2cStart(m.mcp23017Address); err != nil {
 		return []error{err}
 	}
 	// Set IOCON register with MCP23017 configuration.
-	ioconReg := m.getPort("A").IOCON // IOCON address is the same for Port A or B.
+	portA, _ := m.getPort("A")
+	ioconReg := portA.IOCON // IOCON address is the same for Port A or B.
 	ioconVal := m.conf.GetUint8Value()
 	if err := m.connection.I2cWrite(m.mcp23017Address, []uint8{ioconReg, ioconVal}); err != nil {
 		return []error{err}
 	}
+
+	if m.interruptConnection != nil {
+		m.mutex.Lock()
+		halt := make(chan bool)
+		m.halt = halt
+		m.mutex.Unlock()
+
+		go func() {
+			for {
+				if err := m.pollInterrupt(); err != nil {
+					gobot.Publish(m.Event(Error), err)
+				}
+				select {
+				case <-time.After(m.interval):
+				case <-halt:
+					return
+				}
+			}
+		}()
+	}
 	return
 }
 
+// DigitalWrite sets pin, "A0" to "B7", to an output and writes level to it.
+func (m *MCP23017Driver) DigitalWrite(pin string, level byte) (err error) {
+	portStr, bit, err := m.parsePin(pin)
+	if err != nil {
+		return
+	}
+	return m.WriteGPIO(bit, level, portStr)
+}
+
+// DigitalRead sets pin, "A0" to "B7", to an input and reads its value.
+// Reading a pin with interrupts enabled clears a pending interrupt of its port.
+func (m *MCP23017Driver) DigitalRead(pin string) (val int, err error) {
+	portStr, bit, err := m.parsePin(pin)
+	if err != nil {
+		return
+	}
+	selectedPort, err := m.getPort(portStr)
+	if err != nil {
+		return
+	}
+	if err = m.write(selectedPort.IODIR, bit, 1); err != nil {
+		return
+	}
+	v, err := m.ReadGPIO(bit, portStr)
+	if err != nil {
+		return
+	}
+	if v != 0 {
+		val = 1
+	}
+	return
+}
+
+// SetInterruptPin sets the host pin which the INTA output, or both INTA and
+// INTB when mirrored, is connected to. It is polled once the driver is
+// started, and is active low unless the Intpol configuration is set.
+func (m *MCP23017Driver) SetInterruptPin(a gpio.DigitalReader, pin string) {
+	m.interruptConnection = a
+	m.interruptPin = pin
+}
+
+// EnableInterrupt sets pin, "A0" to "B7", to an input which interrupts on
+// every change of its value from the current one.
+func (m *MCP23017Driver) EnableInterrupt(pin string) (err error) {
+	portStr, bit, err := m.parsePin(pin)
+	if err != nil {
+		return
+	}
+	selectedPort, err := m.getPort(portStr)
+	if err != nil {
+		return
+	}
+	if err = m.write(selectedPort.IODIR, bit, 1); err != nil {
+		return
+	}
+	// compare against the previous value rather than DEFVAL
+	if err = m.write(selectedPort.INTCON, bit, 0); err != nil {
+		return
+	}
+	if err = m.write(selectedPort.GPINTEN, bit, 1); err != nil {
+		return
+	}
+	// reading the port clears an interrupt pending from a previous value
+	_, err = m.read(selectedPort.GPIO)
+	return
+}
+
+// DisableInterrupt disables the interrupt on change of pin, "A0" to "B7".
+func (m *MCP23017Driver) DisableInterrupt(pin string) (err error) {
+	portStr, bit, err := m.parsePin(pin)
+	if err != nil {
+		return
+	}
+	selectedPort, err := m.getPort(portStr)
+	if err != nil {
+		return
+	}
+	return m.write(selectedPort.GPINTEN, bit, 0)
+}
+
 // WriteGPIO writes a value to a gpio pin (0-7) and a port (A or B).
 func (m *MCP23017Driver) WriteGPIO(pin uint8, val uint8, portStr string) (err error) {
-	selectedPort := m.getPort(portStr)
+	selectedPort, err := m.getPort(portStr)
+	if err != nil {
+		return err
+	}
 	// Set IODIR register bit for given pin to an output.
 	if err := m.write(selectedPort.IODIR, uint8(pin), 0); err != nil {
 		return err
@@ -158,7 +345,10 @@ func (m *MCP23017Driver) WriteGPIO(pin uint8, val uint8, portStr string) (err er
 // ReadGPIO reads a value from a given gpio pin (0-7) and a
 // port (A or B).
 func (m *MCP23017Driver) ReadGPIO(pin uint8, portStr string) (val uint8, err error) {
-	selectedPort := m.getPort(portStr)
+	selectedPort, err := m.getPort(portStr)
+	if err != nil {
+		return val, err
+	}
 	val, err = m.read(selectedPort.GPIO)
 	if err != nil {
 		return val, err
@@ -170,7 +360,10 @@ func (m *MCP23017Driver) ReadGPIO(pin uint8, portStr string) (val uint8, err err
 // val = 1 pull up enabled.
 // val = 0 pull up disabled.
 func (m *MCP23017Driver) SetPullUp(pin uint8, val uint8, portStr string) error {
-	selectedPort := m.getPort(portStr)
+	selectedPort, err := m.getPort(portStr)
+	if err != nil {
+		return err
+	}
 	return m.write(selectedPort.GPPU, pin, val)
 }
 
@@ -178,29 +371,75 @@ func (m *MCP23017Driver) SetPullUp(pin uint8, val uint8, portStr string) error {
 // val = 1 opposite logic state of the input pin.
 // val = 0 same logic state of the input pin.
 func (m *MCP23017Driver) SetGPIOPolarity(pin uint8, val uint8, portStr string) (err error) {
-	selectedPort := m.getPort(portStr)
+	selectedPort, err := m.getPort(portStr)
+	if err != nil {
+		return
+	}
 	return m.write(selectedPort.IPOL, pin, val)
 }
 
+// pollInterrupt reads the interrupt pin, and when it is active reads which
+// pins of each port caused the interrupt and their captured values, which
+// clears the interrupt.
+func (m *MCP23017Driver) pollInterrupt() (err error) {
+	level, err := m.interruptConnection.DigitalRead(m.interruptPin)
+	if err != nil || level != int(m.conf.Intpol) {
+		return
+	}
+	for i, portStr := range []string{"A", "B"} {
+		selectedPort, _ := m.getPort(portStr)
+		flags, captured, err := m.readInterrupt(selectedPort)
+		if err != nil {
+			return err
+		}
+		for bit := uint8(0); bit < 8; bit++ {
+			if flags&(1<<bit) != 0 {
+				gobot.Publish(m.Event(mcp23017Pins[i*8+int(bit)]), int(captured>>bit&1))
+			}
+		}
+	}
+	return
+}
+
+// readInterrupt reads which pins of selectedPort caused an interrupt, and
+// when any did their captured values, which clears the interrupt.
+func (m *MCP23017Driver) readInterrupt(selectedPort port) (flags uint8, captured uint8, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if flags, err = m.readRegister(selectedPort.INTF); err != nil || flags == 0 {
+		return
+	}
+	captured, err = m.readRegister(selectedPort.INTCAP)
+	return
+}
+
 // write gets the value of the passed in register, and then overwrites
-// the bit specified by the pin, with the given value.
+// the bit specified by the pin, with the given value. The value of the
+// register is only read the first time, afterwards the cached value is
+// modified and the write is skipped when it does not change.
 func (m *MCP23017Driver) write(reg uint8, pin uint8, val uint8) (err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	var ioval uint8
-	iodir, err := m.read(reg)
-	if err != nil {
-		return err
+	iodir, cached := m.cache[reg]
+	if !cached {
+		if iodir, err = m.readRegister(reg); err != nil {
+			return err
+		}
 	}
 	if val == 0 {
 		ioval = clearBit(iodir, uint8(pin))
 	} else if val == 1 {
 		ioval = setBit(iodir, uint8(pin))
 	}
-	if debug {
-		log.Printf("Writing: MCP address: 0x%X, register: 0x%X\t, value: 0x%X\n", m.mcp23017Address, reg, ioval)
+	if cached && ioval == iodir {
+		return nil
 	}
+	m.trace("Writing MCP23017 register", reg, ioval)
 	if err = m.connection.I2cWrite(m.mcp23017Address, []uint8{reg, ioval}); err != nil {
 		return err
 	}
+	m.cache[reg] = ioval
 	return nil
 }
 
@@ -209,6 +448,13 @@ func (m *MCP23017Driver) write(reg uint8, pin uint8, val uint8) (err error) {
 // device address. To read a specific register, read register + 1 bytes, and then index
 // the result with the given register to get the value.
 func (m *MCP23017Driver) read(reg uint8) (val uint8, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.readRegister(reg)
+}
+
+// readRegister reads a register, the mutex must be held.
+func (m *MCP23017Driver) readRegister(reg uint8) (val uint8, err error) {
 	register := int(reg)
 	bytesToRead := register + 1
 	v, err := m.connection.I2cRead(m.mcp23017Address, bytesToRead)
@@ -218,24 +464,42 @@ func (m *MCP23017Driver) read(reg uint8) (val uint8, err error) {
 	if len(v) != bytesToRead {
 		return val, fmt.Errorf("Read was unable to get %d bytes for register: 0x%X\n", bytesToRead, reg)
 	}
-	if debug {
-		log.Printf("Reading: MCP address: 0x%X, register:0x%X\t,value: 0x%X\n", m.mcp23017Address, reg, v[register])
-	}
+	m.trace("Read MCP23017 register", reg, v[register])
 	return v[register], nil
 }
 
-// getPort return the port (A or B) given a string and the bank.
-// Port A is the default if an incorrect or no port is specified.
-func (m *MCP23017Driver) getPort(portStr string) (selectedPort port) {
+// trace logs a register transfer to gobot.DefaultLogger at DebugLevel, the
+// fields are only built when DebugLevel entries are logged.
+func (m *MCP23017Driver) trace(msg string, reg uint8, val uint8) {
+	if !gobot.Enabled(gobot.DefaultLogger, gobot.DebugLevel) {
+		return
+	}
+	gobot.DefaultLogger.Log(gobot.DebugLevel, msg, gobot.Fields{
+		"device": m.Name(), "address": fmt.Sprintf("0x%X", m.mcp23017Address),
+		"register": fmt.Sprintf("0x%X", reg), "value": fmt.Sprintf("0x%X", val)})
+}
+
+// getPort return the port (A or B) given a string and the bank, or
+// ErrInvalidPort if another port is specified.
+func (m *MCP23017Driver) getPort(portStr string) (selectedPort port, err error) {
 	portStr = strings.ToUpper(portStr)
 	switch {
 	case portStr == "A":
-		return getBank(m.conf.Bank).PortA
+		return getBank(m.conf.Bank).PortA, nil
 	case portStr == "B":
-		return getBank(m.conf.Bank).PortB
+		return getBank(m.conf.Bank).PortB, nil
 	default:
-		return getBank(m.conf.Bank).PortA
+		return selectedPort, ErrInvalidPort
+	}
+}
+
+// parsePin returns the port and bit of a pin named "A0" to "B7".
+func (m *MCP23017Driver) parsePin(pin string) (portStr string, bit uint8, err error) {
+	pin = strings.ToUpper(pin)
+	if len(pin) != 2 || (pin[0] != 'A' && pin[0] != 'B') || pin[1] < '0' || pin[1] > '7' {
+		return "", 0, ErrInvalidPin
 	}
+	return pin[:1], pin[1] - '0', nil
 }
 
 // setBit is used to set a bit at a given position to 1.
@@ -1,13 +1,16 @@
 package i2c
 
 import (
+	"bytes"
 	"errors"
-	"io/ioutil"
-	"log"
-	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/gobottest"
+	"github.com/hybridgroup/gobot/platforms/gpio"
 )
 
 type i2cMcpTestAdaptor struct {
@@ -99,6 +102,16 @@ func TestMCP23017DriverStart(t *testing.T) {
 func TestMCP23017DriverHalt(t *testing.T) {
 	mcp := initTestMCP23017Driver(0)
 	gobottest.Assert(t, len(mcp.Halt()), 0)
+
+	// Halt does not block when Start failed with an interrupt pin set
+	mcp, adaptor := initTestMCP23017DriverWithStubbedAdaptor(0)
+	mcp.SetInterruptPin(&mcpInterruptTestAdaptor{level: 1}, "7")
+	adaptor.i2cMcpStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Refute(t, len(mcp.Start()), 0)
+	gobottest.Assert(t, len(mcp.Halt()), 0)
+	gobottest.Assert(t, len(mcp.Halt()), 0)
 }
 
 func TestMCP23017DriverCommandsWriteGPIO(t *testing.T) {
@@ -111,6 +124,17 @@ func TestMCP23017DriverCommandsWriteGPIO(t *testing.T) {
 	}
 	result := mcp.Command("WriteGPIO")(pinValPort)
 	gobottest.Assert(t, result.(map[string]interface{})["err"], nil)
+
+	// parameters decoded from JSON are float64
+	result = mcp.Command("WriteGPIO")(map[string]interface{}{"pin": 7.0, "val": 1.0})
+	gobottest.Assert(t, result.(map[string]interface{})["err"], nil)
+
+	result = mcp.Command("WriteGPIO")(map[string]interface{}{"pin": 8.0, "val": 1.0})
+	gobottest.Assert(t, result.(*gobot.CommandError).Param, "pin")
+	result = mcp.Command("WriteGPIO")(map[string]interface{}{"pin": 7.0})
+	gobottest.Assert(t, result.(*gobot.CommandError).Param, "val")
+	result = mcp.Command("WriteGPIO")(map[string]interface{}{"pin": 7.0, "val": 1.0, "port": "C"})
+	gobottest.Assert(t, result, &gobot.CommandError{Command: "WriteGPIO", Param: "port", Message: "must be A or B"})
 }
 
 func TestMCP23017DriverCommandsReadGPIO(t *testing.T) {
@@ -120,6 +144,15 @@ func TestMCP23017DriverCommandsReadGPIO(t *testing.T) {
 	}
 	result := mcp.Command("ReadGPIO")(pinPort)
 	gobottest.Assert(t, result.(map[string]interface{})["err"], nil)
+
+	result = mcp.Command("ReadGPIO")(map[string]interface{}{"pin": 7.0, "port": "B"})
+	gobottest.Assert(t, result.(map[string]interface{})["err"], nil)
+	result = mcp.Command("ReadGPIO")(map[string]interface{}{"pin": 7.0, "port": "C"})
+	gobottest.Assert(t, result.(*gobot.CommandError).Param, "port")
+
+	_, err := mcp.ReadGPIO(7, "C")
+	gobottest.Assert(t, err, ErrInvalidPort)
+	gobottest.Assert(t, mcp.WriteGPIO(7, 1, "C"), ErrInvalidPort)
 }
 
 func TestMCP23017DriverWriteGPIO(t *testing.T) {
@@ -237,7 +270,7 @@ func TestMCP23017DriverSetGPIOPolarity(t *testing.T) {
 func TestMCP23017DriverWrite(t *testing.T) {
 	// clear bit
 	mcp, adaptor := initTestMCP23017DriverWithStubbedAdaptor(0)
-	port := mcp.getPort("A")
+	port, _ := mcp.getPort("A")
 	adaptor.i2cMcpReadImpl = func(a int, b int) ([]byte, error) {
 		return make([]byte, b), nil
 	}
@@ -249,7 +282,7 @@ func TestMCP23017DriverWrite(t *testing.T) {
 
 	// set bit
 	mcp, adaptor = initTestMCP23017DriverWithStubbedAdaptor(0)
-	port = mcp.getPort("B")
+	port, _ = mcp.getPort("B")
 	adaptor.i2cMcpReadImpl = func(a int, b int) ([]byte, error) {
 		return make([]byte, b), nil
 	}
@@ -278,9 +311,6 @@ func TestMCP23017DriverWrite(t *testing.T) {
 	err = mcp.write(port.IODIR, uint8(7), 0)
 	gobottest.Assert(t, err, errors.New("read error"))
 
-	//debug
-	debug = true
-	log.SetOutput(ioutil.Discard)
 	adaptor.i2cMcpReadImpl = func(a int, b int) ([]byte, error) {
 		return make([]byte, b), nil
 	}
@@ -289,14 +319,38 @@ func TestMCP23017DriverWrite(t *testing.T) {
 	}
 	err = mcp.write(port.IODIR, uint8(7), 1)
 	gobottest.Assert(t, err, nil)
-	debug = false
-	log.SetOutput(os.Stdout)
+}
+
+func TestMCP23017DriverTrace(t *testing.T) {
+	logger := gobot.DefaultLogger
+	defer func() { gobot.DefaultLogger = logger }()
+	buf := &bytes.Buffer{}
+	gobot.DefaultLogger = gobot.NewLogger(buf, gobot.DebugLevel)
+
+	mcp, adaptor := initTestMCP23017DriverWithStubbedAdaptor(0)
+	adaptor.i2cMcpReadImpl = func(a int, b int) ([]byte, error) {
+		return make([]byte, b), nil
+	}
+	adaptor.i2cMcpWriteImpl = func() error {
+		return nil
+	}
+	gobottest.Assert(t, mcp.write(0x01, 7, 1), nil)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	gobottest.Assert(t, len(lines), 2)
+	gobottest.Assert(t, strings.HasSuffix(lines[0], "DEBUG Read MCP23017 register address=0x20 device=bot register=0x1 value=0x0"), true)
+	gobottest.Assert(t, strings.HasSuffix(lines[1], "DEBUG Writing MCP23017 register address=0x20 device=bot register=0x1 value=0x80"), true)
+
+	// nothing is traced unless DebugLevel entries are logged
+	buf.Reset()
+	gobot.DefaultLogger = gobot.NewLogger(buf, gobot.InfoLevel)
+	gobottest.Assert(t, mcp.write(0x01, 7, 0), nil)
+	gobottest.Assert(t, buf.Len(), 0)
 }
 
 func TestMCP23017DriverReadPort(t *testing.T) {
 	// read
 	mcp, adaptor := initTestMCP23017DriverWithStubbedAdaptor(0)
-	port := mcp.getPort("A")
+	port, _ := mcp.getPort("A")
 	adaptor.i2cMcpReadImpl = func(a int, b int) ([]byte, error) {
 		return []byte{255}, nil
 	}
@@ -315,18 +369,15 @@ func TestMCP23017DriverReadPort(t *testing.T) {
 
 	// read
 	mcp, adaptor = initTestMCP23017DriverWithStubbedAdaptor(0)
-	port = mcp.getPort("A")
+	port, _ = mcp.getPort("A")
 	adaptor.i2cMcpReadImpl = func(a int, b int) ([]byte, error) {
 		return []byte{}, nil
 	}
 	_, err = mcp.read(port.IODIR)
 	gobottest.Assert(t, err, errors.New("Read was unable to get 1 bytes for register: 0x0\n"))
 
-	// debug
-	debug = true
-	log.SetOutput(ioutil.Discard)
 	mcp, adaptor = initTestMCP23017DriverWithStubbedAdaptor(0)
-	port = mcp.getPort("A")
+	port, _ = mcp.getPort("A")
 
 	adaptor.i2cMcpReadImpl = func(a int, b int) ([]byte, error) {
 		return []byte{255}, nil
@@ -334,33 +385,35 @@ func TestMCP23017DriverReadPort(t *testing.T) {
 
 	val, _ = mcp.read(port.IODIR)
 	gobottest.Assert(t, val, uint8(255))
-	debug = false
-	log.SetOutput(os.Stdout)
 }
 
 func TestMCP23017DriverGetPort(t *testing.T) {
 	// port A
 	mcp := initTestMCP23017Driver(0)
 	expectedPort := getBank(0).PortA
-	actualPort := mcp.getPort("A")
+	actualPort, err := mcp.getPort("A")
+	gobottest.Assert(t, err, nil)
 	gobottest.Assert(t, expectedPort, actualPort)
 
 	// port B
 	mcp = initTestMCP23017Driver(0)
 	expectedPort = getBank(0).PortB
-	actualPort = mcp.getPort("B")
+	actualPort, err = mcp.getPort("b")
+	gobottest.Assert(t, err, nil)
 	gobottest.Assert(t, expectedPort, actualPort)
 
-	// default
+	// invalid
 	mcp = initTestMCP23017Driver(0)
-	expectedPort = getBank(0).PortA
-	actualPort = mcp.getPort("")
-	gobottest.Assert(t, expectedPort, actualPort)
+	_, err = mcp.getPort("")
+	gobottest.Assert(t, err, ErrInvalidPort)
+	_, err = mcp.getPort("C")
+	gobottest.Assert(t, err, ErrInvalidPort)
 
 	// port A bank 1
 	mcp = initTestMCP23017Driver(1)
 	expectedPort = getBank(1).PortA
-	actualPort = mcp.getPort("")
+	actualPort, err = mcp.getPort("A")
+	gobottest.Assert(t, err, nil)
 	gobottest.Assert(t, expectedPort, actualPort)
 }
 
@@ -375,3 +428,180 @@ func TestClearBit(t *testing.T) {
 	actualVal := clearBit(128, 7)
 	gobottest.Assert(t, expectedVal, actualVal)
 }
+
+// i2cMcpRegisterTestAdaptor is backed by a register file, as the driver reads
+// a register by reading every register up to it.
+type i2cMcpRegisterTestAdaptor struct {
+	*i2cMcpTestAdaptor
+	mutex     sync.Mutex
+	registers []byte
+	written   [][]byte
+	reads     int
+}
+
+func (t *i2cMcpRegisterTestAdaptor) I2cWrite(address int, buf []byte) (err error) {
+	if err = t.i2cMcpWriteImpl(); err != nil {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.written = append(t.written, append([]byte{}, buf...))
+	t.registers[buf[0]] = buf[1]
+	return
+}
+func (t *i2cMcpRegisterTestAdaptor) I2cRead(address int, numBytes int) (data []byte, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.reads++
+	return append([]byte{}, t.registers[:numBytes]...), nil
+}
+
+func initTestMCP23017DriverWithRegisterAdaptor() (*MCP23017Driver, *i2cMcpRegisterTestAdaptor) {
+	adaptor := &i2cMcpRegisterTestAdaptor{
+		i2cMcpTestAdaptor: newMcpI2cTestAdaptor("adaptor"),
+		registers:         make([]byte, 0x16),
+	}
+	// power on state, every pin is an input
+	adaptor.registers[0x00] = 0xFF
+	adaptor.registers[0x01] = 0xFF
+	return NewMCP23017Driver(adaptor, "bot", MCP23017Config{}, 0x20, 1*time.Millisecond), adaptor
+}
+
+type mcpInterruptTestAdaptor struct {
+	mutex sync.Mutex
+	level int
+}
+
+func (t *mcpInterruptTestAdaptor) DigitalRead(string) (val int, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.level, nil
+}
+func (t *mcpInterruptTestAdaptor) setLevel(level int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.level = level
+}
+func (t *mcpInterruptTestAdaptor) Name() string             { return "host" }
+func (t *mcpInterruptTestAdaptor) Connect() (errs []error)  { return }
+func (t *mcpInterruptTestAdaptor) Finalize() (errs []error) { return }
+
+func TestMCP23017DriverParsePin(t *testing.T) {
+	mcp := initTestMCP23017Driver(0)
+
+	portStr, bit, err := mcp.parsePin("A3")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, portStr, "A")
+	gobottest.Assert(t, bit, uint8(3))
+
+	portStr, bit, err = mcp.parsePin("b7")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, portStr, "B")
+	gobottest.Assert(t, bit, uint8(7))
+
+	for _, pin := range []string{"", "A", "A8", "C0", "A10", "3"} {
+		_, _, err = mcp.parsePin(pin)
+		gobottest.Assert(t, err, ErrInvalidPin)
+	}
+}
+
+func TestMCP23017DriverDigitalWrite(t *testing.T) {
+	mcp, adaptor := initTestMCP23017DriverWithRegisterAdaptor()
+
+	gobottest.Assert(t, mcp.DigitalWrite("B3", 1), nil)
+	gobottest.Assert(t, adaptor.written, [][]byte{{0x01, 0xF7}, {0x15, 0x08}})
+	gobottest.Assert(t, adaptor.reads, 2)
+
+	// IODIR and OLAT are cached, so only OLAT is written
+	gobottest.Assert(t, mcp.DigitalWrite("B3", 0), nil)
+	gobottest.Assert(t, mcp.DigitalWrite("B4", 1), nil)
+	gobottest.Assert(t, adaptor.written[2:], [][]byte{{0x15, 0x00}, {0x01, 0xE7}, {0x15, 0x10}})
+	gobottest.Assert(t, adaptor.reads, 2)
+
+	gobottest.Assert(t, mcp.DigitalWrite("C1", 1), ErrInvalidPin)
+}
+
+func TestMCP23017DriverDigitalRead(t *testing.T) {
+	mcp, adaptor := initTestMCP23017DriverWithRegisterAdaptor()
+	adaptor.registers[0x12] = 0x08
+
+	val, err := mcp.DigitalRead("A3")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 1)
+	val, err = mcp.DigitalRead("A2")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 0)
+	// IODIR is only written the first time, as it is then cached
+	gobottest.Assert(t, adaptor.written, [][]byte{{0x00, 0xFF}})
+
+	// an output is set back to an input
+	gobottest.Assert(t, mcp.DigitalWrite("A2", 1), nil)
+	_, err = mcp.DigitalRead("A2")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, adaptor.registers[0x00], uint8(0xFF))
+
+	_, err = mcp.DigitalRead("A9")
+	gobottest.Assert(t, err, ErrInvalidPin)
+}
+
+func TestMCP23017DriverGpioDrivers(t *testing.T) {
+	mcp, adaptor := initTestMCP23017DriverWithRegisterAdaptor()
+
+	led := gpio.NewLedDriver(mcp, "led", "A3")
+	gobottest.Assert(t, led.Connection().Name(), "bot")
+	gobottest.Assert(t, led.On(), nil)
+	gobottest.Assert(t, adaptor.registers[0x14], uint8(0x08))
+	gobottest.Assert(t, led.Off(), nil)
+	gobottest.Assert(t, adaptor.registers[0x14], uint8(0x00))
+}
+
+func TestMCP23017DriverInterrupt(t *testing.T) {
+	mcp, adaptor := initTestMCP23017DriverWithRegisterAdaptor()
+	host := &mcpInterruptTestAdaptor{level: 1}
+	mcp.SetInterruptPin(host, "7")
+
+	gobottest.Assert(t, mcp.EnableInterrupt("B7"), nil)
+	gobottest.Assert(t, adaptor.registers[0x05], uint8(0x80))
+	gobottest.Assert(t, adaptor.registers[0x09], uint8(0x00))
+
+	gobottest.Assert(t, len(mcp.Start()), 0)
+
+	sem := make(chan int)
+	gobot.Once(mcp.Event("B7"), func(data interface{}) {
+		sem <- data.(int)
+	})
+
+	// the interrupt output is active low
+	adaptor.mutex.Lock()
+	adaptor.registers[0x0F] = 0x80
+	adaptor.registers[0x11] = 0x80
+	adaptor.registers[0x13] = 0x00
+	adaptor.mutex.Unlock()
+	host.setLevel(0)
+
+	select {
+	case val := <-sem:
+		gobottest.Assert(t, val, 1)
+	case <-time.After(1 * time.Second):
+		t.Errorf("MCP23017 Event \"B7\" was not published")
+	}
+	host.setLevel(1)
+
+	// the current value is read rather than the captured one
+	adaptor.mutex.Lock()
+	reads := adaptor.reads
+	adaptor.mutex.Unlock()
+	val, err := mcp.DigitalRead("B7")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 0)
+	adaptor.mutex.Lock()
+	gobottest.Assert(t, adaptor.reads, reads+1)
+	adaptor.mutex.Unlock()
+
+	gobottest.Assert(t, len(mcp.Halt()), 0)
+
+	gobottest.Assert(t, mcp.DisableInterrupt("B7"), nil)
+	gobottest.Assert(t, adaptor.registers[0x05], uint8(0x00))
+	gobottest.Assert(t, mcp.EnableInterrupt("B9"), ErrInvalidPin)
+	gobottest.Assert(t, mcp.DisableInterrupt("B9"), ErrInvalidPin)
+}
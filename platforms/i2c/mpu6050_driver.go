@@ -3,6 +3,7 @@ package i2c
 import (
 	"bytes"
 	"encoding/binary"
+	"sync"
 	"time"
 
 	"github.com/hybridgroup/gobot"
@@ -27,6 +28,35 @@ const MPU6050_ACONFIG_AFS_SEL_LENGTH = 2
 const MPU6050_ACCEL_FS_2 = 0x00
 const MPU6050_PWR1_SLEEP_BIT = 6
 const MPU6050_PWR1_ENABLE_BIT = 0
+const MPU6050_RA_SMPLRT_DIV = 0x19
+const MPU6050_RA_CONFIG = 0x1A
+const MPU6050_RA_FIFO_EN = 0x23
+const MPU6050_RA_USER_CTRL = 0x6A
+const MPU6050_RA_FIFO_COUNTH = 0x72
+const MPU6050_RA_FIFO_R_W = 0x74
+const MPU6050_GYRO_FS_500 = 0x01
+const MPU6050_GYRO_FS_1000 = 0x02
+const MPU6050_GYRO_FS_2000 = 0x03
+const MPU6050_ACCEL_FS_4 = 0x01
+const MPU6050_ACCEL_FS_8 = 0x02
+const MPU6050_ACCEL_FS_16 = 0x03
+const MPU6050_DLPF_BW_256 = 0x00
+const MPU6050_DLPF_BW_188 = 0x01
+const MPU6050_DLPF_BW_98 = 0x02
+const MPU6050_DLPF_BW_42 = 0x03
+const MPU6050_DLPF_BW_20 = 0x04
+const MPU6050_DLPF_BW_10 = 0x05
+const MPU6050_DLPF_BW_5 = 0x06
+const MPU6050_FIFO_EN_ACCEL_GYRO = 0x78
+const MPU6050_USERCTRL_FIFO_EN = 0x40
+const MPU6050_USERCTRL_FIFO_RESET = 0x04
+
+// mpu6050FIFOSampleSize is the size of an accelerometer and gyroscope sample
+// in the FIFO
+const mpu6050FIFOSampleSize = 12
+
+// mpu6050FIFOSize is the size of the FIFO, when it is full new samples are lost
+const mpu6050FIFOSize = 1024
 
 type ThreeDData struct {
 	X int16
@@ -34,22 +64,46 @@ type ThreeDData struct {
 	Z int16
 }
 
+// ScaledThreeDData is ThreeDData converted to physical units
+type ScaledThreeDData struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// MPU6050Sample is an accelerometer and gyroscope sample, it is emitted on the
+// Data event and returned by ReadFIFO
+type MPU6050Sample struct {
+	Accelerometer ThreeDData
+	Gyroscope     ThreeDData
+}
+
 type MPU6050Driver struct {
 	name          string
 	connection    I2c
 	interval      time.Duration
+	dlpf          byte
+	gyroRange     byte
+	accelRange    byte
+	sampleRateDiv byte
+	mutex         sync.Mutex
 	Accelerometer ThreeDData
 	Gyroscope     ThreeDData
 	Temperature   int16
 	gobot.Eventer
 }
 
-// NewMPU6050Driver creates a new driver with specified name and i2c interface
+// NewMPU6050Driver creates a new driver with specified name and i2c interface.
+// The sensor is configured with a 256Hz low pass filter, a gyroscope range of
+// +/-250 deg/s and an accelerometer range of +/-2g.
 func NewMPU6050Driver(a I2c, name string, v ...time.Duration) *MPU6050Driver {
 	m := &MPU6050Driver{
 		name:       name,
 		connection: a,
 		interval:   10 * time.Millisecond,
+		dlpf:       MPU6050_DLPF_BW_256,
+		gyroRange:  MPU6050_GYRO_FS_250,
+		accelRange: MPU6050_ACCEL_FS_2,
 		Eventer:    gobot.NewEventer(),
 	}
 
@@ -57,6 +111,7 @@ func NewMPU6050Driver(a I2c, name string, v ...time.Duration) *MPU6050Driver {
 		m.interval = v[0]
 	}
 
+	m.AddEvent(Data)
 	m.AddEvent(Error)
 	return m
 }
@@ -66,6 +121,9 @@ func (h *MPU6050Driver) Connection() gobot.Connection { return h.connection.(gob
 
 // Start writes initialization bytes and reads from adaptor
 // using specified interval to accelerometer andtemperature data
+// Emits the Events:
+//	Data MPU6050Sample - Event is emitted every sample.
+//	Error error - Event is emitted on error reading from the sensor.
 func (h *MPU6050Driver) Start() (errs []error) {
 	if err := h.initialize(); err != nil {
 		return []error{err}
@@ -73,21 +131,14 @@ func (h *MPU6050Driver) Start() (errs []error) {
 
 	go func() {
 		for {
-			if err := h.connection.I2cWrite(mpu6050Address, []byte{MPU6050_RA_ACCEL_XOUT_H}); err != nil {
+			if err := h.read(); err != nil {
 				gobot.Publish(h.Event(Error), err)
 				continue
 			}
-
-			ret, err := h.connection.I2cRead(mpu6050Address, 14)
-			if err != nil {
-				gobot.Publish(h.Event(Error), err)
-				continue
-			}
-			buf := bytes.NewBuffer(ret)
-			binary.Read(buf, binary.BigEndian, &h.Accelerometer)
-			binary.Read(buf, binary.BigEndian, &h.Temperature)
-			binary.Read(buf, binary.BigEndian, &h.Gyroscope)
-			h.convertToCelsius()
+			gobot.Publish(h.Event(Data), MPU6050Sample{
+				Accelerometer: h.Accelerometer,
+				Gyroscope:     h.Gyroscope,
+			})
 			<-time.After(h.interval)
 		}
 	}()
@@ -97,43 +148,185 @@ func (h *MPU6050Driver) Start() (errs []error) {
 // Halt returns true if devices is halted successfully
 func (h *MPU6050Driver) Halt() (errs []error) { return }
 
+func (h *MPU6050Driver) read() (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if err = h.connection.I2cWrite(mpu6050Address, []byte{MPU6050_RA_ACCEL_XOUT_H}); err != nil {
+		return
+	}
+
+	ret, err := h.connection.I2cRead(mpu6050Address, 14)
+	if err != nil {
+		return
+	}
+	buf := bytes.NewBuffer(ret)
+	binary.Read(buf, binary.BigEndian, &h.Accelerometer)
+	binary.Read(buf, binary.BigEndian, &h.Temperature)
+	binary.Read(buf, binary.BigEndian, &h.Gyroscope)
+	h.convertToCelsius()
+	return
+}
+
 func (h *MPU6050Driver) initialize() (err error) {
 	if err = h.connection.I2cStart(mpu6050Address); err != nil {
 		return
 	}
 
-	// setClockSource
-	if err = h.connection.I2cWrite(mpu6050Address, []byte{MPU6050_RA_PWR_MGMT_1,
-		MPU6050_PWR1_CLKSEL_BIT,
-		MPU6050_PWR1_CLKSEL_LENGTH,
-		MPU6050_CLOCK_PLL_XGYRO}); err != nil {
+	// setClockSource, which also disables sleep
+	if err = h.writeRegister(MPU6050_RA_PWR_MGMT_1, MPU6050_CLOCK_PLL_XGYRO); err != nil {
+		return
+	}
+	if err = h.writeRegister(MPU6050_RA_CONFIG, h.dlpf); err != nil {
+		return
+	}
+	if err = h.writeRegister(MPU6050_RA_SMPLRT_DIV, h.sampleRateDiv); err != nil {
+		return
+	}
+	if err = h.writeRegister(MPU6050_RA_GYRO_CONFIG, h.gyroRange<<3); err != nil {
+		return
+	}
+	return h.writeRegister(MPU6050_RA_ACCEL_CONFIG, h.accelRange<<3)
+}
+
+// SetDLPF sets the bandwidth of the digital low pass filter applied to the
+// accelerometer and gyroscope, one of the MPU6050_DLPF_BW constants
+func (h *MPU6050Driver) SetDLPF(bandwidth byte) (err error) {
+	h.dlpf = bandwidth & 0x07
+	return h.writeRegister(MPU6050_RA_CONFIG, h.dlpf)
+}
+
+// SetSampleRateDivider sets the rate samples are written to the FIFO, which
+// is the gyroscope output rate of 8kHz, or 1kHz when the low pass filter is
+// enabled, divided by 1 + div
+func (h *MPU6050Driver) SetSampleRateDivider(div byte) (err error) {
+	h.sampleRateDiv = div
+	return h.writeRegister(MPU6050_RA_SMPLRT_DIV, div)
+}
+
+// SetGyroRange sets the full scale range of the gyroscope, one of the
+// MPU6050_GYRO_FS constants
+func (h *MPU6050Driver) SetGyroRange(fs byte) (err error) {
+	h.gyroRange = fs & 0x03
+	return h.writeRegister(MPU6050_RA_GYRO_CONFIG, h.gyroRange<<3)
+}
+
+// SetAccelRange sets the full scale range of the accelerometer, one of the
+// MPU6050_ACCEL_FS constants
+func (h *MPU6050Driver) SetAccelRange(fs byte) (err error) {
+	h.accelRange = fs & 0x03
+	return h.writeRegister(MPU6050_RA_ACCEL_CONFIG, h.accelRange<<3)
+}
+
+// GyroscopeScale returns the sensitivity of the gyroscope in LSB per deg/s
+func (h *MPU6050Driver) GyroscopeScale() float64 {
+	return 131.0 / float64(int(1)<<h.gyroRange)
+}
+
+// AccelerometerScale returns the sensitivity of the accelerometer in LSB per g
+func (h *MPU6050Driver) AccelerometerScale() float64 {
+	return 16384.0 / float64(int(1)<<h.accelRange)
+}
+
+// ScaleGyroscope converts raw gyroscope data to deg/s
+func (h *MPU6050Driver) ScaleGyroscope(d ThreeDData) ScaledThreeDData {
+	scale := h.GyroscopeScale()
+	return ScaledThreeDData{
+		X: float64(d.X) / scale,
+		Y: float64(d.Y) / scale,
+		Z: float64(d.Z) / scale,
+	}
+}
+
+// ScaleAccelerometer converts raw accelerometer data to g
+func (h *MPU6050Driver) ScaleAccelerometer(d ThreeDData) ScaledThreeDData {
+	scale := h.AccelerometerScale()
+	return ScaledThreeDData{
+		X: float64(d.X) / scale,
+		Y: float64(d.Y) / scale,
+		Z: float64(d.Z) / scale,
+	}
+}
+
+// EnableFIFO resets the FIFO and starts buffering accelerometer and gyroscope
+// samples in it at the sample rate
+func (h *MPU6050Driver) EnableFIFO() (err error) {
+	if err = h.writeRegister(MPU6050_RA_USER_CTRL, MPU6050_USERCTRL_FIFO_RESET); err != nil {
+		return
+	}
+	if err = h.writeRegister(MPU6050_RA_FIFO_EN, MPU6050_FIFO_EN_ACCEL_GYRO); err != nil {
+		return
+	}
+	return h.writeRegister(MPU6050_RA_USER_CTRL, MPU6050_USERCTRL_FIFO_EN)
+}
+
+// DisableFIFO stops buffering samples in the FIFO
+func (h *MPU6050Driver) DisableFIFO() (err error) {
+	if err = h.writeRegister(MPU6050_RA_FIFO_EN, 0); err != nil {
 		return
 	}
+	return h.writeRegister(MPU6050_RA_USER_CTRL, 0)
+}
 
-	// setFullScaleGyroRange
-  if err = h.connection.I2cWrite(mpu6050Address, []byte{MPU6050_RA_GYRO_CONFIG,
-    MPU6050_GCONFIG_FS_SEL_BIT,
-    MPU6050_GCONFIG_FS_SEL_LENGTH,
-    MPU6050_GYRO_FS_250}); err != nil {
-    return
-  }
+// FIFOCount returns the number of bytes in the FIFO
+func (h *MPU6050Driver) FIFOCount() (count int, err error) {
+	ret, err := h.readRegister(MPU6050_RA_FIFO_COUNTH, 2)
+	if err != nil {
+		return
+	}
+	return int(ret[0])<<8 | int(ret[1]), nil
+}
 
-	// setFullScaleAccelRange
-	if err = h.connection.I2cWrite(mpu6050Address, []byte{MPU6050_RA_ACCEL_CONFIG,
-		MPU6050_ACONFIG_AFS_SEL_BIT,
-		MPU6050_ACONFIG_AFS_SEL_LENGTH,
-		MPU6050_ACCEL_FS_2}); err != nil {
+// ReadFIFO returns the complete samples buffered in the FIFO. A full FIFO has
+// overflowed and lost samples, it is reset and ErrFIFOOverflow is returned.
+func (h *MPU6050Driver) ReadFIFO() (samples []MPU6050Sample, err error) {
+	count, err := h.FIFOCount()
+	if err != nil {
 		return
 	}
+	if count >= mpu6050FIFOSize {
+		if err = h.EnableFIFO(); err != nil {
+			return
+		}
+		return nil, ErrFIFOOverflow
+	}
 
-	// setSleepEnabled
-	if err = h.connection.I2cWrite(mpu6050Address, []byte{MPU6050_RA_PWR_MGMT_1,
-		MPU6050_PWR1_ENABLE_BIT,
-		0}); err != nil {
+	n := count / mpu6050FIFOSampleSize
+	if n == 0 {
 		return
 	}
+	ret, err := h.readRegister(MPU6050_RA_FIFO_R_W, n*mpu6050FIFOSampleSize)
+	if err != nil {
+		return
+	}
+	buf := bytes.NewBuffer(ret)
+	samples = make([]MPU6050Sample, n)
+	for i := range samples {
+		binary.Read(buf, binary.BigEndian, &samples[i].Accelerometer)
+		binary.Read(buf, binary.BigEndian, &samples[i].Gyroscope)
+	}
+	return
+}
+
+func (h *MPU6050Driver) writeRegister(register byte, val byte) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.connection.I2cWrite(mpu6050Address, []byte{register, val})
+}
 
-	return nil
+func (h *MPU6050Driver) readRegister(register byte, n int) (ret []byte, err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if err = h.connection.I2cWrite(mpu6050Address, []byte{register}); err != nil {
+		return
+	}
+	if ret, err = h.connection.I2cRead(mpu6050Address, n); err != nil {
+		return
+	}
+	if len(ret) < n {
+		return nil, ErrNotEnoughBytes
+	}
+	return
 }
 
 // The temperature sensor is -40 to +85 degrees Celsius.
@@ -1,9 +1,11 @@
 package i2c
 
 import (
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/gobottest"
 )
 
@@ -51,3 +53,116 @@ func TestMPU6050DriverHalt(t *testing.T) {
 
 	gobottest.Assert(t, len(mpu.Halt()), 0)
 }
+
+func TestMPU6050DriverInitialize(t *testing.T) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	mpu := NewMPU6050Driver(adaptor, "bot")
+
+	gobottest.Assert(t, mpu.initialize(), nil)
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_PWR_MGMT_1], byte(MPU6050_CLOCK_PLL_XGYRO))
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_CONFIG], byte(MPU6050_DLPF_BW_256))
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_GYRO_CONFIG], byte(0x00))
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_ACCEL_CONFIG], byte(0x00))
+
+	adaptor.i2cWriteImpl = func() error {
+		return errors.New("write error")
+	}
+	gobottest.Assert(t, mpu.initialize(), errors.New("write error"))
+}
+
+func TestMPU6050DriverConfiguration(t *testing.T) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	mpu := NewMPU6050Driver(adaptor, "bot")
+
+	gobottest.Assert(t, mpu.SetDLPF(MPU6050_DLPF_BW_42), nil)
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_CONFIG], byte(0x03))
+	gobottest.Assert(t, mpu.SetSampleRateDivider(9), nil)
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_SMPLRT_DIV], byte(9))
+
+	gobottest.Assert(t, mpu.GyroscopeScale(), 131.0)
+	gobottest.Assert(t, mpu.SetGyroRange(MPU6050_GYRO_FS_2000), nil)
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_GYRO_CONFIG], byte(0x18))
+	gobottest.Assert(t, mpu.GyroscopeScale(), 16.375)
+
+	gobottest.Assert(t, mpu.AccelerometerScale(), 16384.0)
+	gobottest.Assert(t, mpu.SetAccelRange(MPU6050_ACCEL_FS_8), nil)
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_ACCEL_CONFIG], byte(0x10))
+	gobottest.Assert(t, mpu.AccelerometerScale(), 4096.0)
+
+	gobottest.Assert(t, mpu.ScaleAccelerometer(ThreeDData{X: 4096, Y: -2048, Z: 0}),
+		ScaledThreeDData{X: 1, Y: -0.5, Z: 0})
+	gobottest.Assert(t, mpu.ScaleGyroscope(ThreeDData{X: 131, Y: 0, Z: -1310}),
+		ScaledThreeDData{X: 8, Y: 0, Z: -80})
+}
+
+func TestMPU6050DriverFIFO(t *testing.T) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	mpu := NewMPU6050Driver(adaptor, "bot")
+
+	gobottest.Assert(t, mpu.EnableFIFO(), nil)
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_FIFO_EN], byte(0x78))
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_USER_CTRL], byte(0x40))
+	gobottest.Assert(t, adaptor.written[0], []byte{MPU6050_RA_USER_CTRL, 0x04})
+
+	// two samples and part of a third
+	adaptor.setRegisters(MPU6050_RA_FIFO_COUNTH, []byte{0x00, 30})
+	adaptor.setRegisters(MPU6050_RA_FIFO_R_W, []byte{
+		0x40, 0x00, 0x00, 0x01, 0xFF, 0xFF, 0x00, 0x83, 0x00, 0x00, 0xFF, 0x7D,
+		0x00, 0x02, 0x00, 0x03, 0x00, 0x04, 0x00, 0x05, 0x00, 0x06, 0x00, 0x07,
+	})
+
+	count, err := mpu.FIFOCount()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, count, 30)
+
+	samples, err := mpu.ReadFIFO()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, samples, []MPU6050Sample{
+		{
+			Accelerometer: ThreeDData{X: 16384, Y: 1, Z: -1},
+			Gyroscope:     ThreeDData{X: 131, Y: 0, Z: -131},
+		},
+		{
+			Accelerometer: ThreeDData{X: 2, Y: 3, Z: 4},
+			Gyroscope:     ThreeDData{X: 5, Y: 6, Z: 7},
+		},
+	})
+
+	adaptor.setRegisters(MPU6050_RA_FIFO_COUNTH, []byte{0x00, 6})
+	samples, err = mpu.ReadFIFO()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, len(samples), 0)
+
+	adaptor.setRegisters(MPU6050_RA_FIFO_COUNTH, []byte{0x04, 0x00})
+	_, err = mpu.ReadFIFO()
+	gobottest.Assert(t, err, ErrFIFOOverflow)
+
+	gobottest.Assert(t, mpu.DisableFIFO(), nil)
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_FIFO_EN], byte(0x00))
+	gobottest.Assert(t, adaptor.registers[MPU6050_RA_USER_CTRL], byte(0x00))
+}
+
+func TestMPU6050DriverData(t *testing.T) {
+	sem := make(chan bool)
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	adaptor.setRegisters(MPU6050_RA_ACCEL_XOUT_H, []byte{
+		0x00, 0x01, 0x00, 0x02, 0x40, 0x00, 0x00, 0x00, 0x00, 0x04, 0x00, 0x05, 0x00, 0x06,
+	})
+	mpu := NewMPU6050Driver(adaptor, "bot")
+
+	gobot.Once(mpu.Event(Data), func(data interface{}) {
+		gobottest.Assert(t, data, MPU6050Sample{
+			Accelerometer: ThreeDData{X: 1, Y: 2, Z: 16384},
+			Gyroscope:     ThreeDData{X: 4, Y: 5, Z: 6},
+		})
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(mpu.Start()), 0)
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("MPU6050 Event \"Data\" was not published")
+	}
+}
@@ -0,0 +1,143 @@
+package i2c
+
+import "math"
+
+const degreesPerRadian = 180.0 / math.Pi
+
+// EulerAngles is an orientation in degrees. Roll is the rotation about the x
+// axis, pitch about the y axis and yaw about the z axis, following the right
+// hand rule.
+type EulerAngles struct {
+	Roll  float64
+	Pitch float64
+	Yaw   float64
+}
+
+// OrientationFilter fuses gyroscope and accelerometer readings into an
+// orientation
+type OrientationFilter interface {
+	// Update advances the filter by dt seconds given the gyroscope rates in
+	// deg/s and the accelerometer readings in g
+	Update(gyro ScaledThreeDData, accel ScaledThreeDData, dt float64) EulerAngles
+}
+
+// ComplementaryFilter integrates the gyroscope and corrects its drift in
+// roll and pitch with the direction of gravity measured by the accelerometer.
+// Yaw is unobservable from gravity and drifts with the gyroscope bias.
+type ComplementaryFilter struct {
+	// Alpha is the weight of the gyroscope, between 0 and 1. The time constant
+	// of the filter is dt * Alpha / (1 - Alpha).
+	Alpha       float64
+	orientation EulerAngles
+	initialized bool
+}
+
+// NewComplementaryFilter returns a new ComplementaryFilter weighing the
+// gyroscope by alpha
+func NewComplementaryFilter(alpha float64) *ComplementaryFilter {
+	return &ComplementaryFilter{Alpha: alpha}
+}
+
+// Update advances the filter by dt seconds
+func (f *ComplementaryFilter) Update(gyro ScaledThreeDData, accel ScaledThreeDData, dt float64) EulerAngles {
+	roll, pitch := accelerometerAngles(accel)
+	if !f.initialized {
+		f.orientation.Roll = roll
+		f.orientation.Pitch = pitch
+		f.initialized = true
+		return f.orientation
+	}
+
+	f.orientation.Roll = wrapAngle(roll + f.Alpha*wrapAngle(f.orientation.Roll+gyro.X*dt-roll))
+	f.orientation.Pitch = wrapAngle(pitch + f.Alpha*wrapAngle(f.orientation.Pitch+gyro.Y*dt-pitch))
+	f.orientation.Yaw = wrapAngle(f.orientation.Yaw + gyro.Z*dt)
+	return f.orientation
+}
+
+// MadgwickFilter is the gradient descent orientation filter of Sebastian
+// Madgwick, which tracks the orientation as a quaternion and corrects the
+// gyroscope towards the direction of gravity measured by the accelerometer.
+type MadgwickFilter struct {
+	// Beta is the gain of the accelerometer correction, higher values
+	// converge faster but follow accelerometer noise
+	Beta float64
+	q    [4]float64
+}
+
+// NewMadgwickFilter returns a new MadgwickFilter with gain beta
+func NewMadgwickFilter(beta float64) *MadgwickFilter {
+	return &MadgwickFilter{Beta: beta, q: [4]float64{1, 0, 0, 0}}
+}
+
+// Update advances the filter by dt seconds
+func (f *MadgwickFilter) Update(gyro ScaledThreeDData, accel ScaledThreeDData, dt float64) EulerAngles {
+	q0, q1, q2, q3 := f.q[0], f.q[1], f.q[2], f.q[3]
+	gx, gy, gz := gyro.X/degreesPerRadian, gyro.Y/degreesPerRadian, gyro.Z/degreesPerRadian
+
+	// rate of change of the quaternion from the gyroscope
+	qDot0 := 0.5 * (-q1*gx - q2*gy - q3*gz)
+	qDot1 := 0.5 * (q0*gx + q2*gz - q3*gy)
+	qDot2 := 0.5 * (q0*gy - q1*gz + q3*gx)
+	qDot3 := 0.5 * (q0*gz + q1*gy - q2*gx)
+
+	// the accelerometer is ignored when it measures free fall
+	if norm := math.Sqrt(accel.X*accel.X + accel.Y*accel.Y + accel.Z*accel.Z); norm > 0 {
+		ax, ay, az := accel.X/norm, accel.Y/norm, accel.Z/norm
+
+		// gradient of the objective function
+		s0 := 4*q0*q2*q2 + 2*q2*ax + 4*q0*q1*q1 - 2*q1*ay
+		s1 := 4*q1*q3*q3 - 2*q3*ax + 4*q0*q0*q1 - 2*q0*ay - 4*q1 + 8*q1*q1*q1 + 8*q1*q2*q2 + 4*q1*az
+		s2 := 4*q0*q0*q2 + 2*q0*ax + 4*q2*q3*q3 - 2*q3*ay - 4*q2 + 8*q2*q1*q1 + 8*q2*q2*q2 + 4*q2*az
+		s3 := 4*q1*q1*q3 - 2*q1*ax + 4*q2*q2*q3 - 2*q2*ay
+		if n := math.Sqrt(s0*s0 + s1*s1 + s2*s2 + s3*s3); n > 0 {
+			qDot0 -= f.Beta * s0 / n
+			qDot1 -= f.Beta * s1 / n
+			qDot2 -= f.Beta * s2 / n
+			qDot3 -= f.Beta * s3 / n
+		}
+	}
+
+	q0 += qDot0 * dt
+	q1 += qDot1 * dt
+	q2 += qDot2 * dt
+	q3 += qDot3 * dt
+	n := math.Sqrt(q0*q0 + q1*q1 + q2*q2 + q3*q3)
+	f.q = [4]float64{q0 / n, q1 / n, q2 / n, q3 / n}
+
+	return f.EulerAngles()
+}
+
+// EulerAngles returns the orientation of the quaternion as euler angles
+func (f *MadgwickFilter) EulerAngles() EulerAngles {
+	q0, q1, q2, q3 := f.q[0], f.q[1], f.q[2], f.q[3]
+	sinPitch := 2 * (q0*q2 - q3*q1)
+	if sinPitch > 1 {
+		sinPitch = 1
+	} else if sinPitch < -1 {
+		sinPitch = -1
+	}
+	return EulerAngles{
+		Roll:  math.Atan2(2*(q0*q1+q2*q3), 1-2*(q1*q1+q2*q2)) * degreesPerRadian,
+		Pitch: math.Asin(sinPitch) * degreesPerRadian,
+		Yaw:   math.Atan2(2*(q0*q3+q1*q2), 1-2*(q2*q2+q3*q3)) * degreesPerRadian,
+	}
+}
+
+// accelerometerAngles returns the roll and pitch in degrees of the direction
+// of gravity
+func accelerometerAngles(accel ScaledThreeDData) (roll float64, pitch float64) {
+	roll = math.Atan2(accel.Y, accel.Z) * degreesPerRadian
+	pitch = math.Atan2(-accel.X, math.Sqrt(accel.Y*accel.Y+accel.Z*accel.Z)) * degreesPerRadian
+	return
+}
+
+// wrapAngle wraps an angle in degrees to (-180, 180]
+func wrapAngle(angle float64) float64 {
+	angle = math.Mod(angle, 360)
+	if angle > 180 {
+		angle -= 360
+	} else if angle <= -180 {
+		angle += 360
+	}
+	return angle
+}
@@ -0,0 +1,253 @@
+package i2c
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var _ gobot.Driver = (*OrientationDriver)(nil)
+
+// compassGain is the fraction of the difference between the compass heading
+// and the fused yaw corrected every sample
+const compassGain = 0.02
+
+// OrientationDriver fuses the samples of a MPU6050Driver, and optionally the
+// heading of a HMC6352Driver, into roll, pitch and yaw
+type OrientationDriver struct {
+	name               string
+	imu                *MPU6050Driver
+	compass            *HMC6352Driver
+	filter             OrientationFilter
+	interval           time.Duration
+	calibrationSamples int
+	calibrationCount   int
+	calibrationSum     ScaledThreeDData
+	gyroBias           ScaledThreeDData
+	heading            float64
+	hasHeading         bool
+	yawOffset          float64
+	orientation        EulerAngles
+	last               time.Time
+	running            bool
+	unsubscribe        func()
+	halt               chan bool
+	mutex              sync.Mutex
+	gobot.Eventer
+	gobot.Commander
+}
+
+// NewOrientationDriver creates a new driver with specified name fusing the
+// samples of imu with a ComplementaryFilter. The gyroscope bias is calibrated
+// from the first 100 samples, during which the sensor must be kept still.
+//
+// Optionally accepts:
+// 	time.Duration: Interval at which the compass is read
+//
+// Adds the following API Commands:
+// 	"Orientation" - See OrientationDriver.Orientation
+// 	"Calibrate" - See OrientationDriver.Calibrate
+func NewOrientationDriver(imu *MPU6050Driver, name string, v ...time.Duration) *OrientationDriver {
+	d := &OrientationDriver{
+		name:               name,
+		imu:                imu,
+		filter:             NewComplementaryFilter(0.98),
+		interval:           100 * time.Millisecond,
+		calibrationSamples: 100,
+		Eventer:            gobot.NewEventer(),
+		Commander:          gobot.NewCommander(),
+	}
+
+	if len(v) > 0 {
+		d.interval = v[0]
+	}
+
+	d.AddEvent(Orientation)
+	d.AddEvent(Calibrated)
+	d.AddEvent(Error)
+
+	d.AddCommand("Orientation", func(params map[string]interface{}) interface{} {
+		return d.Orientation()
+	})
+	d.AddCommand("Calibrate", func(params map[string]interface{}) interface{} {
+		samples, _ := params["samples"].(float64)
+		d.Calibrate(int(samples))
+		return nil
+	})
+
+	return d
+}
+
+func (d *OrientationDriver) Name() string                 { return d.name }
+func (d *OrientationDriver) Connection() gobot.Connection { return d.imu.Connection() }
+
+// SetFilter sets the filter fusing the gyroscope and accelerometer
+func (d *OrientationDriver) SetFilter(filter OrientationFilter) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.filter = filter
+}
+
+// SetCompass sets a compass which corrects the drift of the yaw. The compass
+// heading is clockwise, so the sensors must be mounted with their z axes
+// pointing up.
+func (d *OrientationDriver) SetCompass(compass *HMC6352Driver) {
+	d.compass = compass
+}
+
+// Start subscribes to the samples of the imu and reads the compass at the
+// given interval.
+// Emits the Events:
+//	Calibrated ScaledThreeDData - Event is emitted with the gyroscope bias in deg/s once calibrated.
+//	Orientation EulerAngles - Event is emitted every sample once calibrated.
+//	Error error - Event is emitted on error reading from the compass.
+func (d *OrientationDriver) Start() (errs []error) {
+	unsubscribe, err := gobot.Subscribe(d.imu.Event(Data), func(data interface{}) {
+		d.update(data.(MPU6050Sample))
+	})
+	if err != nil {
+		return []error{err}
+	}
+
+	d.mutex.Lock()
+	d.running = true
+	d.last = time.Time{}
+	d.unsubscribe = unsubscribe
+	halt := make(chan bool)
+	d.halt = halt
+	d.mutex.Unlock()
+
+	if d.compass != nil {
+		go func() {
+			for {
+				heading, err := d.compass.Heading()
+				if err != nil {
+					gobot.Publish(d.Event(Error), err)
+				} else {
+					d.mutex.Lock()
+					d.heading = float64(heading)
+					d.hasHeading = true
+					d.mutex.Unlock()
+				}
+				select {
+				case <-time.After(d.interval):
+				case <-halt:
+					return
+				}
+			}
+		}()
+	}
+	return
+}
+
+// Halt stops fusing samples
+func (d *OrientationDriver) Halt() (errs []error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.running = false
+	if d.unsubscribe != nil {
+		d.unsubscribe()
+		d.unsubscribe = nil
+	}
+	if d.halt != nil {
+		close(d.halt)
+		d.halt = nil
+	}
+	return
+}
+
+// Calibrate restarts the gyroscope bias calibration over the next samples,
+// during which the sensor must be kept still
+func (d *OrientationDriver) Calibrate(samples int) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if samples < 1 {
+		samples = 1
+	}
+	d.calibrationSamples = samples
+	d.calibrationCount = 0
+	d.calibrationSum = ScaledThreeDData{}
+}
+
+// Calibrating returns true until the gyroscope bias has been calibrated
+func (d *OrientationDriver) Calibrating() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.calibrationCount < d.calibrationSamples
+}
+
+// GyroscopeBias returns the calibrated gyroscope bias in deg/s
+func (d *OrientationDriver) GyroscopeBias() ScaledThreeDData {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.gyroBias
+}
+
+// Orientation returns the last fused orientation
+func (d *OrientationDriver) Orientation() EulerAngles {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.orientation
+}
+
+func (d *OrientationDriver) update(sample MPU6050Sample) {
+	now := time.Now()
+	d.mutex.Lock()
+	if !d.running {
+		d.mutex.Unlock()
+		return
+	}
+	dt := 0.0
+	if !d.last.IsZero() {
+		dt = now.Sub(d.last).Seconds()
+	}
+	d.last = now
+	d.mutex.Unlock()
+
+	d.fuse(sample, dt)
+}
+
+// fuse calibrates the gyroscope bias or fuses sample, dt seconds after the
+// previous sample
+func (d *OrientationDriver) fuse(sample MPU6050Sample, dt float64) {
+	gyro := d.imu.ScaleGyroscope(sample.Gyroscope)
+	accel := d.imu.ScaleAccelerometer(sample.Accelerometer)
+
+	d.mutex.Lock()
+	if d.calibrationCount < d.calibrationSamples {
+		d.calibrationSum.X += gyro.X
+		d.calibrationSum.Y += gyro.Y
+		d.calibrationSum.Z += gyro.Z
+		d.calibrationCount++
+		if d.calibrationCount < d.calibrationSamples {
+			d.mutex.Unlock()
+			return
+		}
+		n := float64(d.calibrationSamples)
+		d.gyroBias = ScaledThreeDData{
+			X: d.calibrationSum.X / n,
+			Y: d.calibrationSum.Y / n,
+			Z: d.calibrationSum.Z / n,
+		}
+		bias := d.gyroBias
+		d.mutex.Unlock()
+		gobot.Publish(d.Event(Calibrated), bias)
+		return
+	}
+
+	gyro.X -= d.gyroBias.X
+	gyro.Y -= d.gyroBias.Y
+	gyro.Z -= d.gyroBias.Z
+	o := d.filter.Update(gyro, accel, dt)
+
+	if d.hasHeading {
+		// the heading is clockwise while yaw follows the right hand rule
+		d.yawOffset += compassGain * wrapAngle(-d.heading-(o.Yaw+d.yawOffset))
+	}
+	o.Yaw = wrapAngle(o.Yaw + d.yawOffset)
+	d.orientation = o
+	d.mutex.Unlock()
+
+	gobot.Publish(d.Event(Orientation), o)
+}
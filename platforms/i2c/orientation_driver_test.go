@@ -0,0 +1,217 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// --------- HELPERS
+func initTestOrientationDriver() *OrientationDriver {
+	return NewOrientationDriver(NewMPU6050Driver(newI2cTestAdaptor("adaptor"), "mpu"), "bot")
+}
+
+// a level sample rotating 10 deg/s about the z axis, with a gyroscope bias of
+// 1 deg/s about the x axis
+var orientationSample = MPU6050Sample{
+	Accelerometer: ThreeDData{Z: 16384},
+	Gyroscope:     ThreeDData{X: 131, Z: 1310},
+}
+
+// --------- TESTS
+
+func TestOrientationDriver(t *testing.T) {
+	d := initTestOrientationDriver()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.interval, 100*time.Millisecond)
+	gobottest.Assert(t, d.Calibrating(), true)
+	gobottest.Refute(t, d.Command("Orientation"), nil)
+	gobottest.Refute(t, d.Command("Calibrate"), nil)
+
+	d = NewOrientationDriver(NewMPU6050Driver(newI2cTestAdaptor("adaptor"), "mpu"), "bot", 10*time.Millisecond)
+	gobottest.Assert(t, d.interval, 10*time.Millisecond)
+}
+
+func TestOrientationDriverCalibrate(t *testing.T) {
+	sem := make(chan bool)
+	d := initTestOrientationDriver()
+	d.Calibrate(2)
+
+	gobot.Once(d.Event(Calibrated), func(data interface{}) {
+		gobottest.Assert(t, data, ScaledThreeDData{X: 1, Y: 0.5})
+		sem <- true
+	})
+
+	d.fuse(MPU6050Sample{Gyroscope: ThreeDData{X: 131}}, 0)
+	gobottest.Assert(t, d.Calibrating(), true)
+	d.fuse(MPU6050Sample{Gyroscope: ThreeDData{X: 131, Y: 131}}, 0.01)
+	gobottest.Assert(t, d.Calibrating(), false)
+	gobottest.Assert(t, d.GyroscopeBias(), ScaledThreeDData{X: 1, Y: 0.5})
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("Orientation Event \"Calibrated\" was not published")
+	}
+
+	d.Command("Calibrate")(map[string]interface{}{"samples": 5.0})
+	gobottest.Assert(t, d.Calibrating(), true)
+	gobottest.Assert(t, d.calibrationSamples, 5)
+}
+
+func TestOrientationDriverFuse(t *testing.T) {
+	sem := make(chan bool)
+	d := initTestOrientationDriver()
+	d.Calibrate(1)
+	d.fuse(MPU6050Sample{Accelerometer: ThreeDData{Z: 16384}, Gyroscope: ThreeDData{X: 131}}, 0)
+
+	gobot.Once(d.Event(Orientation), func(data interface{}) {
+		gobottest.Assert(t, roundAngles(data.(EulerAngles)), EulerAngles{})
+		sem <- true
+	})
+
+	d.fuse(orientationSample, 0)
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("Orientation Event \"Orientation\" was not published")
+	}
+
+	for i := 0; i < 100; i++ {
+		d.fuse(orientationSample, 0.01)
+	}
+	gobottest.Assert(t, roundAngles(d.Orientation()), EulerAngles{Yaw: 10})
+	gobottest.Assert(t, roundAngles(d.Command("Orientation")(nil).(EulerAngles)), EulerAngles{Yaw: 10})
+}
+
+func TestOrientationDriverCompass(t *testing.T) {
+	d := initTestOrientationDriver()
+	d.SetFilter(NewMadgwickFilter(0.1))
+	d.Calibrate(1)
+	d.fuse(MPU6050Sample{Accelerometer: ThreeDData{Z: 16384}}, 0)
+
+	// a heading of 90 degrees clockwise is a yaw of -90 degrees
+	d.heading = 90
+	d.hasHeading = true
+	for i := 0; i < 1000; i++ {
+		d.fuse(MPU6050Sample{Accelerometer: ThreeDData{Z: 16384}}, 0.01)
+	}
+	gobottest.Assert(t, roundAngles(d.Orientation()), EulerAngles{Yaw: -90})
+}
+
+func TestOrientationDriverStart(t *testing.T) {
+	sem := make(chan bool)
+	mpuAdaptor := newI2cRegisterTestAdaptor("adaptor")
+	mpu := NewMPU6050Driver(mpuAdaptor, "mpu")
+	compassAdaptor := newI2cTestAdaptor("adaptor")
+	compassAdaptor.i2cReadImpl = func() ([]byte, error) {
+		return []byte{0x03, 0x84}, nil
+	}
+	d := NewOrientationDriver(mpu, "bot", 10*time.Millisecond)
+	d.SetCompass(NewHMC6352Driver(compassAdaptor, "compass"))
+	d.Calibrate(1)
+
+	gobot.Once(d.Event(Orientation), func(data interface{}) {
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	gobot.Publish(mpu.Event(Data), MPU6050Sample{Accelerometer: ThreeDData{Z: 16384}})
+	<-time.After(10 * time.Millisecond)
+	gobot.Publish(mpu.Event(Data), MPU6050Sample{Accelerometer: ThreeDData{Z: 16384}})
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("Orientation Event \"Orientation\" was not published")
+	}
+
+	d.mutex.Lock()
+	gobottest.Assert(t, d.heading, 90.0)
+	d.mutex.Unlock()
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+
+	// samples are ignored once halted
+	before := d.Orientation()
+	d.update(MPU6050Sample{Accelerometer: ThreeDData{Y: 16384}})
+	gobottest.Assert(t, d.Orientation(), before)
+}
+
+func TestOrientationDriverRestart(t *testing.T) {
+	d := initTestOrientationDriver()
+	d.Calibrate(10)
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	gobottest.Assert(t, len(d.Halt()), 0)
+	gobottest.Assert(t, len(d.Start()), 0)
+	defer d.Halt()
+
+	d.mutex.Lock()
+	gobottest.Assert(t, d.last.IsZero(), true)
+	d.mutex.Unlock()
+
+	// each sample is fused once after a restart
+	gobot.Publish(d.imu.Event(Data), orientationSample)
+	<-time.After(20 * time.Millisecond)
+	d.mutex.Lock()
+	gobottest.Assert(t, d.calibrationCount, 1)
+	d.last = time.Now().Add(-1 * time.Hour)
+	d.mutex.Unlock()
+
+	// the downtime is not fused as the first interval after a restart
+	gobottest.Assert(t, len(d.Halt()), 0)
+	gobottest.Assert(t, len(d.Start()), 0)
+	d.mutex.Lock()
+	gobottest.Assert(t, d.last.IsZero(), true)
+	d.mutex.Unlock()
+}
+
+func TestOrientationDriverCompassError(t *testing.T) {
+	sem := make(chan bool)
+	compassAdaptor := newI2cTestAdaptor("adaptor")
+	compassAdaptor.i2cReadImpl = func() ([]byte, error) {
+		return nil, errors.New("read error")
+	}
+	d := initTestOrientationDriver()
+	d.SetCompass(NewHMC6352Driver(compassAdaptor, "compass"))
+
+	gobot.Once(d.Event(Error), func(data interface{}) {
+		gobottest.Assert(t, data, errors.New("read error"))
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("Orientation Event \"Error\" was not published")
+	}
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+	// halting again does not block
+	gobottest.Assert(t, len(d.Halt()), 0)
+}
+
+func TestOrientationDriverHaltNotStarted(t *testing.T) {
+	d := initTestOrientationDriver()
+	d.SetCompass(NewHMC6352Driver(newI2cTestAdaptor("adaptor"), "compass"))
+
+	done := make(chan bool)
+	go func() {
+		d.Halt()
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(1 * time.Second):
+		t.Errorf("Halt blocked on a driver which was not started")
+	}
+}
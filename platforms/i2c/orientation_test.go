@@ -0,0 +1,92 @@
+package i2c
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func roundAngles(o EulerAngles) EulerAngles {
+	round := func(v float64) float64 { return math.Floor(v*10+0.5) / 10 }
+	return EulerAngles{Roll: round(o.Roll), Pitch: round(o.Pitch), Yaw: round(o.Yaw)}
+}
+
+func TestAccelerometerAngles(t *testing.T) {
+	roll, pitch := accelerometerAngles(ScaledThreeDData{Z: 1})
+	gobottest.Assert(t, roll, 0.0)
+	gobottest.Assert(t, pitch, 0.0)
+
+	roll, _ = accelerometerAngles(ScaledThreeDData{Y: 1, Z: 1})
+	gobottest.Assert(t, math.Floor(roll+0.5), 45.0)
+
+	_, pitch = accelerometerAngles(ScaledThreeDData{X: -1})
+	gobottest.Assert(t, pitch, 90.0)
+}
+
+func TestWrapAngle(t *testing.T) {
+	gobottest.Assert(t, wrapAngle(0), 0.0)
+	gobottest.Assert(t, wrapAngle(180), 180.0)
+	gobottest.Assert(t, wrapAngle(-180), 180.0)
+	gobottest.Assert(t, wrapAngle(190), -170.0)
+	gobottest.Assert(t, wrapAngle(-190), 170.0)
+	gobottest.Assert(t, wrapAngle(730), 10.0)
+}
+
+func TestComplementaryFilter(t *testing.T) {
+	f := NewComplementaryFilter(0.98)
+	level := ScaledThreeDData{Z: 1}
+
+	// the first sample is taken from the accelerometer
+	o := f.Update(ScaledThreeDData{}, ScaledThreeDData{Y: 1, Z: 1}, 0)
+	gobottest.Assert(t, roundAngles(o), EulerAngles{Roll: 45})
+
+	// converges to the accelerometer
+	for i := 0; i < 500; i++ {
+		o = f.Update(ScaledThreeDData{}, level, 0.01)
+	}
+	gobottest.Assert(t, roundAngles(o), EulerAngles{})
+
+	// integrates the gyroscope
+	for i := 0; i < 100; i++ {
+		o = f.Update(ScaledThreeDData{Z: 90}, level, 0.01)
+	}
+	gobottest.Assert(t, roundAngles(o), EulerAngles{Yaw: 90})
+
+	// short term rotation is tracked by the gyroscope
+	f = NewComplementaryFilter(0.98)
+	f.Update(ScaledThreeDData{}, level, 0)
+	o = f.Update(ScaledThreeDData{X: 100}, level, 0.1)
+	gobottest.Assert(t, roundAngles(o), EulerAngles{Roll: 9.8})
+}
+
+func TestMadgwickFilter(t *testing.T) {
+	f := NewMadgwickFilter(0.5)
+	gobottest.Assert(t, f.EulerAngles(), EulerAngles{})
+
+	// converges to the accelerometer, within the step of the gradient descent
+	var o EulerAngles
+	for i := 0; i < 2000; i++ {
+		o = f.Update(ScaledThreeDData{}, ScaledThreeDData{Y: 1, Z: 1}, 0.01)
+	}
+	gobottest.Assert(t, math.Abs(o.Roll-45) < 0.5, true)
+	gobottest.Assert(t, math.Abs(o.Pitch) < 0.5, true)
+
+	f = NewMadgwickFilter(0.5)
+	for i := 0; i < 2000; i++ {
+		o = f.Update(ScaledThreeDData{}, ScaledThreeDData{X: -1, Z: 1}, 0.01)
+	}
+	gobottest.Assert(t, math.Abs(o.Roll) < 0.5, true)
+	gobottest.Assert(t, math.Abs(o.Pitch-45) < 0.5, true)
+
+	// integrates the gyroscope
+	f = NewMadgwickFilter(0.1)
+	for i := 0; i < 100; i++ {
+		o = f.Update(ScaledThreeDData{Z: 90}, ScaledThreeDData{Z: 1}, 0.01)
+	}
+	gobottest.Assert(t, roundAngles(o), EulerAngles{Yaw: 90})
+
+	// free fall is ignored
+	o = f.Update(ScaledThreeDData{}, ScaledThreeDData{}, 0.01)
+	gobottest.Assert(t, roundAngles(o), EulerAngles{Yaw: 90})
+}
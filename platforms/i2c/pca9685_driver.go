@@ -0,0 +1,259 @@
+package i2c
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/gpio"
+)
+
+var _ gobot.Driver = (*PCA9685Driver)(nil)
+
+var _ gpio.DigitalWriter = (*PCA9685Driver)(nil)
+var _ gpio.PwmWriter = (*PCA9685Driver)(nil)
+var _ gpio.ServoWriter = (*PCA9685Driver)(nil)
+
+// PCA9685Address is the i2c address of a PCA9685 with all address pins low
+const PCA9685Address = 0x40
+
+const PCA9685_REGISTER_MODE1 = 0x00
+const PCA9685_REGISTER_MODE2 = 0x01
+const PCA9685_REGISTER_LED0_ON_L = 0x06
+const PCA9685_REGISTER_ALL_LED_ON_L = 0xFA
+const PCA9685_REGISTER_PRESCALE = 0xFE
+const PCA9685_MODE1_RESTART = 0x80
+const PCA9685_MODE1_AI = 0x20
+const PCA9685_MODE1_SLEEP = 0x10
+const PCA9685_MODE2_OUTDRV = 0x04
+const PCA9685_FULL = 0x1000
+
+// pca9685Oscillator is the frequency of the internal oscillator
+const pca9685Oscillator = 25000000.0
+
+// ErrInvalidChannel is the error resulting when a PCA9685 channel is not
+// between 0 and 15
+var ErrInvalidChannel = errors.New("Invalid channel, must be between 0 and 15")
+
+// ErrInvalidFrequency is the error resulting when a PCA9685 PWM frequency is
+// not between 24Hz and 1526Hz
+var ErrInvalidFrequency = errors.New("Invalid frequency, must be between 24Hz and 1526Hz")
+
+// PCA9685Driver is the gobot driver for the NXP PCA9685 16 channel, 12 bit
+// PWM controller. It implements gpio.DigitalWriter, gpio.PwmWriter and
+// gpio.ServoWriter, so gpio drivers such as the gpio.ServoDriver and
+// gpio.LedDriver can be bound to its channels "0" to "15" as if it were an
+// adaptor.
+type PCA9685Driver struct {
+	name       string
+	connection I2c
+	address    int
+	frequency  float64
+	servoMin   time.Duration
+	servoMax   time.Duration
+	mutex      sync.Mutex
+	gobot.Commander
+}
+
+// NewPCA9685Driver creates a new driver with specified name, i2c interface and
+// device address, with a PWM frequency of 50Hz suited to servos
+//
+// Adds the following API Commands:
+// 	"SetPWM" - See PCA9685Driver.SetPWM
+// 	"SetFrequency" - See PCA9685Driver.SetFrequency
+func NewPCA9685Driver(a I2c, name string, deviceAddress int) *PCA9685Driver {
+	d := &PCA9685Driver{
+		name:       name,
+		connection: a,
+		address:    deviceAddress,
+		frequency:  50,
+		servoMin:   1000 * time.Microsecond,
+		servoMax:   2000 * time.Microsecond,
+		Commander:  gobot.NewCommander(),
+	}
+
+	countRange := &gobot.ParamRange{Min: 0, Max: PCA9685_FULL}
+	d.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetPWM",
+		Description: "Sets the counts within the PWM period at which a channel turns on and off",
+		Params: []gobot.CommandParam{
+			{Name: "channel", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 15}},
+			{Name: "on", Type: gobot.IntegerParam, Required: true, Range: countRange},
+			{Name: "off", Type: gobot.IntegerParam, Required: true, Range: countRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return d.SetPWM(params["channel"].(int), uint16(params["on"].(int)), uint16(params["off"].(int)))
+	})
+	d.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetFrequency",
+		Description: "Sets the PWM frequency of every channel in hertz",
+		Params: []gobot.CommandParam{
+			{Name: "frequency", Type: gobot.NumberParam, Required: true, Range: &gobot.ParamRange{Min: 24, Max: 1526}},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return d.SetFrequency(params["frequency"].(float64))
+	})
+
+	return d
+}
+
+func (d *PCA9685Driver) Name() string                 { return d.name }
+func (d *PCA9685Driver) Connection() gobot.Connection { return d.connection.(gobot.Connection) }
+
+// Connect is a no-op, the PCA9685 is initialized by Start
+func (d *PCA9685Driver) Connect() (errs []error) { return }
+
+// Finalize is a no-op, the PCA9685 outputs are turned off by Halt
+func (d *PCA9685Driver) Finalize() (errs []error) { return }
+
+// Start configures register auto increment and totem pole outputs, turns
+// every output off and sets the PWM frequency
+func (d *PCA9685Driver) Start() (errs []error) {
+	if err := d.connection.I2cStart(d.address); err != nil {
+		return []error{err}
+	}
+	// the ALL_LED registers are written in one transaction, which requires
+	// auto increment
+	if err := d.connection.I2cWrite(d.address, []byte{PCA9685_REGISTER_MODE1, PCA9685_MODE1_AI}); err != nil {
+		return []error{err}
+	}
+	if err := d.connection.I2cWrite(d.address, []byte{PCA9685_REGISTER_MODE2, PCA9685_MODE2_OUTDRV}); err != nil {
+		return []error{err}
+	}
+	if err := d.SetAllPWM(0, PCA9685_FULL); err != nil {
+		return []error{err}
+	}
+	// wait for the oscillator
+	<-time.After(500 * time.Microsecond)
+	if err := d.SetFrequency(d.frequency); err != nil {
+		return []error{err}
+	}
+	return
+}
+
+// Halt turns every output off
+func (d *PCA9685Driver) Halt() (errs []error) {
+	if err := d.SetAllPWM(0, PCA9685_FULL); err != nil {
+		return []error{err}
+	}
+	return
+}
+
+// Frequency returns the PWM frequency in hertz
+func (d *PCA9685Driver) Frequency() float64 { return d.frequency }
+
+// SetFrequency sets the PWM frequency of every channel, between 24Hz and
+// 1526Hz. The prescaler can only be written while the oscillator is asleep.
+func (d *PCA9685Driver) SetFrequency(frequency float64) (err error) {
+	if frequency < 24 || frequency > 1526 {
+		return ErrInvalidFrequency
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	prescale := math.Floor(pca9685Oscillator/(4096*frequency)+0.5) - 1
+	if prescale < 3 {
+		prescale = 3
+	} else if prescale > 255 {
+		prescale = 255
+	}
+	d.frequency = frequency
+
+	if err = d.connection.I2cWrite(d.address, []byte{PCA9685_REGISTER_MODE1}); err != nil {
+		return
+	}
+	ret, err := d.connection.I2cRead(d.address, 1)
+	if err != nil {
+		return
+	}
+	if len(ret) < 1 {
+		return ErrNotEnoughBytes
+	}
+	mode := ret[0] &^ PCA9685_MODE1_RESTART
+
+	if err = d.connection.I2cWrite(d.address, []byte{PCA9685_REGISTER_MODE1, mode | PCA9685_MODE1_SLEEP}); err != nil {
+		return
+	}
+	if err = d.connection.I2cWrite(d.address, []byte{PCA9685_REGISTER_PRESCALE, byte(prescale)}); err != nil {
+		return
+	}
+	if err = d.connection.I2cWrite(d.address, []byte{PCA9685_REGISTER_MODE1, mode}); err != nil {
+		return
+	}
+	<-time.After(500 * time.Microsecond)
+	return d.connection.I2cWrite(d.address, []byte{PCA9685_REGISTER_MODE1, mode | PCA9685_MODE1_RESTART})
+}
+
+// SetPWM sets the 12 bit counts within the PWM period at which channel turns
+// on and off. Setting bit 12, PCA9685_FULL, of on or off turns the channel
+// fully on or off.
+func (d *PCA9685Driver) SetPWM(channel int, on uint16, off uint16) (err error) {
+	if channel < 0 || channel > 15 {
+		return ErrInvalidChannel
+	}
+	return d.writePWM(PCA9685_REGISTER_LED0_ON_L+byte(4*channel), on, off)
+}
+
+// SetAllPWM sets the on and off counts of every channel
+func (d *PCA9685Driver) SetAllPWM(on uint16, off uint16) (err error) {
+	return d.writePWM(PCA9685_REGISTER_ALL_LED_ON_L, on, off)
+}
+
+// DigitalWrite turns channel pin fully on when level is 1 and off otherwise
+func (d *PCA9685Driver) DigitalWrite(pin string, level byte) (err error) {
+	channel, err := strconv.Atoi(pin)
+	if err != nil {
+		return
+	}
+	if level == 1 {
+		return d.SetPWM(channel, PCA9685_FULL, 0)
+	}
+	return d.SetPWM(channel, 0, PCA9685_FULL)
+}
+
+// PwmWrite sets the duty cycle of channel pin to level out of 255
+func (d *PCA9685Driver) PwmWrite(pin string, level byte) (err error) {
+	channel, err := strconv.Atoi(pin)
+	if err != nil {
+		return
+	}
+	switch level {
+	case 0:
+		return d.SetPWM(channel, 0, PCA9685_FULL)
+	case 255:
+		return d.SetPWM(channel, PCA9685_FULL, 0)
+	default:
+		return d.SetPWM(channel, 0, uint16(int(level)*4095/255))
+	}
+}
+
+// ServoWrite moves the servo on channel pin to angle, between 0 and 180
+// degrees, by setting a pulse width within the servo pulse range
+func (d *PCA9685Driver) ServoWrite(pin string, angle byte) (err error) {
+	channel, err := strconv.Atoi(pin)
+	if err != nil {
+		return
+	}
+	pulse := float64(d.servoMin) + gobot.FromScale(float64(angle), 0, 180)*float64(d.servoMax-d.servoMin)
+	period := float64(time.Second) / d.frequency
+	return d.SetPWM(channel, 0, uint16(math.Floor(pulse/period*4096+0.5)))
+}
+
+// SetServoPulseRange sets the pulse widths of the 0 and 180 degree servo
+// positions, which default to 1ms and 2ms
+func (d *PCA9685Driver) SetServoPulseRange(min time.Duration, max time.Duration) {
+	d.servoMin = min
+	d.servoMax = max
+}
+
+func (d *PCA9685Driver) writePWM(register byte, on uint16, off uint16) (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.connection.I2cWrite(d.address, []byte{register,
+		byte(on), byte(on >> 8),
+		byte(off), byte(off >> 8),
+	})
+}
@@ -0,0 +1,209 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+	"github.com/hybridgroup/gobot/platforms/gpio"
+)
+
+// --------- HELPERS
+func initTestPCA9685Driver() (driver *PCA9685Driver) {
+	driver, _ = initTestPCA9685DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestPCA9685DriverWithStubbedAdaptor() (*PCA9685Driver, *i2cRegisterTestAdaptor) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	return NewPCA9685Driver(adaptor, "bot", PCA9685Address), adaptor
+}
+
+// pca9685Channel returns the on and off counts of channel
+func pca9685Channel(adaptor *i2cRegisterTestAdaptor, channel int) (on uint16, off uint16) {
+	adaptor.mutex.Lock()
+	defer adaptor.mutex.Unlock()
+	r := byte(PCA9685_REGISTER_LED0_ON_L + 4*channel)
+	on = uint16(adaptor.registers[r+1])<<8 | uint16(adaptor.registers[r])
+	off = uint16(adaptor.registers[r+3])<<8 | uint16(adaptor.registers[r+2])
+	return
+}
+
+// --------- TESTS
+
+func TestPCA9685Driver(t *testing.T) {
+	d := initTestPCA9685Driver()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.address, 0x40)
+	gobottest.Assert(t, d.Frequency(), 50.0)
+	gobottest.Refute(t, d.Command("SetPWM"), nil)
+	gobottest.Refute(t, d.Command("SetFrequency"), nil)
+	gobottest.Assert(t, len(d.Connect()), 0)
+	gobottest.Assert(t, len(d.Finalize()), 0)
+}
+
+func TestPCA9685DriverStart(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	// auto increment is enabled before the ALL_LED registers are written
+	gobottest.Assert(t, adaptor.written[:3], [][]byte{
+		{PCA9685_REGISTER_MODE1, PCA9685_MODE1_AI},
+		{PCA9685_REGISTER_MODE2, PCA9685_MODE2_OUTDRV},
+		{PCA9685_REGISTER_ALL_LED_ON_L, 0x00, 0x00, 0x00, 0x10},
+	})
+	gobottest.Assert(t, adaptor.registers[PCA9685_REGISTER_MODE2], byte(PCA9685_MODE2_OUTDRV))
+	gobottest.Assert(t, adaptor.registers[PCA9685_REGISTER_MODE1], byte(PCA9685_MODE1_RESTART|PCA9685_MODE1_AI))
+	// 25MHz / (4096 * 50Hz) - 1
+	gobottest.Assert(t, adaptor.registers[PCA9685_REGISTER_PRESCALE], byte(121))
+
+	d, adaptor = initTestPCA9685DriverWithStubbedAdaptor()
+	adaptor.i2cStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("start error"))
+
+	d, adaptor = initTestPCA9685DriverWithStubbedAdaptor()
+	adaptor.i2cWriteImpl = func() error {
+		return errors.New("write error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("write error"))
+}
+
+func TestPCA9685DriverHalt(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+	gobottest.Assert(t, adaptor.written[0], []byte{PCA9685_REGISTER_ALL_LED_ON_L, 0x00, 0x00, 0x00, 0x10})
+}
+
+func TestPCA9685DriverSetFrequency(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+	adaptor.setRegisters(PCA9685_REGISTER_MODE1, []byte{PCA9685_MODE1_RESTART | PCA9685_MODE1_AI})
+
+	gobottest.Assert(t, d.SetFrequency(1000), nil)
+	gobottest.Assert(t, d.Frequency(), 1000.0)
+	gobottest.Assert(t, adaptor.registers[PCA9685_REGISTER_PRESCALE], byte(5))
+	// the prescaler is written while asleep
+	gobottest.Assert(t, adaptor.written[1], []byte{PCA9685_REGISTER_MODE1, PCA9685_MODE1_SLEEP | PCA9685_MODE1_AI})
+	gobottest.Assert(t, adaptor.written[2], []byte{PCA9685_REGISTER_PRESCALE, 5})
+	gobottest.Assert(t, adaptor.registers[PCA9685_REGISTER_MODE1], byte(PCA9685_MODE1_RESTART|PCA9685_MODE1_AI))
+
+	gobottest.Assert(t, d.SetFrequency(24), nil)
+	gobottest.Assert(t, adaptor.registers[PCA9685_REGISTER_PRESCALE], byte(253))
+	gobottest.Assert(t, d.Command("SetFrequency")(map[string]interface{}{"frequency": 1526.0}), nil)
+	gobottest.Assert(t, adaptor.registers[PCA9685_REGISTER_PRESCALE], byte(3))
+
+	// out of range frequencies are refused
+	gobottest.Assert(t, d.SetFrequency(0), ErrInvalidFrequency)
+	gobottest.Assert(t, d.SetFrequency(2000), ErrInvalidFrequency)
+	gobottest.Assert(t, d.Frequency(), 1526.0)
+	err := d.Command("SetFrequency")(map[string]interface{}{})
+	gobottest.Assert(t, err, &gobot.CommandError{Command: "SetFrequency", Param: "frequency", Message: "is required"})
+	gobottest.Refute(t, d.Command("SetFrequency")(map[string]interface{}{"frequency": 10.0}), nil)
+	gobottest.Assert(t, d.Frequency(), 1526.0)
+
+	adaptor.i2cReadImpl = func() ([]byte, error) {
+		return nil, errors.New("read error")
+	}
+	gobottest.Assert(t, d.SetFrequency(50), errors.New("read error"))
+}
+
+func TestPCA9685DriverSetPWM(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, d.SetPWM(15, 0x123, 0x456), nil)
+	gobottest.Assert(t, adaptor.written[0], []byte{0x42, 0x23, 0x01, 0x56, 0x04})
+	on, off := pca9685Channel(adaptor, 15)
+	gobottest.Assert(t, on, uint16(0x123))
+	gobottest.Assert(t, off, uint16(0x456))
+
+	gobottest.Assert(t, d.Command("SetPWM")(map[string]interface{}{"channel": 1.0, "on": 0.0, "off": 100.0}), nil)
+	_, off = pca9685Channel(adaptor, 1)
+	gobottest.Assert(t, off, uint16(100))
+
+	gobottest.Assert(t, d.SetPWM(16, 0, 0), ErrInvalidChannel)
+	gobottest.Assert(t, d.SetPWM(-1, 0, 0), ErrInvalidChannel)
+	gobottest.Refute(t, d.Command("SetPWM")(map[string]interface{}{"channel": 16.0, "on": 0.0, "off": 100.0}), nil)
+}
+
+func TestPCA9685DriverDigitalWrite(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, d.DigitalWrite("2", 1), nil)
+	on, off := pca9685Channel(adaptor, 2)
+	gobottest.Assert(t, on, uint16(PCA9685_FULL))
+	gobottest.Assert(t, off, uint16(0))
+
+	gobottest.Assert(t, d.DigitalWrite("2", 0), nil)
+	on, off = pca9685Channel(adaptor, 2)
+	gobottest.Assert(t, on, uint16(0))
+	gobottest.Assert(t, off, uint16(PCA9685_FULL))
+
+	gobottest.Refute(t, d.DigitalWrite("a", 1), nil)
+}
+
+func TestPCA9685DriverPwmWrite(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, d.PwmWrite("4", 128), nil)
+	on, off := pca9685Channel(adaptor, 4)
+	gobottest.Assert(t, on, uint16(0))
+	gobottest.Assert(t, off, uint16(2055))
+
+	gobottest.Assert(t, d.PwmWrite("4", 255), nil)
+	on, _ = pca9685Channel(adaptor, 4)
+	gobottest.Assert(t, on, uint16(PCA9685_FULL))
+
+	gobottest.Assert(t, d.PwmWrite("4", 0), nil)
+	_, off = pca9685Channel(adaptor, 4)
+	gobottest.Assert(t, off, uint16(PCA9685_FULL))
+
+	gobottest.Refute(t, d.PwmWrite("a", 1), nil)
+	gobottest.Assert(t, d.PwmWrite("16", 1), ErrInvalidChannel)
+}
+
+func TestPCA9685DriverServoWrite(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+
+	// 1ms, 1.5ms and 2ms pulses of a 20ms period
+	gobottest.Assert(t, d.ServoWrite("0", 0), nil)
+	_, off := pca9685Channel(adaptor, 0)
+	gobottest.Assert(t, off, uint16(205))
+	gobottest.Assert(t, d.ServoWrite("0", 90), nil)
+	_, off = pca9685Channel(adaptor, 0)
+	gobottest.Assert(t, off, uint16(307))
+	gobottest.Assert(t, d.ServoWrite("0", 180), nil)
+	_, off = pca9685Channel(adaptor, 0)
+	gobottest.Assert(t, off, uint16(410))
+
+	d.SetServoPulseRange(500*time.Microsecond, 2500*time.Microsecond)
+	gobottest.Assert(t, d.ServoWrite("0", 0), nil)
+	_, off = pca9685Channel(adaptor, 0)
+	gobottest.Assert(t, off, uint16(102))
+
+	gobottest.Refute(t, d.ServoWrite("a", 1), nil)
+}
+
+func TestPCA9685DriverGpioDrivers(t *testing.T) {
+	d, adaptor := initTestPCA9685DriverWithStubbedAdaptor()
+
+	servo := gpio.NewServoDriver(d, "servo", "3")
+	gobottest.Assert(t, servo.Connection().Name(), "bot")
+	gobottest.Assert(t, servo.Center(), nil)
+	_, off := pca9685Channel(adaptor, 3)
+	gobottest.Assert(t, off, uint16(307))
+
+	led := gpio.NewLedDriver(d, "led", "7")
+	gobottest.Assert(t, led.On(), nil)
+	on, _ := pca9685Channel(adaptor, 7)
+	gobottest.Assert(t, on, uint16(PCA9685_FULL))
+	gobottest.Assert(t, led.Brightness(128), nil)
+	on, off = pca9685Channel(adaptor, 7)
+	gobottest.Assert(t, on, uint16(0))
+	gobottest.Assert(t, off, uint16(2055))
+}
@@ -0,0 +1,243 @@
+package i2c
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var _ gobot.Driver = (*SHT3xDriver)(nil)
+
+// SHT3xAddress is the i2c address of a SHT3x with ADDR pulled low, the
+// address is 0x45 with ADDR pulled high
+const SHT3xAddress = 0x44
+
+const SHT3X_CMD_SOFT_RESET = 0x30A2
+const SHT3X_CMD_HEATER_ENABLE = 0x306D
+const SHT3X_CMD_HEATER_DISABLE = 0x3066
+
+// SHT3xRepeatability is the repeatability of a SHT3x single shot measurement,
+// trading measurement time for lower noise
+type SHT3xRepeatability uint16
+
+const (
+	// SHT3xLow measures in 4.5ms
+	SHT3xLow SHT3xRepeatability = 0x2416
+	// SHT3xMedium measures in 6.5ms
+	SHT3xMedium SHT3xRepeatability = 0x240B
+	// SHT3xHigh measures in 15.5ms
+	SHT3xHigh SHT3xRepeatability = 0x2400
+)
+
+// ErrInvalidCRC is the error resulting when the checksum of data read from a
+// sensor does not match
+var ErrInvalidCRC = errors.New("Invalid crc")
+
+// SHT3xDriver is the gobot driver for the Sensirion SHT3x humidity and
+// temperature sensors
+type SHT3xDriver struct {
+	name          string
+	connection    I2c
+	address       int
+	interval      time.Duration
+	repeatability SHT3xRepeatability
+	temperature   float32
+	humidity      float32
+	halt          chan bool
+	mutex         sync.Mutex
+	gobot.Eventer
+	gobot.Commander
+}
+
+// NewSHT3xDriver creates a new driver with specified name, i2c interface and
+// device address, taking high repeatability measurements every second
+//
+// Optionally accepts:
+// 	time.Duration: Interval at which the sensor is sampled
+//
+// Adds the following API Commands:
+// 	"Temperature" - See SHT3xDriver.ReadTemperature
+// 	"Humidity" - See SHT3xDriver.ReadHumidity
+// 	"Heater" - See SHT3xDriver.SetHeater
+func NewSHT3xDriver(a I2c, name string, deviceAddress int, v ...time.Duration) *SHT3xDriver {
+	d := &SHT3xDriver{
+		name:          name,
+		connection:    a,
+		address:       deviceAddress,
+		interval:      1 * time.Second,
+		repeatability: SHT3xHigh,
+		halt:          make(chan bool),
+		Eventer:       gobot.NewEventer(),
+		Commander:     gobot.NewCommander(),
+	}
+
+	if len(v) > 0 {
+		d.interval = v[0]
+	}
+
+	d.AddEvent(Data)
+	d.AddEvent(Error)
+
+	d.AddCommand("Temperature", func(params map[string]interface{}) interface{} {
+		val, err := d.ReadTemperature()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+	d.AddCommand("Humidity", func(params map[string]interface{}) interface{} {
+		val, err := d.ReadHumidity()
+		return map[string]interface{}{"val": val, "err": err}
+	})
+	d.AddCommand("Heater", func(params map[string]interface{}) interface{} {
+		enabled, _ := params["enabled"].(bool)
+		return d.SetHeater(enabled)
+	})
+
+	return d
+}
+
+func (d *SHT3xDriver) Name() string                 { return d.name }
+func (d *SHT3xDriver) Connection() gobot.Connection { return d.connection.(gobot.Connection) }
+
+// Start resets the sensor and samples it at the given interval.
+// Emits the Events:
+//	Data map[string]float32 - "temperature" in celsius and "humidity" in %RH, emitted every sample.
+//	Error error - Event is emitted on error reading from the sensor.
+func (d *SHT3xDriver) Start() (errs []error) {
+	if err := d.connection.I2cStart(d.address); err != nil {
+		return []error{err}
+	}
+	if err := d.command(SHT3X_CMD_SOFT_RESET); err != nil {
+		return []error{err}
+	}
+	<-time.After(2 * time.Millisecond)
+
+	go func() {
+		for {
+			temperature, humidity, err := d.read()
+			if err != nil {
+				gobot.Publish(d.Event(Error), err)
+			} else {
+				gobot.Publish(d.Event(Data), map[string]float32{
+					"temperature": temperature,
+					"humidity":    humidity,
+				})
+			}
+			select {
+			case <-time.After(d.interval):
+			case <-d.halt:
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops sampling the sensor
+func (d *SHT3xDriver) Halt() (errs []error) {
+	d.halt <- true
+	return
+}
+
+// SetRepeatability sets the repeatability of the measurements
+func (d *SHT3xDriver) SetRepeatability(repeatability SHT3xRepeatability) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.repeatability = repeatability
+}
+
+// SetHeater enables or disables the internal heater, which is used to
+// evaporate condensation and to verify the sensor
+func (d *SHT3xDriver) SetHeater(enabled bool) (err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if enabled {
+		return d.command(SHT3X_CMD_HEATER_ENABLE)
+	}
+	return d.command(SHT3X_CMD_HEATER_DISABLE)
+}
+
+// Temperature returns the last sampled temperature in celsius
+func (d *SHT3xDriver) Temperature() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.temperature
+}
+
+// Humidity returns the last sampled relative humidity in %RH
+func (d *SHT3xDriver) Humidity() float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.humidity
+}
+
+// ReadTemperature measures and returns the temperature in celsius
+func (d *SHT3xDriver) ReadTemperature() (temperature float32, err error) {
+	temperature, _, err = d.read()
+	return
+}
+
+// ReadHumidity measures and returns the relative humidity in %RH
+func (d *SHT3xDriver) ReadHumidity() (humidity float32, err error) {
+	_, humidity, err = d.read()
+	return
+}
+
+func (d *SHT3xDriver) command(cmd uint16) (err error) {
+	return d.connection.I2cWrite(d.address, []byte{byte(cmd >> 8), byte(cmd)})
+}
+
+// read takes a single shot measurement without clock stretching
+func (d *SHT3xDriver) read() (temperature float32, humidity float32, err error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if err = d.command(uint16(d.repeatability)); err != nil {
+		return
+	}
+	<-time.After(d.measurementTime())
+
+	ret, err := d.connection.I2cRead(d.address, 6)
+	if err != nil {
+		return
+	}
+	if len(ret) < 6 {
+		return 0, 0, ErrNotEnoughBytes
+	}
+	if sht3xCRC(ret[0:2]) != ret[2] || sht3xCRC(ret[3:5]) != ret[5] {
+		return 0, 0, ErrInvalidCRC
+	}
+
+	rawT := uint16(ret[0])<<8 | uint16(ret[1])
+	rawH := uint16(ret[3])<<8 | uint16(ret[4])
+	d.temperature = -45.0 + 175.0*float32(rawT)/65535.0
+	d.humidity = 100.0 * float32(rawH) / 65535.0
+	return d.temperature, d.humidity, nil
+}
+
+func (d *SHT3xDriver) measurementTime() time.Duration {
+	switch d.repeatability {
+	case SHT3xLow:
+		return 5 * time.Millisecond
+	case SHT3xMedium:
+		return 7 * time.Millisecond
+	default:
+		return 16 * time.Millisecond
+	}
+}
+
+// sht3xCRC returns the CRC-8 of data, polynomial 0x31 initialized to 0xFF
+func sht3xCRC(data []byte) byte {
+	crc := byte(0xFF)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,120 @@
+package i2c
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// 0x6666 is 25C and 40%RH, followed by the crc of each word
+var sht3xMeasurement = []byte{0x66, 0x66, 0x93, 0x66, 0x66, 0x93}
+
+// --------- HELPERS
+func initTestSHT3xDriver() (driver *SHT3xDriver) {
+	driver, _ = initTestSHT3xDriverWithStubbedAdaptor()
+	return
+}
+
+func initTestSHT3xDriverWithStubbedAdaptor() (*SHT3xDriver, *i2cRegisterTestAdaptor) {
+	adaptor := newI2cRegisterTestAdaptor("adaptor")
+	// a measurement is read straight after its command
+	adaptor.writeHook = func(buf []byte) {
+		adaptor.setRegisters(buf[0], sht3xMeasurement)
+	}
+	return NewSHT3xDriver(adaptor, "bot", SHT3xAddress), adaptor
+}
+
+// --------- TESTS
+
+func TestSHT3xDriver(t *testing.T) {
+	d := initTestSHT3xDriver()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.address, 0x44)
+	gobottest.Assert(t, d.interval, 1*time.Second)
+	gobottest.Refute(t, d.Command("Temperature"), nil)
+	gobottest.Refute(t, d.Command("Humidity"), nil)
+	gobottest.Refute(t, d.Command("Heater"), nil)
+
+	d = NewSHT3xDriver(newI2cTestAdaptor("adaptor"), "bot", 0x45, 10*time.Millisecond)
+	gobottest.Assert(t, d.address, 0x45)
+	gobottest.Assert(t, d.interval, 10*time.Millisecond)
+}
+
+func TestSHT3xCRC(t *testing.T) {
+	// datasheet example
+	gobottest.Assert(t, sht3xCRC([]byte{0xBE, 0xEF}), byte(0x92))
+	gobottest.Assert(t, sht3xCRC([]byte{0x66, 0x66}), byte(0x93))
+}
+
+func TestSHT3xDriverRead(t *testing.T) {
+	d, adaptor := initTestSHT3xDriverWithStubbedAdaptor()
+
+	temperature, err := d.ReadTemperature()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, temperature, float32(25))
+	humidity, err := d.ReadHumidity()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, humidity, float32(40))
+	gobottest.Assert(t, d.Temperature(), float32(25))
+	gobottest.Assert(t, d.Humidity(), float32(40))
+	gobottest.Assert(t, adaptor.written[len(adaptor.written)-1], []byte{0x24, 0x00})
+
+	d.SetRepeatability(SHT3xLow)
+	d.ReadTemperature()
+	gobottest.Assert(t, adaptor.written[len(adaptor.written)-1], []byte{0x24, 0x16})
+
+	gobottest.Assert(t, d.Command("Heater")(map[string]interface{}{"enabled": true}), nil)
+	gobottest.Assert(t, adaptor.written[len(adaptor.written)-1], []byte{0x30, 0x6D})
+	gobottest.Assert(t, d.SetHeater(false), nil)
+	gobottest.Assert(t, adaptor.written[len(adaptor.written)-1], []byte{0x30, 0x66})
+
+	adaptor.writeHook = func(buf []byte) {
+		adaptor.setRegisters(buf[0], []byte{0x66, 0x66, 0x00, 0x66, 0x66, 0x93})
+	}
+	_, err = d.ReadTemperature()
+	gobottest.Assert(t, err, ErrInvalidCRC)
+
+	adaptor.i2cReadImpl = func() ([]byte, error) {
+		return nil, errors.New("read error")
+	}
+	_, err = d.ReadHumidity()
+	gobottest.Assert(t, err, errors.New("read error"))
+}
+
+func TestSHT3xDriverStart(t *testing.T) {
+	sem := make(chan bool)
+	d, adaptor := initTestSHT3xDriverWithStubbedAdaptor()
+
+	gobot.Once(d.Event(Data), func(data interface{}) {
+		gobottest.Assert(t, data, map[string]float32{
+			"temperature": 25,
+			"humidity":    40,
+		})
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("SHT3x Event \"Data\" was not published")
+	}
+
+	adaptor.mutex.Lock()
+	gobottest.Assert(t, adaptor.written[0], []byte{0x30, 0xA2})
+	adaptor.mutex.Unlock()
+
+	gobottest.Assert(t, len(d.Halt()), 0)
+
+	d, adaptor = initTestSHT3xDriverWithStubbedAdaptor()
+	adaptor.i2cStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("start error"))
+}
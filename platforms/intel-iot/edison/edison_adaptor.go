@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/platforms/gpio"
@@ -501,6 +502,8 @@ func (e *EdisonAdaptor) I2cStart(address int) (err error) {
 
 // I2cWrite writes data to i2c device
 func (e *EdisonAdaptor) I2cWrite(address int, data []byte) (err error) {
+	defer gobot.MeterI2c(e.Name(), address, "write", time.Now(), &err)
+
 	if err = e.i2cDevice.SetAddress(address); err != nil {
 		return err
 	}
@@ -510,6 +513,8 @@ func (e *EdisonAdaptor) I2cWrite(address int, data []byte) (err error) {
 
 // I2cRead returns size bytes from the i2c device
 func (e *EdisonAdaptor) I2cRead(address int, size int) (data []byte, err error) {
+	defer gobot.MeterI2c(e.Name(), address, "read", time.Now(), &err)
+
 	data = make([]byte, size)
 	if err = e.i2cDevice.SetAddress(address); err != nil {
 		return
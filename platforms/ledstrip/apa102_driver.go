@@ -0,0 +1,124 @@
+package ledstrip
+
+import (
+	"image/color"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/platforms/gpio"
+)
+
+var _ gobot.Driver = (*APA102Driver)(nil)
+
+// APA102Speed is the SPI clock speed used to drive APA102 strips
+const APA102Speed = 4000000
+
+// APA102Driver represents a strip of APA102 (DotStar) pixels driven by SPI or
+// by bit-banging a pair of digital pins
+type APA102Driver struct {
+	name       string
+	pin        string
+	connection gobot.Connection
+	start      func() error
+	write      func([]byte) error
+	*Strip
+}
+
+// NewAPA102Driver returns a new APA102Driver given a SpiWriter, name, SPI bus
+// such as "0.0" and the number of pixels on the strip.
+//
+// Adds the Strip API Commands, see newStrip.
+func NewAPA102Driver(a SpiWriter, name string, bus string, length int) *APA102Driver {
+	d := &APA102Driver{
+		name:       name,
+		pin:        bus,
+		connection: a.(gobot.Connection),
+		start: func() error {
+			return a.SpiStart(bus, APA102Speed)
+		},
+		write: func(data []byte) error {
+			return a.SpiWrite(bus, data)
+		},
+	}
+	d.Strip = newStrip(length, d.send)
+	return d
+}
+
+// NewAPA102DigitalDriver returns a new APA102Driver given a DigitalWriter,
+// name, data pin, clock pin and the number of pixels on the strip. Frames are
+// bit-banged most significant bit first, which is considerably slower than
+// NewAPA102Driver.
+//
+// Adds the Strip API Commands, see newStrip.
+func NewAPA102DigitalDriver(a gpio.DigitalWriter, name string, dataPin string, clockPin string, length int) *APA102Driver {
+	d := &APA102Driver{
+		name:       name,
+		pin:        dataPin,
+		connection: a.(gobot.Connection),
+		start: func() error {
+			return a.DigitalWrite(clockPin, 0)
+		},
+		write: func(data []byte) (err error) {
+			for _, b := range data {
+				for i := uint(0); i < 8; i++ {
+					if err = a.DigitalWrite(dataPin, (b>>(7-i))&0x01); err != nil {
+						return
+					}
+					if err = a.DigitalWrite(clockPin, 1); err != nil {
+						return
+					}
+					if err = a.DigitalWrite(clockPin, 0); err != nil {
+						return
+					}
+				}
+			}
+			return
+		},
+	}
+	d.Strip = newStrip(length, d.send)
+	return d
+}
+
+// Name returns the APA102Drivers name
+func (d *APA102Driver) Name() string { return d.name }
+
+// Pin returns the APA102Drivers SPI bus or data pin
+func (d *APA102Driver) Pin() string { return d.pin }
+
+// Connection returns the APA102Drivers Connection
+func (d *APA102Driver) Connection() gobot.Connection { return d.connection }
+
+// Start initializes the strip and turns every pixel off
+func (d *APA102Driver) Start() (errs []error) {
+	if err := d.start(); err != nil {
+		return []error{err}
+	}
+	d.Clear()
+	if err := d.Show(); err != nil {
+		return []error{err}
+	}
+	return
+}
+
+// Halt stops any running animation
+func (d *APA102Driver) Halt() (errs []error) {
+	d.Stop()
+	return
+}
+
+// send writes a start frame of zeros, a frame per pixel at full global
+// brightness and an end frame with enough clock edges for the data to
+// propagate to the last pixel
+func (d *APA102Driver) send(pixels []color.RGBA) error {
+	end := (len(pixels) + 15) / 16
+	if end < 4 {
+		end = 4
+	}
+	data := make([]byte, 4, 4+len(pixels)*4+end)
+	for _, c := range pixels {
+		data = append(data, 0xE0|0x1F, c.B, c.G, c.R)
+	}
+	for i := 0; i < end; i++ {
+		data = append(data, 0xFF)
+	}
+	return d.write(data)
+}
@@ -0,0 +1,89 @@
+package ledstrip
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// --------- HELPERS
+func initTestAPA102DriverWithStubbedAdaptor() (*APA102Driver, *ledstripTestAdaptor) {
+	adaptor := newLedstripTestAdaptor("adaptor")
+	return NewAPA102Driver(adaptor, "bot", "0.0", 2), adaptor
+}
+
+// --------- TESTS
+
+func TestAPA102Driver(t *testing.T) {
+	d, _ := initTestAPA102DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Pin(), "0.0")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.Len(), 2)
+	gobottest.Refute(t, d.Command("Show"), nil)
+}
+
+func TestAPA102DriverStart(t *testing.T) {
+	d, adaptor := initTestAPA102DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	gobottest.Assert(t, adaptor.lastWritten(), []byte{
+		0x00, 0x00, 0x00, 0x00,
+		0xFF, 0x00, 0x00, 0x00,
+		0xFF, 0x00, 0x00, 0x00,
+		0xFF, 0xFF, 0xFF, 0xFF,
+	})
+	gobottest.Assert(t, len(d.Halt()), 0)
+
+	adaptor.spiStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("start error"))
+
+	adaptor.spiStartImpl = func() error { return nil }
+	adaptor.spiWriteImpl = func() error {
+		return errors.New("write error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("write error"))
+}
+
+func TestAPA102DriverShow(t *testing.T) {
+	d, adaptor := initTestAPA102DriverWithStubbedAdaptor()
+
+	d.SetPixel(0, color.RGBA{R: 0x11, G: 0x22, B: 0x33})
+	d.SetPixel(1, color.RGBA{R: 0x44, G: 0x55, B: 0x66})
+	gobottest.Assert(t, d.Show(), nil)
+	gobottest.Assert(t, adaptor.lastWritten(), []byte{
+		0x00, 0x00, 0x00, 0x00,
+		0xFF, 0x33, 0x22, 0x11,
+		0xFF, 0x66, 0x55, 0x44,
+		0xFF, 0xFF, 0xFF, 0xFF,
+	})
+
+	// long strips need an end frame of a clock edge per 2 pixels
+	d = NewAPA102Driver(adaptor, "bot", "0.0", 100)
+	d.Show()
+	gobottest.Assert(t, len(adaptor.lastWritten()), 4+400+7)
+}
+
+func TestAPA102DigitalDriver(t *testing.T) {
+	adaptor := newLedstripTestAdaptor("adaptor")
+	d := NewAPA102DigitalDriver(adaptor, "bot", "11", "13", 0)
+
+	gobottest.Assert(t, d.Pin(), "11")
+	gobottest.Assert(t, len(d.Start()), 0)
+	// clock low, then 8 bytes of 3 writes per bit
+	gobottest.Assert(t, len(adaptor.pins), 1+8*8*3)
+	gobottest.Assert(t, adaptor.pins[:4], []string{"13", "11", "13", "13"})
+	gobottest.Assert(t, adaptor.levels[:4], []byte{0, 0, 1, 0})
+	// the end frame is all ones
+	gobottest.Assert(t, adaptor.levels[len(adaptor.levels)-3:], []byte{1, 1, 0})
+
+	adaptor.digitalWriteImpl = func() error {
+		return errors.New("write error")
+	}
+	gobottest.Assert(t, d.Show(), errors.New("write error"))
+}
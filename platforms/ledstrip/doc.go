@@ -0,0 +1,12 @@
+/*
+Package ledstrip provides Gobot drivers for addressable LED strips such as
+APA102 (DotStar) and WS2812 (NeoPixel).
+
+Installing:
+
+	go get -d -u github.com/hybridgroup/gobot/... && go install github.com/hybridgroup/gobot/platforms/ledstrip
+
+For further information refer to ledstrip README:
+https://github.com/hybridgroup/gobot/blob/master/platforms/ledstrip/README.md
+*/
+package ledstrip
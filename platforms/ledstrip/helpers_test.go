@@ -0,0 +1,85 @@
+package ledstrip
+
+import "sync"
+
+type ledstripTestAdaptor struct {
+	name              string
+	mutex             sync.Mutex
+	written           [][]byte
+	colors            [][]uint32
+	pins              []string
+	levels            []byte
+	spiStartImpl      func() error
+	spiWriteImpl      func() error
+	neoPixelStartImpl func() error
+	neoPixelWriteImpl func() error
+	digitalWriteImpl  func() error
+}
+
+func (t *ledstripTestAdaptor) SpiStart(string, int) (err error) {
+	return t.spiStartImpl()
+}
+func (t *ledstripTestAdaptor) SpiWrite(bus string, data []byte) (err error) {
+	t.mutex.Lock()
+	t.written = append(t.written, data)
+	t.mutex.Unlock()
+	return t.spiWriteImpl()
+}
+func (t *ledstripTestAdaptor) NeoPixelStart(string, int) (err error) {
+	return t.neoPixelStartImpl()
+}
+func (t *ledstripTestAdaptor) NeoPixelWrite(pin string, colors []uint32) (err error) {
+	t.mutex.Lock()
+	t.colors = append(t.colors, colors)
+	t.mutex.Unlock()
+	return t.neoPixelWriteImpl()
+}
+func (t *ledstripTestAdaptor) DigitalWrite(pin string, level byte) (err error) {
+	t.mutex.Lock()
+	t.pins = append(t.pins, pin)
+	t.levels = append(t.levels, level)
+	t.mutex.Unlock()
+	return t.digitalWriteImpl()
+}
+func (t *ledstripTestAdaptor) Name() string             { return t.name }
+func (t *ledstripTestAdaptor) Connect() (errs []error)  { return }
+func (t *ledstripTestAdaptor) Finalize() (errs []error) { return }
+
+func (t *ledstripTestAdaptor) lastWritten() []byte {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.written) == 0 {
+		return nil
+	}
+	return t.written[len(t.written)-1]
+}
+
+func (t *ledstripTestAdaptor) lastColors() []uint32 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.colors) == 0 {
+		return nil
+	}
+	return t.colors[len(t.colors)-1]
+}
+
+func newLedstripTestAdaptor(name string) *ledstripTestAdaptor {
+	return &ledstripTestAdaptor{
+		name: name,
+		spiStartImpl: func() error {
+			return nil
+		},
+		spiWriteImpl: func() error {
+			return nil
+		},
+		neoPixelStartImpl: func() error {
+			return nil
+		},
+		neoPixelWriteImpl: func() error {
+			return nil
+		},
+		digitalWriteImpl: func() error {
+			return nil
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package ledstrip
+
+import (
+	"errors"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var (
+	// ErrPixelOutOfRange is the error resulting when a pixel index is not on the strip
+	ErrPixelOutOfRange = errors.New("pixel index is out of range")
+	// ErrInvalidGamma is the error resulting when a gamma correction is not positive
+	ErrInvalidGamma = errors.New("gamma must be greater than 0")
+	// ErrInvalidInterval is the error resulting when an animation interval is not positive
+	ErrInvalidInterval = errors.New("interval must be greater than 0")
+)
+
+const (
+	// Error event
+	Error = "error"
+)
+
+// SpiWriter interface represents an Adaptor which has SPI capabilities
+type SpiWriter interface {
+	gobot.Adaptor
+	SpiStart(bus string, speed int) (err error)
+	SpiWrite(bus string, data []byte) (err error)
+}
+
+// NeoPixelWriter interface represents an Adaptor which drives WS2812 pixels
+// itself, such as a Firmata board running the node-pixel firmware. Colors are
+// 0xRRGGBB.
+type NeoPixelWriter interface {
+	gobot.Adaptor
+	NeoPixelStart(pin string, length int) (err error)
+	NeoPixelWrite(pin string, colors []uint32) (err error)
+}
@@ -0,0 +1,391 @@
+package ledstrip
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+// frameInterval is the interval between frames of a Fade
+const frameInterval = 20 * time.Millisecond
+
+// Strip is the framebuffer shared by the led strip drivers. Pixels are set
+// in the framebuffer and sent to the strip, with brightness and gamma
+// correction applied, by Show.
+type Strip struct {
+	pixels     []color.RGBA
+	brightness byte
+	gamma      float64
+	table      [256]byte
+	show       func([]color.RGBA) error
+	animation  chan bool
+	mutex      sync.Mutex
+	gobot.Eventer
+	gobot.Commander
+}
+
+// newStrip returns a Strip of length pixels at full brightness and without
+// gamma correction, which sends corrected pixels to show.
+//
+// Adds the following API Commands:
+// 	"SetPixel" - See Strip.SetPixel
+// 	"Fill" - See Strip.Fill
+// 	"Clear" - See Strip.Clear
+// 	"Show" - See Strip.Show
+// 	"Brightness" - See Strip.SetBrightness
+// 	"Gamma" - See Strip.SetGamma
+// 	"Fade" - See Strip.Fade
+// 	"Chase" - See Strip.Chase
+// 	"Rainbow" - See Strip.Rainbow
+// 	"Stop" - See Strip.Stop
+func newStrip(length int, show func([]color.RGBA) error) *Strip {
+	s := &Strip{
+		pixels:     make([]color.RGBA, length),
+		brightness: 255,
+		show:       show,
+		Eventer:    gobot.NewEventer(),
+		Commander:  gobot.NewCommander(),
+	}
+	s.SetGamma(1.0)
+
+	s.AddEvent(Error)
+
+	colorParams := []gobot.CommandParam{
+		{Name: "red", Type: gobot.IntegerParam, Range: levelRange, Default: 0},
+		{Name: "green", Type: gobot.IntegerParam, Range: levelRange, Default: 0},
+		{Name: "blue", Type: gobot.IntegerParam, Range: levelRange, Default: 0},
+	}
+	intervalParam := gobot.CommandParam{
+		Name: "interval", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 1, Max: 3600000},
+		Description: "Interval between frames in milliseconds",
+	}
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetPixel",
+		Description: "Sets a pixel of the framebuffer to a color",
+		Params: append([]gobot.CommandParam{
+			{Name: "index", Type: gobot.IntegerParam, Required: true},
+		}, colorParams...),
+	}, func(params map[string]interface{}) interface{} {
+		return s.SetPixel(params["index"].(int), paramsColor(params))
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Fill",
+		Description: "Sets every pixel of the framebuffer to a color",
+		Params:      colorParams,
+	}, func(params map[string]interface{}) interface{} {
+		s.Fill(paramsColor(params))
+		return nil
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Clear",
+		Description: "Turns every pixel of the framebuffer off",
+	}, func(params map[string]interface{}) interface{} {
+		s.Clear()
+		return nil
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Show",
+		Description: "Sends the framebuffer to the strip",
+	}, func(params map[string]interface{}) interface{} {
+		return s.Show()
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Brightness",
+		Description: "Sets the brightness level applied by Show",
+		Params: []gobot.CommandParam{
+			{Name: "level", Type: gobot.IntegerParam, Required: true, Range: levelRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		s.SetBrightness(byte(params["level"].(int)))
+		return nil
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Gamma",
+		Description: "Sets the gamma correction applied by Show",
+		Params: []gobot.CommandParam{
+			{Name: "gamma", Type: gobot.NumberParam, Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.SetGamma(params["gamma"].(float64))
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Fade",
+		Description: "Fades every pixel to a color",
+		Params: append([]gobot.CommandParam{
+			{Name: "duration", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 3600000}, Description: "Duration of the fade in milliseconds"},
+		}, colorParams...),
+	}, func(params map[string]interface{}) interface{} {
+		s.Fade(paramsColor(params), time.Duration(params["duration"].(int))*time.Millisecond)
+		return nil
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Chase",
+		Description: "Moves a single pixel of a color along the strip",
+		Params:      append([]gobot.CommandParam{intervalParam}, colorParams...),
+	}, func(params map[string]interface{}) interface{} {
+		return s.Chase(paramsColor(params), time.Duration(params["interval"].(int))*time.Millisecond)
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Rainbow",
+		Description: "Rotates the color wheel across the strip",
+		Params:      []gobot.CommandParam{intervalParam},
+	}, func(params map[string]interface{}) interface{} {
+		return s.Rainbow(time.Duration(params["interval"].(int)) * time.Millisecond)
+	})
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Stop",
+		Description: "Stops the running animation",
+	}, func(params map[string]interface{}) interface{} {
+		s.Stop()
+		return nil
+	})
+
+	return s
+}
+
+// levelRange is the range of a color or brightness level
+var levelRange = &gobot.ParamRange{Min: 0, Max: 255}
+
+// paramsColor returns the color of params coerced by a schema with colorParams
+func paramsColor(params map[string]interface{}) color.RGBA {
+	return color.RGBA{
+		R: byte(params["red"].(int)),
+		G: byte(params["green"].(int)),
+		B: byte(params["blue"].(int)),
+		A: 255,
+	}
+}
+
+// Len returns the number of pixels on the strip
+func (s *Strip) Len() int { return len(s.pixels) }
+
+// Pixel returns the color of pixel i in the framebuffer
+func (s *Strip) Pixel(i int) color.RGBA {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if i < 0 || i >= len(s.pixels) {
+		return color.RGBA{}
+	}
+	return s.pixels[i]
+}
+
+// Pixels returns a copy of the framebuffer
+func (s *Strip) Pixels() []color.RGBA {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]color.RGBA{}, s.pixels...)
+}
+
+// SetPixel sets pixel i in the framebuffer to c
+func (s *Strip) SetPixel(i int, c color.RGBA) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if i < 0 || i >= len(s.pixels) {
+		return ErrPixelOutOfRange
+	}
+	s.pixels[i] = c
+	return
+}
+
+// Fill sets every pixel in the framebuffer to c
+func (s *Strip) Fill(c color.RGBA) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := range s.pixels {
+		s.pixels[i] = c
+	}
+}
+
+// Clear turns every pixel in the framebuffer off
+func (s *Strip) Clear() {
+	s.Fill(color.RGBA{})
+}
+
+// Brightness returns the brightness level applied by Show
+func (s *Strip) Brightness() byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.brightness
+}
+
+// SetBrightness sets the 0-255 brightness level applied by Show
+func (s *Strip) SetBrightness(level byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.brightness = level
+}
+
+// Gamma returns the gamma correction applied by Show
+func (s *Strip) Gamma() float64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.gamma
+}
+
+// SetGamma sets the gamma correction applied by Show. A gamma of 1.0 disables
+// correction, LEDs typically look linear with a gamma around 2.5.
+func (s *Strip) SetGamma(gamma float64) (err error) {
+	if gamma <= 0 {
+		return ErrInvalidGamma
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.gamma = gamma
+	for i := range s.table {
+		s.table[i] = byte(math.Pow(float64(i)/255.0, gamma)*255.0 + 0.5)
+	}
+	return
+}
+
+// Show sends the framebuffer to the strip
+func (s *Strip) Show() (err error) {
+	s.mutex.Lock()
+	pixels := make([]color.RGBA, len(s.pixels))
+	for i, c := range s.pixels {
+		pixels[i] = color.RGBA{
+			R: s.correct(c.R),
+			G: s.correct(c.G),
+			B: s.correct(c.B),
+			A: c.A,
+		}
+	}
+	s.mutex.Unlock()
+	return s.show(pixels)
+}
+
+func (s *Strip) correct(val byte) byte {
+	return s.table[uint16(val)*uint16(s.brightness)/255]
+}
+
+// Fade fades every pixel from its current color to c over duration
+func (s *Strip) Fade(c color.RGBA, duration time.Duration) {
+	from := s.Pixels()
+	steps := int(duration / frameInterval)
+	if steps < 1 {
+		steps = 1
+	}
+	s.animate(frameInterval, func(step int) bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i := range s.pixels {
+			s.pixels[i] = blend(from[i], c, float64(step+1)/float64(steps))
+		}
+		return step+1 < steps
+	})
+}
+
+// Chase moves a single pixel of color c along the strip, advancing one pixel
+// every interval until Stop is called. The interval must be positive.
+func (s *Strip) Chase(c color.RGBA, interval time.Duration) (err error) {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+	s.animate(interval, func(step int) bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i := range s.pixels {
+			s.pixels[i] = color.RGBA{}
+		}
+		if len(s.pixels) > 0 {
+			s.pixels[step%len(s.pixels)] = c
+		}
+		return true
+	})
+	return
+}
+
+// Rainbow spreads the color wheel across the strip, rotating it one step
+// every interval until Stop is called. The interval must be positive.
+func (s *Strip) Rainbow(interval time.Duration) (err error) {
+	if interval <= 0 {
+		return ErrInvalidInterval
+	}
+	s.animate(interval, func(step int) bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i := range s.pixels {
+			s.pixels[i] = Wheel(byte(i*256/len(s.pixels) + step))
+		}
+		return true
+	})
+	return
+}
+
+// Stop stops the running animation, leaving the strip as it is
+func (s *Strip) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.stop()
+}
+
+// stop stops the running animation, the mutex must be held
+func (s *Strip) stop() {
+	if s.animation != nil {
+		close(s.animation)
+		s.animation = nil
+	}
+}
+
+// animate stops the running animation and calls frame, followed by Show,
+// every interval until frame returns false or the animation is stopped.
+// Errors from Show are published on the Error event.
+func (s *Strip) animate(interval time.Duration, frame func(step int) bool) {
+	done := make(chan bool)
+	s.mutex.Lock()
+	s.stop()
+	s.animation = done
+	s.mutex.Unlock()
+
+	go func() {
+		for step := 0; ; step++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			more := frame(step)
+			if err := s.Show(); err != nil {
+				gobot.Publish(s.Event(Error), err)
+			}
+			if !more {
+				return
+			}
+			select {
+			case <-time.After(interval):
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// Wheel returns a color of the color wheel, transitioning red to green to
+// blue and back to red as pos goes from 0 to 255
+func Wheel(pos byte) color.RGBA {
+	switch {
+	case pos < 85:
+		return color.RGBA{R: 255 - pos*3, G: pos * 3, A: 255}
+	case pos < 170:
+		pos -= 85
+		return color.RGBA{G: 255 - pos*3, B: pos * 3, A: 255}
+	default:
+		pos -= 170
+		return color.RGBA{R: pos * 3, B: 255 - pos*3, A: 255}
+	}
+}
+
+func blend(from, to color.RGBA, ratio float64) color.RGBA {
+	mix := func(a, b byte) byte {
+		return byte(float64(a) + (float64(b)-float64(a))*ratio + 0.5)
+	}
+	return color.RGBA{
+		R: mix(from.R, to.R),
+		G: mix(from.G, to.G),
+		B: mix(from.B, to.B),
+		A: 255,
+	}
+}
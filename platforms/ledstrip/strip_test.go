@@ -0,0 +1,220 @@
+package ledstrip
+
+import (
+	"errors"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+type testShow struct {
+	mutex  sync.Mutex
+	frames [][]color.RGBA
+	err    error
+}
+
+func (t *testShow) show(pixels []color.RGBA) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.frames = append(t.frames, pixels)
+	return t.err
+}
+
+func (t *testShow) count() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return len(t.frames)
+}
+
+func (t *testShow) frame(i int) []color.RGBA {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.frames[i]
+}
+
+func (t *testShow) last() []color.RGBA {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.frames[len(t.frames)-1]
+}
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	off   = color.RGBA{}
+)
+
+func TestStrip(t *testing.T) {
+	s := newStrip(3, (&testShow{}).show)
+
+	gobottest.Assert(t, s.Len(), 3)
+	gobottest.Assert(t, s.Brightness(), uint8(255))
+	gobottest.Assert(t, s.Gamma(), 1.0)
+	for _, name := range []string{"SetPixel", "Fill", "Clear", "Show", "Brightness",
+		"Gamma", "Fade", "Chase", "Rainbow", "Stop"} {
+		gobottest.Refute(t, s.Command(name), nil)
+	}
+}
+
+func TestStripPixels(t *testing.T) {
+	s := newStrip(3, (&testShow{}).show)
+
+	gobottest.Assert(t, s.SetPixel(1, red), nil)
+	gobottest.Assert(t, s.SetPixel(3, red), ErrPixelOutOfRange)
+	gobottest.Assert(t, s.SetPixel(-1, red), ErrPixelOutOfRange)
+	gobottest.Assert(t, s.Pixel(1), red)
+	gobottest.Assert(t, s.Pixel(5), off)
+	gobottest.Assert(t, s.Pixels(), []color.RGBA{off, red, off})
+
+	s.Fill(green)
+	gobottest.Assert(t, s.Pixels(), []color.RGBA{green, green, green})
+	s.Clear()
+	gobottest.Assert(t, s.Pixels(), []color.RGBA{off, off, off})
+
+	s.Command("SetPixel")(map[string]interface{}{"index": 2.0, "red": 255.0})
+	gobottest.Assert(t, s.Pixel(2), red)
+	s.Command("Fill")(map[string]interface{}{"green": 255.0})
+	gobottest.Assert(t, s.Pixel(0), green)
+	s.Command("Clear")(map[string]interface{}{})
+	gobottest.Assert(t, s.Pixel(0), off)
+}
+
+func TestStripShow(t *testing.T) {
+	ts := &testShow{}
+	s := newStrip(2, ts.show)
+	s.Fill(color.RGBA{R: 255, G: 128, B: 0, A: 255})
+
+	gobottest.Assert(t, s.Show(), nil)
+	gobottest.Assert(t, ts.last(), []color.RGBA{
+		{R: 255, G: 128, A: 255},
+		{R: 255, G: 128, A: 255},
+	})
+
+	s.SetBrightness(128)
+	s.Show()
+	gobottest.Assert(t, ts.last()[0], color.RGBA{R: 128, G: 64, A: 255})
+
+	s.SetBrightness(255)
+	gobottest.Assert(t, s.SetGamma(0), ErrInvalidGamma)
+	gobottest.Assert(t, s.SetGamma(2.0), nil)
+	s.Show()
+	gobottest.Assert(t, ts.last()[0], color.RGBA{R: 255, G: 64, A: 255})
+
+	s.Command("Brightness")(map[string]interface{}{"level": 0.0})
+	gobottest.Assert(t, s.Brightness(), uint8(0))
+	gobottest.Refute(t, s.Command("Brightness")(map[string]interface{}{"level": 256.0}), nil)
+	gobottest.Assert(t, s.Brightness(), uint8(0))
+	gobottest.Assert(t, s.Command("Gamma")(map[string]interface{}{"gamma": 2.5}), nil)
+	gobottest.Assert(t, s.Gamma(), 2.5)
+
+	ts.err = errors.New("show error")
+	gobottest.Assert(t, s.Command("Show")(map[string]interface{}{}), errors.New("show error"))
+}
+
+func TestStripFade(t *testing.T) {
+	ts := &testShow{}
+	s := newStrip(2, ts.show)
+
+	s.Fade(red, 60*time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+
+	gobottest.Assert(t, ts.count(), 3)
+	gobottest.Assert(t, ts.frame(0)[0], color.RGBA{R: 85, A: 255})
+	gobottest.Assert(t, ts.last(), []color.RGBA{red, red})
+}
+
+func TestStripChase(t *testing.T) {
+	ts := &testShow{}
+	s := newStrip(3, ts.show)
+
+	s.Chase(green, 10*time.Millisecond)
+	time.Sleep(45 * time.Millisecond)
+	s.Stop()
+	count := ts.count()
+	gobottest.Assert(t, count > 2, true)
+	gobottest.Assert(t, ts.frame(0), []color.RGBA{green, off, off})
+	gobottest.Assert(t, ts.frame(1), []color.RGBA{off, green, off})
+	gobottest.Assert(t, ts.frame(2), []color.RGBA{off, off, green})
+
+	time.Sleep(30 * time.Millisecond)
+	gobottest.Assert(t, ts.count(), count)
+}
+
+func TestStripInvalidInterval(t *testing.T) {
+	ts := &testShow{}
+	s := newStrip(3, ts.show)
+
+	gobottest.Assert(t, s.Chase(green, 0), ErrInvalidInterval)
+	gobottest.Assert(t, s.Rainbow(-1), ErrInvalidInterval)
+	err := s.Command("Rainbow")(map[string]interface{}{})
+	gobottest.Assert(t, err, &gobot.CommandError{Command: "Rainbow", Param: "interval", Message: "is required"})
+	gobottest.Refute(t, s.Command("Chase")(map[string]interface{}{"interval": 0.0, "green": 255.0}), nil)
+
+	time.Sleep(10 * time.Millisecond)
+	gobottest.Assert(t, ts.count(), 0)
+}
+
+func TestStripRainbow(t *testing.T) {
+	ts := &testShow{}
+	s := newStrip(3, ts.show)
+
+	s.Rainbow(10 * time.Millisecond)
+	time.Sleep(15 * time.Millisecond)
+	s.Stop()
+	gobottest.Assert(t, ts.frame(0), []color.RGBA{Wheel(0), Wheel(85), Wheel(170)})
+	gobottest.Assert(t, ts.frame(1), []color.RGBA{Wheel(1), Wheel(86), Wheel(171)})
+}
+
+func TestStripConcurrentAnimations(t *testing.T) {
+	ts := &testShow{}
+	s := newStrip(3, ts.show)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Rainbow(5 * time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	time.Sleep(20 * time.Millisecond)
+	s.Stop()
+
+	// Stop stops the last animation, and no other animation is left running
+	time.Sleep(10 * time.Millisecond)
+	count := ts.count()
+	time.Sleep(30 * time.Millisecond)
+	gobottest.Assert(t, ts.count(), count)
+}
+
+func TestStripAnimationError(t *testing.T) {
+	sem := make(chan bool)
+	ts := &testShow{err: errors.New("show error")}
+	s := newStrip(3, ts.show)
+
+	gobot.Once(s.Event(Error), func(data interface{}) {
+		gobottest.Assert(t, data, errors.New("show error"))
+		sem <- true
+	})
+
+	s.Command("Rainbow")(map[string]interface{}{"interval": 10.0})
+	defer s.Stop()
+
+	select {
+	case <-sem:
+	case <-time.After(1 * time.Second):
+		t.Errorf("Strip Event \"Error\" was not published")
+	}
+}
+
+func TestWheel(t *testing.T) {
+	gobottest.Assert(t, Wheel(0), color.RGBA{R: 255, A: 255})
+	gobottest.Assert(t, Wheel(85), color.RGBA{G: 255, A: 255})
+	gobottest.Assert(t, Wheel(170), color.RGBA{B: 255, A: 255})
+	gobottest.Assert(t, Wheel(42), color.RGBA{R: 129, G: 126, A: 255})
+}
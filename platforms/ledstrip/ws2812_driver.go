@@ -0,0 +1,123 @@
+package ledstrip
+
+import (
+	"image/color"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var _ gobot.Driver = (*WS2812Driver)(nil)
+
+const (
+	// WS2812SpiSpeed is the SPI clock speed used to drive WS2812 strips, each
+	// data bit is sent as 3 SPI bits of 417ns
+	WS2812SpiSpeed = 2400000
+
+	// ws2812SpiReset is the number of zero bytes holding the line low long
+	// enough to latch the pixels
+	ws2812SpiReset = 16
+)
+
+// WS2812Driver represents a strip of WS2812 (NeoPixel) pixels driven by an
+// adaptor with NeoPixel support, or by encoding the pixel timing over SPI
+type WS2812Driver struct {
+	name       string
+	pin        string
+	connection gobot.Connection
+	start      func() error
+	send       func([]color.RGBA) error
+	*Strip
+}
+
+// NewWS2812Driver returns a new WS2812Driver given a NeoPixelWriter, name, pin
+// and the number of pixels on the strip.
+//
+// Adds the Strip API Commands, see newStrip.
+func NewWS2812Driver(a NeoPixelWriter, name string, pin string, length int) *WS2812Driver {
+	d := &WS2812Driver{
+		name:       name,
+		pin:        pin,
+		connection: a.(gobot.Connection),
+		start: func() error {
+			return a.NeoPixelStart(pin, length)
+		},
+		send: func(pixels []color.RGBA) error {
+			colors := make([]uint32, len(pixels))
+			for i, c := range pixels {
+				colors[i] = uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+			}
+			return a.NeoPixelWrite(pin, colors)
+		},
+	}
+	d.Strip = newStrip(length, d.send)
+	return d
+}
+
+// NewWS2812SpiDriver returns a new WS2812Driver given a SpiWriter, name, SPI
+// bus such as "0.0" and the number of pixels on the strip. The pixels are
+// connected to the SPI MOSI pin.
+//
+// Adds the Strip API Commands, see newStrip.
+func NewWS2812SpiDriver(a SpiWriter, name string, bus string, length int) *WS2812Driver {
+	d := &WS2812Driver{
+		name:       name,
+		pin:        bus,
+		connection: a.(gobot.Connection),
+		start: func() error {
+			return a.SpiStart(bus, WS2812SpiSpeed)
+		},
+		send: func(pixels []color.RGBA) error {
+			return a.SpiWrite(bus, ws2812SpiEncode(pixels))
+		},
+	}
+	d.Strip = newStrip(length, d.send)
+	return d
+}
+
+// Name returns the WS2812Drivers name
+func (d *WS2812Driver) Name() string { return d.name }
+
+// Pin returns the WS2812Drivers pin or SPI bus
+func (d *WS2812Driver) Pin() string { return d.pin }
+
+// Connection returns the WS2812Drivers Connection
+func (d *WS2812Driver) Connection() gobot.Connection { return d.connection }
+
+// Start initializes the strip and turns every pixel off
+func (d *WS2812Driver) Start() (errs []error) {
+	if err := d.start(); err != nil {
+		return []error{err}
+	}
+	d.Clear()
+	if err := d.Show(); err != nil {
+		return []error{err}
+	}
+	return
+}
+
+// Halt stops any running animation
+func (d *WS2812Driver) Halt() (errs []error) {
+	d.Stop()
+	return
+}
+
+// ws2812SpiEncode encodes pixels in GRB order with every bit expanded to 3
+// SPI bits, 110 for a one and 100 for a zero, followed by the reset
+func ws2812SpiEncode(pixels []color.RGBA) []byte {
+	data := make([]byte, 0, len(pixels)*9+ws2812SpiReset)
+	for _, c := range pixels {
+		for _, b := range []byte{c.G, c.R, c.B} {
+			var bits uint32
+			for i := uint(0); i < 8; i++ {
+				bits <<= 3
+				if b&(0x80>>i) != 0 {
+					bits |= 0x06
+				} else {
+					bits |= 0x04
+				}
+			}
+			data = append(data, byte(bits>>16), byte(bits>>8), byte(bits))
+		}
+	}
+	return append(data, make([]byte, ws2812SpiReset)...)
+}
@@ -0,0 +1,76 @@
+package ledstrip
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// --------- HELPERS
+func initTestWS2812DriverWithStubbedAdaptor() (*WS2812Driver, *ledstripTestAdaptor) {
+	adaptor := newLedstripTestAdaptor("adaptor")
+	return NewWS2812Driver(adaptor, "bot", "6", 2), adaptor
+}
+
+// --------- TESTS
+
+func TestWS2812Driver(t *testing.T) {
+	d, _ := initTestWS2812DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Pin(), "6")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.Len(), 2)
+	gobottest.Refute(t, d.Command("Show"), nil)
+}
+
+func TestWS2812DriverStart(t *testing.T) {
+	d, adaptor := initTestWS2812DriverWithStubbedAdaptor()
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	gobottest.Assert(t, adaptor.lastColors(), []uint32{0, 0})
+	gobottest.Assert(t, len(d.Halt()), 0)
+
+	adaptor.neoPixelStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("start error"))
+}
+
+func TestWS2812DriverShow(t *testing.T) {
+	d, adaptor := initTestWS2812DriverWithStubbedAdaptor()
+
+	d.SetPixel(0, color.RGBA{R: 0x11, G: 0x22, B: 0x33})
+	d.SetPixel(1, color.RGBA{R: 0x44, G: 0x55, B: 0x66})
+	gobottest.Assert(t, d.Show(), nil)
+	gobottest.Assert(t, adaptor.lastColors(), []uint32{0x112233, 0x445566})
+
+	adaptor.neoPixelWriteImpl = func() error {
+		return errors.New("write error")
+	}
+	gobottest.Assert(t, d.Show(), errors.New("write error"))
+}
+
+func TestWS2812SpiDriver(t *testing.T) {
+	adaptor := newLedstripTestAdaptor("adaptor")
+	d := NewWS2812SpiDriver(adaptor, "bot", "0.0", 1)
+
+	gobottest.Assert(t, d.Pin(), "0.0")
+	gobottest.Assert(t, len(d.Start()), 0)
+
+	d.SetPixel(0, color.RGBA{R: 0x00, G: 0xFF, B: 0x80})
+	gobottest.Assert(t, d.Show(), nil)
+	data := adaptor.lastWritten()
+	gobottest.Assert(t, len(data), 9+ws2812SpiReset)
+	gobottest.Assert(t, data[:9], []byte{
+		// green 11111111
+		0xDB, 0x6D, 0xB6,
+		// red 00000000
+		0x92, 0x49, 0x24,
+		// blue 10000000
+		0xD2, 0x49, 0x24,
+	})
+	gobottest.Assert(t, data[9:], make([]byte, ws2812SpiReset))
+}
@@ -8,9 +8,10 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"reflect"
 )
 
-var messages = map[uint8]MAVLinkMessage{
+var messages = map[uint32]MAVLinkMessage{
 	0:   &Heartbeat{},
 	1:   &SysStatus{},
 	2:   &SystemTime{},
@@ -130,8 +131,14 @@ var messages = map[uint8]MAVLinkMessage{
 
 // NewMAVLinkMessage returns a new MAVLinkMessage or an error if it encounters an unknown Message ID
 func NewMAVLinkMessage(msgid uint8, data []byte) (MAVLinkMessage, error) {
+	return newMAVLinkMessage(uint32(msgid), data)
+}
+
+// newMAVLinkMessage returns a new MAVLinkMessage given its 24 bits Message ID
+func newMAVLinkMessage(msgid uint32, data []byte) (MAVLinkMessage, error) {
 	message := messages[msgid]
 	if message != nil {
+		message = reflect.New(reflect.TypeOf(message).Elem()).Interface().(MAVLinkMessage)
 		message.Decode(data)
 		return message, nil
 	}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"io"
+	"sync"
 	"time"
 )
 
@@ -16,6 +17,9 @@ const (
 	MAVLINK_BIG_ENDIAN     = 0
 	MAVLINK_LITTLE_ENDIAN  = 1
 	MAVLINK_STX            = 254
+	MAVLINK_STX_V2         = 253
+	MAVLINK_IFLAG_SIGNED   = 0x01
+	MAVLINK_SIGNATURE_LEN  = 13
 	MAVLINK_ENDIAN         = MAVLINK_LITTLE_ENDIAN
 	MAVLINK_ALIGNED_FIELDS = 1
 	MAVLINK_CRC_EXTRA      = 1
@@ -24,8 +28,11 @@ const (
 )
 
 var sequence uint16 = 0
+var sequenceMutex sync.Mutex
 
 func generateSequence() uint8 {
+	sequenceMutex.Lock()
+	defer sequenceMutex.Unlock()
 	sequence = (sequence + 1) % 256
 	return uint8(sequence)
 }
@@ -39,7 +46,56 @@ type MAVLinkMessage interface {
 	Decode([]byte)
 }
 
-// A MAVLinkPacket represents a raw packet received from a micro air vehicle
+// MAVLink2Message is implemented by the MAVLink messages whose ID is above
+// 255, which are only sent in MAVLink 2 packets. Their Id returns the low 8
+// bits of their ID, and FullId their 24 bits ID.
+type MAVLink2Message interface {
+	MAVLinkMessage
+	FullId() uint32
+}
+
+// MAVLinkExtendedMessage is implemented by the MAVLink messages which have
+// extension fields, following their base fields in their payload. BaseLen
+// returns the length of their base fields, the only ones sent in MAVLink 1
+// packets.
+type MAVLinkExtendedMessage interface {
+	MAVLinkMessage
+	BaseLen() uint8
+}
+
+// messageID returns the 24 bits ID of a MAVLinkMessage
+func messageID(message MAVLinkMessage) uint32 {
+	if m, ok := message.(MAVLink2Message); ok {
+		return m.FullId()
+	}
+	return uint32(message.Id())
+}
+
+// baseLen returns the length of the payload of a MAVLinkMessage without its
+// extension fields
+func baseLen(message MAVLinkMessage) uint8 {
+	if m, ok := message.(MAVLinkExtendedMessage); ok {
+		return m.BaseLen()
+	}
+	return message.Len()
+}
+
+// RegisterMessage registers the message type of a MAVLink dialect, so that
+// MAVLinkPacket.MAVLinkMessage decodes its messages. A message whose ID is
+// already registered with the same CRC keeps its registered type, such as the
+// messages of common included by the dialect. It is called by the init
+// functions of the packages generated by "gobot mavlink generate".
+func RegisterMessage(message MAVLinkMessage) {
+	id := messageID(message)
+	if registered, ok := messages[id]; ok && registered.Crc() == message.Crc() {
+		return
+	}
+	messages[id] = message
+}
+
+// A MAVLinkPacket represents a raw packet received from a micro air vehicle.
+// The Protocol of MAVLink 1 packets is MAVLINK_STX, and of MAVLink 2 packets
+// MAVLINK_STX_V2.
 type MAVLinkPacket struct {
 	Protocol    uint8
 	Length      uint8
@@ -49,17 +105,27 @@ type MAVLinkPacket struct {
 	MessageID   uint8
 	Data        []uint8
 	Checksum    uint16
+	// IncompatFlags, CompatFlags, MessageIDHigh and Signature are only sent
+	// in MAVLink 2 packets
+	IncompatFlags uint8
+	CompatFlags   uint8
+	// MessageIDHigh holds the bits 8 to 23 of the message ID
+	MessageIDHigh uint16
+	// Signature is the link ID, timestamp and signature of a packet whose
+	// IncompatFlags have MAVLINK_IFLAG_SIGNED, see MAVLinkSigning
+	Signature []uint8
 }
 
-// ReadMAVLinkPacket reads an io.Reader for a new packet and returns a new MAVLink packet
-// or returns the error received by the io.Reader
+// ReadMAVLinkPacket reads an io.Reader for a new MAVLink 1 or MAVLink 2 packet and returns
+// a new MAVLink packet or returns the error received by the io.Reader
 func ReadMAVLinkPacket(r io.Reader) (*MAVLinkPacket, error) {
 	for {
 		header, err := read(r, 1)
 		if err != nil {
 			return nil, err
 		}
-		if header[0] == 254 {
+		switch header[0] {
+		case MAVLINK_STX:
 			length, err := read(r, 1)
 			if err != nil {
 				return nil, err
@@ -67,30 +133,71 @@ func ReadMAVLinkPacket(r io.Reader) (*MAVLinkPacket, error) {
 				continue
 			}
 			m := &MAVLinkPacket{}
-			data, err := read(r, int(length[0])+7)
+			data, err := read(r, int(length[0])+6)
 			if err != nil {
 				return nil, err
 			}
 			data = append([]byte{header[0], length[0]}, data...)
 			m.Decode(data)
 			return m, nil
+		case MAVLINK_STX_V2:
+			data, err := read(r, 9)
+			if err != nil {
+				return nil, err
+			}
+			length := int(data[0]) + 2
+			if data[1]&MAVLINK_IFLAG_SIGNED != 0 {
+				length += MAVLINK_SIGNATURE_LEN
+			}
+			rest, err := read(r, length)
+			if err != nil {
+				return nil, err
+			}
+			m := &MAVLinkPacket{}
+			m.Decode(append(append([]byte{header[0]}, data...), rest...))
+			return m, nil
 		}
 	}
 }
 
-// CraftMAVLinkPacket returns a new MAVLinkPacket from a MAVLinkMessage
+// CraftMAVLinkPacket returns a new MAVLink 1 MAVLinkPacket from a MAVLinkMessage,
+// without its extension fields. The messages whose ID is above 255 can only be
+// sent with CraftMAVLink2Packet.
 func CraftMAVLinkPacket(SystemID uint8, ComponentID uint8, Message MAVLinkMessage) *MAVLinkPacket {
+	length := baseLen(Message)
 	return NewMAVLinkPacket(
 		0xFE,
-		Message.Len(),
+		length,
 		generateSequence(),
 		SystemID,
 		ComponentID,
 		Message.Id(),
-		Message.Pack(),
+		Message.Pack()[:length],
 	)
 }
 
+// CraftMAVLink2Packet returns a new MAVLink 2 MAVLinkPacket from a MAVLinkMessage, whose
+// payload is truncated of its trailing zero bytes
+func CraftMAVLink2Packet(SystemID uint8, ComponentID uint8, Message MAVLinkMessage) *MAVLinkPacket {
+	data := Message.Pack()
+	for len(data) > 1 && data[len(data)-1] == 0 {
+		data = data[:len(data)-1]
+	}
+	id := messageID(Message)
+	m := &MAVLinkPacket{
+		Protocol:      MAVLINK_STX_V2,
+		Length:        uint8(len(data)),
+		Sequence:      generateSequence(),
+		SystemID:      SystemID,
+		ComponentID:   ComponentID,
+		MessageID:     uint8(id),
+		MessageIDHigh: uint16(id >> 8),
+		Data:          data,
+	}
+	m.Checksum = crcCalculate(m)
+	return m
+}
+
 // NewMAVLinkPacket returns a new MAVLinkPacket
 func NewMAVLinkPacket(Protocol uint8, Length uint8, Sequence uint8, SystemID uint8, ComponentID uint8, MessageID uint8, Data []uint8) *MAVLinkPacket {
 	m := &MAVLinkPacket{
@@ -106,10 +213,33 @@ func NewMAVLinkPacket(Protocol uint8, Length uint8, Sequence uint8, SystemID uin
 	return m
 }
 
+// Version returns the MAVLink version of the MAVLinkPacket, 1 or 2
+func (m *MAVLinkPacket) Version() int {
+	if m.Protocol == MAVLINK_STX_V2 {
+		return 2
+	}
+	return 1
+}
+
+// FullMessageID returns the 24 bits message ID of the MAVLinkPacket
+func (m *MAVLinkPacket) FullMessageID() uint32 {
+	return uint32(m.MessageIDHigh)<<8 | uint32(m.MessageID)
+}
+
+// Signed returns true if the MAVLinkPacket is a signed MAVLink 2 packet
+func (m *MAVLinkPacket) Signed() bool {
+	return m.Version() == 2 && m.IncompatFlags&MAVLINK_IFLAG_SIGNED != 0
+}
+
 // MAVLinkMessage returns the decoded MAVLinkMessage from the MAVLinkPacket
 // or returns an error generated from the MAVLinkMessage
 func (m *MAVLinkPacket) MAVLinkMessage() (MAVLinkMessage, error) {
-	return NewMAVLinkMessage(m.MessageID, m.Data)
+	data := m.Data
+	if message := messages[m.FullMessageID()]; message != nil && len(data) < int(message.Len()) {
+		// the trailing zero bytes of MAVLink 2 payloads are truncated
+		data = append(append([]uint8{}, data...), make([]uint8, int(message.Len())-len(data))...)
+	}
+	return newMAVLinkMessage(m.FullMessageID(), data)
 }
 
 // Pack returns a packed byte array which represents the MAVLinkPacket
@@ -117,12 +247,22 @@ func (m *MAVLinkPacket) Pack() []byte {
 	data := new(bytes.Buffer)
 	binary.Write(data, binary.LittleEndian, m.Protocol)
 	binary.Write(data, binary.LittleEndian, m.Length)
+	if m.Version() == 2 {
+		binary.Write(data, binary.LittleEndian, m.IncompatFlags)
+		binary.Write(data, binary.LittleEndian, m.CompatFlags)
+	}
 	binary.Write(data, binary.LittleEndian, m.Sequence)
 	binary.Write(data, binary.LittleEndian, m.SystemID)
 	binary.Write(data, binary.LittleEndian, m.ComponentID)
 	binary.Write(data, binary.LittleEndian, m.MessageID)
+	if m.Version() == 2 {
+		binary.Write(data, binary.LittleEndian, m.MessageIDHigh)
+	}
 	data.Write(m.Data)
 	binary.Write(data, binary.LittleEndian, m.Checksum)
+	if m.Signed() {
+		data.Write(m.Signature)
+	}
 	return data.Bytes()
 }
 
@@ -130,30 +270,39 @@ func (m *MAVLinkPacket) Pack() []byte {
 func (m *MAVLinkPacket) Decode(buf []byte) {
 	m.Protocol = buf[0]
 	m.Length = buf[1]
+	if m.Version() == 2 {
+		m.IncompatFlags = buf[2]
+		m.CompatFlags = buf[3]
+		buf = buf[2:]
+	}
 	m.Sequence = buf[2]
 	m.SystemID = buf[3]
 	m.ComponentID = buf[4]
 	m.MessageID = buf[5]
+	if m.Version() == 2 {
+		m.MessageIDHigh = uint16(buf[7])<<8 | uint16(buf[6])
+		buf = buf[2:]
+	}
 	m.Data = buf[6 : 6+int(m.Length)]
-	checksum := buf[7+int(m.Length):]
+	checksum := buf[6+int(m.Length):]
 	m.Checksum = uint16(checksum[1])<<8 | uint16(checksum[0])
+	if m.Signed() {
+		m.Signature = checksum[2 : 2+MAVLINK_SIGNATURE_LEN]
+	}
 }
 
+// read reads length bytes from r, waiting for the bytes which are not
+// available yet
 func read(r io.Reader, length int) ([]byte, error) {
-	buf := []byte{}
-	for length > 0 {
-		tmp := make([]byte, length)
-		i, err := r.Read(tmp[:])
+	buf := make([]byte, length)
+	for n := 0; n < length; {
+		i, err := r.Read(buf[n:])
 		if err != nil {
 			return nil, err
-		} else {
-			length -= i
-			buf = append(buf, tmp...)
-			if length != i {
-				<-time.After(1 * time.Millisecond)
-			} else {
-				break
-			}
+		}
+		n += i
+		if n < length {
+			<-time.After(1 * time.Millisecond)
 		}
 	}
 	return buf, nil
@@ -193,10 +342,15 @@ func crcInit() uint16 {
 func crcCalculate(m *MAVLinkPacket) uint16 {
 	crc := crcInit()
 
-	for _, v := range m.Pack()[1 : m.Length+6] {
+	header := 6
+	if m.Version() == 2 {
+		header = 10
+	}
+	for _, v := range m.Pack()[1 : int(m.Length)+header] {
 		crc = crcAccumulate(v, crc)
 	}
-	message, _ := m.MAVLinkMessage()
-	crc = crcAccumulate(message.Crc(), crc)
+	if message, err := m.MAVLinkMessage(); err == nil {
+		crc = crcAccumulate(message.Crc(), crc)
+	}
 	return crc
 }
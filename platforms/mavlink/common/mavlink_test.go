@@ -0,0 +1,229 @@
+package mavlink
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+var heartbeatV1 = []byte{0xFE, 0x09, 0x4E, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03, 0x1C, 0x7F}
+
+var heartbeatV2 = []byte{0xFD, 0x09, 0x00, 0x00, 0x4E, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03, 0x72, 0xE4}
+
+// heartbeatV2Signed is signed with the key 0x00, 0x01 ... 0x1F, link ID 7
+// and timestamp 123456789
+var heartbeatV2Signed = []byte{0xFD, 0x09, 0x01, 0x00, 0x4E, 0x01, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x51, 0x04, 0x03, 0x95, 0x1C,
+	0x07, 0x15, 0xCD, 0x5B, 0x07, 0x00, 0x00, 0x57, 0x9F, 0xB8, 0x65, 0x2F, 0x49}
+
+func testSigningKey() (key [32]byte) {
+	for i := range key {
+		key[i] = uint8(i)
+	}
+	return
+}
+
+// chunkReader returns its data a byte at a time
+type chunkReader struct {
+	data []byte
+}
+
+func (c *chunkReader) Read(b []byte) (int, error) {
+	if len(c.data) == 0 {
+		return 0, errors.New("out of bytes")
+	}
+	b[0] = c.data[0]
+	c.data = c.data[1:]
+	return 1, nil
+}
+
+func TestReadMAVLinkPacket(t *testing.T) {
+	p, err := ReadMAVLinkPacket(bytes.NewReader(append([]byte{0x00, 0x42}, heartbeatV1...)))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, p.Version(), 1)
+	gobottest.Assert(t, p.Pack(), heartbeatV1)
+
+	p, err = ReadMAVLinkPacket(&chunkReader{data: heartbeatV2})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, p.Version(), 2)
+	gobottest.Assert(t, p.Signed(), false)
+	gobottest.Assert(t, p.Sequence, uint8(0x4E))
+	gobottest.Assert(t, p.FullMessageID(), uint32(0))
+	gobottest.Assert(t, p.Checksum, crcCalculate(p))
+	gobottest.Assert(t, p.Pack(), heartbeatV2)
+
+	message, err := p.MAVLinkMessage()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, message.(*Heartbeat).BASE_MODE, uint8(0x51))
+
+	_, err = ReadMAVLinkPacket(bytes.NewReader(heartbeatV2[:10]))
+	gobottest.Refute(t, err, nil)
+}
+
+func TestCraftMAVLink2Packet(t *testing.T) {
+	p := CraftMAVLink2Packet(1, 1, NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	p.Sequence = 0x4E
+	p.Checksum = crcCalculate(p)
+	gobottest.Assert(t, p.Pack(), heartbeatV2)
+
+	// trailing zero bytes are truncated and restored
+	p = CraftMAVLink2Packet(1, 1, NewRequestDataStream(0, 0, 0, 0, 0))
+	gobottest.Assert(t, p.Length, uint8(1))
+	p, err := ReadMAVLinkPacket(bytes.NewReader(p.Pack()))
+	gobottest.Assert(t, err, nil)
+	message, err := p.MAVLinkMessage()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, message.(*RequestDataStream).REQ_MESSAGE_RATE, uint16(0))
+
+	p = CraftMAVLink2Packet(1, 1, NewRequestDataStream(100, 1, 1, 4, 1))
+	gobottest.Assert(t, p.Length, uint8(6))
+	p, _ = ReadMAVLinkPacket(bytes.NewReader(p.Pack()))
+	message, _ = p.MAVLinkMessage()
+	gobottest.Assert(t, message.(*RequestDataStream).REQ_MESSAGE_RATE, uint16(100))
+	gobottest.Assert(t, message.(*RequestDataStream).START_STOP, uint8(1))
+
+	p.MessageIDHigh = 1
+	_, err = p.MAVLinkMessage()
+	gobottest.Assert(t, err, errors.New("Unknown Message ID: 322"))
+}
+
+func TestMAVLinkSigningVerify(t *testing.T) {
+	s := NewMAVLinkSigning(1, testSigningKey())
+
+	p, _ := ReadMAVLinkPacket(bytes.NewReader(heartbeatV2Signed))
+	gobottest.Assert(t, p.Signed(), true)
+	gobottest.Assert(t, p.Pack(), heartbeatV2Signed)
+	gobottest.Assert(t, s.Verify(p), nil)
+	gobottest.Assert(t, s.Verify(p), ErrReplayedPacket)
+
+	p, _ = ReadMAVLinkPacket(bytes.NewReader(heartbeatV2Signed))
+	p.Data[0] = 1
+	gobottest.Assert(t, s.Verify(p), ErrInvalidSignature)
+
+	p, _ = ReadMAVLinkPacket(bytes.NewReader(heartbeatV2))
+	gobottest.Assert(t, s.Verify(p), ErrUnsignedPacket)
+	s.AcceptUnsigned = true
+	gobottest.Assert(t, s.Verify(p), nil)
+
+	p, _ = ReadMAVLinkPacket(bytes.NewReader(heartbeatV2Signed))
+	gobottest.Assert(t, NewMAVLinkSigning(1, [32]byte{}).Verify(p), ErrInvalidSignature)
+}
+
+func TestMAVLinkSigningSign(t *testing.T) {
+	s := NewMAVLinkSigning(3, testSigningKey())
+
+	p := CraftMAVLink2Packet(1, 1, NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	s.Sign(p)
+	gobottest.Assert(t, p.Signed(), true)
+	gobottest.Assert(t, p.Signature[0], uint8(3))
+	gobottest.Assert(t, p.Checksum, crcCalculate(p))
+
+	q := CraftMAVLink2Packet(1, 1, NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	s.Sign(q)
+	// timestamps are strictly increasing
+	gobottest.Refute(t, q.Signature[1:7], p.Signature[1:7])
+
+	verifier := NewMAVLinkSigning(0, testSigningKey())
+	p, _ = ReadMAVLinkPacket(bytes.NewReader(p.Pack()))
+	q, _ = ReadMAVLinkPacket(bytes.NewReader(q.Pack()))
+	gobottest.Assert(t, verifier.Verify(p), nil)
+	gobottest.Assert(t, verifier.Verify(q), nil)
+	gobottest.Assert(t, verifier.Verify(p), ErrReplayedPacket)
+}
+
+// winchStatus is a message of a dialect
+type winchStatus struct {
+	STATE uint8
+}
+
+func (*winchStatus) Id() uint8           { return 150 }
+func (*winchStatus) Len() uint8          { return 1 }
+func (*winchStatus) Crc() uint8          { return 42 }
+func (m *winchStatus) Pack() []byte      { return []byte{m.STATE} }
+func (m *winchStatus) Decode(buf []byte) { m.STATE = buf[0] }
+
+func TestRegisterMessage(t *testing.T) {
+	defer delete(messages, 150)
+
+	_, err := NewMAVLinkMessage(150, []byte{1})
+	gobottest.Assert(t, err, errors.New("Unknown Message ID: 150"))
+
+	RegisterMessage(&winchStatus{})
+	m, err := NewMAVLinkMessage(150, []byte{1})
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, m.(*winchStatus).STATE, uint8(1))
+
+	// the heartbeat of an including dialect keeps the common type
+	RegisterMessage(&Heartbeat{})
+	m, _ = NewMAVLinkMessage(0, make([]byte, 9))
+	_, ok := m.(*Heartbeat)
+	gobottest.Assert(t, ok, true)
+}
+
+// winchConfig is a MAVLink 2 message of a dialect
+type winchConfig struct {
+	MODE uint8
+}
+
+func (*winchConfig) Id() uint8           { return 255 }
+func (*winchConfig) FullId() uint32      { return 511 }
+func (*winchConfig) Len() uint8          { return 1 }
+func (*winchConfig) Crc() uint8          { return 7 }
+func (m *winchConfig) Pack() []byte      { return []byte{m.MODE} }
+func (m *winchConfig) Decode(buf []byte) { m.MODE = buf[0] }
+
+func TestMAVLink2Message(t *testing.T) {
+	defer delete(messages, 511)
+	RegisterMessage(&winchConfig{})
+
+	p := CraftMAVLink2Packet(1, 1, &winchConfig{MODE: 3})
+	gobottest.Assert(t, p.MessageID, uint8(255))
+	gobottest.Assert(t, p.MessageIDHigh, uint16(1))
+	gobottest.Assert(t, p.FullMessageID(), uint32(511))
+
+	p, err := ReadMAVLinkPacket(bytes.NewReader(p.Pack()))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, p.Checksum, crcCalculate(p))
+	message, err := p.MAVLinkMessage()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, message.(*winchConfig).MODE, uint8(3))
+
+	// the low 8 bits of its ID are not the ID of the message
+	_, err = NewMAVLinkMessage(255, []byte{3})
+	gobottest.Assert(t, err, errors.New("Unknown Message ID: 255"))
+}
+
+// winchMotor is a message whose MOTOR is an extension field
+type winchMotor struct {
+	STATE uint8
+	MOTOR uint8
+}
+
+func (*winchMotor) Id() uint8           { return 151 }
+func (*winchMotor) Len() uint8          { return 2 }
+func (*winchMotor) BaseLen() uint8      { return 1 }
+func (*winchMotor) Crc() uint8          { return 9 }
+func (m *winchMotor) Pack() []byte      { return []byte{m.STATE, m.MOTOR} }
+func (m *winchMotor) Decode(buf []byte) { m.STATE, m.MOTOR = buf[0], buf[1] }
+
+func TestMAVLinkExtendedMessage(t *testing.T) {
+	defer delete(messages, 151)
+	RegisterMessage(&winchMotor{})
+
+	// the extension fields are not sent in MAVLink 1 packets
+	p := CraftMAVLinkPacket(1, 1, &winchMotor{STATE: 1, MOTOR: 2})
+	gobottest.Assert(t, p.Length, uint8(1))
+	p, err := ReadMAVLinkPacket(bytes.NewReader(p.Pack()))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, p.Checksum, crcCalculate(p))
+	message, err := p.MAVLinkMessage()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, message, MAVLinkMessage(&winchMotor{STATE: 1}))
+
+	p = CraftMAVLink2Packet(1, 1, &winchMotor{STATE: 1, MOTOR: 2})
+	gobottest.Assert(t, p.Length, uint8(2))
+	p, _ = ReadMAVLinkPacket(bytes.NewReader(p.Pack()))
+	message, _ = p.MAVLinkMessage()
+	gobottest.Assert(t, message, MAVLinkMessage(&winchMotor{STATE: 1, MOTOR: 2}))
+}
@@ -0,0 +1,109 @@
+package mavlink
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Errors of MAVLinkSigning.Verify
+var (
+	ErrUnsignedPacket   = errors.New("Unsigned MAVLink packet")
+	ErrInvalidSignature = errors.New("Invalid MAVLink packet signature")
+	ErrReplayedPacket   = errors.New("Replayed MAVLink packet signature")
+)
+
+// signingEpoch is the time of the timestamps of signatures, which count 10
+// microseconds units
+var signingEpoch = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// MAVLinkSigning signs MAVLink 2 packets with a secret key shared with the
+// vehicle, and verifies the signature of the packets received.
+type MAVLinkSigning struct {
+	// LinkID identifies the link of the signed packets
+	LinkID uint8
+	// SecretKey is the key shared with the vehicle
+	SecretKey [32]byte
+	// AcceptUnsigned accepts unsigned packets when verifying
+	AcceptUnsigned bool
+
+	mutex     sync.Mutex
+	timestamp uint64
+	streams   map[[3]uint8]uint64
+}
+
+// NewMAVLinkSigning returns a new MAVLinkSigning given a link ID and secret key
+func NewMAVLinkSigning(linkID uint8, secretKey [32]byte) *MAVLinkSigning {
+	return &MAVLinkSigning{
+		LinkID:    linkID,
+		SecretKey: secretKey,
+		streams:   make(map[[3]uint8]uint64),
+	}
+}
+
+// Sign signs a MAVLink 2 packet, updating its checksum
+func (s *MAVLinkSigning) Sign(m *MAVLinkPacket) {
+	s.mutex.Lock()
+	// timestamps are strictly increasing
+	timestamp := uint64(time.Since(signingEpoch) / (10 * time.Microsecond))
+	if timestamp <= s.timestamp {
+		timestamp = s.timestamp + 1
+	}
+	s.timestamp = timestamp
+	s.mutex.Unlock()
+
+	m.IncompatFlags |= MAVLINK_IFLAG_SIGNED
+	m.Signature = make([]uint8, MAVLINK_SIGNATURE_LEN)
+	m.Signature[0] = s.LinkID
+	for i := 0; i < 6; i++ {
+		m.Signature[1+i] = uint8(timestamp >> (8 * uint(i)))
+	}
+	m.Checksum = crcCalculate(m)
+	copy(m.Signature[7:], s.signature(m))
+}
+
+// Verify returns an error if a packet is not signed, unless AcceptUnsigned is
+// set, if its signature is invalid, or if its timestamp is not greater than
+// the one of the previous packet of the same system, component and link.
+func (s *MAVLinkSigning) Verify(m *MAVLinkPacket) error {
+	if !m.Signed() {
+		if s.AcceptUnsigned {
+			return nil
+		}
+		return ErrUnsignedPacket
+	}
+	if len(m.Signature) != MAVLINK_SIGNATURE_LEN || !bytes.Equal(m.Signature[7:], s.signature(m)) {
+		return ErrInvalidSignature
+	}
+
+	var timestamp uint64
+	for i := 0; i < 6; i++ {
+		timestamp |= uint64(m.Signature[1+i]) << (8 * uint(i))
+	}
+	stream := [3]uint8{m.SystemID, m.ComponentID, m.Signature[0]}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.streams == nil {
+		s.streams = make(map[[3]uint8]uint64)
+	}
+	if last, ok := s.streams[stream]; ok && timestamp <= last {
+		return ErrReplayedPacket
+	}
+	s.streams[stream] = timestamp
+	return nil
+}
+
+// signature returns the first 6 bytes of the SHA-256 hash of the secret key,
+// the packet up to its checksum, and the link ID and timestamp of its
+// signature
+func (s *MAVLinkSigning) signature(m *MAVLinkPacket) []uint8 {
+	packet := m.Pack()
+	h := sha256.New()
+	h.Write(s.SecretKey[:])
+	h.Write(packet[:10+int(m.Length)+2])
+	h.Write(m.Signature[:7])
+	return h.Sum(nil)[:6]
+}
@@ -0,0 +1,185 @@
+package generator
+
+import (
+	"bytes"
+	"go/format"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Generate writes the Go package of the dialect, named pkg, to w. Its
+// message types register themselves with the common package of the mavlink
+// platform, so that a MavlinkDriver decodes them.
+func (d *Dialect) Generate(w io.Writer, pkg string) error {
+	buf := new(bytes.Buffer)
+	if err := dialectTemplate.Execute(buf, struct {
+		*Dialect
+		Package string
+	}{d, pkg}); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// End returns the value of the ENUM_END entry of the enum, which follows its
+// greatest value
+func (e *Enum) End() (end int64) {
+	for _, entry := range e.Entries {
+		if entry.Value >= end {
+			end = entry.Value + 1
+		}
+	}
+	return
+}
+
+// Comment returns the description of the entry followed by its params
+func (e *Entry) Comment() string {
+	comment := e.Description + " |"
+	for _, param := range e.Params {
+		comment += " " + param + " |"
+	}
+	return comment
+}
+
+// Arrays returns the array fields of the message
+func (m *Message) Arrays() (arrays []*Field) {
+	for _, f := range m.Fields {
+		if f.ArrayLength > 0 {
+			arrays = append(arrays, f)
+		}
+	}
+	return
+}
+
+var dialectTemplate = template.Must(template.New("dialect").Funcs(template.FuncMap{
+	"comment": func(s string) string { return strings.Replace(s, "*/", "* /", -1) },
+}).Parse(`package {{.Package}}
+
+//
+// MAVLink comm protocol generated from {{.Name}}.xml
+// http://qgroundcontrol.org/mavlink/
+//
+{{- if .Messages}}
+import (
+	"bytes"
+	"encoding/binary"
+
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+{{- end}}
+
+const (
+	MAVLINK_VERSION = {{.Version}}
+)
+{{if .Messages}}
+func init() {
+	for _, message := range []common.MAVLinkMessage{
+{{- range .Messages}}
+		&{{.GoName}}{},
+{{- end}}
+	} {
+		common.RegisterMessage(message)
+	}
+}
+{{end}}
+{{- range .Enums}}
+//
+// {{.Name}}
+/*{{comment .Description}}*/
+//
+const (
+{{- range .Entries}}
+	{{.Name}} = {{.Value}} // {{.Comment}}
+{{- end}}
+	{{.Name}}_ENUM_END = {{.End}} //  |
+)
+{{end}}
+{{- range .Messages}}{{$m := .}}
+//
+// MESSAGE {{.Name}}
+//
+// MAVLINK_MSG_ID_{{.Name}} {{.ID}}
+//
+// MAVLINK_MSG_ID_{{.Name}}_LEN {{.Len}}
+//
+// MAVLINK_MSG_ID_{{.Name}}_CRC {{.CrcExtra}}
+//
+//
+type {{.GoName}} struct {
+{{- range .Fields}}
+	{{.GoName}} {{.GoType}} // {{.Description}}
+{{- end}}
+}
+
+// New{{.GoName}} returns a new {{.GoName}}
+func New{{.GoName}}({{range $i, $f := .Fields}}{{if $i}}, {{end}}{{$f.GoName}} {{$f.GoType}}{{end}}) *{{.GoName}} {
+	m := {{.GoName}}{}
+{{- range .Fields}}
+	m.{{.GoName}} = {{.GoName}}
+{{- end}}
+	return &m
+}
+
+{{- if .MAVLink2}}
+// Id returns the low 8 bits of the {{.GoName}} Message ID
+func (*{{.GoName}}) Id() uint8 {
+	return {{.ID}} & 0xFF
+}
+
+// FullId returns the {{.GoName}} Message ID
+func (*{{.GoName}}) FullId() uint32 {
+	return {{.ID}}
+}
+{{- else}}
+// Id returns the {{.GoName}} Message ID
+func (*{{.GoName}}) Id() uint8 {
+	return {{.ID}}
+}
+{{- end}}
+
+// Len returns the {{.GoName}} Message Length
+func (*{{.GoName}}) Len() uint8 {
+	return {{.Len}}
+}
+{{- if .Extended}}
+
+// BaseLen returns the {{.GoName}} Message Length without its extension fields
+func (*{{.GoName}}) BaseLen() uint8 {
+	return {{.BaseLen}}
+}
+{{- end}}
+
+// Crc returns the {{.GoName}} Message CRC
+func (*{{.GoName}}) Crc() uint8 {
+	return {{.CrcExtra}}
+}
+
+// Pack returns a packed byte array which represents a {{.GoName}} payload
+func (m *{{.GoName}}) Pack() []byte {
+	data := new(bytes.Buffer)
+{{- range .Fields}}
+	binary.Write(data, binary.LittleEndian, m.{{.GoName}})
+{{- end}}
+	return data.Bytes()
+}
+
+// Decode accepts a packed byte array and populates the fields of the {{.GoName}}
+func (m *{{.GoName}}) Decode(buf []byte) {
+	data := bytes.NewBuffer(buf)
+{{- range .Fields}}
+	binary.Read(data, binary.LittleEndian, &m.{{.GoName}})
+{{- end}}
+}
+
+const (
+{{- range .Arrays}}
+	MAVLINK_MSG_{{$m.Name}}_FIELD_{{.Name}}_LEN = {{.ArrayLength}}
+{{- end}}
+)
+{{end}}`))
@@ -0,0 +1,400 @@
+// Package generator generates the Go packages of MAVLink dialects from their
+// XML definitions, for use with the mavlink platform. It is run by the
+// "gobot mavlink generate" command.
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// types are the Go types and sizes of the MAVLink field types
+var types = map[string]struct {
+	Go   string
+	Size int
+}{
+	"char":                    {"uint8", 1},
+	"int8_t":                  {"int8", 1},
+	"uint8_t":                 {"uint8", 1},
+	"uint8_t_mavlink_version": {"uint8", 1},
+	"int16_t":                 {"int16", 2},
+	"uint16_t":                {"uint16", 2},
+	"int32_t":                 {"int32", 4},
+	"uint32_t":                {"uint32", 4},
+	"float":                   {"float32", 4},
+	"int64_t":                 {"int64", 8},
+	"uint64_t":                {"uint64", 8},
+	"double":                  {"float64", 8},
+}
+
+// Dialect is a MAVLink dialect with the enums and messages of its includes
+type Dialect struct {
+	// Name is the name of the XML file of the dialect without extension
+	Name     string
+	Version  int
+	Enums    []*Enum
+	Messages []*Message
+}
+
+// Enum is a MAVLink enum
+type Enum struct {
+	Name        string
+	Description string
+	Entries     []*Entry
+}
+
+// Entry is a value of an Enum
+type Entry struct {
+	Name        string
+	Value       int64
+	Description string
+	// Params are the descriptions of the params of a MAV_CMD entry
+	Params []string
+}
+
+// Message is a MAVLink message
+type Message struct {
+	ID          uint32
+	Name        string
+	Description string
+	// Fields are in the order of the payload, the base fields sorted by
+	// decreasing size of their type followed by the extension fields
+	Fields []*Field
+}
+
+// Field is a field of a Message
+type Field struct {
+	Name        string
+	Type        string
+	ArrayLength int
+	Enum        string
+	Description string
+	// Extension fields are not part of the CRC extra of their message
+	Extension bool
+}
+
+type xmlDialect struct {
+	Includes []string     `xml:"include"`
+	Version  int          `xml:"version"`
+	Enums    []xmlEnum    `xml:"enums>enum"`
+	Messages []xmlMessage `xml:"messages>message"`
+}
+
+type xmlEnum struct {
+	Name        string     `xml:"name,attr"`
+	Description string     `xml:"description"`
+	Entries     []xmlEntry `xml:"entry"`
+}
+
+type xmlEntry struct {
+	Name        string     `xml:"name,attr"`
+	Value       string     `xml:"value,attr"`
+	Description string     `xml:"description"`
+	Params      []xmlParam `xml:"param"`
+}
+
+type xmlParam struct {
+	Index int    `xml:"index,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type xmlMessage struct {
+	ID       uint32       `xml:"id,attr"`
+	Name     string       `xml:"name,attr"`
+	Elements []xmlElement `xml:",any"`
+}
+
+// xmlElement is a description, field or extensions element of a message
+type xmlElement struct {
+	XMLName xml.Name
+	Type    string `xml:"type,attr"`
+	Name    string `xml:"name,attr"`
+	Enum    string `xml:"enum,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Load reads the XML definitions of a dialect and of its includes, which
+// are relative to the directory of the dialect
+func Load(path string) (*Dialect, error) {
+	d := &Dialect{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	if err := d.load(path, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	sort.Stable(byID(d.Messages))
+	return d, nil
+}
+
+// load adds the enums and messages of the includes of a file, and then the
+// ones of the file
+func (d *Dialect) load(path string, loaded map[string]bool) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if loaded[abs] {
+		return nil
+	}
+	loaded[abs] = true
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	x := xmlDialect{}
+	if err := xml.NewDecoder(f).Decode(&x); err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+
+	for _, include := range x.Includes {
+		if err := d.load(filepath.Join(filepath.Dir(path), strings.TrimSpace(include)), loaded); err != nil {
+			return err
+		}
+	}
+	if x.Version != 0 {
+		d.Version = x.Version
+	}
+	for _, e := range x.Enums {
+		if err := d.addEnum(e); err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+	}
+	for _, m := range x.Messages {
+		if err := d.addMessage(m); err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// addEnum adds an enum, or its entries to the enum of the same name
+func (d *Dialect) addEnum(x xmlEnum) error {
+	var enum *Enum
+	for _, e := range d.Enums {
+		if e.Name == x.Name {
+			enum = e
+		}
+	}
+	if enum == nil {
+		enum = &Enum{Name: x.Name, Description: text(x.Description)}
+		d.Enums = append(d.Enums, enum)
+	}
+
+	next := int64(0)
+	if len(enum.Entries) > 0 {
+		next = enum.Entries[len(enum.Entries)-1].Value + 1
+	}
+	for _, x := range x.Entries {
+		value := next
+		if x.Value != "" {
+			v, err := strconv.ParseInt(x.Value, 0, 64)
+			if err != nil {
+				return fmt.Errorf("enum %v: entry %v: invalid value %q", enum.Name, x.Name, x.Value)
+			}
+			value = v
+		}
+		next = value + 1
+
+		entry := &Entry{Name: x.Name, Value: value, Description: text(x.Description)}
+		for _, p := range x.Params {
+			for len(entry.Params) < p.Index {
+				entry.Params = append(entry.Params, "")
+			}
+			entry.Params[p.Index-1] = text(p.Text)
+		}
+		duplicate := false
+		for _, e := range enum.Entries {
+			duplicate = duplicate || e.Name == entry.Name
+		}
+		if !duplicate {
+			enum.Entries = append(enum.Entries, entry)
+		}
+	}
+	return nil
+}
+
+// addMessage adds a message
+func (d *Dialect) addMessage(x xmlMessage) error {
+	m := &Message{ID: x.ID, Name: x.Name}
+	extension := false
+	for _, e := range x.Elements {
+		switch e.XMLName.Local {
+		case "description":
+			m.Description = text(e.Text)
+		case "extensions":
+			extension = true
+		case "field":
+			f, err := newField(e, extension)
+			if err != nil {
+				return fmt.Errorf("message %v: %v", m.Name, err)
+			}
+			m.Fields = append(m.Fields, f)
+		}
+	}
+	sort.Stable(byPayloadOrder(m.Fields))
+
+	if m.ID > 0xFFFFFF {
+		return fmt.Errorf("message %v: invalid ID %v", m.Name, m.ID)
+	}
+	for _, message := range d.Messages {
+		if message.ID == m.ID {
+			if message.Name == m.Name {
+				return nil
+			}
+			return fmt.Errorf("message %v: ID %v is already the ID of %v", m.Name, m.ID, message.Name)
+		}
+	}
+	d.Messages = append(d.Messages, m)
+	return nil
+}
+
+// byID sorts messages by ID
+type byID []*Message
+
+func (m byID) Len() int           { return len(m) }
+func (m byID) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m byID) Less(i, j int) bool { return m[i].ID < m[j].ID }
+
+// byPayloadOrder sorts fields in the order of the payload, the base fields
+// by decreasing size of their type followed by the extension fields
+type byPayloadOrder []*Field
+
+func (f byPayloadOrder) Len() int      { return len(f) }
+func (f byPayloadOrder) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f byPayloadOrder) Less(i, j int) bool {
+	if f[i].Extension || f[j].Extension {
+		return !f[i].Extension && f[j].Extension
+	}
+	return f[i].Size() > f[j].Size()
+}
+
+// newField returns the field of a field element, whose type is a MAVLink type
+// or an array of one, as in "char[16]"
+func newField(e xmlElement, extension bool) (*Field, error) {
+	f := &Field{
+		Name:        e.Name,
+		Type:        e.Type,
+		Enum:        e.Enum,
+		Description: text(e.Text),
+		Extension:   extension,
+	}
+	if i := strings.Index(e.Type, "["); i >= 0 && strings.HasSuffix(e.Type, "]") {
+		n, err := strconv.Atoi(e.Type[i+1 : len(e.Type)-1])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("field %v: invalid type %q", e.Name, e.Type)
+		}
+		f.Type, f.ArrayLength = e.Type[:i], n
+	}
+	if _, ok := types[f.Type]; !ok {
+		return nil, fmt.Errorf("field %v: invalid type %q", e.Name, e.Type)
+	}
+	return f, nil
+}
+
+// text returns s with its whitespace collapsed
+func text(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Size returns the size of the type of the field, or of its elements
+func (f *Field) Size() int {
+	return types[f.Type].Size
+}
+
+// Len returns the length of the field in the payload
+func (f *Field) Len() int {
+	if f.ArrayLength > 0 {
+		return f.Size() * f.ArrayLength
+	}
+	return f.Size()
+}
+
+// GoName returns the name of the field in Go
+func (f *Field) GoName() string {
+	return strings.ToUpper(f.Name)
+}
+
+// GoType returns the type of the field in Go
+func (f *Field) GoType() string {
+	if f.ArrayLength > 0 {
+		return fmt.Sprintf("[%v]%v", f.ArrayLength, types[f.Type].Go)
+	}
+	return types[f.Type].Go
+}
+
+// GoName returns the name of the type of the message in Go, the camel case
+// of its name
+func (m *Message) GoName() string {
+	name := ""
+	for _, word := range strings.Split(strings.ToLower(m.Name), "_") {
+		if word != "" {
+			name += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return name
+}
+
+// MAVLink2 returns true if the ID of the message is above 255, so that it is
+// only sent in MAVLink 2 packets
+func (m *Message) MAVLink2() bool {
+	return m.ID > 255
+}
+
+// Len returns the length of the payload of the message
+func (m *Message) Len() (n int) {
+	for _, f := range m.Fields {
+		n += f.Len()
+	}
+	return
+}
+
+// BaseLen returns the length of the payload of the message without its
+// extension fields, which are not sent in MAVLink 1 packets
+func (m *Message) BaseLen() (n int) {
+	for _, f := range m.Fields {
+		if !f.Extension {
+			n += f.Len()
+		}
+	}
+	return
+}
+
+// Extended returns true if the message has extension fields
+func (m *Message) Extended() bool {
+	return m.BaseLen() != m.Len()
+}
+
+// CrcExtra returns the CRC extra of the message, the X.25 checksum of its
+// name and of the types and names of its base fields
+func (m *Message) CrcExtra() uint8 {
+	crc := uint16(0xffff)
+	accumulate := func(s string) {
+		for i := 0; i < len(s); i++ {
+			tmp := s[i] ^ uint8(crc&0xff)
+			tmp ^= tmp << 4
+			crc = (crc >> 8) ^ (uint16(tmp) << 8) ^ (uint16(tmp) << 3) ^ (uint16(tmp) >> 4)
+		}
+	}
+
+	accumulate(m.Name + " ")
+	for _, f := range m.Fields {
+		if f.Extension {
+			continue
+		}
+		t := f.Type
+		if t == "uint8_t_mavlink_version" {
+			t = "uint8_t"
+		}
+		accumulate(t + " ")
+		accumulate(f.Name + " ")
+		if f.ArrayLength > 0 {
+			accumulate(string([]byte{uint8(f.ArrayLength)}))
+		}
+	}
+	return uint8(crc&0xff) ^ uint8(crc>>8)
+}
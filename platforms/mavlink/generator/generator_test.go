@@ -0,0 +1,167 @@
+package generator
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+func message(d *Dialect, name string) *Message {
+	for _, m := range d.Messages {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+func enum(d *Dialect, name string) *Enum {
+	for _, e := range d.Enums {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+func TestLoad(t *testing.T) {
+	d, err := Load("testdata/common.xml")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, d.Name, "common")
+	gobottest.Assert(t, d.Version, 3)
+	gobottest.Assert(t, len(d.Enums), 2)
+	gobottest.Assert(t, len(d.Messages), 5)
+
+	autopilot := enum(d, "MAV_AUTOPILOT")
+	gobottest.Assert(t, autopilot.Entries[1].Value, int64(3))
+	gobottest.Assert(t, autopilot.Entries[2].Name, "MAV_AUTOPILOT_OPENPILOT")
+	gobottest.Assert(t, autopilot.Entries[2].Value, int64(4))
+	gobottest.Assert(t, autopilot.End(), int64(5))
+
+	land := enum(d, "MAV_CMD").Entries[0]
+	gobottest.Assert(t, len(land.Params), 7)
+	gobottest.Assert(t, land.Params[3], "Desired yaw angle.")
+	gobottest.Assert(t, land.Comment(), "Land at location | Empty | Empty | Empty | Desired yaw angle. | Latitude | Longitude | Altitude |")
+
+	heartbeat := message(d, "HEARTBEAT")
+	gobottest.Assert(t, heartbeat.GoName(), "Heartbeat")
+	gobottest.Assert(t, heartbeat.Fields[0].Name, "custom_mode")
+	gobottest.Assert(t, heartbeat.Fields[5].GoName(), "MAVLINK_VERSION")
+	gobottest.Assert(t, heartbeat.Fields[5].GoType(), "uint8")
+
+	param := message(d, "PARAM_REQUEST_READ")
+	gobottest.Assert(t, param.GoName(), "ParamRequestRead")
+	gobottest.Assert(t, param.Fields[3].GoType(), "[16]uint8")
+	gobottest.Assert(t, len(param.Arrays()), 1)
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load("testdata/missing.xml")
+	gobottest.Refute(t, err, nil)
+}
+
+func TestMessageCrcExtra(t *testing.T) {
+	d, err := Load("testdata/common.xml")
+	gobottest.Assert(t, err, nil)
+
+	for _, expected := range []common.MAVLinkMessage{
+		&common.Heartbeat{},
+		&common.ParamRequestRead{},
+		&common.CommandLong{},
+		&common.AttitudeSetpointExternal{},
+		&common.Statustext{},
+	} {
+		var m *Message
+		for _, message := range d.Messages {
+			if uint8(message.ID) == expected.Id() {
+				m = message
+			}
+		}
+		gobottest.Refute(t, m, nil)
+		gobottest.Assert(t, m.CrcExtra(), expected.Crc())
+		gobottest.Assert(t, uint8(m.Len()), expected.Len())
+	}
+}
+
+func TestLoadIncludes(t *testing.T) {
+	d, err := Load("testdata/custom.xml")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, d.Name, "custom")
+	gobottest.Assert(t, d.Version, 4)
+	gobottest.Assert(t, len(d.Enums), 3)
+	gobottest.Assert(t, len(d.Messages), 7)
+	gobottest.Assert(t, d.Messages[5].Name, "STATUSTEXT")
+	gobottest.Assert(t, d.Messages[6].Name, "WINCH_CONFIG")
+	gobottest.Assert(t, d.Messages[6].MAVLink2(), true)
+	gobottest.Assert(t, d.Messages[5].MAVLink2(), false)
+
+	cmd := enum(d, "MAV_CMD")
+	gobottest.Assert(t, len(cmd.Entries), 2)
+	gobottest.Assert(t, cmd.End(), int64(42001))
+
+	state := enum(d, "WINCH_STATE")
+	gobottest.Assert(t, state.Entries[1].Value, int64(1))
+	gobottest.Assert(t, state.Entries[1].Description, "Reeling in or out")
+
+	winch := message(d, "WINCH_STATUS")
+	names := []string{}
+	for _, f := range winch.Fields {
+		names = append(names, f.Name)
+	}
+	gobottest.Assert(t, names, []string{"length", "tension", "state", "motor", "time"})
+	gobottest.Assert(t, winch.Len(), 18)
+	gobottest.Assert(t, winch.BaseLen(), 9)
+	gobottest.Assert(t, winch.Extended(), true)
+	gobottest.Assert(t, message(d, "HEARTBEAT").Extended(), false)
+	gobottest.Assert(t, winch.Fields[2].Enum, "WINCH_STATE")
+
+	without := *winch
+	without.Fields = winch.Fields[:3]
+	gobottest.Assert(t, winch.CrcExtra(), without.CrcExtra())
+}
+
+func TestGenerate(t *testing.T) {
+	d, err := Load("testdata/custom.xml")
+	gobottest.Assert(t, err, nil)
+
+	buf := new(bytes.Buffer)
+	gobottest.Assert(t, d.Generate(buf, "custom"), nil)
+	src := buf.String()
+
+	f, err := parser.ParseFile(token.NewFileSet(), "custom.go", src, parser.ParseComments)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, f.Name.Name, "custom")
+
+	for _, decl := range []string{
+		"MAVLINK_VERSION = 4",
+		"common.RegisterMessage(message)",
+		"MAV_CMD_CUSTOM_WINCH = 42000",
+		"WINCH_STATE_ENUM_END = 2",
+		"type WinchStatus struct {",
+		"func NewWinchStatus(LENGTH float32, TENSION [2]int16, STATE uint8, MOTOR uint8, TIME float64) *WinchStatus {",
+		"func (*WinchStatus) Crc() uint8 {",
+		"func (*WinchStatus) BaseLen() uint8 {\n\treturn 9\n}",
+		"func (*WinchConfig) FullId() uint32 {\n\treturn 300\n}",
+		"return 300 & 0xFF",
+		"MAVLINK_MSG_WINCH_STATUS_FIELD_tension_LEN = 2",
+		"the * / of a comment is escaped",
+	} {
+		gobottest.Assert(t, strings.Contains(src, decl), true)
+	}
+	gobottest.Assert(t, strings.Contains(src, "func (*WinchStatus) FullId()"), false)
+	gobottest.Assert(t, strings.Contains(src, "func (*WinchConfig) BaseLen()"), false)
+}
+
+func TestGenerateWithoutMessages(t *testing.T) {
+	d := &Dialect{Name: "empty", Version: 1}
+	buf := new(bytes.Buffer)
+	gobottest.Assert(t, d.Generate(buf, "empty"), nil)
+	_, err := parser.ParseFile(token.NewFileSet(), "empty.go", buf.String(), 0)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, strings.Contains(buf.String(), "common"), false)
+}
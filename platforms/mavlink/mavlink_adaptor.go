@@ -16,13 +16,29 @@ type MavlinkAdaptor struct {
 	connect func(string) (io.ReadWriteCloser, error)
 }
 
-// NewMavLinkAdaptor creates a new mavlink adaptor with specified name and port
+// NewMavLinkAdaptor creates a new mavlink adaptor with specified name and port.
+//
+// The port is a serial port, or a network address of the form:
+//	"udp:0.0.0.0:14550" - listens to UDP packets, replying to the last sender
+//	"udpout:127.0.0.1:14550" - sends UDP packets to an address
+//	"tcp:127.0.0.1:5760" - connects to a TCP server
 func NewMavlinkAdaptor(name string, port string) *MavlinkAdaptor {
 	return &MavlinkAdaptor{
 		name: name,
 		port: port,
 		connect: func(port string) (io.ReadWriteCloser, error) {
-			return serial.OpenPort(&serial.Config{Name: port, Baud: 57600})
+			if network, address, ok := networkPort(port); ok {
+				conn, err := dial(network, address)
+				if err != nil {
+					return nil, err
+				}
+				return gobot.NewMeteredReadWriteCloser(conn, port), nil
+			}
+			sp, err := serial.OpenPort(&serial.Config{Name: port, Baud: 57600})
+			if err != nil {
+				return nil, err
+			}
+			return gobot.NewMeteredReadWriteCloser(sp, port), nil
 		},
 	}
 }
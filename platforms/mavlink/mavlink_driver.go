@@ -1,6 +1,8 @@
 package mavlink
 
 import (
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/hybridgroup/gobot"
@@ -14,6 +16,12 @@ type MavlinkDriver struct {
 	connection gobot.Connection
 	interval   time.Duration
 	gobot.Eventer
+	mutex      sync.Mutex
+	version    int
+	receivedV2 bool
+	signing    *common.MAVLinkSigning
+	writing    sync.Mutex
+	halt       chan bool
 }
 
 type MavlinkInterface interface {
@@ -24,6 +32,12 @@ type MavlinkInterface interface {
 // It add the following events:
 //	"packet" - triggered when a new packet is read
 //	"message" - triggered when a new valid message is processed
+//	"errorIO" - triggered when a packet can not be read, the packets are no
+//	longer read once the connection is closed
+//	"errorMAVLink" - triggered when a packet is rejected or its message can not be decoded
+//
+// The packets and messages of a single vehicle are also published to the
+// events returned by VehicleEvent.
 func NewMavlinkDriver(a *MavlinkAdaptor, name string, v ...time.Duration) *MavlinkDriver {
 	m := &MavlinkDriver{
 		name:       name,
@@ -55,31 +69,179 @@ func (m *MavlinkDriver) adaptor() *MavlinkAdaptor {
 // Start begins process to read mavlink packets every m.Interval
 // and process them
 func (m *MavlinkDriver) Start() (errs []error) {
+	m.mutex.Lock()
+	halt := make(chan bool)
+	m.halt = halt
+	m.mutex.Unlock()
+
 	go func() {
+		backoff := time.Duration(0)
 		for {
 			packet, err := common.ReadMAVLinkPacket(m.adaptor().sp)
-			if err != nil {
-				gobot.Publish(m.Event("errorIO"), err)
-				continue
+			select {
+			case <-halt:
+				return
+			default:
 			}
-			gobot.Publish(m.Event("packet"), packet)
-			message, err := packet.MAVLinkMessage()
 			if err != nil {
-				gobot.Publish(m.Event("errorMAVLink"), err)
+				gobot.Publish(m.Event("errorIO"), err)
+				if closed(err) {
+					return
+				}
+				backoff = nextBackoff(backoff)
+				select {
+				case <-halt:
+					return
+				case <-time.After(backoff):
+				}
 				continue
 			}
-			gobot.Publish(m.Event("message"), message)
+			backoff = 0
+			m.receive(packet)
 			<-time.After(m.interval)
 		}
 	}()
 	return
 }
 
-// Halt returns true if device is halted successfully
-func (m *MavlinkDriver) Halt() (errs []error) { return }
+// receive publishes a packet read from the mavlink device and its message,
+// or the error rejecting it
+func (m *MavlinkDriver) receive(packet *common.MAVLinkPacket) {
+	if packet.Version() == 2 {
+		if flags := packet.IncompatFlags &^ common.MAVLINK_IFLAG_SIGNED; flags != 0 {
+			gobot.Publish(m.Event("errorMAVLink"),
+				fmt.Errorf("Unsupported MAVLink incompatibility flags: 0x%02X", flags))
+			return
+		}
+	}
+
+	m.mutex.Lock()
+	signing := m.signing
+	if packet.Version() == 2 {
+		m.receivedV2 = true
+	}
+	m.mutex.Unlock()
+
+	if signing != nil {
+		if err := signing.Verify(packet); err != nil {
+			gobot.Publish(m.Event("errorMAVLink"), err)
+			return
+		}
+	}
+
+	gobot.Publish(m.Event("packet"), packet)
+	for _, event := range m.vehicleEvents("packet", packet) {
+		gobot.Publish(event, packet)
+	}
+	message, err := packet.MAVLinkMessage()
+	if err != nil {
+		gobot.Publish(m.Event("errorMAVLink"), err)
+		return
+	}
+	gobot.Publish(m.Event("message"), message)
+	for _, event := range m.vehicleEvents("message", packet) {
+		gobot.Publish(event, message)
+	}
+}
+
+// VehicleEvent returns the "packet" or "message" event of the packets sent
+// by a system and component, or by any component of the system when
+// componentID is 0. The event is named after them, as in "message:1:0".
+func (m *MavlinkDriver) VehicleEvent(name string, systemID uint8, componentID uint8) *gobot.Event {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	event := fmt.Sprintf("%v:%v:%v", name, systemID, componentID)
+	if m.Event(event) == nil {
+		m.AddEvent(event)
+	}
+	return m.Event(event)
+}
+
+// vehicleEvents returns the events returned by VehicleEvent for the system
+// and component of a packet
+func (m *MavlinkDriver) vehicleEvents(name string, packet *common.MAVLinkPacket) (events []*gobot.Event) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	components := []uint8{packet.ComponentID}
+	if packet.ComponentID != 0 {
+		components = append(components, 0)
+	}
+	for _, component := range components {
+		if event := m.Event(fmt.Sprintf("%v:%v:%v", name, packet.SystemID, component)); event != nil {
+			events = append(events, event)
+		}
+	}
+	return
+}
+
+// Halt stops processing the packets read once the next one is read
+func (m *MavlinkDriver) Halt() (errs []error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.halt != nil {
+		close(m.halt)
+		m.halt = nil
+	}
+	return
+}
+
+// SetVersion sets the MAVLink version of the messages sent by SendMessage, 1
+// or 2. The default 0 negotiates the version: messages are sent as MAVLink 1
+// until a MAVLink 2 packet is received, or a signing is set.
+func (m *MavlinkDriver) SetVersion(version int) error {
+	if version < 0 || version > 2 {
+		return fmt.Errorf("Unsupported MAVLink version: %v", version)
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.version = version
+	return nil
+}
+
+// Version returns the MAVLink version of the messages sent by SendMessage
+func (m *MavlinkDriver) Version() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.version != 0 {
+		return m.version
+	}
+	if m.receivedV2 || m.signing != nil {
+		return 2
+	}
+	return 1
+}
 
-// SendPacket sends a packet to mavlink device
+// SetSigning sets the MAVLinkSigning signing the MAVLink 2 packets sent, and
+// verifying the packets received, nil to disable signing. Unsigned packets
+// and packets with an invalid signature are rejected with an "errorMAVLink"
+// event, unless the signing accepts unsigned packets.
+func (m *MavlinkDriver) SetSigning(signing *common.MAVLinkSigning) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.signing = signing
+}
+
+// SendMessage sends a message to mavlink device in a packet of the version
+// returned by Version. The messages whose ID is above 255 are always sent in
+// MAVLink 2 packets.
+func (m *MavlinkDriver) SendMessage(systemID uint8, componentID uint8, message common.MAVLinkMessage) error {
+	if _, ok := message.(common.MAVLink2Message); ok || m.Version() == 2 {
+		return m.SendPacket(common.CraftMAVLink2Packet(systemID, componentID, message))
+	}
+	return m.SendPacket(common.CraftMAVLinkPacket(systemID, componentID, message))
+}
+
+// SendPacket sends a packet to mavlink device. Unsigned MAVLink 2 packets
+// are signed when a signing is set.
 func (m *MavlinkDriver) SendPacket(packet *common.MAVLinkPacket) (err error) {
+	m.mutex.Lock()
+	signing := m.signing
+	m.mutex.Unlock()
+	if signing != nil && packet.Version() == 2 && !packet.Signed() {
+		signing.Sign(packet)
+	}
+	m.writing.Lock()
+	defer m.writing.Unlock()
 	_, err = m.adaptor().sp.Write(packet.Pack())
 	return err
 }
@@ -1,6 +1,7 @@
 package mavlink
 
 import (
+	"bytes"
 	"errors"
 	"io"
 	"testing"
@@ -82,7 +83,182 @@ func TestMavlinkDriverStart(t *testing.T) {
 
 }
 
+func TestMavlinkDriverReadError(t *testing.T) {
+	d := initTestMavlinkDriver()
+	reader := &errorReader{errs: []error{errors.New("read error"), errors.New("read error")}}
+	d.adaptor().sp = reader
+
+	sem := make(chan error, 3)
+	gobot.On(d.Event("errorIO"), func(data interface{}) {
+		sem <- data.(error)
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	defer d.Halt()
+	for _, expected := range []error{errors.New("read error"), errors.New("read error"), io.EOF} {
+		select {
+		case err := <-sem:
+			gobottest.Assert(t, err, expected)
+		case <-time.After(1 * time.Second):
+			t.Fatalf("MavlinkDriver Event \"errorIO\" was not published")
+		}
+	}
+
+	// the connection is no longer read once it returns io.EOF
+	<-time.After(50 * time.Millisecond)
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+	gobottest.Assert(t, reader.reads, 3)
+}
+
 func TestMavlinkDriverHalt(t *testing.T) {
 	d := initTestMavlinkDriver()
 	gobottest.Assert(t, len(d.Halt()), 0)
 }
+
+func TestMavlinkDriverVersion(t *testing.T) {
+	d := initTestMavlinkDriver()
+	gobottest.Assert(t, d.Version(), 1)
+
+	d.receive(common.CraftMAVLinkPacket(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)))
+	gobottest.Assert(t, d.Version(), 1)
+	d.receive(common.CraftMAVLink2Packet(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)))
+	gobottest.Assert(t, d.Version(), 2)
+
+	gobottest.Assert(t, d.SetVersion(1), nil)
+	gobottest.Assert(t, d.Version(), 1)
+	gobottest.Assert(t, d.SetVersion(3), errors.New("Unsupported MAVLink version: 3"))
+
+	var sent []byte
+	testAdaptorRead = func(p []byte) (int, error) {
+		sent = p
+		return len(p), nil
+	}
+	defer func() {
+		testAdaptorRead = func(p []byte) (int, error) { return len(p), nil }
+	}()
+
+	gobottest.Assert(t, d.SendMessage(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)), nil)
+	gobottest.Assert(t, sent[0], uint8(common.MAVLINK_STX))
+	gobottest.Assert(t, d.SendMessage(1, 1, &winchConfig{}), nil)
+	gobottest.Assert(t, sent[0], uint8(common.MAVLINK_STX_V2))
+	d.SetVersion(2)
+	gobottest.Assert(t, d.SendMessage(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)), nil)
+	gobottest.Assert(t, sent[0], uint8(common.MAVLINK_STX_V2))
+}
+
+// winchConfig is a message whose ID is above 255
+type winchConfig struct {
+	MODE uint8
+}
+
+func (*winchConfig) Id() uint8           { return 44 }
+func (*winchConfig) FullId() uint32      { return 300 }
+func (*winchConfig) Len() uint8          { return 1 }
+func (*winchConfig) Crc() uint8          { return 7 }
+func (m *winchConfig) Pack() []byte      { return []byte{m.MODE} }
+func (m *winchConfig) Decode(buf []byte) { m.MODE = buf[0] }
+
+func TestMavlinkDriverSigning(t *testing.T) {
+	d := initTestMavlinkDriver()
+	var key [32]byte
+	key[0] = 0x42
+	d.SetSigning(common.NewMAVLinkSigning(1, key))
+	gobottest.Assert(t, d.Version(), 2)
+
+	var sent []byte
+	testAdaptorRead = func(p []byte) (int, error) {
+		sent = p
+		return len(p), nil
+	}
+	defer func() {
+		testAdaptorRead = func(p []byte) (int, error) { return len(p), nil }
+	}()
+
+	gobottest.Assert(t, d.SendMessage(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)), nil)
+	packet, err := common.ReadMAVLinkPacket(bytes.NewReader(sent))
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, packet.Signed(), true)
+
+	verifier := initTestMavlinkDriver()
+	verifier.SetSigning(common.NewMAVLinkSigning(2, key))
+	errs := make(chan error, 1)
+	messages := make(chan common.MAVLinkMessage, 1)
+	gobot.On(verifier.Event("errorMAVLink"), func(data interface{}) {
+		errs <- data.(error)
+	})
+	gobot.On(verifier.Event("message"), func(data interface{}) {
+		messages <- data.(common.MAVLinkMessage)
+	})
+
+	verifier.receive(packet)
+	select {
+	case m := <-messages:
+		gobottest.Assert(t, m.Id(), uint8(0))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("message was not emitted")
+	}
+
+	for _, p := range []*common.MAVLinkPacket{
+		packet,
+		common.CraftMAVLink2Packet(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)),
+	} {
+		verifier.receive(p)
+		select {
+		case <-errs:
+		case <-time.After(100 * time.Millisecond):
+			t.Errorf("error was not emitted")
+		}
+	}
+
+	packet = common.CraftMAVLink2Packet(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	packet.IncompatFlags = 0x02
+	verifier.receive(packet)
+	select {
+	case e := <-errs:
+		gobottest.Assert(t, e, errors.New("Unsupported MAVLink incompatibility flags: 0x02"))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("error was not emitted")
+	}
+}
+
+func TestMavlinkDriverVehicleEvent(t *testing.T) {
+	d := initTestMavlinkDriver()
+	event := d.VehicleEvent("message", 1, 0)
+	gobottest.Assert(t, d.VehicleEvent("message", 1, 0), event)
+	gobottest.Refute(t, d.Event("message:1:0"), nil)
+
+	messages := make(chan common.MAVLinkMessage, 2)
+	gobot.On(event, func(data interface{}) {
+		messages <- data.(common.MAVLinkMessage)
+	})
+	packets := make(chan *common.MAVLinkPacket, 2)
+	gobot.On(d.VehicleEvent("packet", 2, 1), func(data interface{}) {
+		packets <- data.(*common.MAVLinkPacket)
+	})
+
+	d.receive(common.CraftMAVLinkPacket(2, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)))
+	d.receive(common.CraftMAVLinkPacket(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)))
+	d.receive(common.CraftMAVLinkPacket(2, 2, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3)))
+
+	select {
+	case p := <-packets:
+		gobottest.Assert(t, p.SystemID, uint8(2))
+		gobottest.Assert(t, p.ComponentID, uint8(1))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("packet was not emitted")
+	}
+	select {
+	case m := <-messages:
+		gobottest.Assert(t, m.Id(), uint8(0))
+	case <-time.After(100 * time.Millisecond):
+		t.Errorf("message was not emitted")
+	}
+	select {
+	case <-packets:
+		t.Errorf("packet of another component was emitted")
+	case <-messages:
+		t.Errorf("message of another system was emitted")
+	case <-time.After(20 * time.Millisecond):
+	}
+}
@@ -0,0 +1,187 @@
+package mavlink
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+var _ gobot.Driver = (*MavlinkRouter)(nil)
+
+// routeMaxBackoff is the longest delay before an endpoint is read again after
+// a read error
+const routeMaxBackoff = 1 * time.Second
+
+// MavlinkRouter forwards the packets read from each of its endpoints to the
+// others, as mavproxy does. It learns the endpoint of each system from the
+// packets it sends, so that a packet targeting a known system is only
+// forwarded to the endpoint of that system, while other packets are forwarded
+// to every other endpoint. Signed packets are forwarded unchanged.
+//
+// The endpoints are read by the router, and so must not be used by a
+// MavlinkDriver.
+type MavlinkRouter struct {
+	name      string
+	endpoints []*MavlinkAdaptor
+	mutex     sync.Mutex
+	routes    map[uint8]int
+	routing   bool
+	// reading is true for each endpoint which is read by the router
+	reading []bool
+	gobot.Eventer
+}
+
+// NewMavlinkRouter creates a new mavlink router with specified name and
+// endpoints, which should all be connections of its robot.
+//
+// It add the following events:
+//	"packet" - triggered when a packet is forwarded
+//	"errorIO" - triggered when a packet can not be read or forwarded
+func NewMavlinkRouter(name string, endpoints ...*MavlinkAdaptor) *MavlinkRouter {
+	r := &MavlinkRouter{
+		name:      name,
+		endpoints: endpoints,
+		routes:    make(map[uint8]int),
+		reading:   make([]bool, len(endpoints)),
+		Eventer:   gobot.NewEventer(),
+	}
+
+	r.AddEvent("packet")
+	r.AddEvent("errorIO")
+
+	return r
+}
+
+// Connection returns the first endpoint of the router
+func (r *MavlinkRouter) Connection() gobot.Connection {
+	if len(r.endpoints) == 0 {
+		return nil
+	}
+	return r.endpoints[0]
+}
+
+func (r *MavlinkRouter) Name() string { return r.name }
+
+// Endpoints returns the endpoints of the router
+func (r *MavlinkRouter) Endpoints() []*MavlinkAdaptor { return r.endpoints }
+
+// Start begins forwarding the packets read from every endpoint
+func (r *MavlinkRouter) Start() (errs []error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.routing = true
+	for i := range r.endpoints {
+		if !r.reading[i] {
+			r.reading[i] = true
+			go r.route(i)
+		}
+	}
+	return
+}
+
+// Halt stops forwarding packets. The endpoints are still read until they are
+// closed, and the packets read are dropped, so that starting the router again
+// does not read an endpoint twice.
+func (r *MavlinkRouter) Halt() (errs []error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.routing = false
+	return
+}
+
+// route forwards the packets read from an endpoint while the router is
+// started, until the endpoint is closed. After a read error the endpoint is
+// read again after a delay, which doubles on each consecutive error up to
+// routeMaxBackoff.
+func (r *MavlinkRouter) route(from int) {
+	defer func() {
+		r.mutex.Lock()
+		r.reading[from] = false
+		r.mutex.Unlock()
+	}()
+
+	backoff := time.Duration(0)
+	for {
+		packet, err := common.ReadMAVLinkPacket(r.endpoints[from].sp)
+		if err != nil {
+			if closed(err) {
+				return
+			}
+			if r.started() {
+				gobot.Publish(r.Event("errorIO"), err)
+			}
+			backoff = nextBackoff(backoff)
+			<-time.After(backoff)
+			continue
+		}
+		backoff = 0
+		if r.started() {
+			r.forward(from, packet)
+		}
+	}
+}
+
+// started returns true while the router forwards packets
+func (r *MavlinkRouter) started() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.routing
+}
+
+// nextBackoff returns the delay before reading again after a read error,
+// the double of the previous delay up to routeMaxBackoff
+func nextBackoff(backoff time.Duration) time.Duration {
+	if backoff *= 2; backoff == 0 {
+		return 10 * time.Millisecond
+	} else if backoff > routeMaxBackoff {
+		return routeMaxBackoff
+	}
+	return backoff
+}
+
+// closed returns true if err is returned by reading a closed connection
+func closed(err error) bool {
+	return err == io.EOF || strings.Contains(err.Error(), "closed")
+}
+
+// forward writes a packet read from an endpoint to the endpoints it is
+// routed to
+func (r *MavlinkRouter) forward(from int, packet *common.MAVLinkPacket) {
+	r.mutex.Lock()
+	r.routes[packet.SystemID] = from
+	to, routed := -1, false
+	if target, ok := targetSystem(packet); ok && target != 0 {
+		to, routed = r.routes[target]
+	}
+	r.mutex.Unlock()
+
+	data := packet.Pack()
+	for i, endpoint := range r.endpoints {
+		if i == from || (routed && i != to) {
+			continue
+		}
+		if _, err := endpoint.sp.Write(data); err != nil {
+			gobot.Publish(r.Event("errorIO"), err)
+		}
+	}
+	gobot.Publish(r.Event("packet"), packet)
+}
+
+// targetSystem returns the TARGET_SYSTEM of the message of a packet, if it
+// has one
+func targetSystem(packet *common.MAVLinkPacket) (uint8, bool) {
+	message, err := packet.MAVLinkMessage()
+	if err != nil {
+		return 0, false
+	}
+	field := reflect.Indirect(reflect.ValueOf(message)).FieldByName("TARGET_SYSTEM")
+	if !field.IsValid() || field.Kind() != reflect.Uint8 {
+		return 0, false
+	}
+	return uint8(field.Uint()), true
+}
@@ -0,0 +1,198 @@
+package mavlink
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+func TestMavlinkRouter(t *testing.T) {
+	var endpoints []*MavlinkAdaptor
+	var conns []net.Conn
+	for _, name := range []string{"vehicle1", "vehicle2", "gcs"} {
+		a := NewMavlinkAdaptor(name, "/dev/null")
+		conn, sp := net.Pipe()
+		a.sp = sp
+		endpoints = append(endpoints, a)
+		conns = append(conns, conn)
+		defer conn.Close()
+	}
+
+	r := NewMavlinkRouter("router", endpoints...)
+	gobottest.Assert(t, r.Name(), "router")
+	gobottest.Assert(t, r.Connection().Name(), "vehicle1")
+	gobottest.Assert(t, len(r.Endpoints()), 3)
+	gobottest.Assert(t, len(r.Start()), 0)
+	defer r.Halt()
+
+	read := func(conn net.Conn) (*common.MAVLinkPacket, error) {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		return common.ReadMAVLinkPacket(conn)
+	}
+
+	// packets are forwarded to every other endpoint
+	heartbeat := common.CraftMAVLinkPacket(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	conns[0].Write(heartbeat.Pack())
+	for _, conn := range conns[1:] {
+		packet, err := read(conn)
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, packet.Pack(), heartbeat.Pack())
+	}
+
+	heartbeat = common.CraftMAVLink2Packet(2, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	conns[1].Write(heartbeat.Pack())
+	for _, conn := range []net.Conn{conns[0], conns[2]} {
+		packet, err := read(conn)
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, packet.SystemID, uint8(2))
+	}
+
+	// packets targeting a known system are only forwarded to its endpoint
+	request := common.CraftMAVLinkPacket(255, 0, common.NewRequestDataStream(10, 2, 1, 0, 1))
+	conns[2].Write(request.Pack())
+	packet, err := read(conns[1])
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, packet.Pack(), request.Pack())
+	_, err = read(conns[0])
+	gobottest.Refute(t, err, nil)
+
+	request = common.CraftMAVLinkPacket(255, 0, common.NewRequestDataStream(10, 7, 1, 0, 1))
+	conns[2].Write(request.Pack())
+	for _, conn := range conns[:2] {
+		packet, err := read(conn)
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, packet.Pack(), request.Pack())
+	}
+}
+
+func TestMavlinkRouterHalt(t *testing.T) {
+	r := NewMavlinkRouter("router")
+	gobottest.Assert(t, r.Connection(), nil)
+	gobottest.Assert(t, len(r.Start()), 0)
+	gobottest.Assert(t, len(r.Halt()), 0)
+	gobottest.Assert(t, len(r.Halt()), 0)
+}
+
+// errorReader returns its errors from Read, and then io.EOF
+type errorReader struct {
+	mutex sync.Mutex
+	errs  []error
+	reads int
+}
+
+func (e *errorReader) Read([]byte) (int, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.reads++
+	if len(e.errs) == 0 {
+		return 0, io.EOF
+	}
+	err := e.errs[0]
+	e.errs = e.errs[1:]
+	return 0, err
+}
+func (e *errorReader) Write(b []byte) (int, error) { return len(b), nil }
+func (e *errorReader) Close() error                { return nil }
+
+// reading returns the number of endpoints read by the router
+func reading(r *MavlinkRouter) (n int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, ok := range r.reading {
+		if ok {
+			n++
+		}
+	}
+	return
+}
+
+func TestMavlinkRouterRestart(t *testing.T) {
+	var endpoints []*MavlinkAdaptor
+	var conns []net.Conn
+	for _, name := range []string{"vehicle", "gcs"} {
+		a := NewMavlinkAdaptor(name, "/dev/null")
+		conn, sp := net.Pipe()
+		a.sp = sp
+		endpoints = append(endpoints, a)
+		conns = append(conns, conn)
+	}
+
+	r := NewMavlinkRouter("router", endpoints...)
+	gobottest.Assert(t, len(r.Start()), 0)
+	gobottest.Assert(t, len(r.Halt()), 0)
+	gobottest.Assert(t, len(r.Start()), 0)
+	gobottest.Assert(t, len(r.Halt()), 0)
+	gobottest.Assert(t, reading(r), 2)
+
+	// packets read while halted are dropped
+	heartbeat := common.CraftMAVLinkPacket(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	conns[0].Write(heartbeat.Pack())
+	conns[1].SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	_, err := common.ReadMAVLinkPacket(conns[1])
+	gobottest.Refute(t, err, nil)
+
+	// the endpoints are read once when started again
+	gobottest.Assert(t, len(r.Start()), 0)
+	gobottest.Assert(t, reading(r), 2)
+	for i := 0; i < 3; i++ {
+		conns[0].Write(heartbeat.Pack())
+		conns[1].SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		packet, err := common.ReadMAVLinkPacket(conns[1])
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, packet.Pack(), heartbeat.Pack())
+	}
+
+	// closed endpoints are no longer read
+	for _, conn := range conns {
+		conn.Close()
+	}
+	timeout := time.After(1 * time.Second)
+	for reading(r) != 0 {
+		select {
+		case <-timeout:
+			t.Errorf("MavlinkRouter still reads closed endpoints")
+			return
+		case <-time.After(1 * time.Millisecond):
+		}
+	}
+}
+
+func TestMavlinkRouterReadError(t *testing.T) {
+	a := NewMavlinkAdaptor("vehicle", "/dev/null")
+	a.sp = &errorReader{errs: []error{errors.New("read error"), errors.New("read error")}}
+	r := NewMavlinkRouter("router", a)
+
+	sem := make(chan error, 2)
+	gobot.On(r.Event("errorIO"), func(data interface{}) {
+		sem <- data.(error)
+	})
+
+	gobottest.Assert(t, len(r.Start()), 0)
+	defer r.Halt()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-sem:
+			gobottest.Assert(t, err, errors.New("read error"))
+		case <-time.After(1 * time.Second):
+			t.Errorf("MavlinkRouter Event \"errorIO\" was not published")
+		}
+	}
+
+	// the endpoint is no longer read once it returns io.EOF
+	timeout := time.After(1 * time.Second)
+	for reading(r) != 0 {
+		select {
+		case <-timeout:
+			t.Errorf("MavlinkRouter still reads an endpoint at EOF")
+			return
+		case <-time.After(1 * time.Millisecond):
+		}
+	}
+}
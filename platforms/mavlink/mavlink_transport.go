@@ -0,0 +1,113 @@
+package mavlink
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ErrNoUDPPeer is returned when writing to a UDP server which did not receive
+// any packet yet
+var ErrNoUDPPeer = errors.New("No MAVLink UDP peer")
+
+// networkPort returns the network and address of a port of the form
+// "udp:host:port", "udpout:host:port" or "tcp:host:port"
+func networkPort(port string) (network string, address string, ok bool) {
+	i := strings.Index(port, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	switch network = port[:i]; network {
+	case "udp", "udpout", "tcp":
+		return network, port[i+1:], true
+	}
+	return "", "", false
+}
+
+// dial opens a connection to a network address. A "udp" address is listened
+// to, replying to the last peer which sent a packet, and an "udpout" or
+// "tcp" address is dialed.
+func dial(network string, address string) (io.ReadWriteCloser, error) {
+	switch network {
+	case "udp":
+		addr, err := net.ResolveUDPAddr("udp", address)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := net.ListenUDP("udp", addr)
+		if err != nil {
+			return nil, err
+		}
+		return newUDPConn(conn, true), nil
+	case "udpout":
+		addr, err := net.ResolveUDPAddr("udp", address)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := net.DialUDP("udp", nil, addr)
+		if err != nil {
+			return nil, err
+		}
+		return newUDPConn(conn, false), nil
+	}
+	return net.Dial(network, address)
+}
+
+// udpConn reads the packets of UDP datagrams as a stream, buffering the rest
+// of a datagram which is read partially
+type udpConn struct {
+	conn     *net.UDPConn
+	server   bool
+	datagram []byte
+	unread   []byte
+	mutex    sync.Mutex
+	peer     *net.UDPAddr
+}
+
+func newUDPConn(conn *net.UDPConn, server bool) *udpConn {
+	return &udpConn{
+		conn:     conn,
+		server:   server,
+		datagram: make([]byte, 65535),
+	}
+}
+
+// Read reads the rest of the last datagram, or the next datagram
+func (c *udpConn) Read(b []byte) (int, error) {
+	if len(c.unread) == 0 {
+		n, addr, err := c.conn.ReadFromUDP(c.datagram)
+		if err != nil {
+			return 0, err
+		}
+		if c.server {
+			c.mutex.Lock()
+			c.peer = addr
+			c.mutex.Unlock()
+		}
+		c.unread = c.datagram[:n]
+	}
+	n := copy(b, c.unread)
+	c.unread = c.unread[n:]
+	return n, nil
+}
+
+// Write writes a datagram, to the last peer of a server
+func (c *udpConn) Write(b []byte) (int, error) {
+	if !c.server {
+		return c.conn.Write(b)
+	}
+	c.mutex.Lock()
+	peer := c.peer
+	c.mutex.Unlock()
+	if peer == nil {
+		return 0, ErrNoUDPPeer
+	}
+	return c.conn.WriteToUDP(b, peer)
+}
+
+// Close closes the connection
+func (c *udpConn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,78 @@
+package mavlink
+
+import (
+	"net"
+	"testing"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+func TestMavlinkNetworkPort(t *testing.T) {
+	network, address, ok := networkPort("udp:0.0.0.0:14550")
+	gobottest.Assert(t, ok, true)
+	gobottest.Assert(t, network, "udp")
+	gobottest.Assert(t, address, "0.0.0.0:14550")
+
+	network, address, ok = networkPort("tcp:127.0.0.1:5760")
+	gobottest.Assert(t, network, "tcp")
+	gobottest.Assert(t, address, "127.0.0.1:5760")
+
+	_, _, ok = networkPort("/dev/ttyACM0")
+	gobottest.Assert(t, ok, false)
+	_, _, ok = networkPort("COM3:")
+	gobottest.Assert(t, ok, false)
+}
+
+func TestMavlinkAdaptorUDP(t *testing.T) {
+	server := NewMavlinkAdaptor("server", "udp:127.0.0.1:0")
+	gobottest.Assert(t, len(server.Connect()), 0)
+	defer server.Finalize()
+	address := server.sp.(*gobot.MeteredReadWriteCloser).ReadWriteCloser.(*udpConn).conn.LocalAddr()
+
+	heartbeat := common.CraftMAVLinkPacket(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	_, err := server.sp.Write(heartbeat.Pack())
+	gobottest.Assert(t, err, ErrNoUDPPeer)
+
+	client := NewMavlinkAdaptor("client", "udpout:"+address.String())
+	gobottest.Assert(t, len(client.Connect()), 0)
+	defer client.Finalize()
+
+	// a datagram of two packets
+	_, err = client.sp.Write(append(heartbeat.Pack(), heartbeat.Pack()...))
+	gobottest.Assert(t, err, nil)
+	for i := 0; i < 2; i++ {
+		packet, err := common.ReadMAVLinkPacket(server.sp)
+		gobottest.Assert(t, err, nil)
+		gobottest.Assert(t, packet.Pack(), heartbeat.Pack())
+	}
+
+	_, err = server.sp.Write(heartbeat.Pack())
+	gobottest.Assert(t, err, nil)
+	packet, err := common.ReadMAVLinkPacket(client.sp)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, packet.Pack(), heartbeat.Pack())
+}
+
+func TestMavlinkAdaptorTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	gobottest.Assert(t, err, nil)
+	defer listener.Close()
+
+	a := NewMavlinkAdaptor("tcp", "tcp:"+listener.Addr().String())
+	gobottest.Assert(t, len(a.Connect()), 0)
+	defer a.Finalize()
+	conn, err := listener.Accept()
+	gobottest.Assert(t, err, nil)
+	defer conn.Close()
+
+	heartbeat := common.CraftMAVLink2Packet(1, 1, common.NewHeartbeat(0, 2, 3, 0x51, 4, 3))
+	conn.Write(heartbeat.Pack())
+	packet, err := common.ReadMAVLinkPacket(a.sp)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, packet.Pack(), heartbeat.Pack())
+
+	a = NewMavlinkAdaptor("tcp", "tcp:127.0.0.1:0")
+	gobottest.Refute(t, len(a.Connect()), 0)
+}
@@ -0,0 +1,729 @@
+package mavlink
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+var _ gobot.Driver = (*MavlinkVehicle)(nil)
+var _ gobot.Commander = (*MavlinkVehicle)(nil)
+
+// ErrVehicleTimeout is returned when a vehicle does not respond to a request
+// and its retries
+var ErrVehicleTimeout = errors.New("MAVLink vehicle did not respond")
+
+// commandResults are the names of the MAV_RESULT values
+var commandResults = map[uint8]string{
+	common.MAV_RESULT_TEMPORARILY_REJECTED: "temporarily rejected",
+	common.MAV_RESULT_DENIED:               "denied",
+	common.MAV_RESULT_UNSUPPORTED:          "unsupported",
+	common.MAV_RESULT_FAILED:               "failed",
+}
+
+// MissionItem is an item of the mission of a vehicle. The meaning of the
+// params and of X, Y and Z depends on the MAV_CMD Command and the MAV_FRAME
+// Frame, X and Y are the latitude and longitude of the global frames.
+type MissionItem struct {
+	Command      uint16  `json:"command"`
+	Frame        uint8   `json:"frame"`
+	Param1       float32 `json:"param1"`
+	Param2       float32 `json:"param2"`
+	Param3       float32 `json:"param3"`
+	Param4       float32 `json:"param4"`
+	X            float32 `json:"x"`
+	Y            float32 `json:"y"`
+	Z            float32 `json:"z"`
+	Autocontinue bool    `json:"autocontinue"`
+}
+
+// Param is an onboard parameter of a vehicle
+type Param struct {
+	Value float32 `json:"value"`
+	// Type is the MAV_PARAM_TYPE of the parameter
+	Type  uint8  `json:"type"`
+	Index uint16 `json:"index"`
+}
+
+// MavlinkVehicle controls a vehicle, given its system and component IDs,
+// through a MavlinkDriver. It sends the heartbeats of a ground control
+// station, sends commands and waits for their acknowledgement, uploads and
+// downloads missions, and reads and writes the parameters of the vehicle,
+// which are cached.
+//
+// A request which is not answered within Timeout is sent again up to Retries
+// times before failing with ErrVehicleTimeout. The MavlinkDriver must be
+// started for the vehicle to receive the answers.
+type MavlinkVehicle struct {
+	name        string
+	driver      *MavlinkDriver
+	systemID    uint8
+	componentID uint8
+	// GCSSystemID and GCSComponentID identify the sender of the packets
+	GCSSystemID    uint8
+	GCSComponentID uint8
+	// Timeout is the duration to wait for the answer to a request
+	Timeout time.Duration
+	// Retries is the number of times a request is sent again
+	Retries int
+	// HeartbeatInterval is the interval of the heartbeats sent, 0 to send none
+	HeartbeatInterval time.Duration
+
+	mutex       sync.Mutex
+	heartbeat   *common.Heartbeat
+	params      map[string]Param
+	paramCount  int
+	waiters     map[*waiter]bool
+	unsubscribe func()
+	heartbeats  chan bool
+	gobot.Eventer
+	gobot.Commander
+}
+
+// waiter receives the messages matched by match
+type waiter struct {
+	match func(common.MAVLinkMessage) bool
+	c     chan common.MAVLinkMessage
+}
+
+// NewMavlinkVehicle creates a new mavlink vehicle with specified name, driver
+// and system and component IDs of the vehicle.
+//
+// It add the following events:
+//	"heartbeat" - triggered when the vehicle sends a heartbeat
+//	"param" - triggered when the vehicle sends the value of a parameter
+//
+// It adds the following API commands:
+//	"Arm", "Disarm", "SetMode", "Takeoff", "Land", "ReturnToLaunch",
+//	"SendCommand", "UploadMission", "DownloadMission", "ClearMission",
+//	"GetParam", "SetParam", "LoadParams", "Params"
+func NewMavlinkVehicle(driver *MavlinkDriver, name string, systemID uint8, componentID uint8) *MavlinkVehicle {
+	v := &MavlinkVehicle{
+		name:              name,
+		driver:            driver,
+		systemID:          systemID,
+		componentID:       componentID,
+		GCSSystemID:       255,
+		GCSComponentID:    common.MAV_COMP_ID_MISSIONPLANNER,
+		Timeout:           time.Second,
+		Retries:           3,
+		HeartbeatInterval: time.Second,
+		params:            make(map[string]Param),
+		waiters:           make(map[*waiter]bool),
+		Eventer:           gobot.NewEventer(),
+		Commander:         gobot.NewCommander(),
+	}
+
+	v.AddEvent("heartbeat")
+	v.AddEvent("param")
+
+	v.addCommands()
+
+	return v
+}
+
+func (v *MavlinkVehicle) Name() string                 { return v.name }
+func (v *MavlinkVehicle) Connection() gobot.Connection { return v.driver.Connection() }
+
+// Driver returns the MavlinkDriver of the vehicle
+func (v *MavlinkVehicle) Driver() *MavlinkDriver { return v.driver }
+
+// SystemID returns the system ID of the vehicle
+func (v *MavlinkVehicle) SystemID() uint8 { return v.systemID }
+
+// ComponentID returns the component ID of the vehicle
+func (v *MavlinkVehicle) ComponentID() uint8 { return v.componentID }
+
+// Start subscribes to the messages of the vehicle and starts sending
+// heartbeats
+func (v *MavlinkVehicle) Start() (errs []error) {
+	unsubscribe, err := gobot.Subscribe(v.driver.VehicleEvent("message", v.systemID, v.componentID), func(data interface{}) {
+		v.handle(data.(common.MAVLinkMessage))
+	})
+	if err != nil {
+		return []error{err}
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.unsubscribe = unsubscribe
+	if v.HeartbeatInterval > 0 {
+		v.heartbeats = gobot.Every(v.HeartbeatInterval, func() {
+			v.send(common.NewHeartbeat(0,
+				common.MAV_TYPE_GCS,
+				common.MAV_AUTOPILOT_INVALID,
+				0,
+				common.MAV_STATE_ACTIVE,
+				common.MAVLINK_VERSION,
+			))
+		})
+	}
+	return
+}
+
+// Halt stops sending heartbeats and unsubscribes from the messages of the
+// vehicle
+func (v *MavlinkVehicle) Halt() (errs []error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if v.heartbeats != nil {
+		close(v.heartbeats)
+		v.heartbeats = nil
+	}
+	if v.unsubscribe != nil {
+		v.unsubscribe()
+		v.unsubscribe = nil
+	}
+	return
+}
+
+// Heartbeat returns the last heartbeat of the vehicle, or nil
+func (v *MavlinkVehicle) Heartbeat() *common.Heartbeat {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	return v.heartbeat
+}
+
+// Armed returns whether the last heartbeat of the vehicle reported it armed
+func (v *MavlinkVehicle) Armed() bool {
+	heartbeat := v.Heartbeat()
+	return heartbeat != nil && heartbeat.BASE_MODE&common.MAV_MODE_FLAG_SAFETY_ARMED != 0
+}
+
+// Mode returns the autopilot specific mode of the last heartbeat of the
+// vehicle
+func (v *MavlinkVehicle) Mode() uint32 {
+	if heartbeat := v.Heartbeat(); heartbeat != nil {
+		return heartbeat.CUSTOM_MODE
+	}
+	return 0
+}
+
+// SendCommand sends a MAV_CMD command with up to 7 params, and waits for its
+// acknowledgement. It returns an error unless the command is accepted.
+func (v *MavlinkVehicle) SendCommand(command uint16, params ...float32) error {
+	p := make([]float32, 7)
+	copy(p, params)
+	attempt := uint8(0)
+	message, err := v.request(func() common.MAVLinkMessage {
+		defer func() { attempt++ }()
+		return common.NewCommandLong(p[0], p[1], p[2], p[3], p[4], p[5], p[6],
+			command, v.systemID, v.componentID, attempt)
+	}, func(m common.MAVLinkMessage) bool {
+		ack, ok := m.(*common.CommandAck)
+		return ok && ack.COMMAND == command
+	})
+	if err != nil {
+		return err
+	}
+	if result := message.(*common.CommandAck).RESULT; result != common.MAV_RESULT_ACCEPTED {
+		name, ok := commandResults[result]
+		if !ok {
+			name = fmt.Sprintf("result %v", result)
+		}
+		return fmt.Errorf("MAVLink command %v %v", command, name)
+	}
+	return nil
+}
+
+// Arm arms the motors of the vehicle
+func (v *MavlinkVehicle) Arm() error {
+	return v.SendCommand(common.MAV_CMD_COMPONENT_ARM_DISARM, 1)
+}
+
+// Disarm disarms the motors of the vehicle
+func (v *MavlinkVehicle) Disarm() error {
+	return v.SendCommand(common.MAV_CMD_COMPONENT_ARM_DISARM, 0)
+}
+
+// Takeoff takes off to altitude meters
+func (v *MavlinkVehicle) Takeoff(altitude float32) error {
+	return v.SendCommand(common.MAV_CMD_NAV_TAKEOFF, 0, 0, 0, 0, 0, 0, altitude)
+}
+
+// Land lands at the current location
+func (v *MavlinkVehicle) Land() error {
+	return v.SendCommand(common.MAV_CMD_NAV_LAND)
+}
+
+// ReturnToLaunch returns to the launch location
+func (v *MavlinkVehicle) ReturnToLaunch() error {
+	return v.SendCommand(common.MAV_CMD_NAV_RETURN_TO_LAUNCH)
+}
+
+// SetMode sets the autopilot specific mode of the vehicle, and waits for a
+// heartbeat reporting it
+func (v *MavlinkVehicle) SetMode(mode uint32) error {
+	_, err := v.request(func() common.MAVLinkMessage {
+		return common.NewSetMode(mode, v.systemID, common.MAV_MODE_FLAG_CUSTOM_MODE_ENABLED)
+	}, func(m common.MAVLinkMessage) bool {
+		heartbeat, ok := m.(*common.Heartbeat)
+		return ok && heartbeat.CUSTOM_MODE == mode
+	})
+	return err
+}
+
+// UploadMission replaces the mission of the vehicle by items
+func (v *MavlinkVehicle) UploadMission(items []MissionItem) error {
+	w := v.wait(func(m common.MAVLinkMessage) bool {
+		switch m.(type) {
+		case *common.MissionRequest, *common.MissionAck:
+			return true
+		}
+		return false
+	})
+	defer v.done(w)
+
+	var message common.MAVLinkMessage = common.NewMissionCount(uint16(len(items)), v.systemID, v.componentID)
+	for attempt := 0; ; {
+		if err := v.send(message); err != nil {
+			return err
+		}
+		select {
+		case m := <-w.c:
+			switch m := m.(type) {
+			case *common.MissionRequest:
+				if int(m.SEQ) >= len(items) {
+					return fmt.Errorf("MAVLink vehicle requested mission item %v of %v", m.SEQ, len(items))
+				}
+				message, attempt = items[m.SEQ].message(m.SEQ, v.systemID, v.componentID), 0
+			case *common.MissionAck:
+				if m.TYPE != common.MAV_MISSION_ACCEPTED {
+					return fmt.Errorf("MAVLink mission rejected with result %v", m.TYPE)
+				}
+				return nil
+			}
+		case <-time.After(v.Timeout):
+			if attempt++; attempt > v.Retries {
+				return ErrVehicleTimeout
+			}
+		}
+	}
+}
+
+// DownloadMission returns the mission of the vehicle
+func (v *MavlinkVehicle) DownloadMission() ([]MissionItem, error) {
+	message, err := v.request(func() common.MAVLinkMessage {
+		return common.NewMissionRequestList(v.systemID, v.componentID)
+	}, func(m common.MAVLinkMessage) bool {
+		_, ok := m.(*common.MissionCount)
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := []MissionItem{}
+	for seq := uint16(0); seq < message.(*common.MissionCount).COUNT; seq++ {
+		seq := seq
+		message, err := v.request(func() common.MAVLinkMessage {
+			return common.NewMissionRequest(seq, v.systemID, v.componentID)
+		}, func(m common.MAVLinkMessage) bool {
+			item, ok := m.(*common.MissionItem)
+			return ok && item.SEQ == seq
+		})
+		if err != nil {
+			return nil, err
+		}
+		item := message.(*common.MissionItem)
+		items = append(items, MissionItem{
+			Command:      item.COMMAND,
+			Frame:        item.FRAME,
+			Param1:       item.PARAM1,
+			Param2:       item.PARAM2,
+			Param3:       item.PARAM3,
+			Param4:       item.PARAM4,
+			X:            item.X,
+			Y:            item.Y,
+			Z:            item.Z,
+			Autocontinue: item.AUTOCONTINUE != 0,
+		})
+	}
+	return items, v.send(common.NewMissionAck(v.systemID, v.componentID, common.MAV_MISSION_ACCEPTED))
+}
+
+// ClearMission clears the mission of the vehicle
+func (v *MavlinkVehicle) ClearMission() error {
+	message, err := v.request(func() common.MAVLinkMessage {
+		return common.NewMissionClearAll(v.systemID, v.componentID)
+	}, func(m common.MAVLinkMessage) bool {
+		_, ok := m.(*common.MissionAck)
+		return ok
+	})
+	if err != nil {
+		return err
+	}
+	if result := message.(*common.MissionAck).TYPE; result != common.MAV_MISSION_ACCEPTED {
+		return fmt.Errorf("MAVLink mission rejected with result %v", result)
+	}
+	return nil
+}
+
+// message returns the MISSION_ITEM message of an item
+func (i MissionItem) message(seq uint16, systemID uint8, componentID uint8) *common.MissionItem {
+	autocontinue := uint8(0)
+	if i.Autocontinue {
+		autocontinue = 1
+	}
+	return common.NewMissionItem(i.Param1, i.Param2, i.Param3, i.Param4, i.X, i.Y, i.Z,
+		seq, i.Command, systemID, componentID, i.Frame, 0, autocontinue)
+}
+
+// Params returns the cached parameters of the vehicle by name
+func (v *MavlinkVehicle) Params() map[string]Param {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	params := make(map[string]Param, len(v.params))
+	for name, param := range v.params {
+		params[name] = param
+	}
+	return params
+}
+
+// GetParam returns the value of a parameter, from the cache when it was
+// already read
+func (v *MavlinkVehicle) GetParam(name string) (float32, error) {
+	v.mutex.Lock()
+	param, ok := v.params[name]
+	v.mutex.Unlock()
+	if ok {
+		return param.Value, nil
+	}
+
+	message, err := v.request(func() common.MAVLinkMessage {
+		return common.NewParamRequestRead(-1, v.systemID, v.componentID, paramID(name))
+	}, matchParam(name))
+	if err != nil {
+		return 0, err
+	}
+	return message.(*common.ParamValue).PARAM_VALUE, nil
+}
+
+// SetParam writes the value of a parameter, and returns an error unless the
+// vehicle reports the new value
+func (v *MavlinkVehicle) SetParam(name string, value float32) error {
+	v.mutex.Lock()
+	param, ok := v.params[name]
+	v.mutex.Unlock()
+	if !ok {
+		param.Type = common.MAV_PARAM_TYPE_REAL32
+	}
+
+	message, err := v.request(func() common.MAVLinkMessage {
+		return common.NewParamSet(value, v.systemID, v.componentID, paramID(name), param.Type)
+	}, matchParam(name))
+	if err != nil {
+		return err
+	}
+	if actual := message.(*common.ParamValue).PARAM_VALUE; actual != value {
+		return fmt.Errorf("MAVLink param %v was not set to %v, it is %v", name, value, actual)
+	}
+	return nil
+}
+
+// LoadParams reads every parameter of the vehicle into the cache, and
+// returns them
+func (v *MavlinkVehicle) LoadParams() (map[string]Param, error) {
+	w := v.wait(func(m common.MAVLinkMessage) bool {
+		_, ok := m.(*common.ParamValue)
+		return ok
+	})
+	defer v.done(w)
+
+	for received, attempt := false, 0; !received; attempt++ {
+		if attempt > v.Retries {
+			return nil, ErrVehicleTimeout
+		}
+		if err := v.send(common.NewParamRequestList(v.systemID, v.componentID)); err != nil {
+			return nil, err
+		}
+		select {
+		case <-w.c:
+			received = true
+		case <-time.After(v.Timeout):
+		}
+	}
+
+	// the list is streamed until no parameter is received for Timeout, and
+	// the missing parameters are then read by index
+	for streaming := true; streaming; {
+		select {
+		case <-w.c:
+		case <-time.After(v.Timeout):
+			streaming = false
+		}
+	}
+	for _, index := range v.missingParams() {
+		index := index
+		_, err := v.request(func() common.MAVLinkMessage {
+			return common.NewParamRequestRead(int16(index), v.systemID, v.componentID, [16]uint8{})
+		}, func(m common.MAVLinkMessage) bool {
+			param, ok := m.(*common.ParamValue)
+			return ok && param.PARAM_INDEX == index
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v.Params(), nil
+}
+
+// missingParams returns the indices of the parameters which are not cached
+func (v *MavlinkVehicle) missingParams() (missing []uint16) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	cached := make(map[uint16]bool)
+	for _, param := range v.params {
+		cached[param.Index] = true
+	}
+	for index := 0; index < v.paramCount; index++ {
+		if !cached[uint16(index)] {
+			missing = append(missing, uint16(index))
+		}
+	}
+	return
+}
+
+func matchParam(name string) func(common.MAVLinkMessage) bool {
+	return func(m common.MAVLinkMessage) bool {
+		param, ok := m.(*common.ParamValue)
+		return ok && paramName(param.PARAM_ID) == name
+	}
+}
+
+// paramID returns the PARAM_ID of a parameter name
+func paramID(name string) (id [16]uint8) {
+	copy(id[:], name)
+	return
+}
+
+// paramName returns the parameter name of a PARAM_ID, which is terminated by
+// a NULL byte when it is shorter than 16 bytes
+func paramName(id [16]uint8) string {
+	for i, c := range id {
+		if c == 0 {
+			return string(id[:i])
+		}
+	}
+	return string(id[:])
+}
+
+// handle updates the state of the vehicle with a message it sent, and passes
+// it to the waiters it matches
+func (v *MavlinkVehicle) handle(message common.MAVLinkMessage) {
+	v.mutex.Lock()
+	switch m := message.(type) {
+	case *common.Heartbeat:
+		v.heartbeat = m
+	case *common.ParamValue:
+		v.params[paramName(m.PARAM_ID)] = Param{Value: m.PARAM_VALUE, Type: m.PARAM_TYPE, Index: m.PARAM_INDEX}
+		v.paramCount = int(m.PARAM_COUNT)
+	}
+	for w := range v.waiters {
+		if w.match(message) {
+			select {
+			case w.c <- message:
+			default:
+			}
+		}
+	}
+	v.mutex.Unlock()
+
+	switch m := message.(type) {
+	case *common.Heartbeat:
+		gobot.Publish(v.Event("heartbeat"), m)
+	case *common.ParamValue:
+		gobot.Publish(v.Event("param"), m)
+	}
+}
+
+// wait returns a waiter receiving the messages matched by match until done
+func (v *MavlinkVehicle) wait(match func(common.MAVLinkMessage) bool) *waiter {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	w := &waiter{match: match, c: make(chan common.MAVLinkMessage, 16)}
+	v.waiters[w] = true
+	return w
+}
+
+func (v *MavlinkVehicle) done(w *waiter) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	delete(v.waiters, w)
+}
+
+// request sends the message returned by message until the vehicle answers
+// with a message matched by match, which is returned
+func (v *MavlinkVehicle) request(message func() common.MAVLinkMessage, match func(common.MAVLinkMessage) bool) (common.MAVLinkMessage, error) {
+	w := v.wait(match)
+	defer v.done(w)
+
+	for attempt := 0; attempt <= v.Retries; attempt++ {
+		if err := v.send(message()); err != nil {
+			return nil, err
+		}
+		select {
+		case m := <-w.c:
+			return m, nil
+		case <-time.After(v.Timeout):
+		}
+	}
+	return nil, ErrVehicleTimeout
+}
+
+func (v *MavlinkVehicle) send(message common.MAVLinkMessage) error {
+	return v.driver.SendMessage(v.GCSSystemID, v.GCSComponentID, message)
+}
+
+func (v *MavlinkVehicle) addCommands() {
+	altitudeRange := &gobot.ParamRange{Min: 0, Max: 10000}
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Arm",
+		Description: "Arms the motors of the vehicle",
+	}, func(params map[string]interface{}) interface{} {
+		return v.Arm()
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Disarm",
+		Description: "Disarms the motors of the vehicle",
+	}, func(params map[string]interface{}) interface{} {
+		return v.Disarm()
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetMode",
+		Description: "Sets the autopilot specific mode of the vehicle",
+		Params: []gobot.CommandParam{
+			{Name: "mode", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 4294967295}},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return v.SetMode(uint32(params["mode"].(int)))
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Takeoff",
+		Description: "Takes off to altitude meters",
+		Params: []gobot.CommandParam{
+			{Name: "altitude", Type: gobot.NumberParam, Required: true, Range: altitudeRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return v.Takeoff(float32(params["altitude"].(float64)))
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Land",
+		Description: "Lands at the current location",
+	}, func(params map[string]interface{}) interface{} {
+		return v.Land()
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "ReturnToLaunch",
+		Description: "Returns to the launch location",
+	}, func(params map[string]interface{}) interface{} {
+		return v.ReturnToLaunch()
+	})
+
+	commandParams := []gobot.CommandParam{
+		{Name: "command", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 65535}},
+	}
+	for i := 1; i <= 7; i++ {
+		commandParams = append(commandParams, gobot.CommandParam{Name: fmt.Sprintf("param%v", i), Type: gobot.NumberParam, Default: 0})
+	}
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SendCommand",
+		Description: "Sends a MAV_CMD command and waits for its acknowledgement",
+		Params:      commandParams,
+	}, func(params map[string]interface{}) interface{} {
+		p := []float32{}
+		for i := 1; i <= 7; i++ {
+			p = append(p, float32(params[fmt.Sprintf("param%v", i)].(float64)))
+		}
+		return v.SendCommand(uint16(params["command"].(int)), p...)
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "UploadMission",
+		Description: "Replaces the mission of the vehicle by items",
+		Params: []gobot.CommandParam{
+			{Name: "items", Type: gobot.ArrayParam, Description: "The mission items, an empty array clears the mission", Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		items := []MissionItem{}
+		data, err := json.Marshal(params["items"])
+		if err == nil {
+			err = json.Unmarshal(data, &items)
+		}
+		if err != nil {
+			return &gobot.CommandError{Command: "UploadMission", Param: "items", Message: "must be an array of mission items"}
+		}
+		return v.UploadMission(items)
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "DownloadMission",
+		Description: "Returns the mission of the vehicle",
+	}, func(params map[string]interface{}) interface{} {
+		items, err := v.DownloadMission()
+		if err != nil {
+			return err
+		}
+		return items
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "ClearMission",
+		Description: "Clears the mission of the vehicle",
+	}, func(params map[string]interface{}) interface{} {
+		return v.ClearMission()
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "GetParam",
+		Description: "Returns the value of a parameter",
+		Params: []gobot.CommandParam{
+			{Name: "name", Type: gobot.StringParam, Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		value, err := v.GetParam(params["name"].(string))
+		if err != nil {
+			return err
+		}
+		return value
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetParam",
+		Description: "Writes the value of a parameter",
+		Params: []gobot.CommandParam{
+			{Name: "name", Type: gobot.StringParam, Required: true},
+			{Name: "value", Type: gobot.NumberParam, Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return v.SetParam(params["name"].(string), float32(params["value"].(float64)))
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "LoadParams",
+		Description: "Reads every parameter of the vehicle",
+	}, func(params map[string]interface{}) interface{} {
+		p, err := v.LoadParams()
+		if err != nil {
+			return err
+		}
+		return p
+	})
+
+	v.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Params",
+		Description: "Returns the cached parameters of the vehicle by name",
+	}, func(params map[string]interface{}) interface{} {
+		return v.Params()
+	})
+}
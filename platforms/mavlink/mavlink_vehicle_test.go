@@ -0,0 +1,370 @@
+package mavlink
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+	common "github.com/hybridgroup/gobot/platforms/mavlink/common"
+)
+
+// testAutopilot answers the requests of a MavlinkVehicle as system 1,
+// component 1
+type testAutopilot struct {
+	conn     net.Conn
+	mutex    sync.Mutex
+	armed    bool
+	mode     uint32
+	names    []string
+	params   map[string]float32
+	readOnly string
+	// dropParam is not sent when the parameters are listed
+	dropParam string
+	mission   []*common.MissionItem
+	uploading []*common.MissionItem
+	// ignore is the number of messages to ignore by message ID
+	ignore     map[uint8]int
+	commands   []*common.CommandLong
+	heartbeats []*common.MAVLinkPacket
+	acked      bool
+}
+
+func newTestAutopilot(conn net.Conn) *testAutopilot {
+	return &testAutopilot{
+		conn:     conn,
+		names:    []string{"RTL_ALT", "SYSID_THISMAV", "WPNAV_SPEED"},
+		params:   map[string]float32{"RTL_ALT": 1500, "SYSID_THISMAV": 1, "WPNAV_SPEED": 500},
+		readOnly: "SYSID_THISMAV",
+		ignore:   make(map[uint8]int),
+	}
+}
+
+func (a *testAutopilot) run() {
+	for {
+		packet, err := common.ReadMAVLinkPacket(a.conn)
+		if err != nil {
+			return
+		}
+		message, err := packet.MAVLinkMessage()
+		if err != nil {
+			continue
+		}
+		a.mutex.Lock()
+		if _, ok := message.(*common.Heartbeat); ok {
+			a.heartbeats = append(a.heartbeats, packet)
+		} else if a.ignore[message.Id()] > 0 {
+			a.ignore[message.Id()]--
+		} else {
+			a.handle(message)
+		}
+		a.mutex.Unlock()
+	}
+}
+
+func (a *testAutopilot) send(message common.MAVLinkMessage) {
+	a.conn.Write(common.CraftMAVLinkPacket(1, 1, message).Pack())
+}
+
+func (a *testAutopilot) heartbeat() {
+	base := uint8(common.MAV_MODE_FLAG_CUSTOM_MODE_ENABLED)
+	if a.armed {
+		base |= common.MAV_MODE_FLAG_SAFETY_ARMED
+	}
+	a.send(common.NewHeartbeat(a.mode, common.MAV_TYPE_QUADROTOR, common.MAV_AUTOPILOT_ARDUPILOTMEGA, base, common.MAV_STATE_ACTIVE, 3))
+}
+
+func (a *testAutopilot) param(name string) {
+	for i, n := range a.names {
+		if n == name {
+			a.send(common.NewParamValue(a.params[name], uint16(len(a.names)), uint16(i), paramID(name), common.MAV_PARAM_TYPE_REAL32))
+		}
+	}
+}
+
+func (a *testAutopilot) handle(message common.MAVLinkMessage) {
+	switch m := message.(type) {
+	case *common.CommandLong:
+		a.commands = append(a.commands, m)
+		result := uint8(common.MAV_RESULT_ACCEPTED)
+		switch m.COMMAND {
+		case common.MAV_CMD_COMPONENT_ARM_DISARM:
+			a.armed = m.PARAM1 == 1
+		case common.MAV_CMD_NAV_TAKEOFF:
+			if !a.armed {
+				result = common.MAV_RESULT_DENIED
+			}
+		case common.MAV_CMD_NAV_LAND, common.MAV_CMD_NAV_RETURN_TO_LAUNCH:
+		default:
+			result = common.MAV_RESULT_UNSUPPORTED
+		}
+		a.send(common.NewCommandAck(m.COMMAND, result))
+		a.heartbeat()
+	case *common.SetMode:
+		a.mode = m.CUSTOM_MODE
+		a.heartbeat()
+	case *common.ParamRequestList:
+		for _, name := range a.names {
+			if name != a.dropParam {
+				a.param(name)
+			}
+		}
+	case *common.ParamRequestRead:
+		if m.PARAM_INDEX >= 0 && int(m.PARAM_INDEX) < len(a.names) {
+			a.param(a.names[m.PARAM_INDEX])
+		} else {
+			a.param(paramName(m.PARAM_ID))
+		}
+	case *common.ParamSet:
+		name := paramName(m.PARAM_ID)
+		if _, ok := a.params[name]; ok && name != a.readOnly {
+			a.params[name] = m.PARAM_VALUE
+		}
+		a.param(name)
+	case *common.MissionCount:
+		a.uploading = make([]*common.MissionItem, m.COUNT)
+		if m.COUNT == 0 {
+			a.mission = nil
+			a.send(common.NewMissionAck(255, 190, common.MAV_MISSION_ACCEPTED))
+		} else {
+			a.send(common.NewMissionRequest(0, 255, 190))
+		}
+	case *common.MissionItem:
+		if int(m.SEQ) >= len(a.uploading) {
+			a.send(common.NewMissionAck(255, 190, common.MAV_MISSION_INVALID_SEQUENCE))
+			return
+		}
+		a.uploading[m.SEQ] = m
+		if int(m.SEQ)+1 < len(a.uploading) {
+			a.send(common.NewMissionRequest(m.SEQ+1, 255, 190))
+		} else {
+			a.mission = a.uploading
+			a.send(common.NewMissionAck(255, 190, common.MAV_MISSION_ACCEPTED))
+		}
+	case *common.MissionRequestList:
+		a.send(common.NewMissionCount(uint16(len(a.mission)), 255, 190))
+	case *common.MissionRequest:
+		if int(m.SEQ) < len(a.mission) {
+			a.send(a.mission[m.SEQ])
+		}
+	case *common.MissionClearAll:
+		a.mission = nil
+		a.send(common.NewMissionAck(255, 190, common.MAV_MISSION_ACCEPTED))
+	case *common.MissionAck:
+		a.acked = true
+	}
+}
+
+func initTestMavlinkVehicle() (*MavlinkVehicle, *testAutopilot, func()) {
+	a := NewMavlinkAdaptor("myAdaptor", "/dev/null")
+	conn, sp := net.Pipe()
+	a.sp = sp
+	d := NewMavlinkDriver(a, "myDriver", 0)
+	v := NewMavlinkVehicle(d, "myVehicle", 1, 1)
+	v.Timeout = 50 * time.Millisecond
+	v.HeartbeatInterval = 0
+
+	autopilot := newTestAutopilot(conn)
+	go autopilot.run()
+	d.Start()
+	v.Start()
+	return v, autopilot, func() {
+		v.Halt()
+		d.Halt()
+		conn.Close()
+		sp.Close()
+	}
+}
+
+// eventually returns whether f returns true within 100ms
+func eventually(f func() bool) bool {
+	for i := 0; i < 100; i++ {
+		if f() {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
+
+func TestMavlinkVehicle(t *testing.T) {
+	v, _, stop := initTestMavlinkVehicle()
+	defer stop()
+
+	gobottest.Assert(t, v.Name(), "myVehicle")
+	gobottest.Assert(t, v.Connection().Name(), "myAdaptor")
+	gobottest.Assert(t, v.Driver().Name(), "myDriver")
+	gobottest.Assert(t, v.SystemID(), uint8(1))
+	gobottest.Assert(t, v.ComponentID(), uint8(1))
+	gobottest.Assert(t, v.Heartbeat() == nil, true)
+	gobottest.Assert(t, v.Armed(), false)
+	gobottest.Assert(t, v.Mode(), uint32(0))
+	for _, name := range []string{"Arm", "Disarm", "SetMode", "Takeoff", "Land", "ReturnToLaunch", "SendCommand",
+		"UploadMission", "DownloadMission", "ClearMission", "GetParam", "SetParam", "LoadParams", "Params"} {
+		gobottest.Refute(t, v.Command(name), nil)
+	}
+}
+
+func TestMavlinkVehicleCommands(t *testing.T) {
+	v, autopilot, stop := initTestMavlinkVehicle()
+	defer stop()
+
+	gobottest.Assert(t, v.Takeoff(10), errors.New("MAVLink command 22 denied"))
+	gobottest.Assert(t, v.Arm(), nil)
+	gobottest.Assert(t, eventually(v.Armed), true)
+	gobottest.Assert(t, v.Takeoff(10), nil)
+	gobottest.Assert(t, v.Land(), nil)
+	gobottest.Assert(t, v.ReturnToLaunch(), nil)
+	gobottest.Assert(t, v.Disarm(), nil)
+	gobottest.Assert(t, eventually(func() bool { return !v.Armed() }), true)
+	gobottest.Assert(t, v.SendCommand(9999), errors.New("MAVLink command 9999 unsupported"))
+
+	autopilot.mutex.Lock()
+	takeoff := autopilot.commands[2]
+	autopilot.mutex.Unlock()
+	gobottest.Assert(t, takeoff.COMMAND, uint16(common.MAV_CMD_NAV_TAKEOFF))
+	gobottest.Assert(t, takeoff.PARAM7, float32(10))
+	gobottest.Assert(t, takeoff.TARGET_SYSTEM, uint8(1))
+
+	gobottest.Assert(t, v.SetMode(4), nil)
+	gobottest.Assert(t, eventually(func() bool { return v.Mode() == 4 }), true)
+	gobottest.Assert(t, v.Command("SetMode")(map[string]interface{}{"mode": 5}), nil)
+	gobottest.Assert(t, eventually(func() bool { return v.Mode() == 5 }), true)
+}
+
+func TestMavlinkVehicleCommandRetries(t *testing.T) {
+	v, autopilot, stop := initTestMavlinkVehicle()
+	defer stop()
+
+	autopilot.mutex.Lock()
+	autopilot.ignore[76] = 2
+	autopilot.mutex.Unlock()
+	gobottest.Assert(t, v.Arm(), nil)
+
+	autopilot.mutex.Lock()
+	gobottest.Assert(t, len(autopilot.commands), 1)
+	// retries are confirmations
+	gobottest.Assert(t, autopilot.commands[0].CONFIRMATION, uint8(2))
+	autopilot.ignore[76] = 10
+	autopilot.mutex.Unlock()
+
+	v.Retries = 1
+	gobottest.Assert(t, v.Disarm(), ErrVehicleTimeout)
+}
+
+func TestMavlinkVehicleMission(t *testing.T) {
+	v, autopilot, stop := initTestMavlinkVehicle()
+	defer stop()
+
+	mission := []MissionItem{
+		{Command: common.MAV_CMD_NAV_TAKEOFF, Frame: common.MAV_FRAME_GLOBAL_RELATIVE_ALT, Z: 10, Autocontinue: true},
+		{Command: common.MAV_CMD_NAV_WAYPOINT, Frame: common.MAV_FRAME_GLOBAL_RELATIVE_ALT, X: 47.39, Y: 8.54, Z: 20, Autocontinue: true},
+		{Command: common.MAV_CMD_NAV_RETURN_TO_LAUNCH},
+	}
+	autopilot.mutex.Lock()
+	autopilot.ignore[39] = 1
+	autopilot.mutex.Unlock()
+	gobottest.Assert(t, v.UploadMission(mission), nil)
+
+	autopilot.mutex.Lock()
+	gobottest.Assert(t, len(autopilot.mission), 3)
+	gobottest.Assert(t, autopilot.mission[1].SEQ, uint16(1))
+	gobottest.Assert(t, autopilot.mission[1].X, float32(47.39))
+	autopilot.mutex.Unlock()
+
+	items, err := v.DownloadMission()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, items, mission)
+	gobottest.Assert(t, eventually(func() bool {
+		autopilot.mutex.Lock()
+		defer autopilot.mutex.Unlock()
+		return autopilot.acked
+	}), true)
+
+	gobottest.Assert(t, v.ClearMission(), nil)
+	items, err = v.DownloadMission()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, items, []MissionItem{})
+
+	gobottest.Assert(t, v.Command("UploadMission")(map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"command": 22, "frame": 3, "z": 15, "autocontinue": true},
+		},
+	}), nil)
+	gobottest.Assert(t, v.Command("DownloadMission")(map[string]interface{}{}), []MissionItem{
+		{Command: 22, Frame: 3, Z: 15, Autocontinue: true},
+	})
+	gobottest.Assert(t, v.Command("UploadMission")(map[string]interface{}{"items": "takeoff"}),
+		&gobot.CommandError{Command: "UploadMission", Param: "items", Message: "must be of type array, got takeoff"})
+	gobottest.Assert(t, v.Command("UploadMission")(map[string]interface{}{"items": []interface{}{"takeoff"}}),
+		&gobot.CommandError{Command: "UploadMission", Param: "items", Message: "must be an array of mission items"})
+
+	// a missing mission does not clear the mission of the vehicle
+	gobottest.Assert(t, v.Command("UploadMission")(map[string]interface{}{}),
+		&gobot.CommandError{Command: "UploadMission", Param: "items", Message: "is required"})
+	gobottest.Assert(t, v.Command("UploadMission")(map[string]interface{}{"items": nil}),
+		&gobot.CommandError{Command: "UploadMission", Param: "items", Message: "is required"})
+	gobottest.Assert(t, v.Command("DownloadMission")(map[string]interface{}{}), []MissionItem{
+		{Command: 22, Frame: 3, Z: 15, Autocontinue: true},
+	})
+}
+
+func TestMavlinkVehicleParams(t *testing.T) {
+	v, autopilot, stop := initTestMavlinkVehicle()
+	defer stop()
+
+	autopilot.mutex.Lock()
+	autopilot.dropParam = "SYSID_THISMAV"
+	autopilot.mutex.Unlock()
+	params, err := v.LoadParams()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, params, map[string]Param{
+		"RTL_ALT":       {Value: 1500, Type: common.MAV_PARAM_TYPE_REAL32, Index: 0},
+		"SYSID_THISMAV": {Value: 1, Type: common.MAV_PARAM_TYPE_REAL32, Index: 1},
+		"WPNAV_SPEED":   {Value: 500, Type: common.MAV_PARAM_TYPE_REAL32, Index: 2},
+	})
+
+	// cached parameters are not read again
+	autopilot.mutex.Lock()
+	autopilot.params["RTL_ALT"] = 2000
+	autopilot.mutex.Unlock()
+	value, err := v.GetParam("RTL_ALT")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, value, float32(1500))
+
+	gobottest.Assert(t, v.SetParam("WPNAV_SPEED", 750), nil)
+	gobottest.Assert(t, v.Params()["WPNAV_SPEED"].Value, float32(750))
+	gobottest.Assert(t, v.SetParam("SYSID_THISMAV", 2),
+		errors.New("MAVLink param SYSID_THISMAV was not set to 2, it is 1"))
+	gobottest.Assert(t, v.Command("SetParam")(map[string]interface{}{"name": "WPNAV_SPEED", "value": 1000}), nil)
+	gobottest.Assert(t, v.Command("GetParam")(map[string]interface{}{"name": "WPNAV_SPEED"}), float32(1000))
+
+	v.Retries = 0
+	_, err = v.GetParam("UNKNOWN")
+	gobottest.Assert(t, err, ErrVehicleTimeout)
+}
+
+func TestMavlinkVehicleHeartbeat(t *testing.T) {
+	v, autopilot, stop := initTestMavlinkVehicle()
+	defer stop()
+
+	v.Halt()
+	v.HeartbeatInterval = 5 * time.Millisecond
+	gobottest.Assert(t, len(v.Start()), 0)
+
+	gobottest.Assert(t, eventually(func() bool {
+		autopilot.mutex.Lock()
+		defer autopilot.mutex.Unlock()
+		return len(autopilot.heartbeats) > 0
+	}), true)
+	autopilot.mutex.Lock()
+	packet := autopilot.heartbeats[0]
+	autopilot.mutex.Unlock()
+	gobottest.Assert(t, packet.SystemID, uint8(255))
+	gobottest.Assert(t, packet.ComponentID, uint8(common.MAV_COMP_ID_MISSIONPLANNER))
+	message, _ := packet.MAVLinkMessage()
+	gobottest.Assert(t, message.(*common.Heartbeat).TYPE, uint8(common.MAV_TYPE_GCS))
+}
@@ -22,7 +22,11 @@ func NewNeuroskyAdaptor(name string, port string) *NeuroskyAdaptor {
 		name: name,
 		port: port,
 		connect: func(n *NeuroskyAdaptor) (io.ReadWriteCloser, error) {
-			return serial.OpenPort(&serial.Config{Name: n.Port(), Baud: 57600})
+			sp, err := serial.OpenPort(&serial.Config{Name: n.Port(), Baud: 57600})
+			if err != nil {
+				return nil, err
+			}
+			return gobot.NewMeteredReadWriteCloser(sp, n.Port()), nil
 		},
 	}
 }
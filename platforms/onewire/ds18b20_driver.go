@@ -0,0 +1,267 @@
+package onewire
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var _ gobot.Driver = (*DS18B20Driver)(nil)
+
+const (
+	// DS18B20Family is the 1-Wire family code of the DS18B20
+	DS18B20Family = 0x28
+
+	ds18b20ConvertT         = 0x44
+	ds18b20ReadScratchpad   = 0xBE
+	ds18b20WriteScratchpad  = 0x4E
+	ds18b20ScratchpadLength = 9
+)
+
+// ErrInvalidResolution is the error resulting when a DS18B20 resolution is not
+// between 9 and 12 bits
+var ErrInvalidResolution = errors.New("DS18B20 resolution must be between 9 and 12 bits")
+
+// DS18B20Temperature is the value published on the Data event
+type DS18B20Temperature struct {
+	ID      string
+	Celsius float64
+}
+
+// DS18B20Driver represents all of the DS18B20 temperature sensors on a 1-Wire bus
+type DS18B20Driver struct {
+	name         string
+	pin          string
+	connection   OneWire
+	interval     time.Duration
+	resolution   int
+	halt         chan bool
+	devices      []string
+	temperatures map[string]float64
+	mutex        sync.Mutex
+	bus          sync.Mutex
+	gobot.Eventer
+	gobot.Commander
+}
+
+// NewDS18B20Driver returns a new DS18B20Driver with a polling interval of
+// 1 second and a 12 bit resolution given a OneWire adaptor, name and bus.
+// The bus is the firmata pin number the sensors are attached to, or the linux
+// w1 bus master such as "w1_bus_master1".
+//
+// Optionally accepts:
+// 	time.Duration: Interval at which the sensors are polled for new information
+//
+// Adds the following API Commands:
+// 	"Devices" - See DS18B20Driver.Devices
+// 	"Temperature" - See DS18B20Driver.ReadTemperature
+// 	"SetResolution" - See DS18B20Driver.SetResolution
+func NewDS18B20Driver(a OneWire, name string, bus string, v ...time.Duration) *DS18B20Driver {
+	d := &DS18B20Driver{
+		name:         name,
+		pin:          bus,
+		connection:   a,
+		interval:     1 * time.Second,
+		resolution:   12,
+		temperatures: make(map[string]float64),
+		Eventer:      gobot.NewEventer(),
+		Commander:    gobot.NewCommander(),
+	}
+
+	if len(v) > 0 {
+		d.interval = v[0]
+	}
+
+	d.AddEvent(Data)
+	d.AddEvent(Error)
+
+	d.AddCommand("Devices", func(params map[string]interface{}) interface{} {
+		return d.Devices()
+	})
+	d.AddCommand("Temperature", func(params map[string]interface{}) interface{} {
+		id, _ := params["id"].(string)
+		val, err := d.ReadTemperature(id)
+		return map[string]interface{}{"val": val, "err": err}
+	})
+	d.AddCommand("SetResolution", func(params map[string]interface{}) interface{} {
+		bits, _ := params["bits"].(float64)
+		return d.SetResolution(int(bits))
+	})
+
+	return d
+}
+
+// Name returns the DS18B20Drivers name
+func (d *DS18B20Driver) Name() string { return d.name }
+
+// Pin returns the DS18B20Drivers 1-Wire bus
+func (d *DS18B20Driver) Pin() string { return d.pin }
+
+// Connection returns the DS18B20Drivers Connection
+func (d *DS18B20Driver) Connection() gobot.Connection {
+	return d.connection.(gobot.Connection)
+}
+
+// Start initializes the 1-Wire bus, enumerates the DS18B20 sensors on it,
+// configures their resolution and reads them at the given interval.
+// Emits the Events:
+//	Data DS18B20Temperature - Event is emitted on change of a sensor's temperature in celsius.
+//	Error error - Event is emitted on error reading from a sensor.
+func (d *DS18B20Driver) Start() (errs []error) {
+	if err := d.connection.OneWireStart(d.pin); err != nil {
+		return []error{err}
+	}
+	if err := d.Search(); err != nil {
+		return []error{err}
+	}
+	if err := d.SetResolution(d.resolution); err != nil {
+		return []error{err}
+	}
+
+	d.mutex.Lock()
+	halt := make(chan bool)
+	d.halt = halt
+	d.mutex.Unlock()
+
+	go func() {
+		for {
+			for _, id := range d.Devices() {
+				val, err := d.ReadTemperature(id)
+				if err != nil {
+					gobot.Publish(d.Event(Error), err)
+					continue
+				}
+				d.mutex.Lock()
+				old, ok := d.temperatures[id]
+				d.temperatures[id] = val
+				d.mutex.Unlock()
+				if !ok || old != val {
+					gobot.Publish(d.Event(Data), DS18B20Temperature{ID: id, Celsius: val})
+				}
+			}
+			select {
+			case <-time.After(d.interval):
+			case <-halt:
+				return
+			}
+		}
+	}()
+	return
+}
+
+// Halt stops polling the sensors for new information
+func (d *DS18B20Driver) Halt() (errs []error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.halt != nil {
+		close(d.halt)
+		d.halt = nil
+	}
+	return
+}
+
+// Search enumerates the DS18B20 sensors on the bus by ROM ID
+func (d *DS18B20Driver) Search() (err error) {
+	ids, err := d.connection.OneWireSearch(d.pin)
+	if err != nil {
+		return
+	}
+	devices := []string{}
+	for _, id := range ids {
+		rom, err := ParseROM(id)
+		if err == nil && rom.Family() == DS18B20Family {
+			devices = append(devices, id)
+		}
+	}
+	d.mutex.Lock()
+	d.devices = devices
+	d.mutex.Unlock()
+	return
+}
+
+// Devices returns the ROM IDs of the DS18B20 sensors found by Search
+func (d *DS18B20Driver) Devices() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]string{}, d.devices...)
+}
+
+// Temperature returns the last temperature in celsius polled from sensor id
+func (d *DS18B20Driver) Temperature(id string) (val float64) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.temperatures[id]
+}
+
+// Temperatures returns the last temperatures in celsius polled from every
+// sensor, keyed by ROM ID
+func (d *DS18B20Driver) Temperatures() map[string]float64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	t := make(map[string]float64)
+	for id, val := range d.temperatures {
+		t[id] = val
+	}
+	return t
+}
+
+// Resolution returns the configured resolution in bits
+func (d *DS18B20Driver) Resolution() int { return d.resolution }
+
+// SetResolution sets the resolution of every sensor on the bus to bits, which
+// must be between 9 (0.5C, 94ms conversion) and 12 (0.0625C, 750ms conversion).
+// The setting is not copied to the sensor's EEPROM.
+func (d *DS18B20Driver) SetResolution(bits int) (err error) {
+	if bits < 9 || bits > 12 {
+		return ErrInvalidResolution
+	}
+	d.bus.Lock()
+	defer d.bus.Unlock()
+	d.resolution = bits
+	config := byte((bits-9)<<5) | 0x1F
+	for _, id := range d.Devices() {
+		// TH and TL alarm registers are left at their power-on defaults
+		if err = d.connection.OneWireWrite(d.pin, id, []byte{ds18b20WriteScratchpad, 0x4B, 0x46, config}); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// ReadTemperature starts a conversion on sensor id, waits for it to complete
+// and returns the temperature in celsius. Other transactions on the bus wait
+// for the conversion and the read to complete.
+func (d *DS18B20Driver) ReadTemperature(id string) (val float64, err error) {
+	d.bus.Lock()
+	defer d.bus.Unlock()
+	if err = d.connection.OneWireWrite(d.pin, id, []byte{ds18b20ConvertT}); err != nil {
+		return
+	}
+	<-time.After(d.conversionTime())
+
+	if err = d.connection.OneWireWrite(d.pin, id, []byte{ds18b20ReadScratchpad}); err != nil {
+		return
+	}
+	data, err := d.connection.OneWireRead(d.pin, id, ds18b20ScratchpadLength)
+	if err != nil {
+		return
+	}
+	if len(data) < ds18b20ScratchpadLength {
+		return 0, ErrNotEnoughBytes
+	}
+	if CRC8(data[:8]) != data[8] {
+		return 0, ErrCRC
+	}
+
+	raw := int16(data[1])<<8 | int16(data[0])
+	// undefined low bits depend on the resolution the sensor reports
+	bits := 9 + uint((data[4]>>5)&0x03)
+	raw &^= (1 << (12 - bits)) - 1
+	return float64(raw) / 16.0, nil
+}
+
+func (d *DS18B20Driver) conversionTime() time.Duration {
+	return (750 * time.Millisecond) >> uint(12-d.resolution)
+}
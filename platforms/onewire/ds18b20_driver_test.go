@@ -0,0 +1,185 @@
+package onewire
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// scratchpad of a 12 bit sensor reading +25.0625C
+var ds18b20Scratchpad = []byte{0x91, 0x01, 0x4B, 0x46, 0x7F, 0xFF, 0x0F, 0x10, 0x25}
+
+// --------- HELPERS
+func initTestDS18B20Driver() (driver *DS18B20Driver) {
+	driver, _ = initTestDS18B20DriverWithStubbedAdaptor()
+	return
+}
+
+func initTestDS18B20DriverWithStubbedAdaptor() (*DS18B20Driver, *oneWireTestAdaptor) {
+	adaptor := newOneWireTestAdaptor("adaptor")
+	adaptor.oneWireSearchImpl = func() ([]string, error) {
+		return []string{"28-0000054c2ec2", "10-000802d1c5e5", "28-00000574c8e1"}, nil
+	}
+	adaptor.oneWireReadImpl = func() ([]byte, error) {
+		return ds18b20Scratchpad, nil
+	}
+	return NewDS18B20Driver(adaptor, "bot", "w1_bus_master1"), adaptor
+}
+
+// --------- TESTS
+
+func TestDS18B20Driver(t *testing.T) {
+	d := initTestDS18B20Driver()
+
+	gobottest.Assert(t, d.Name(), "bot")
+	gobottest.Assert(t, d.Pin(), "w1_bus_master1")
+	gobottest.Assert(t, d.Connection().Name(), "adaptor")
+	gobottest.Assert(t, d.interval, 1*time.Second)
+	gobottest.Assert(t, d.Resolution(), 12)
+	gobottest.Refute(t, d.Command("Devices"), nil)
+	gobottest.Refute(t, d.Command("Temperature"), nil)
+	gobottest.Refute(t, d.Command("SetResolution"), nil)
+
+	d = NewDS18B20Driver(newOneWireTestAdaptor("adaptor"), "bot", "2", 100*time.Millisecond)
+	gobottest.Assert(t, d.interval, 100*time.Millisecond)
+}
+
+func TestDS18B20DriverSearch(t *testing.T) {
+	d := initTestDS18B20Driver()
+
+	gobottest.Assert(t, d.Search(), nil)
+	gobottest.Assert(t, d.Devices(), []string{"28-0000054c2ec2", "28-00000574c8e1"})
+	gobottest.Assert(t, d.Command("Devices")(map[string]interface{}{}), []string{"28-0000054c2ec2", "28-00000574c8e1"})
+}
+
+func TestDS18B20DriverSetResolution(t *testing.T) {
+	d, adaptor := initTestDS18B20DriverWithStubbedAdaptor()
+	d.Search()
+
+	gobottest.Assert(t, d.SetResolution(8), ErrInvalidResolution)
+	gobottest.Assert(t, d.SetResolution(13), ErrInvalidResolution)
+	gobottest.Assert(t, d.SetResolution(9), nil)
+	gobottest.Assert(t, d.Resolution(), 9)
+	gobottest.Assert(t, d.conversionTime(), 93750*time.Microsecond)
+	gobottest.Assert(t, adaptor.written, [][]byte{
+		{0x4E, 0x4B, 0x46, 0x1F},
+		{0x4E, 0x4B, 0x46, 0x1F},
+	})
+
+	gobottest.Assert(t, d.Command("SetResolution")(map[string]interface{}{"bits": 11.0}), nil)
+	gobottest.Assert(t, d.Resolution(), 11)
+}
+
+func TestDS18B20DriverReadTemperature(t *testing.T) {
+	d, adaptor := initTestDS18B20DriverWithStubbedAdaptor()
+	d.SetResolution(9)
+
+	val, err := d.ReadTemperature("28-0000054c2ec2")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, val, 25.0625)
+	gobottest.Assert(t, adaptor.written, [][]byte{{0x44}, {0xBE}})
+
+	// a 9 bit sensor leaves the low bits undefined
+	adaptor.oneWireReadImpl = func() ([]byte, error) {
+		return []byte{0x91, 0x01, 0x4B, 0x46, 0x1F, 0xFF, 0x0F, 0x10, 0xb5}, nil
+	}
+	val, _ = d.ReadTemperature("28-0000054c2ec2")
+	gobottest.Assert(t, val, 25.0)
+
+	// power-on value, negative temperatures
+	adaptor.oneWireReadImpl = func() ([]byte, error) {
+		data := []byte{0x5E, 0xFF, 0x4B, 0x46, 0x7F, 0xFF, 0x02, 0x10, 0x00}
+		data[8] = CRC8(data[:8])
+		return data, nil
+	}
+	val, _ = d.ReadTemperature("28-0000054c2ec2")
+	gobottest.Assert(t, val, -10.125)
+
+	adaptor.oneWireReadImpl = func() ([]byte, error) {
+		return []byte{0x91, 0x01, 0x4B, 0x46, 0x7F, 0xFF, 0x0F, 0x10, 0x00}, nil
+	}
+	_, err = d.ReadTemperature("28-0000054c2ec2")
+	gobottest.Assert(t, err, ErrCRC)
+
+	adaptor.oneWireReadImpl = func() ([]byte, error) {
+		return []byte{0x91, 0x01}, nil
+	}
+	_, err = d.ReadTemperature("28-0000054c2ec2")
+	gobottest.Assert(t, err, ErrNotEnoughBytes)
+
+	adaptor.oneWireWriteImpl = func() error {
+		return errors.New("write error")
+	}
+	_, err = d.ReadTemperature("28-0000054c2ec2")
+	gobottest.Assert(t, err, errors.New("write error"))
+
+	ret := d.Command("Temperature")(map[string]interface{}{"id": "28-0000054c2ec2"})
+	gobottest.Assert(t, ret.(map[string]interface{})["err"], errors.New("write error"))
+}
+
+func TestDS18B20DriverReadTemperatureTransaction(t *testing.T) {
+	d, adaptor := initTestDS18B20DriverWithStubbedAdaptor()
+	d.SetResolution(9)
+	adaptor.written = nil
+
+	done := make(chan bool)
+	for i := 0; i < 2; i++ {
+		go func() {
+			d.ReadTemperature("28-0000054c2ec2")
+			done <- true
+		}()
+	}
+	<-done
+	<-done
+
+	// the conversions do not interleave on the bus
+	gobottest.Assert(t, adaptor.written, [][]byte{{0x44}, {0xBE}, {0x44}, {0xBE}})
+}
+
+func TestDS18B20DriverStart(t *testing.T) {
+	sem := make(chan bool)
+	d, _ := initTestDS18B20DriverWithStubbedAdaptor()
+	d.resolution = 9
+
+	gobot.On(d.Event(Data), func(data interface{}) {
+		gobottest.Assert(t, data.(DS18B20Temperature).Celsius, 25.0625)
+		sem <- true
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-sem:
+		case <-time.After(1 * time.Second):
+			t.Errorf("DS18B20 Event \"Data\" was not published")
+		}
+	}
+
+	gobottest.Assert(t, d.Temperature("28-0000054c2ec2"), 25.0625)
+	gobottest.Assert(t, len(d.Halt()), 0)
+	gobottest.Assert(t, d.Temperatures(), map[string]float64{
+		"28-0000054c2ec2": 25.0625,
+		"28-00000574c8e1": 25.0625,
+	})
+}
+
+func TestDS18B20DriverStartError(t *testing.T) {
+	d, adaptor := initTestDS18B20DriverWithStubbedAdaptor()
+	adaptor.oneWireStartImpl = func() error {
+		return errors.New("start error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("start error"))
+
+	d, adaptor = initTestDS18B20DriverWithStubbedAdaptor()
+	adaptor.oneWireSearchImpl = func() ([]string, error) {
+		return nil, errors.New("search error")
+	}
+	gobottest.Assert(t, d.Start()[0], errors.New("search error"))
+	// Halt does not block when Start failed
+	gobottest.Assert(t, len(d.Halt()), 0)
+	gobottest.Assert(t, len(d.Halt()), 0)
+}
@@ -0,0 +1,45 @@
+package onewire
+
+type oneWireTestAdaptor struct {
+	name              string
+	written           [][]byte
+	oneWireStartImpl  func() error
+	oneWireSearchImpl func() ([]string, error)
+	oneWireWriteImpl  func() error
+	oneWireReadImpl   func() ([]byte, error)
+}
+
+func (t *oneWireTestAdaptor) OneWireStart(string) (err error) {
+	return t.oneWireStartImpl()
+}
+func (t *oneWireTestAdaptor) OneWireSearch(string) (ids []string, err error) {
+	return t.oneWireSearchImpl()
+}
+func (t *oneWireTestAdaptor) OneWireWrite(bus string, id string, data []byte) (err error) {
+	t.written = append(t.written, data)
+	return t.oneWireWriteImpl()
+}
+func (t *oneWireTestAdaptor) OneWireRead(string, string, int) (data []byte, err error) {
+	return t.oneWireReadImpl()
+}
+func (t *oneWireTestAdaptor) Name() string             { return t.name }
+func (t *oneWireTestAdaptor) Connect() (errs []error)  { return }
+func (t *oneWireTestAdaptor) Finalize() (errs []error) { return }
+
+func newOneWireTestAdaptor(name string) *oneWireTestAdaptor {
+	return &oneWireTestAdaptor{
+		name: name,
+		oneWireStartImpl: func() error {
+			return nil
+		},
+		oneWireSearchImpl: func() ([]string, error) {
+			return []string{}, nil
+		},
+		oneWireWriteImpl: func() error {
+			return nil
+		},
+		oneWireReadImpl: func() ([]byte, error) {
+			return []byte{}, nil
+		},
+	}
+}
@@ -0,0 +1,115 @@
+package onewire
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hybridgroup/gobot"
+)
+
+var (
+	// ErrInvalidROM is the error resulting when a ROM ID can not be parsed
+	ErrInvalidROM = errors.New("Invalid 1-Wire ROM ID")
+	// ErrCRC is the error resulting when data read from a device fails its CRC check
+	ErrCRC = errors.New("1-Wire CRC mismatch")
+	// ErrNotEnoughBytes is the error resulting when a device returns less data than requested
+	ErrNotEnoughBytes = errors.New("Not enough bytes read")
+)
+
+const (
+	// Data event
+	Data = "data"
+	// Error event
+	Error = "error"
+)
+
+// OneWireStarter interface represents an Adaptor which can initialize a 1-Wire bus
+type OneWireStarter interface {
+	OneWireStart(bus string) (err error)
+}
+
+// OneWireSearcher interface represents an Adaptor which can enumerate the
+// ROM IDs of the devices on a 1-Wire bus
+type OneWireSearcher interface {
+	OneWireSearch(bus string) (ids []string, err error)
+}
+
+// OneWireWriter interface represents an Adaptor which can reset the bus,
+// select the device id and write data to it
+type OneWireWriter interface {
+	OneWireWrite(bus string, id string, data []byte) (err error)
+}
+
+// OneWireReader interface represents an Adaptor which can read size bytes
+// from the device selected by the last OneWireWrite
+type OneWireReader interface {
+	OneWireRead(bus string, id string, size int) (data []byte, err error)
+}
+
+// OneWire interface represents an Adaptor which has 1-Wire capabilities
+type OneWire interface {
+	gobot.Adaptor
+	OneWireStarter
+	OneWireSearcher
+	OneWireWriter
+	OneWireReader
+}
+
+// ROM is the 64-bit registration number of a 1-Wire device, in the order it
+// is sent on the wire: family code, 48-bit serial number (LSB first) and CRC.
+type ROM [8]byte
+
+// ParseROM parses a ROM ID in the format used by the linux w1 subsystem,
+// eg. "28-0000054c2ec2", and computes its CRC.
+func ParseROM(id string) (rom ROM, err error) {
+	parts := strings.Split(id, "-")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 12 {
+		return rom, ErrInvalidROM
+	}
+	family, err := strconv.ParseUint(parts[0], 16, 8)
+	if err != nil {
+		return rom, ErrInvalidROM
+	}
+	serial, err := strconv.ParseUint(parts[1], 16, 48)
+	if err != nil {
+		return rom, ErrInvalidROM
+	}
+	rom[0] = byte(family)
+	for i := 1; i < 7; i++ {
+		rom[i] = byte(serial >> (8 * uint(i-1)))
+	}
+	rom[7] = CRC8(rom[:7])
+	return
+}
+
+// Family returns the family code of the ROM
+func (r ROM) Family() byte { return r[0] }
+
+// Valid returns true if the ROM CRC matches its contents
+func (r ROM) Valid() bool { return CRC8(r[:7]) == r[7] }
+
+// String returns the ROM ID in the format used by the linux w1 subsystem
+func (r ROM) String() string {
+	var serial uint64
+	for i := 6; i > 0; i-- {
+		serial = serial<<8 | uint64(r[i])
+	}
+	return fmt.Sprintf("%02x-%012x", r[0], serial)
+}
+
+// CRC8 computes the Dallas/Maxim 1-Wire CRC (polynomial x^8 + x^5 + x^4 + 1) of data
+func CRC8(data []byte) (crc byte) {
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			mix := (crc ^ b) & 0x01
+			crc >>= 1
+			if mix != 0 {
+				crc ^= 0x8C
+			}
+			b >>= 1
+		}
+	}
+	return
+}
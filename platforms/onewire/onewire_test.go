@@ -0,0 +1,30 @@
+package onewire
+
+import (
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func TestCRC8(t *testing.T) {
+	// scratchpad from the DS18B20 datasheet, +25.0625C
+	gobottest.Assert(t, CRC8([]byte{0x91, 0x01, 0x4B, 0x46, 0x7F, 0xFF, 0x0F, 0x10}), byte(0x25))
+	gobottest.Assert(t, CRC8([]byte{}), byte(0x00))
+}
+
+func TestParseROM(t *testing.T) {
+	rom, err := ParseROM("28-0000054c2ec2")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, rom.Family(), byte(0x28))
+	gobottest.Assert(t, rom[1:7], []byte{0xc2, 0x2e, 0x4c, 0x05, 0x00, 0x00})
+	gobottest.Assert(t, rom.Valid(), true)
+	gobottest.Assert(t, rom.String(), "28-0000054c2ec2")
+
+	rom[7]++
+	gobottest.Assert(t, rom.Valid(), false)
+
+	for _, id := range []string{"", "28", "28-0000054c2ec", "zz-0000054c2ec2", "28-00000zzzzzzz"} {
+		_, err = ParseROM(id)
+		gobottest.Assert(t, err, ErrInvalidROM)
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hybridgroup/gobot"
 	"github.com/hybridgroup/gobot/platforms/gpio"
 	"github.com/hybridgroup/gobot/platforms/i2c"
+	"github.com/hybridgroup/gobot/platforms/ledstrip"
+	"github.com/hybridgroup/gobot/platforms/onewire"
 	"github.com/hybridgroup/gobot/sysfs"
 )
 
@@ -21,17 +24,23 @@ var _ gpio.DigitalWriter = (*RaspiAdaptor)(nil)
 
 var _ i2c.I2c = (*RaspiAdaptor)(nil)
 
+var _ onewire.OneWire = (*RaspiAdaptor)(nil)
+
+var _ ledstrip.SpiWriter = (*RaspiAdaptor)(nil)
+
 var readFile = func() ([]byte, error) {
 	return ioutil.ReadFile("/proc/cpuinfo")
 }
 
 type RaspiAdaptor struct {
-	name        string
-	revision    string
-	i2cLocation string
-	digitalPins map[int]sysfs.DigitalPin
-	pwmPins     []int
-	i2cDevice   sysfs.I2cDevice
+	name           string
+	revision       string
+	i2cLocation    string
+	digitalPins    map[int]sysfs.DigitalPin
+	pwmPins        []int
+	i2cDevice      sysfs.I2cDevice
+	oneWireDevices map[string]sysfs.OneWireDevice
+	spiDevices     map[string]sysfs.SpiDevice
 }
 
 var pins = map[string]map[string]int{
@@ -124,9 +133,11 @@ var pins = map[string]map[string]int{
 // NewRaspiAdaptor creates a RaspiAdaptor with specified name and
 func NewRaspiAdaptor(name string) *RaspiAdaptor {
 	r := &RaspiAdaptor{
-		name:        name,
-		digitalPins: make(map[int]sysfs.DigitalPin),
-		pwmPins:     []int{},
+		name:           name,
+		digitalPins:    make(map[int]sysfs.DigitalPin),
+		oneWireDevices: make(map[string]sysfs.OneWireDevice),
+		spiDevices:     make(map[string]sysfs.SpiDevice),
+		pwmPins:        []int{},
 	}
 	content, _ := readFile()
 	for _, v := range strings.Split(string(content), "\n") {
@@ -169,6 +180,16 @@ func (r *RaspiAdaptor) Finalize() (errs []error) {
 			errs = append(errs, err)
 		}
 	}
+	for _, device := range r.oneWireDevices {
+		if err := device.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, device := range r.spiDevices {
+		if err := device.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if r.i2cDevice != nil {
 		if err := r.i2cDevice.Close(); err != nil {
 			errs = append(errs, err)
@@ -260,6 +281,8 @@ func (r *RaspiAdaptor) I2cStart(address int) (err error) {
 
 // I2CWrite writes data to i2c device
 func (r *RaspiAdaptor) I2cWrite(address int, data []byte) (err error) {
+	defer gobot.MeterI2c(r.Name(), address, "write", time.Now(), &err)
+
 	if err = r.i2cDevice.SetAddress(address); err != nil {
 		return
 	}
@@ -269,6 +292,8 @@ func (r *RaspiAdaptor) I2cWrite(address int, data []byte) (err error) {
 
 // I2cRead returns value from i2c device using specified size
 func (r *RaspiAdaptor) I2cRead(address int, size int) (data []byte, err error) {
+	defer gobot.MeterI2c(r.Name(), address, "read", time.Now(), &err)
+
 	if err = r.i2cDevice.SetAddress(address); err != nil {
 		return
 	}
@@ -307,3 +332,73 @@ func (r *RaspiAdaptor) piBlaster(data string) (err error) {
 	_, err = fi.WriteString(data)
 	return
 }
+
+// OneWireStart checks that the linux 1-wire bus master bus, eg.
+// "w1_bus_master1", is available. An empty bus defaults to w1_bus_master1.
+// The Raspberry Pi requires the w1-gpio overlay (dtoverlay=w1-gpio) which uses pin 7.
+func (r *RaspiAdaptor) OneWireStart(bus string) (err error) {
+	_, err = sysfs.OneWireSlaves(bus)
+	return
+}
+
+// OneWireSearch returns the ROM IDs of the devices found by the 1-wire bus master
+func (r *RaspiAdaptor) OneWireSearch(bus string) (ids []string, err error) {
+	return sysfs.OneWireSlaves(bus)
+}
+
+// OneWireWrite resets the 1-wire bus, selects device id and writes data to it
+func (r *RaspiAdaptor) OneWireWrite(bus string, id string, data []byte) (err error) {
+	device, err := r.oneWireDevice(id)
+	if err != nil {
+		return
+	}
+	_, err = device.Write(data)
+	return
+}
+
+// OneWireRead returns size bytes from 1-wire device id
+func (r *RaspiAdaptor) OneWireRead(bus string, id string, size int) (data []byte, err error) {
+	device, err := r.oneWireDevice(id)
+	if err != nil {
+		return
+	}
+	data = make([]byte, size)
+	_, err = device.Read(data)
+	return
+}
+
+// oneWireDevice returns the sysfs 1-wire device for id, opening it on first use
+func (r *RaspiAdaptor) oneWireDevice(id string) (device sysfs.OneWireDevice, err error) {
+	if device, ok := r.oneWireDevices[id]; ok {
+		return device, nil
+	}
+	if device, err = sysfs.NewOneWireDevice(id); err != nil {
+		return nil, err
+	}
+	r.oneWireDevices[id] = device
+	return
+}
+
+// SpiStart opens the spidev device for bus, such as "0.0" for
+// /dev/spidev0.0, at speed hertz
+func (r *RaspiAdaptor) SpiStart(bus string, speed int) (err error) {
+	if device, ok := r.spiDevices[bus]; ok {
+		return device.SetSpeed(speed)
+	}
+	device, err := sysfs.NewSpiDevice("/dev/spidev"+bus, speed)
+	if err != nil {
+		return
+	}
+	r.spiDevices[bus] = device
+	return
+}
+
+// SpiWrite writes data to the spidev device for bus
+func (r *RaspiAdaptor) SpiWrite(bus string, data []byte) (err error) {
+	device, ok := r.spiDevices[bus]
+	if !ok {
+		return fmt.Errorf("SPI bus %v has not been started", bus)
+	}
+	_, err = device.Write(data)
+	return
+}
@@ -158,3 +158,50 @@ func TestRaspiAdaptorI2c(t *testing.T) {
 	data, _ := a.I2cRead(0xff, 2)
 	gobottest.Assert(t, data, []byte{0x00, 0x01})
 }
+
+func TestRaspiAdaptorOneWire(t *testing.T) {
+	a := initTestRaspiAdaptor()
+	fs := sysfs.NewMockFilesystem([]string{
+		"/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves",
+		"/sys/bus/w1/devices/28-0000054c2ec2/rw",
+	})
+	sysfs.SetFilesystem(fs)
+	fs.Files["/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves"].Contents = "28-0000054c2ec2\n"
+
+	gobottest.Assert(t, a.OneWireStart(""), nil)
+	gobottest.Refute(t, a.OneWireStart("w1_bus_master2"), nil)
+
+	ids, err := a.OneWireSearch("w1_bus_master1")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, ids, []string{"28-0000054c2ec2"})
+
+	gobottest.Assert(t, a.OneWireWrite("", "28-0000054c2ec2", []byte{0x44}), nil)
+	gobottest.Assert(t, fs.Files["/sys/bus/w1/devices/28-0000054c2ec2/rw"].Contents, "\x44")
+	data, _ := a.OneWireRead("", "28-0000054c2ec2", 1)
+	gobottest.Assert(t, data, []byte{0x44})
+
+	gobottest.Refute(t, a.OneWireWrite("", "28-00000574c8e1", []byte{0x44}), nil)
+	_, err = a.OneWireRead("", "28-00000574c8e1", 1)
+	gobottest.Refute(t, err, nil)
+
+	gobottest.Assert(t, len(a.Finalize()), 0)
+}
+
+func TestRaspiAdaptorSpi(t *testing.T) {
+	a := initTestRaspiAdaptor()
+	fs := sysfs.NewMockFilesystem([]string{
+		"/dev/spidev0.0",
+	})
+	sysfs.SetFilesystem(fs)
+	sysfs.SetSyscall(&sysfs.MockSyscall{})
+
+	gobottest.Refute(t, a.SpiWrite("0.0", []byte{0x01}), nil)
+	gobottest.Refute(t, a.SpiStart("0.1", 4000000), nil)
+
+	gobottest.Assert(t, a.SpiStart("0.0", 4000000), nil)
+	gobottest.Assert(t, a.SpiStart("0.0", 2400000), nil)
+	gobottest.Assert(t, a.SpiWrite("0.0", []byte{0x00, 0xFF}), nil)
+	gobottest.Assert(t, fs.Files["/dev/spidev0.0"].Contents, "\x00\xff")
+
+	gobottest.Assert(t, len(a.Finalize()), 0)
+}
@@ -8,6 +8,18 @@ import (
 )
 
 var _ gobot.Adaptor = (*SpheroAdaptor)(nil)
+var _ Transport = (*SpheroAdaptor)(nil)
+
+// Transport is a connection to a Sphero which a SpheroDriver writes packets
+// to, and reads their responses and the asynchronous packets from. The
+// SpheroAdaptor connects to a Sphero over serial Bluetooth, and the
+// ble.SpheroBLEAdaptor to a SPRK+, BB-8 or Ollie over BLE.
+type Transport interface {
+	gobot.Connection
+	io.ReadWriter
+	// Connected returns whether the Sphero is connected
+	Connected() bool
+}
 
 // Represents a Connection to a Sphero
 type SpheroAdaptor struct {
@@ -24,7 +36,11 @@ func NewSpheroAdaptor(name string, port string) *SpheroAdaptor {
 		name: name,
 		port: port,
 		connect: func(port string) (io.ReadWriteCloser, error) {
-			return serial.OpenPort(&serial.Config{Name: port, Baud: 115200})
+			sp, err := serial.OpenPort(&serial.Config{Name: port, Baud: 115200})
+			if err != nil {
+				return nil, err
+			}
+			return gobot.NewMeteredReadWriteCloser(sp, port), nil
 		},
 	}
 }
@@ -63,6 +79,15 @@ func (a *SpheroAdaptor) Disconnect() (errs []error) {
 	return
 }
 
+// Connected returns whether the Sphero is connected
+func (a *SpheroAdaptor) Connected() bool { return a.connected }
+
+// Read reads the packets sent by the Sphero
+func (a *SpheroAdaptor) Read(b []byte) (int, error) { return a.sp.Read(b) }
+
+// Write writes packets to the Sphero
+func (a *SpheroAdaptor) Write(b []byte) (int, error) { return a.sp.Write(b) }
+
 // Finalize finalizes the SpheroAdaptor
 func (a *SpheroAdaptor) Finalize() (errs []error) {
 	return a.Disconnect()
@@ -0,0 +1,388 @@
+package sphero
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+// Point is a position in centimeters on the ground plane of the locators of
+// the Spheros, whose Y axis is the heading 0
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Keyframe is what a Sphero does from a time of a Timeline until its next
+// keyframe
+type Keyframe struct {
+	// At is the time of the keyframe from the start of the timeline
+	At time.Duration `json:"at"`
+	// Heading in degrees and Speed the Sphero rolls at, a Speed of 0 stops it
+	Heading uint16 `json:"heading"`
+	Speed   uint8  `json:"speed"`
+	// Color of the RGB LED, nil to leave it unchanged
+	Color []uint8 `json:"color,omitempty"`
+}
+
+// Track is the start position and the keyframes of a Sphero of a Timeline
+type Track struct {
+	Start     Point      `json:"start"`
+	Keyframes []Keyframe `json:"keyframes"`
+}
+
+// Timeline is the tracks of the Spheros of a choreography by name
+type Timeline map[string]Track
+
+// Choreography executes a Timeline, synchronized across the SpheroDrivers of
+// its tracks. While a Sphero rolls, its locator is read every
+// CorrectionInterval to steer it back to its planned path when it drifts
+// away by more than Tolerance.
+//
+// The keyframes and corrections are scheduled by gobot.DefaultClock. Planned
+// paths are rendered by WriteSVG for review, without driving the Spheros.
+type Choreography struct {
+	// Timeline is the tracks executed by the choreography
+	Timeline Timeline
+	// MaxSpeed is the speed in centimeters per second of a Sphero rolling at
+	// speed 255, used to plan the paths
+	MaxSpeed float64
+	// CorrectionInterval is the interval between locator readings, 0 to
+	// disable corrections
+	CorrectionInterval time.Duration
+	// Tolerance is the distance in centimeters a Sphero may drift away from
+	// its planned path without being corrected
+	Tolerance float64
+
+	mutex   sync.Mutex
+	drivers map[string]*SpheroDriver
+	start   time.Time
+	done    chan bool
+	stopped bool
+}
+
+// NewChoreography returns a new Choreography of timeline, with a MaxSpeed of
+// 200 cm/s, a CorrectionInterval of 100ms and a Tolerance of 5cm
+func NewChoreography(timeline Timeline) *Choreography {
+	return &Choreography{
+		Timeline:           timeline,
+		MaxSpeed:           200,
+		CorrectionInterval: 100 * time.Millisecond,
+		Tolerance:          5,
+		drivers:            make(map[string]*SpheroDriver),
+	}
+}
+
+// AddSphero sets the SpheroDriver executing a track of the timeline
+func (c *Choreography) AddSphero(track string, driver *SpheroDriver) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.drivers[track] = driver
+}
+
+// Duration returns the time of the last keyframe of the timeline
+func (c *Choreography) Duration() (d time.Duration) {
+	for _, track := range c.Timeline {
+		for _, keyframe := range track.Keyframes {
+			if keyframe.At > d {
+				d = keyframe.At
+			}
+		}
+	}
+	return
+}
+
+// Start sets the locator of every Sphero to the start position of its track
+// and schedules the keyframes. It returns an error when a track has no
+// SpheroDriver. The Spheros are stopped at the end of the timeline.
+func (c *Choreography) Start() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.done != nil {
+		return errors.New("Choreography already started")
+	}
+	for _, name := range c.tracks() {
+		if c.drivers[name] == nil {
+			return fmt.Errorf("Choreography: no Sphero for track %q", name)
+		}
+	}
+
+	c.done = make(chan bool)
+	c.start = gobot.DefaultClock.Now()
+	for _, name := range c.tracks() {
+		track, driver := c.keyframes(name), c.drivers[name]
+		driver.ConfigureLocator(LocatorConfig{
+			Flags: 0x01,
+			X:     int16(track.Start.X),
+			Y:     int16(track.Start.Y),
+		})
+		for _, keyframe := range track.Keyframes {
+			keyframe := keyframe
+			c.afterFunc(keyframe.At, func() {
+				if c.running() {
+					if len(keyframe.Color) == 3 {
+						driver.SetRGB(keyframe.Color[0], keyframe.Color[1], keyframe.Color[2])
+					}
+					driver.Roll(keyframe.Speed, keyframe.Heading%360)
+				}
+			})
+		}
+		if c.CorrectionInterval > 0 {
+			c.scheduleCorrection(track, driver, c.CorrectionInterval, false)
+		}
+	}
+	c.afterFunc(c.Duration(), func() { c.Stop() })
+	return nil
+}
+
+// Stop stops the choreography and the Spheros
+func (c *Choreography) Stop() {
+	c.mutex.Lock()
+	if c.done == nil || c.stopped {
+		c.mutex.Unlock()
+		return
+	}
+	c.stopped = true
+	var drivers []*SpheroDriver
+	for _, name := range c.tracks() {
+		drivers = append(drivers, c.drivers[name])
+	}
+	c.mutex.Unlock()
+
+	for _, driver := range drivers {
+		driver.Stop()
+	}
+	close(c.done)
+}
+
+// Done returns a channel which is closed once the choreography is stopped
+func (c *Choreography) Done() <-chan bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.done
+}
+
+// Plan returns the planned position of a track at a time of the timeline
+func (c *Choreography) Plan(track string, at time.Duration) Point {
+	return c.plan(c.keyframes(track), at)
+}
+
+func (c *Choreography) running() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return !c.stopped
+}
+
+// afterFunc calls f at a time of the timeline
+func (c *Choreography) afterFunc(at time.Duration, f func()) {
+	gobot.DefaultClock.AfterFunc(c.start.Add(at).Sub(gobot.DefaultClock.Now()), f)
+}
+
+// scheduleCorrection corrects the drift of a Sphero at a time of the
+// timeline, and schedules the next correction. The corrections which are
+// due while the locator is read are skipped.
+func (c *Choreography) scheduleCorrection(track Track, driver *SpheroDriver, at time.Duration, corrected bool) {
+	if at >= c.Duration() {
+		return
+	}
+	c.afterFunc(at, func() {
+		if !c.running() {
+			return
+		}
+		corrected := c.correct(track, driver, at, corrected)
+		next := at + c.CorrectionInterval
+		for next <= gobot.DefaultClock.Now().Sub(c.start) {
+			next += c.CorrectionInterval
+		}
+		c.scheduleCorrection(track, driver, next, corrected)
+	})
+}
+
+// correct steers a rolling Sphero which drifted away from its planned path
+// towards its planned position at the next correction, returning whether it
+// did. A Sphero which was corrected and is back on its path resumes the roll
+// of its keyframe.
+func (c *Choreography) correct(track Track, driver *SpheroDriver, at time.Duration, corrected bool) bool {
+	keyframe := current(track, at)
+	if keyframe == nil || keyframe.Speed == 0 {
+		return false
+	}
+	locator := driver.ReadLocator()
+	if len(locator) < 2 {
+		return corrected
+	}
+	actual := Point{X: float64(locator[0]), Y: float64(locator[1])}
+	if distance(actual, c.plan(track, at)) <= c.Tolerance {
+		if corrected {
+			driver.Roll(keyframe.Speed, keyframe.Heading%360)
+		}
+		return false
+	}
+
+	target := c.plan(track, at+c.CorrectionInterval)
+	dx, dy := target.X-actual.X, target.Y-actual.Y
+	heading := math.Mod(math.Atan2(dx, dy)*180/math.Pi+360, 360)
+	speed := math.Hypot(dx, dy) / c.CorrectionInterval.Seconds() / c.MaxSpeed * 255
+	driver.Roll(uint8(math.Max(1, math.Min(255, speed))), uint16(heading+0.5)%360)
+	return true
+}
+
+// plan returns the position of a track at a time, rolling in a straight line
+// between keyframes
+func (c *Choreography) plan(track Track, at time.Duration) Point {
+	position := track.Start
+	for i, keyframe := range track.Keyframes {
+		if keyframe.At >= at {
+			break
+		}
+		end := at
+		if i+1 < len(track.Keyframes) && track.Keyframes[i+1].At < at {
+			end = track.Keyframes[i+1].At
+		}
+		position = c.roll(position, keyframe, end-keyframe.At)
+	}
+	return position
+}
+
+// roll returns the position reached from p after rolling as keyframe for d
+// duration
+func (c *Choreography) roll(p Point, keyframe Keyframe, d time.Duration) Point {
+	v := float64(keyframe.Speed) / 255 * c.MaxSpeed * d.Seconds()
+	rad := float64(keyframe.Heading%360) * math.Pi / 180
+	return Point{X: p.X + v*math.Sin(rad), Y: p.Y + v*math.Cos(rad)}
+}
+
+// keyframes returns a track of the timeline with its keyframes sorted by time
+func (c *Choreography) keyframes(name string) Track {
+	track := c.Timeline[name]
+	keyframes := append([]Keyframe{}, track.Keyframes...)
+	sort.Stable(byTime(keyframes))
+	track.Keyframes = keyframes
+	return track
+}
+
+// byTime sorts keyframes by time
+type byTime []Keyframe
+
+func (k byTime) Len() int           { return len(k) }
+func (k byTime) Swap(i, j int)      { k[i], k[j] = k[j], k[i] }
+func (k byTime) Less(i, j int) bool { return k[i].At < k[j].At }
+
+// tracks returns the names of the tracks of the timeline in order
+func (c *Choreography) tracks() (names []string) {
+	for name := range c.Timeline {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// current returns the last keyframe of a track at or before a time, or nil
+func current(track Track, at time.Duration) (keyframe *Keyframe) {
+	for i := range track.Keyframes {
+		if track.Keyframes[i].At > at {
+			break
+		}
+		keyframe = &track.Keyframes[i]
+	}
+	return
+}
+
+func distance(a Point, b Point) float64 {
+	return math.Hypot(a.X-b.X, a.Y-b.Y)
+}
+
+// WriteSVG renders the planned paths of the timeline as an SVG image to w,
+// as a dry run of the choreography. Each path starts with a circle labelled
+// by the name of its track, and its segments have the color of their
+// keyframe.
+func (c *Choreography) WriteSVG(w io.Writer) error {
+	const step = 100 * time.Millisecond
+	type segment struct {
+		color  []uint8
+		points []Point
+	}
+
+	paths := map[string][]segment{}
+	min, max := Point{X: math.Inf(1), Y: math.Inf(1)}, Point{X: math.Inf(-1), Y: math.Inf(-1)}
+	extend := func(p Point) {
+		min = Point{X: math.Min(min.X, p.X), Y: math.Min(min.Y, p.Y)}
+		max = Point{X: math.Max(max.X, p.X), Y: math.Max(max.Y, p.Y)}
+	}
+
+	for _, name := range c.tracks() {
+		track := c.keyframes(name)
+		extend(track.Start)
+		color := []uint8{128, 128, 128}
+		for i, keyframe := range track.Keyframes {
+			if len(keyframe.Color) == 3 {
+				color = keyframe.Color
+			}
+			end := c.Duration()
+			if i+1 < len(track.Keyframes) {
+				end = track.Keyframes[i+1].At
+			}
+			s := segment{color: color}
+			for at := keyframe.At; ; at += step {
+				if at > end {
+					at = end
+				}
+				p := c.plan(track, at)
+				s.points = append(s.points, p)
+				extend(p)
+				if at == end {
+					break
+				}
+			}
+			paths[name] = append(paths[name], s)
+		}
+	}
+	if math.IsInf(min.X, 1) {
+		min, max = Point{}, Point{}
+	}
+
+	// the Y axis of SVG points down
+	const margin = 20
+	width, height := max.X-min.X+2*margin, max.Y-min.Y+2*margin
+	ew := &errWriter{w: w}
+	ew.printf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="%.1f %.1f %.1f %.1f" width="%.0f" height="%.0f">`+"\n",
+		min.X-margin, -max.Y-margin, width, height, width, height)
+	for _, name := range c.tracks() {
+		for _, s := range paths[name] {
+			ew.printf(`  <polyline fill="none" stroke="rgb(%d,%d,%d)" stroke-width="2" points="`,
+				s.color[0], s.color[1], s.color[2])
+			for i, p := range s.points {
+				if i > 0 {
+					ew.printf(" ")
+				}
+				ew.printf("%.1f,%.1f", p.X, -p.Y)
+			}
+			ew.printf(`"/>` + "\n")
+		}
+		start := c.Timeline[name].Start
+		ew.printf(`  <circle cx="%.1f" cy="%.1f" r="4"/>`+"\n", start.X, -start.Y)
+		ew.printf(`  <text x="%.1f" y="%.1f" font-size="10">%v</text>`+"\n", start.X+6, -start.Y-6, html.EscapeString(name))
+	}
+	ew.printf("</svg>\n")
+	return ew.err
+}
+
+// errWriter keeps the first error of its writes
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) printf(format string, a ...interface{}) {
+	if e.err == nil {
+		_, e.err = fmt.Fprintf(e.w, format, a...)
+	}
+}
@@ -0,0 +1,186 @@
+package sphero
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func testTimeline() Timeline {
+	return Timeline{
+		"left": {
+			Start: Point{X: -50, Y: 0},
+			Keyframes: []Keyframe{
+				{At: 0, Heading: 0, Speed: 51, Color: []uint8{255, 0, 0}},
+				{At: time.Second, Heading: 90, Speed: 51, Color: []uint8{0, 255, 0}},
+				{At: 2 * time.Second, Speed: 0},
+			},
+		},
+		"right": {
+			Start: Point{X: 50, Y: 0},
+			Keyframes: []Keyframe{
+				{At: 2 * time.Second, Speed: 0},
+				{At: 0, Heading: 180, Speed: 51},
+			},
+		},
+	}
+}
+
+func TestChoreographyCorrectionSchedule(t *testing.T) {
+	clock := gobot.NewVirtualClock(time.Now())
+	defaultClock := gobot.DefaultClock
+	gobot.DefaultClock = clock
+	defer func() { gobot.DefaultClock = defaultClock }()
+
+	d := initTestSpheroDriver()
+	start := clock.Now()
+	var reads []time.Duration
+	defer respond(d, func(p *packet) (uint8, []uint8) {
+		if p.header[3] == 0x15 {
+			// reading the locator takes 30ms
+			reads = append(reads, clock.Now().Sub(start))
+			clock.Advance(30 * time.Millisecond)
+		}
+		return 0x00, nil
+	})()
+
+	c := NewChoreography(Timeline{"left": {Keyframes: []Keyframe{{Speed: 51}, {At: time.Second}}}})
+	c.AddSphero("left", d)
+	gobottest.Assert(t, c.Start(), nil)
+	for i := 0; i < 100; i++ {
+		clock.Advance(10 * time.Millisecond)
+	}
+
+	// the corrections are not delayed by the locator readings
+	gobottest.Assert(t, reads, []time.Duration{
+		100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond,
+		400 * time.Millisecond, 500 * time.Millisecond, 600 * time.Millisecond,
+		700 * time.Millisecond, 800 * time.Millisecond, 900 * time.Millisecond,
+	})
+
+	// the readings longer than the interval skip the corrections due meanwhile
+	reads = nil
+	start = clock.Now()
+	c = NewChoreography(Timeline{"left": {Keyframes: []Keyframe{{Speed: 51}, {At: time.Second}}}})
+	c.CorrectionInterval = 20 * time.Millisecond
+	c.AddSphero("left", d)
+	gobottest.Assert(t, c.Start(), nil)
+	for i := 0; i < 10; i++ {
+		clock.Advance(10 * time.Millisecond)
+	}
+	gobottest.Assert(t, reads[:3], []time.Duration{
+		20 * time.Millisecond, 60 * time.Millisecond, 100 * time.Millisecond,
+	})
+}
+
+func TestChoreographyPlan(t *testing.T) {
+	c := NewChoreography(testTimeline())
+	gobottest.Assert(t, c.Duration(), 2*time.Second)
+
+	round := func(p Point) Point {
+		return Point{X: math.Floor(p.X*10+0.5) / 10, Y: math.Floor(p.Y*10+0.5) / 10}
+	}
+	gobottest.Assert(t, round(c.Plan("left", 0)), Point{X: -50, Y: 0})
+	gobottest.Assert(t, round(c.Plan("left", 500*time.Millisecond)), Point{X: -50, Y: 20})
+	gobottest.Assert(t, round(c.Plan("left", 1500*time.Millisecond)), Point{X: -30, Y: 40})
+	gobottest.Assert(t, round(c.Plan("left", 3*time.Second)), Point{X: -10, Y: 40})
+	gobottest.Assert(t, round(c.Plan("right", 2*time.Second)), Point{X: 50, Y: -80})
+}
+
+func TestChoreographyWriteSVG(t *testing.T) {
+	c := NewChoreography(testTimeline())
+	c.Timeline["<b>"] = Track{}
+	buf := &bytes.Buffer{}
+	gobottest.Assert(t, c.WriteSVG(buf), nil)
+
+	svg := buf.String()
+	gobottest.Assert(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="-70.0 -60.0 140.0 160.0"`), true)
+	gobottest.Assert(t, strings.Count(svg, "<polyline"), 5)
+	gobottest.Assert(t, strings.Contains(svg, `stroke="rgb(255,0,0)" stroke-width="2" points="-50.0,-0.0 -50.0,-4.0`), true)
+	gobottest.Assert(t, strings.Contains(svg, `<text x="56.0" y="-6.0" font-size="10">right</text>`), true)
+	gobottest.Assert(t, strings.Contains(svg, "&lt;b&gt;"), true)
+	gobottest.Assert(t, strings.HasSuffix(svg, "</svg>\n"), true)
+
+	gobottest.Assert(t, c.WriteSVG(errorWriter{}), errors.New("write error"))
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(b []byte) (int, error) { return 0, errors.New("write error") }
+
+func TestChoreographyStartErrors(t *testing.T) {
+	c := NewChoreography(testTimeline())
+	_, d, stop := initTestSimulator()
+	defer stop()
+	c.AddSphero("left", d)
+	gobottest.Assert(t, c.Start(), errors.New(`Choreography: no Sphero for track "right"`))
+}
+
+func TestChoreography(t *testing.T) {
+	left, leftDriver, stopLeft := initTestSimulator()
+	defer stopLeft()
+	right, rightDriver, stopRight := initTestSimulator()
+	defer stopRight()
+	gobottest.Assert(t, len(leftDriver.Start()), 0)
+	gobottest.Assert(t, len(rightDriver.Start()), 0)
+	// the Spheros are slower than planned, and drift
+	left.MaxSpeed = 160
+	right.MaxSpeed = 160
+
+	clock := gobot.NewVirtualClock(time.Now())
+	defaultClock := gobot.DefaultClock
+	gobot.DefaultClock = clock
+	defer func() { gobot.DefaultClock = defaultClock }()
+
+	c := NewChoreography(testTimeline())
+	c.AddSphero("left", leftDriver)
+	c.AddSphero("right", rightDriver)
+	gobottest.Assert(t, c.Start(), nil)
+	gobottest.Assert(t, c.Start(), errors.New("Choreography already started"))
+
+	// advance the clock of the choreography and the simulators together,
+	// waiting for the Spheros to receive the commands
+	for i := 0; i < 200; i++ {
+		clock.Advance(10 * time.Millisecond)
+		gobottest.Assert(t, leftDriver.Ping(), nil)
+		gobottest.Assert(t, rightDriver.Ping(), nil)
+		left.Step(10 * time.Millisecond)
+		right.Step(10 * time.Millisecond)
+	}
+
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("Choreography is not done")
+	}
+	gobottest.Assert(t, leftDriver.Ping(), nil)
+	gobottest.Assert(t, left.Speed(), uint8(0))
+	gobottest.Assert(t, left.RGB(), []uint8{0, 255, 0})
+
+	for name, sim := range map[string]*SpheroSimulator{"left": left, "right": right} {
+		x, y := sim.Position()
+		if d := distance(Point{X: x, Y: y}, c.Plan(name, 2*time.Second)); d > c.Tolerance {
+			t.Errorf("%v drifted %.1fcm away from its planned position", name, d)
+		}
+	}
+
+	// without corrections, the Spheros drift away
+	left.SetPosition(0, 0)
+	c = NewChoreography(Timeline{"left": {Keyframes: []Keyframe{{Speed: 51}, {At: 2 * time.Second}}}})
+	c.CorrectionInterval = 0
+	c.AddSphero("left", leftDriver)
+	gobottest.Assert(t, c.Start(), nil)
+	for i := 0; i < 200; i++ {
+		clock.Advance(10 * time.Millisecond)
+		gobottest.Assert(t, leftDriver.Ping(), nil)
+		left.Step(10 * time.Millisecond)
+	}
+	_, y := left.Position()
+	gobottest.Assert(t, int(y+0.5), 64)
+}
@@ -4,6 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/hybridgroup/gobot"
@@ -12,11 +15,30 @@ import (
 var _ gobot.Driver = (*SpheroDriver)(nil)
 
 const (
-	SensorData = "sensordata"
-	Collision  = "collision"
-	Error      = "error"
+	SensorData        = "sensordata"
+	Collision         = "collision"
+	Error             = "error"
+	PowerNotification = "power"
+	Diagnostics       = "diagnostics"
+	PreSleep          = "presleep"
+	MacroMarker       = "macromarker"
+	OrbBasicPrint     = "orbbasicprint"
+	OrbBasicError     = "orbbasicerror"
+	SelfLevelComplete = "selflevel"
 )
 
+// ErrNoResponse is the error of a command the Sphero did not respond to
+var ErrNoResponse = errors.New("Sphero did not respond")
+
+// OrbBasic storage areas, see UploadOrbBasic
+const (
+	OrbBasicRAM   uint8 = 0x00
+	OrbBasicFlash uint8 = 0x01
+)
+
+// maxBody is the maximum length of the body of a packet
+const maxBody = 254
+
 type packet struct {
 	header   []uint8
 	body     []uint8
@@ -32,11 +54,13 @@ type SpheroDriver struct {
 	syncResponse    [][]uint8
 	packetChannel   chan *packet
 	responseChannel chan []uint8
+	mutex           sync.Mutex
 	gobot.Eventer
 	gobot.Commander
 }
 
-// NewSpheroDriver returns a new SpheroDriver given a SpheroAdaptor and name.
+// NewSpheroDriver returns a new SpheroDriver given a Transport, such as a
+// SpheroAdaptor or a ble.SpheroBLEAdaptor, and name.
 //
 // Adds the following API Commands:
 // 	"ConfigureLocator" - See SpheroDriver.ConfigureLocator
@@ -49,7 +73,24 @@ type SpheroDriver struct {
 // 	"SetStabilization" - See SpheroDriver.SetStabilization
 //  "SetDataStreaming" - See SpheroDriver.SetDataStreaming
 //  "SetRotationRate" - See SpheroDriver.SetRotationRate
-func NewSpheroDriver(a *SpheroAdaptor, name string) *SpheroDriver {
+// 	"GetPowerState" - See SpheroDriver.GetPowerState
+// 	"SetPowerNotification" - See SpheroDriver.SetPowerNotification
+// 	"Sleep" - See SpheroDriver.Sleep
+// 	"RunL1Diagnostics" - See SpheroDriver.RunL1Diagnostics
+// 	"SetRawMotorValues" - See SpheroDriver.SetRawMotorValues
+// 	"Boost" - See SpheroDriver.Boost
+// 	"SelfLevel" - See SpheroDriver.SelfLevel
+// 	"GetPermanentOptionFlags" - See SpheroDriver.GetPermanentOptionFlags
+// 	"SetPermanentOptionFlags" - See SpheroDriver.SetPermanentOptionFlags
+// 	"GetTemporaryOptionFlags" - See SpheroDriver.GetTemporaryOptionFlags
+// 	"SetTemporaryOptionFlags" - See SpheroDriver.SetTemporaryOptionFlags
+// 	"RunMacro" - See SpheroDriver.RunMacro
+// 	"AbortMacro" - See SpheroDriver.AbortMacro
+// 	"GetMacroStatus" - See SpheroDriver.GetMacroStatus
+// 	"UploadOrbBasic" - See SpheroDriver.UploadOrbBasic
+// 	"ExecuteOrbBasic" - See SpheroDriver.ExecuteOrbBasic
+// 	"AbortOrbBasic" - See SpheroDriver.AbortOrbBasic
+func NewSpheroDriver(a Transport, name string) *SpheroDriver {
 	s := &SpheroDriver{
 		name:            name,
 		connection:      a,
@@ -62,6 +103,13 @@ func NewSpheroDriver(a *SpheroAdaptor, name string) *SpheroDriver {
 	s.AddEvent(Error)
 	s.AddEvent(Collision)
 	s.AddEvent(SensorData)
+	s.AddEvent(PowerNotification)
+	s.AddEvent(Diagnostics)
+	s.AddEvent(PreSleep)
+	s.AddEvent(MacroMarker)
+	s.AddEvent(OrbBasicPrint)
+	s.AddEvent(OrbBasicError)
+	s.AddEvent(SelfLevelComplete)
 
 	s.AddCommand("SetRGB", func(params map[string]interface{}) interface{} {
 		r := uint8(params["r"].(float64))
@@ -136,14 +184,203 @@ func NewSpheroDriver(a *SpheroAdaptor, name string) *SpheroDriver {
 		return nil
 	})
 
+	byteRange := &gobot.ParamRange{Min: 0, Max: 255}
+	wordRange := &gobot.ParamRange{Min: 0, Max: 65535}
+	flagsRange := &gobot.ParamRange{Min: 0, Max: 4294967295}
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "GetPowerState",
+		Description: "Returns the battery voltage, number of charges and power state",
+	}, func(params map[string]interface{}) interface{} {
+		state, err := s.GetPowerState()
+		if err != nil {
+			return err
+		}
+		return state
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetPowerNotification",
+		Description: "Enables or disables the power notification events",
+		Params: []gobot.CommandParam{
+			{Name: "enable", Type: gobot.BooleanParam, Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.SetPowerNotification(params["enable"].(bool))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Sleep",
+		Description: "Puts the Sphero to sleep, waking it up after wakeup seconds unless 0",
+		Params: []gobot.CommandParam{
+			{Name: "wakeup", Type: gobot.IntegerParam, Default: 0, Range: wordRange},
+			{Name: "macro", Type: gobot.IntegerParam, Default: 0, Range: byteRange},
+			{Name: "line", Type: gobot.IntegerParam, Default: 0, Range: wordRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.Sleep(uint16(params["wakeup"].(int)), uint8(params["macro"].(int)), uint16(params["line"].(int)))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "RunL1Diagnostics",
+		Description: "Runs the level 1 diagnostics, published to the diagnostics event",
+	}, func(params map[string]interface{}) interface{} {
+		return s.RunL1Diagnostics()
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetRawMotorValues",
+		Description: "Sets the mode and power of the left and right motors",
+		Params: []gobot.CommandParam{
+			{Name: "lmode", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 4}},
+			{Name: "lpower", Type: gobot.IntegerParam, Required: true, Range: byteRange},
+			{Name: "rmode", Type: gobot.IntegerParam, Required: true, Range: &gobot.ParamRange{Min: 0, Max: 4}},
+			{Name: "rpower", Type: gobot.IntegerParam, Required: true, Range: byteRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.SetRawMotorValues(uint8(params["lmode"].(int)), uint8(params["lpower"].(int)),
+			uint8(params["rmode"].(int)), uint8(params["rpower"].(int)))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "Boost",
+		Description: "Starts or stops boosting in the current heading",
+		Params: []gobot.CommandParam{
+			{Name: "enable", Type: gobot.BooleanParam, Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.Boost(params["enable"].(bool))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SelfLevel",
+		Description: "Levels the Sphero, published to the selflevel event",
+		Params: []gobot.CommandParam{
+			{Name: "options", Type: gobot.IntegerParam, Default: int(DefaultSelfLevelConfig().Options), Range: byteRange},
+			{Name: "angleLimit", Type: gobot.IntegerParam, Default: 0, Range: &gobot.ParamRange{Min: 0, Max: 90}},
+			{Name: "timeout", Type: gobot.IntegerParam, Default: 0, Range: byteRange},
+			{Name: "trueTime", Type: gobot.IntegerParam, Default: 0, Range: byteRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.SelfLevel(SelfLevelConfig{
+			Options:    uint8(params["options"].(int)),
+			AngleLimit: uint8(params["angleLimit"].(int)),
+			Timeout:    uint8(params["timeout"].(int)),
+			TrueTime:   uint8(params["trueTime"].(int)),
+		})
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "GetPermanentOptionFlags",
+		Description: "Returns the permanent option flags",
+	}, func(params map[string]interface{}) interface{} {
+		flags, err := s.GetPermanentOptionFlags()
+		if err != nil {
+			return err
+		}
+		return flags
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetPermanentOptionFlags",
+		Description: "Sets the permanent option flags",
+		Params: []gobot.CommandParam{
+			{Name: "flags", Type: gobot.IntegerParam, Required: true, Range: flagsRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.SetPermanentOptionFlags(uint32(params["flags"].(int)))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "GetTemporaryOptionFlags",
+		Description: "Returns the temporary option flags",
+	}, func(params map[string]interface{}) interface{} {
+		flags, err := s.GetTemporaryOptionFlags()
+		if err != nil {
+			return err
+		}
+		return flags
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "SetTemporaryOptionFlags",
+		Description: "Sets the temporary option flags",
+		Params: []gobot.CommandParam{
+			{Name: "flags", Type: gobot.IntegerParam, Required: true, Range: flagsRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.SetTemporaryOptionFlags(uint32(params["flags"].(int)))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "RunMacro",
+		Description: "Runs a macro",
+		Params: []gobot.CommandParam{
+			{Name: "id", Type: gobot.IntegerParam, Required: true, Range: byteRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.RunMacro(uint8(params["id"].(int)))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "AbortMacro",
+		Description: "Aborts the running macro",
+	}, func(params map[string]interface{}) interface{} {
+		status, err := s.AbortMacro()
+		if err != nil {
+			return err
+		}
+		return status
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "GetMacroStatus",
+		Description: "Returns the ID and command number of the running macro",
+	}, func(params map[string]interface{}) interface{} {
+		status, err := s.GetMacroStatus()
+		if err != nil {
+			return err
+		}
+		return status
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "UploadOrbBasic",
+		Description: "Uploads an orbBasic program to RAM (0) or flash (1)",
+		Params: []gobot.CommandParam{
+			{Name: "area", Type: gobot.IntegerParam, Default: int(OrbBasicRAM), Range: &gobot.ParamRange{Min: 0, Max: 1}},
+			{Name: "program", Type: gobot.StringParam, Required: true},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.UploadOrbBasic(uint8(params["area"].(int)), params["program"].(string))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "ExecuteOrbBasic",
+		Description: "Executes the orbBasic program of an area from a line",
+		Params: []gobot.CommandParam{
+			{Name: "area", Type: gobot.IntegerParam, Default: int(OrbBasicRAM), Range: &gobot.ParamRange{Min: 0, Max: 1}},
+			{Name: "line", Type: gobot.IntegerParam, Default: 0, Range: wordRange},
+		},
+	}, func(params map[string]interface{}) interface{} {
+		return s.ExecuteOrbBasic(uint8(params["area"].(int)), uint16(params["line"].(int)))
+	})
+
+	s.AddCommandSchema(gobot.CommandSchema{
+		Name:        "AbortOrbBasic",
+		Description: "Aborts the running orbBasic program",
+	}, func(params map[string]interface{}) interface{} {
+		return s.AbortOrbBasic()
+	})
+
 	return s
 }
 
 func (s *SpheroDriver) Name() string                 { return s.name }
 func (s *SpheroDriver) Connection() gobot.Connection { return s.connection }
 
-func (s *SpheroDriver) adaptor() *SpheroAdaptor {
-	return s.Connection().(*SpheroAdaptor)
+func (s *SpheroDriver) adaptor() Transport {
+	return s.Connection().(Transport)
 }
 
 // Start starts the SpheroDriver and enables Collision Detection.
@@ -153,6 +390,13 @@ func (s *SpheroDriver) adaptor() *SpheroAdaptor {
 // 	Collision  sphero.CollisionPacket - On Collision Detected
 // 	SensorData sphero.DataStreamingPacket - On Data Streaming event
 // 	Error      error- On error while processing asynchronous response
+// 	PowerNotification uint8 - On power state change, see SetPowerNotification
+// 	Diagnostics string - On level 1 diagnostics, see RunL1Diagnostics
+// 	PreSleep nil - 10 seconds before the Sphero goes to sleep
+// 	MacroMarker sphero.MacroMarkerPacket - On macro marker or end
+// 	OrbBasicPrint string - On orbBasic PRINT statement
+// 	OrbBasicError string - On orbBasic error
+// 	SelfLevelComplete uint8 - On self level result, see SelfLevel
 func (s *SpheroDriver) Start() (errs []error) {
 	go func() {
 		for {
@@ -167,7 +411,7 @@ func (s *SpheroDriver) Start() (errs []error) {
 	go func() {
 		for {
 			response := <-s.responseChannel
-			s.syncResponse = append(s.syncResponse, response)
+			s.addSyncResponse(response)
 		}
 	}()
 
@@ -175,7 +419,12 @@ func (s *SpheroDriver) Start() (errs []error) {
 		for {
 			header := s.readHeader()
 			if len(header) > 0 {
-				body := s.readBody(header[4])
+				length := int(header[4])
+				if header[1] == 0xFE {
+					// asynchronous packets have a 16 bit length
+					length += int(header[3]) << 8
+				}
+				body := s.readBody(length)
 				data := append(header, body...)
 				checksum := data[len(data)-1]
 				if checksum != calculateChecksum(data[2:len(data)-1]) {
@@ -183,7 +432,9 @@ func (s *SpheroDriver) Start() (errs []error) {
 				}
 				switch header[1] {
 				case 0xFE:
+					s.mutex.Lock()
 					s.asyncResponse = append(s.asyncResponse, data)
+					s.mutex.Unlock()
 				case 0xFF:
 					s.responseChannel <- data
 				}
@@ -193,14 +444,12 @@ func (s *SpheroDriver) Start() (errs []error) {
 
 	go func() {
 		for {
-			var evt []uint8
-			for len(s.asyncResponse) != 0 {
-				evt, s.asyncResponse = s.asyncResponse[len(s.asyncResponse)-1], s.asyncResponse[:len(s.asyncResponse)-1]
-				if evt[2] == 0x07 {
-					s.handleCollisionDetected(evt)
-				} else if evt[2] == 0x03 {
-					s.handleDataStreaming(evt)
-				}
+			s.mutex.Lock()
+			responses := s.asyncResponse
+			s.asyncResponse = nil
+			s.mutex.Unlock()
+			for _, evt := range responses {
+				s.handleAsyncResponse(evt)
 			}
 			time.Sleep(100 * time.Millisecond)
 		}
@@ -215,7 +464,7 @@ func (s *SpheroDriver) Start() (errs []error) {
 // Halt halts the SpheroDriver and sends a SpheroDriver.Stop command to the Sphero.
 // Returns true on successful halt.
 func (s *SpheroDriver) Halt() (errs []error) {
-	if s.adaptor().connected {
+	if s.adaptor().Connected() {
 		gobot.Every(10*time.Millisecond, func() {
 			s.Stop()
 		})
@@ -309,6 +558,212 @@ func (s *SpheroDriver) enableStopOnDisconnect() {
 	s.packetChannel <- s.craftPacket([]uint8{0x00, 0x00, 0x00, 0x01}, 0x02, 0x37)
 }
 
+// Ping verifies the Sphero is connected and responding
+func (s *SpheroDriver) Ping() error {
+	_, err := s.syncRequest([]uint8{}, 0x00, 0x01)
+	return err
+}
+
+// GetPowerState returns the battery voltage, number of charges and power
+// state of the Sphero
+func (s *SpheroDriver) GetPowerState() (state PowerState, err error) {
+	err = s.syncRead([]uint8{}, 0x00, 0x20, &state)
+	return
+}
+
+// SetPowerNotification enables or disables the PowerNotification event,
+// published every 10 seconds with the power state of the Sphero
+func (s *SpheroDriver) SetPowerNotification(on bool) error {
+	_, err := s.syncRequest([]uint8{boolToByte(on)}, 0x00, 0x21)
+	return err
+}
+
+// Sleep puts the Sphero to sleep, waking it up after wakeup seconds unless
+// it is 0. On wake up, it runs the macro with the given ID unless it is 0,
+// or the orbBasic program in flash from the given line unless it is 0.
+func (s *SpheroDriver) Sleep(wakeup uint16, macro uint8, orbBasicLine uint16) error {
+	_, err := s.syncRequest([]uint8{
+		uint8(wakeup >> 8), uint8(wakeup & 0xFF), macro, uint8(orbBasicLine >> 8), uint8(orbBasicLine & 0xFF),
+	}, 0x00, 0x22)
+	return err
+}
+
+// RunL1Diagnostics runs the level 1 diagnostics of the Sphero, whose text is
+// published to the Diagnostics event
+func (s *SpheroDriver) RunL1Diagnostics() error {
+	_, err := s.syncRequest([]uint8{}, 0x00, 0x40)
+	return err
+}
+
+// SelfLevel levels the Sphero, publishing the result to the
+// SelfLevelComplete event
+func (s *SpheroDriver) SelfLevel(c SelfLevelConfig) error {
+	_, err := s.syncRequest([]uint8{c.Options, c.AngleLimit, c.Timeout, c.TrueTime}, 0x02, 0x09)
+	return err
+}
+
+// Boost starts or stops boosting the Sphero in its current heading
+func (s *SpheroDriver) Boost(on bool) error {
+	_, err := s.syncRequest([]uint8{boolToByte(on)}, 0x02, 0x31)
+	return err
+}
+
+// SetRawMotorValues sets the mode and power of the left and right motors,
+// disabling stabilization. The modes are MotorOff, MotorForward,
+// MotorReverse, MotorBrake and MotorIgnore.
+func (s *SpheroDriver) SetRawMotorValues(lmode uint8, lpower uint8, rmode uint8, rpower uint8) error {
+	_, err := s.syncRequest([]uint8{lmode, lpower, rmode, rpower}, 0x02, 0x33)
+	return err
+}
+
+// SetPermanentOptionFlags sets the option flags stored by the Sphero, such
+// as OptionVectorDrive
+func (s *SpheroDriver) SetPermanentOptionFlags(flags uint32) error {
+	_, err := s.syncRequest(uint32ToBytes(flags), 0x02, 0x35)
+	return err
+}
+
+// GetPermanentOptionFlags returns the option flags stored by the Sphero
+func (s *SpheroDriver) GetPermanentOptionFlags() (flags uint32, err error) {
+	err = s.syncRead([]uint8{}, 0x02, 0x36, &flags)
+	return
+}
+
+// SetTemporaryOptionFlags sets the option flags of the Sphero until it
+// sleeps, such as OptionStopOnDisconnect
+func (s *SpheroDriver) SetTemporaryOptionFlags(flags uint32) error {
+	_, err := s.syncRequest(uint32ToBytes(flags), 0x02, 0x37)
+	return err
+}
+
+// GetTemporaryOptionFlags returns the option flags of the Sphero until it
+// sleeps
+func (s *SpheroDriver) GetTemporaryOptionFlags() (flags uint32, err error) {
+	err = s.syncRead([]uint8{}, 0x02, 0x38, &flags)
+	return
+}
+
+// RunMacro runs the macro with the given ID, publishing its markers to the
+// MacroMarker event
+func (s *SpheroDriver) RunMacro(id uint8) error {
+	_, err := s.syncRequest([]uint8{id}, 0x02, 0x50)
+	return err
+}
+
+// SaveTemporaryMacro saves the commands of the temporary macro, whose ID is
+// 255, replacing the previous one
+func (s *SpheroDriver) SaveTemporaryMacro(commands []uint8) error {
+	_, err := s.syncRequest(commands, 0x02, 0x51)
+	return err
+}
+
+// SaveMacro saves the commands of a macro with the given ID
+func (s *SpheroDriver) SaveMacro(id uint8, commands []uint8) error {
+	_, err := s.syncRequest(append([]uint8{id}, commands...), 0x02, 0x52)
+	return err
+}
+
+// AbortMacro aborts the running macro, returning its ID and the number of
+// the command it was executing
+func (s *SpheroDriver) AbortMacro() (status MacroStatus, err error) {
+	err = s.syncRead([]uint8{}, 0x02, 0x55, &status)
+	return
+}
+
+// GetMacroStatus returns the ID and the number of the command executed of
+// the running macro
+func (s *SpheroDriver) GetMacroStatus() (status MacroStatus, err error) {
+	err = s.syncRead([]uint8{}, 0x02, 0x56, &status)
+	return
+}
+
+// EraseOrbBasic erases the orbBasic program of an area, OrbBasicRAM or
+// OrbBasicFlash
+func (s *SpheroDriver) EraseOrbBasic(area uint8) error {
+	_, err := s.syncRequest([]uint8{area}, 0x02, 0x60)
+	return err
+}
+
+// UploadOrbBasic replaces the orbBasic program of an area, OrbBasicRAM or
+// OrbBasicFlash, by program, appending it in fragments
+func (s *SpheroDriver) UploadOrbBasic(area uint8, program string) error {
+	if err := s.EraseOrbBasic(area); err != nil {
+		return err
+	}
+	if !strings.HasSuffix(program, "\n") {
+		program += "\n"
+	}
+	data := []uint8(program)
+	for len(data) > 0 {
+		n := len(data)
+		if n > maxBody-1 {
+			n = maxBody - 1
+		}
+		if _, err := s.syncRequest(append([]uint8{area}, data[:n]...), 0x02, 0x61); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// ExecuteOrbBasic executes the orbBasic program of an area from a line,
+// publishing its output to the OrbBasicPrint and OrbBasicError events
+func (s *SpheroDriver) ExecuteOrbBasic(area uint8, line uint16) error {
+	_, err := s.syncRequest([]uint8{area, uint8(line >> 8), uint8(line & 0xFF)}, 0x02, 0x62)
+	return err
+}
+
+// AbortOrbBasic aborts the running orbBasic program
+func (s *SpheroDriver) AbortOrbBasic() error {
+	_, err := s.syncRequest([]uint8{}, 0x02, 0x63)
+	return err
+}
+
+func boolToByte(b bool) uint8 {
+	if b {
+		return 0x01
+	}
+	return 0x00
+}
+
+func uint32ToBytes(v uint32) []uint8 {
+	return []uint8{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}
+}
+
+// handleAsyncResponse publishes an asynchronous packet to its event given its
+// ID code
+func (s *SpheroDriver) handleAsyncResponse(data []uint8) {
+	body := data[5 : len(data)-1]
+	switch data[2] {
+	case 0x01:
+		if len(body) == 1 {
+			gobot.Publish(s.Event(PowerNotification), body[0])
+		}
+	case 0x02:
+		gobot.Publish(s.Event(Diagnostics), string(body))
+	case 0x03:
+		s.handleDataStreaming(data)
+	case 0x05:
+		gobot.Publish(s.Event(PreSleep), nil)
+	case 0x06:
+		var marker MacroMarkerPacket
+		if binary.Read(bytes.NewReader(body), binary.BigEndian, &marker) == nil {
+			gobot.Publish(s.Event(MacroMarker), marker)
+		}
+	case 0x07:
+		s.handleCollisionDetected(data)
+	case 0x08:
+		gobot.Publish(s.Event(OrbBasicPrint), string(body))
+	case 0x09:
+		gobot.Publish(s.Event(OrbBasicError), string(body))
+	case 0x0B:
+		if len(body) == 1 {
+			gobot.Publish(s.Event(SelfLevelComplete), body[0])
+		}
+	}
+}
+
 func (s *SpheroDriver) handleCollisionDetected(data []uint8) {
 	// ensure data is the right length:
 	if len(data) != 22 || data[4] != 17 {
@@ -322,7 +777,7 @@ func (s *SpheroDriver) handleCollisionDetected(data []uint8) {
 
 func (s *SpheroDriver) handleDataStreaming(data []uint8) {
 	// ensure data is the right length:
-	if len(data) != 90 {
+	if len(data) != 5+binary.Size(DataStreamingPacket{})+1 {
 		return
 	}
 	var dataPacket DataStreamingPacket
@@ -331,15 +786,34 @@ func (s *SpheroDriver) handleDataStreaming(data []uint8) {
 	gobot.Publish(s.Event(SensorData), dataPacket)
 }
 
+func (s *SpheroDriver) addSyncResponse(response []uint8) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.syncResponse = append(s.syncResponse, response)
+}
+
+// takeSyncResponse removes and returns the response with the sequence number
+// seq, or nil
+func (s *SpheroDriver) takeSyncResponse(seq uint8) []byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for key, response := range s.syncResponse {
+		if response[3] == seq && len(response) > 5 {
+			s.syncResponse = append(s.syncResponse[:key], s.syncResponse[key+1:]...)
+			return response
+		}
+	}
+	return nil
+}
+
 func (s *SpheroDriver) getSyncResponse(packet *packet) []byte {
+	// discard the response of a previous packet with the same sequence number
+	s.takeSyncResponse(packet.header[4])
+
 	s.packetChannel <- packet
 	for i := 0; i < 500; i++ {
-		for key := range s.syncResponse {
-			if s.syncResponse[key][3] == packet.header[4] && len(s.syncResponse[key]) > 6 {
-				var response []byte
-				response, s.syncResponse = s.syncResponse[len(s.syncResponse)-1], s.syncResponse[:len(s.syncResponse)-1]
-				return response
-			}
+		if response := s.takeSyncResponse(packet.header[4]); response != nil {
+			return response
 		}
 		time.Sleep(100 * time.Microsecond)
 	}
@@ -347,11 +821,44 @@ func (s *SpheroDriver) getSyncResponse(packet *packet) []byte {
 	return []byte{}
 }
 
+// syncRequest sends a packet and returns the data of its response, or an
+// error if the Sphero did not respond or responded with an error code
+func (s *SpheroDriver) syncRequest(body []uint8, did byte, cid byte) (data []byte, err error) {
+	if len(body) > maxBody {
+		return nil, fmt.Errorf("Command 0x%02X: body of %v bytes is too long", cid, len(body))
+	}
+	response := s.getSyncResponse(s.craftPacket(body, did, cid))
+	if len(response) < 6 {
+		return nil, ErrNoResponse
+	}
+	if response[2] != 0x00 {
+		return nil, fmt.Errorf("Command 0x%02X: Sphero responded with error code 0x%02X", cid, response[2])
+	}
+	return response[5 : len(response)-1], nil
+}
+
+// syncRead sends a packet and reads the data of its response into v
+func (s *SpheroDriver) syncRead(body []uint8, did byte, cid byte, v interface{}) error {
+	data, err := s.syncRequest(body, did, cid)
+	if err != nil {
+		return err
+	}
+	if len(data) < binary.Size(v) {
+		return fmt.Errorf("Command 0x%02X: response of %v bytes is too short", cid, len(data))
+	}
+	return binary.Read(bytes.NewReader(data), binary.BigEndian, v)
+}
+
 func (s *SpheroDriver) craftPacket(body []uint8, did byte, cid byte) *packet {
+	s.mutex.Lock()
+	seq := s.seq
+	s.seq++
+	s.mutex.Unlock()
+
 	packet := new(packet)
 	packet.body = body
 	dlen := len(packet.body) + 1
-	packet.header = []uint8{0xFF, 0xFF, did, cid, s.seq, uint8(dlen)}
+	packet.header = []uint8{0xFF, 0xFF, did, cid, seq, uint8(dlen)}
 	packet.checksum = s.calculateChecksum(packet)
 	return packet
 }
@@ -359,13 +866,12 @@ func (s *SpheroDriver) craftPacket(body []uint8, did byte, cid byte) *packet {
 func (s *SpheroDriver) write(packet *packet) (err error) {
 	buf := append(packet.header, packet.body...)
 	buf = append(buf, packet.checksum)
-	length, err := s.adaptor().sp.Write(buf)
+	length, err := s.adaptor().Write(buf)
 	if err != nil {
 		return err
 	} else if length != len(buf) {
 		return errors.New("Not enough bytes written")
 	}
-	s.seq++
 	return
 }
 
@@ -386,8 +892,8 @@ func (s *SpheroDriver) readHeader() []uint8 {
 	return s.readNextChunk(5)
 }
 
-func (s *SpheroDriver) readBody(length uint8) []uint8 {
-	return s.readNextChunk(int(length))
+func (s *SpheroDriver) readBody(length int) []uint8 {
+	return s.readNextChunk(length)
 }
 
 func (s *SpheroDriver) readNextChunk(length int) []uint8 {
@@ -396,7 +902,7 @@ func (s *SpheroDriver) readNextChunk(length int) []uint8 {
 
 	for bytesRead < length {
 		time.Sleep(1 * time.Millisecond)
-		n, err := s.adaptor().sp.Read(read[bytesRead:])
+		n, err := s.adaptor().Read(read[bytesRead:])
 		if err != nil {
 			return nil
 		}
@@ -3,8 +3,16 @@ package sphero
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/api"
 	"github.com/hybridgroup/gobot/gobottest"
 )
 
@@ -78,7 +86,7 @@ func TestSpheroDriverStart(t *testing.T) {
 
 func TestSpheroDriverHalt(t *testing.T) {
 	d := initTestSpheroDriver()
-	d.adaptor().connected = true
+	d.adaptor().(*SpheroAdaptor).connected = true
 	gobottest.Assert(t, len(d.Halt()), 0)
 }
 
@@ -140,6 +148,166 @@ func TestConfigureLocator(t *testing.T) {
 	gobottest.Assert(t, data.body, buf.Bytes())
 }
 
+// respond answers the packets sent by the driver with the response code and
+// data returned by f, until the returned function is called
+func respond(d *SpheroDriver, f func(p *packet) (uint8, []uint8)) (stop func()) {
+	done := make(chan bool)
+	go func() {
+		for {
+			select {
+			case p := <-d.packetChannel:
+				code, data := f(p)
+				response := append([]uint8{0xFF, 0xFF, code, p.header[4], uint8(len(data) + 1)}, data...)
+				d.addSyncResponse(append(response, calculateChecksum(response[2:])))
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func TestSpheroDriverSyncResponses(t *testing.T) {
+	d := initTestSpheroDriver()
+	var sent []*packet
+	defer respond(d, func(p *packet) (uint8, []uint8) {
+		sent = append(sent, p)
+		switch p.header[3] {
+		case 0x20:
+			return 0x00, []uint8{0x01, 0x02, 0x02, 0xF0, 0x00, 0x2A, 0x00, 0x3C}
+		case 0x36:
+			return 0x00, []uint8{0x00, 0x00, 0x00, 0x12}
+		case 0x56:
+			return 0x00, []uint8{0x05, 0x00, 0x07}
+		case 0x31:
+			return 0x07, nil
+		}
+		return 0x00, nil
+	})()
+
+	state, err := d.GetPowerState()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, state, PowerState{RecVer: 1, PowerState: PowerOK, BattVoltage: 752, NumCharges: 42, TimeSinceChg: 60})
+
+	flags, err := d.GetPermanentOptionFlags()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, flags, OptionVectorDrive|OptionMotionTimeouts)
+
+	status, err := d.GetMacroStatus()
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, status, MacroStatus{ID: 5, CommandNum: 7})
+
+	gobottest.Assert(t, d.Boost(true), errors.New("Command 0x31: Sphero responded with error code 0x07"))
+
+	// the response to GetTemporaryOptionFlags is too short
+	_, err = d.GetTemporaryOptionFlags()
+	gobottest.Assert(t, err, errors.New("Command 0x38: response of 0 bytes is too short"))
+
+	gobottest.Assert(t, d.SetRawMotorValues(MotorForward, 100, MotorReverse, 50), nil)
+	gobottest.Assert(t, sent[len(sent)-1].body, []uint8{0x01, 100, 0x02, 50})
+
+	gobottest.Assert(t, d.Sleep(0x0102, 3, 0x0405), nil)
+	gobottest.Assert(t, sent[len(sent)-1].body, []uint8{0x01, 0x02, 0x03, 0x04, 0x05})
+
+	gobottest.Assert(t, d.SetTemporaryOptionFlags(OptionStopOnDisconnect), nil)
+	gobottest.Assert(t, sent[len(sent)-1].body, []uint8{0x00, 0x00, 0x00, 0x01})
+
+	gobottest.Assert(t, d.SaveMacro(1, make([]uint8, 254)),
+		errors.New("Command 0x52: body of 255 bytes is too long"))
+
+	ret := d.Command("SelfLevel")(map[string]interface{}{})
+	gobottest.Assert(t, ret, nil)
+	gobottest.Assert(t, sent[len(sent)-1].body, []uint8{0x0B, 0x00, 0x00, 0x00})
+
+	ret = d.Command("GetPowerState")(nil)
+	gobottest.Assert(t, ret, state)
+}
+
+func TestSpheroDriverNoResponse(t *testing.T) {
+	d := initTestSpheroDriver()
+	gobottest.Assert(t, d.Ping(), ErrNoResponse)
+}
+
+func TestSpheroDriverNoResponseAPI(t *testing.T) {
+	d := initTestSpheroDriver()
+	g := gobot.NewGobot()
+	g.AddRobot(gobot.NewRobot("Robot1", []gobot.Device{d}))
+	a := api.NewAPI(g)
+	a.Handler()
+
+	request, _ := http.NewRequest("POST",
+		"/api/robots/Robot1/devices/bot/commands/GetPowerState",
+		bytes.NewBufferString("{}"),
+	)
+	response := httptest.NewRecorder()
+	a.ServeHTTP(response, request)
+	gobottest.Assert(t, response.Code, 500)
+
+	var body map[string]interface{}
+	json.NewDecoder(response.Body).Decode(&body)
+	gobottest.Assert(t, body, map[string]interface{}{"error": ErrNoResponse.Error()})
+}
+
+func TestSpheroDriverUploadOrbBasic(t *testing.T) {
+	d := initTestSpheroDriver()
+	var sent []*packet
+	defer respond(d, func(p *packet) (uint8, []uint8) {
+		sent = append(sent, p)
+		return 0x00, nil
+	})()
+
+	program := strings.Repeat("10 print \"hi\"\n", 30)
+	gobottest.Assert(t, d.UploadOrbBasic(OrbBasicFlash, strings.TrimSuffix(program, "\n")), nil)
+
+	gobottest.Assert(t, len(sent), 3)
+	gobottest.Assert(t, sent[0].header[3], uint8(0x60))
+	gobottest.Assert(t, sent[0].body, []uint8{OrbBasicFlash})
+
+	var uploaded []uint8
+	for _, p := range sent[1:] {
+		gobottest.Assert(t, p.header[3], uint8(0x61))
+		gobottest.Assert(t, p.body[0], OrbBasicFlash)
+		uploaded = append(uploaded, p.body[1:]...)
+	}
+	gobottest.Assert(t, len(sent[1].body), 254)
+	gobottest.Assert(t, string(uploaded), program)
+}
+
+func TestSpheroDriverAsyncEvents(t *testing.T) {
+	d := initTestSpheroDriver()
+	async := func(code uint8, data ...uint8) []uint8 {
+		return append(append([]uint8{0xFF, 0xFE, code, 0x00, uint8(len(data) + 1)}, data...), 0x00)
+	}
+	events := make(chan interface{}, 1)
+	for _, name := range []string{PowerNotification, Diagnostics, PreSleep, MacroMarker, OrbBasicPrint, OrbBasicError, SelfLevelComplete} {
+		gobot.On(d.Event(name), func(data interface{}) { events <- data })
+	}
+	receive := func() interface{} {
+		select {
+		case data := <-events:
+			return data
+		case <-time.After(time.Second):
+			t.Fatal("Event was not published")
+		}
+		return nil
+	}
+
+	d.handleAsyncResponse(async(0x01, PowerLow))
+	gobottest.Assert(t, receive(), PowerLow)
+	d.handleAsyncResponse(async(0x02, []uint8("Battery OK")...))
+	gobottest.Assert(t, receive(), "Battery OK")
+	d.handleAsyncResponse(async(0x05))
+	gobottest.Assert(t, receive(), nil)
+	d.handleAsyncResponse(async(0x06, 0x02, 0x03, 0x00, 0x04))
+	gobottest.Assert(t, receive(), MacroMarkerPacket{Marker: 2, ID: 3, CommandNum: 4})
+	d.handleAsyncResponse(async(0x08, []uint8("hi\n")...))
+	gobottest.Assert(t, receive(), "hi\n")
+	d.handleAsyncResponse(async(0x09, []uint8("Syntax error")...))
+	gobottest.Assert(t, receive(), "Syntax error")
+	d.handleAsyncResponse(async(0x0B, 0x01))
+	gobottest.Assert(t, receive(), uint8(0x01))
+}
+
 func TestCalculateChecksum(t *testing.T) {
 	tests := []struct {
 		data     []byte
@@ -184,3 +184,103 @@ type DataStreamingPacket struct {
 	// 0080 0000h	Velocity Y	-32768 to 32767	mm/s
 	VeloY int16
 }
+
+// PowerState is the response of GetPowerState
+type PowerState struct {
+	// Record version code
+	RecVer uint8
+	// High-level state of the power system, see PowerCharging, PowerOK,
+	// PowerLow and PowerCritical
+	PowerState uint8
+	// Current battery voltage scaled in 100ths of a volt
+	BattVoltage uint16
+	// Number of battery recharges in the life of this Sphero
+	NumCharges uint16
+	// Seconds awake since last recharge
+	TimeSinceChg uint16
+}
+
+const (
+	// PowerCharging is the power state of a charging Sphero
+	PowerCharging uint8 = 0x01
+	// PowerOK is the power state of a Sphero with a charged battery
+	PowerOK uint8 = 0x02
+	// PowerLow is the power state of a Sphero with a low battery
+	PowerLow uint8 = 0x03
+	// PowerCritical is the power state of a Sphero about to shut down
+	PowerCritical uint8 = 0x04
+)
+
+const (
+	// MotorOff turns a motor off with SetRawMotorValues
+	MotorOff uint8 = 0x00
+	// MotorForward drives a motor forward with SetRawMotorValues
+	MotorForward uint8 = 0x01
+	// MotorReverse drives a motor in reverse with SetRawMotorValues
+	MotorReverse uint8 = 0x02
+	// MotorBrake brakes a motor with SetRawMotorValues
+	MotorBrake uint8 = 0x03
+	// MotorIgnore leaves a motor unchanged with SetRawMotorValues
+	MotorIgnore uint8 = 0x04
+)
+
+// Permanent option flags, see SetPermanentOptionFlags
+const (
+	// Set to prevent Sphero from immediately going to sleep when placed in
+	// the charger and connected over Bluetooth
+	OptionPreventSleepInCharger uint32 = 1 << 0
+	// Set to enable Vector Drive, that is, when Sphero is stopped and a new
+	// roll command is issued it achieves the heading before moving along it
+	OptionVectorDrive uint32 = 1 << 1
+	// Set to disable self-leveling when Sphero is inserted into the charger
+	OptionDisableSelfLevelInCharger uint32 = 1 << 2
+	// Set to force the tail LED always on
+	OptionTailLightAlwaysOn uint32 = 1 << 3
+	// Set to enable motion timeouts, see the Sphero API
+	OptionMotionTimeouts uint32 = 1 << 4
+)
+
+// Temporary option flags, see SetTemporaryOptionFlags
+const (
+	// Set to stop Sphero when the Bluetooth connection is lost
+	OptionStopOnDisconnect uint32 = 1 << 0
+)
+
+// SelfLevelConfig provides configuration for SelfLevel.
+type SelfLevelConfig struct {
+	// Bitwise options: 01h start (or abort when clear), 02h rotate to the
+	// final heading, 04h sleep after leveling, 08h control the LEDs
+	Options uint8
+	// Accuracy in degrees, 0 for the default of 2 degrees
+	AngleLimit uint8
+	// Maximum seconds to level, 0 for the default of 15 seconds
+	Timeout uint8
+	// Hundreds of milliseconds the Sphero must stay within AngleLimit,
+	// 0 for the default of 300ms
+	TrueTime uint8
+}
+
+// DefaultSelfLevelConfig returns a SelfLevelConfig which starts leveling
+// with the default accuracy and timeouts, rotating to the final heading and
+// controlling the LEDs
+func DefaultSelfLevelConfig() SelfLevelConfig {
+	return SelfLevelConfig{Options: 0x01 | 0x02 | 0x08}
+}
+
+// MacroStatus is the response of AbortMacro and GetMacroStatus
+type MacroStatus struct {
+	// ID of the macro which is running, 00h if none is
+	ID uint8
+	// Number of the command being executed
+	CommandNum uint16
+}
+
+// MacroMarkerPacket represents the response from a macro marker event
+type MacroMarkerPacket struct {
+	// Value of the marker, 00h when the macro ended
+	Marker uint8
+	// ID of the macro
+	ID uint8
+	// Number of the command of the marker
+	CommandNum uint16
+}
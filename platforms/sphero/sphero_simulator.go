@@ -0,0 +1,490 @@
+package sphero
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+)
+
+// Response codes of the packets answered by a SpheroSimulator
+const (
+	responseOK          uint8 = 0x00
+	responseBadChecksum uint8 = 0x01
+	responseBadData     uint8 = 0x03
+	responseUnknown     uint8 = 0x04
+)
+
+// SimulatorStep is the interval at which a connected SpheroSimulator is
+// advanced by the ticks of gobot.DefaultClock
+var SimulatorStep = 10 * time.Millisecond
+
+var _ io.ReadWriteCloser = (*SpheroSimulator)(nil)
+
+// SpheroSimulator is a simulated Sphero, used as the serial port of a
+// SpheroAdaptor to run a SpheroDriver without a Sphero. It parses the packets
+// written to it, answers them like a Sphero, rolls in a square arena in
+// response to Roll, and sends the data streaming and collision asynchronous
+// packets.
+//
+// Once connected, it is advanced every SimulatorStep of gobot.DefaultClock.
+// To advance it deterministically in tests, set gobot.DefaultClock to a
+// gobot.VirtualClock which is not advanced before connecting it, and call
+// Step.
+type SpheroSimulator struct {
+	// Arena is the half width in centimeters of the square arena centered on
+	// the origin, the Sphero collides with its walls and stops. An Arena of 0
+	// has no walls.
+	Arena float64
+	// MaxSpeed is the speed in centimeters per second of the Sphero rolling
+	// at speed 255
+	MaxSpeed float64
+
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	in      []uint8
+	out     []uint8
+	closed  bool
+	done    chan bool
+	elapsed time.Duration
+
+	x, y         float64
+	speed        uint8
+	heading      uint16
+	rgb          []uint8
+	backLED      uint8
+	stabilized   bool
+	streaming    DataStreamingConfig
+	streamed     uint8
+	nextFrame    time.Duration
+	collision    CollisionConfig
+	notifyPower  bool
+	nextPower    time.Duration
+	permanent    uint32
+	temporary    uint32
+	collisions   int
+	packetErrors int
+}
+
+// NewSpheroSimulator returns a new SpheroSimulator at the origin of an arena
+// of 2 by 2 meters, with a MaxSpeed of 200 cm/s
+func NewSpheroSimulator() *SpheroSimulator {
+	s := &SpheroSimulator{
+		Arena:      100,
+		MaxSpeed:   200,
+		rgb:        []uint8{0, 0, 0},
+		stabilized: true,
+		closed:     true,
+	}
+	s.cond = sync.NewCond(&s.mutex)
+	return s
+}
+
+// NewSimulatedSpheroAdaptor returns a new SpheroAdaptor given a name,
+// connected to sim instead of a serial port
+func NewSimulatedSpheroAdaptor(name string, sim *SpheroSimulator) *SpheroAdaptor {
+	a := NewSpheroAdaptor(name, "simulator")
+	a.connect = func(string) (io.ReadWriteCloser, error) {
+		sim.open()
+		return sim, nil
+	}
+	return a
+}
+
+// open resets the connection of the simulator and starts advancing it
+func (s *SpheroSimulator) open() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		return
+	}
+	s.closed = false
+	s.in = nil
+	s.out = nil
+	s.done = make(chan bool)
+
+	clock := gobot.DefaultClock
+	tick := clock.Tick(SimulatorStep)
+	go func(done chan bool) {
+		// step by the time elapsed since the last tick, as ticks are dropped
+		// when the simulator does not keep up
+		last := clock.Now()
+		for {
+			select {
+			case <-tick:
+				now := clock.Now()
+				s.Step(now.Sub(last))
+				last = now
+			case <-done:
+				return
+			}
+		}
+	}(s.done)
+}
+
+// Read reads the packets sent by the simulator, blocking until there are
+// some. It returns io.EOF once the simulator is closed.
+func (s *SpheroSimulator) Read(b []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for len(s.out) == 0 && !s.closed {
+		s.cond.Wait()
+	}
+	if s.closed {
+		return 0, io.EOF
+	}
+	n := copy(b, s.out)
+	s.out = s.out[n:]
+	return n, nil
+}
+
+// Write writes packets to the simulator, answering every complete packet
+func (s *SpheroSimulator) Write(b []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+	s.in = append(s.in, b...)
+	for s.parse() {
+	}
+	return len(b), nil
+}
+
+// Close closes the connection to the simulator, which keeps its state
+func (s *SpheroSimulator) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+		s.cond.Broadcast()
+	}
+	return nil
+}
+
+// Position returns the position in centimeters of the Sphero
+func (s *SpheroSimulator) Position() (x float64, y float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.x, s.y
+}
+
+// SetPosition moves the Sphero to a position in centimeters
+func (s *SpheroSimulator) SetPosition(x float64, y float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.x, s.y = x, y
+}
+
+// Heading returns the heading in degrees of the Sphero
+func (s *SpheroSimulator) Heading() uint16 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.heading
+}
+
+// Speed returns the speed of the Sphero, from 0 to 255
+func (s *SpheroSimulator) Speed() uint8 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.speed
+}
+
+// RGB returns the color of the RGB LED of the Sphero
+func (s *SpheroSimulator) RGB() []uint8 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return []uint8{s.rgb[0], s.rgb[1], s.rgb[2]}
+}
+
+// Collisions returns the number of times the Sphero collided with the walls
+// of the arena
+func (s *SpheroSimulator) Collisions() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.collisions
+}
+
+// PacketErrors returns the number of packets which were answered with an
+// error code, because of a bad checksum, bad data or an unknown command
+func (s *SpheroSimulator) PacketErrors() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.packetErrors
+}
+
+// Step advances the simulator by d duration, rolling the Sphero and sending
+// the asynchronous packets which are due
+func (s *SpheroSimulator) Step(d time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.elapsed += d
+	s.roll(d)
+
+	if s.streaming.N > 0 && (s.streaming.Pcnt == 0 || s.streamed < s.streaming.Pcnt) {
+		for s.nextFrame <= s.elapsed {
+			s.sendAsync(0x03, s.dataStreamingFrames())
+			s.nextFrame += s.streamingPeriod()
+			if s.streaming.Pcnt > 0 {
+				s.streamed++
+				if s.streamed == s.streaming.Pcnt {
+					break
+				}
+			}
+		}
+	}
+
+	if s.notifyPower && s.nextPower <= s.elapsed {
+		s.sendAsync(0x01, []uint8{PowerOK})
+		s.nextPower = s.elapsed + 10*time.Second
+	}
+}
+
+// roll moves the Sphero at its speed and heading for d duration, stopping it
+// at the walls of the arena
+func (s *SpheroSimulator) roll(d time.Duration) {
+	if s.speed == 0 {
+		return
+	}
+	vx, vy := s.velocity()
+	s.x += vx * d.Seconds()
+	s.y += vy * d.Seconds()
+	if s.Arena <= 0 {
+		return
+	}
+
+	var axis uint8
+	if math.Abs(s.x) > s.Arena {
+		s.x = math.Copysign(s.Arena, s.x)
+		axis |= 0x01
+	}
+	if math.Abs(s.y) > s.Arena {
+		s.y = math.Copysign(s.Arena, s.y)
+		axis |= 0x02
+	}
+	if axis == 0 {
+		return
+	}
+
+	s.collisions++
+	if s.collision.Method != 0 {
+		packet := CollisionPacket{Axis: axis, Speed: s.speed, Timestamp: uint32(s.elapsed / time.Millisecond)}
+		if axis&0x01 != 0 {
+			packet.X = int16(-math.Copysign(1000, vx))
+			packet.XMagnitude = int16(math.Abs(vx))
+		}
+		if axis&0x02 != 0 {
+			packet.Y = int16(-math.Copysign(1000, vy))
+			packet.YMagnitude = int16(math.Abs(vy))
+		}
+		buf := new(bytes.Buffer)
+		binary.Write(buf, binary.BigEndian, packet)
+		s.sendAsync(0x07, buf.Bytes())
+	}
+	s.speed = 0
+}
+
+// velocity returns the velocity in centimeters per second of the Sphero,
+// whose heading is clockwise from the Y axis
+func (s *SpheroSimulator) velocity() (vx float64, vy float64) {
+	v := float64(s.speed) / 255 * s.MaxSpeed
+	rad := float64(s.heading) * math.Pi / 180
+	return v * math.Sin(rad), v * math.Cos(rad)
+}
+
+// streamingPeriod returns the interval between data streaming packets, of M
+// frames sampled at 400Hz divided by N
+func (s *SpheroSimulator) streamingPeriod() time.Duration {
+	m := s.streaming.M
+	if m == 0 {
+		m = 1
+	}
+	return time.Duration(s.streaming.N) * time.Duration(m) * time.Second / 400
+}
+
+// dataStreamingFrames returns the data of a data streaming packet, of the M
+// frames of the sensor values selected by the masks
+func (s *SpheroSimulator) dataStreamingFrames() []uint8 {
+	vx, vy := s.velocity()
+	yaw := int16(s.heading)
+	if yaw > 180 {
+		yaw -= 360
+	}
+	sample := DataStreamingPacket{
+		RawAccZ:   250,
+		FiltYaw:   yaw,
+		FiltAccZ:  4096,
+		Quat0:     10000,
+		OdomX:     int16(s.x),
+		OdomY:     int16(s.y),
+		AccelOne:  1000,
+		VeloX:     int16(vx * 10),
+		VeloY:     int16(vy * 10),
+		RawLMotor: int16(s.speed) * 8,
+		RawRMotor: int16(s.speed) * 8,
+	}
+	values := make([]int16, binary.Size(sample)/2)
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, sample)
+	binary.Read(buf, binary.BigEndian, &values)
+
+	var frame []uint8
+	for i, value := range values {
+		mask, bit := s.streaming.Mask, uint(31-i)
+		if i >= 32 {
+			mask, bit = s.streaming.Mask2, uint(63-i)
+		}
+		if mask&(1<<bit) != 0 {
+			frame = append(frame, uint8(value>>8), uint8(value))
+		}
+	}
+
+	var data []uint8
+	for i := uint16(0); i < s.streaming.M || i == 0; i++ {
+		data = append(data, frame...)
+	}
+	return data
+}
+
+// parse answers the first packet of the input, returning false when there
+// is no complete packet
+func (s *SpheroSimulator) parse() bool {
+	// skip to the start of a packet
+	for len(s.in) > 0 && s.in[0] != 0xFF {
+		s.in = s.in[1:]
+	}
+	if len(s.in) < 6 {
+		return false
+	}
+	if s.in[1] != 0xFF && s.in[1] != 0xFE {
+		s.in = s.in[1:]
+		return true
+	}
+	dlen := int(s.in[5])
+	if len(s.in) < 6+dlen {
+		return false
+	}
+	packet := s.in[:6+dlen]
+	s.in = s.in[6+dlen:]
+
+	answer := packet[1] == 0xFF
+	seq := packet[4]
+	if dlen == 0 || packet[len(packet)-1] != calculateChecksum(packet[2:len(packet)-1]) {
+		s.respond(answer, responseBadChecksum, seq, nil)
+		return true
+	}
+	code, data := s.handle(packet[2], packet[3], packet[6:len(packet)-1])
+	s.respond(answer, code, seq, data)
+	return true
+}
+
+// handle executes a command, returning its response code and data
+func (s *SpheroSimulator) handle(did uint8, cid uint8, body []uint8) (code uint8, data []uint8) {
+	// expected length of the body of the commands, -1 for any length
+	lengths := map[uint16]int{
+		0x0001: 0, 0x0020: 0, 0x0021: 1, 0x0022: 5, 0x0040: 0,
+		0x0201: 2, 0x0202: 1, 0x0203: 1, 0x0209: 4, 0x0211: -1, 0x0212: 6,
+		0x0213: 7, 0x0215: 0, 0x0220: 4, 0x0221: 1, 0x0222: 0, 0x0230: 4,
+		0x0231: 1, 0x0233: 4, 0x0235: 4, 0x0236: 0, 0x0237: 4, 0x0238: 0,
+		0x0250: 1, 0x0251: -1, 0x0252: -1, 0x0255: 0, 0x0256: 0,
+		0x0260: 1, 0x0261: -1, 0x0262: 3, 0x0263: 0,
+	}
+	length, ok := lengths[uint16(did)<<8|uint16(cid)]
+	if !ok {
+		return responseUnknown, nil
+	}
+	if length >= 0 && len(body) != length {
+		return responseBadData, nil
+	}
+
+	buf := new(bytes.Buffer)
+	switch uint16(did)<<8 | uint16(cid) {
+	case 0x0020:
+		binary.Write(buf, binary.BigEndian, PowerState{RecVer: 0x01, PowerState: PowerOK, BattVoltage: 800})
+	case 0x0021:
+		s.notifyPower = body[0] != 0
+		s.nextPower = s.elapsed
+	case 0x0202:
+		s.stabilized = body[0] != 0
+	case 0x0211:
+		if len(body) != 9 && len(body) != 13 {
+			return responseBadData, nil
+		}
+		config := DataStreamingConfig{}
+		// Mask2 is optional
+		body = append(append([]uint8{}, body...), 0, 0, 0, 0)
+		binary.Read(bytes.NewReader(body), binary.BigEndian, &config)
+		s.streaming = config
+		s.streamed = 0
+		s.nextFrame = s.elapsed + s.streamingPeriod()
+	case 0x0212:
+		s.collision = CollisionConfig{Method: body[0], Xt: body[1], Yt: body[2], Xs: body[3], Ys: body[4], Dead: body[5]}
+	case 0x0213:
+		config := LocatorConfig{}
+		binary.Read(bytes.NewReader(body), binary.BigEndian, &config)
+		s.x, s.y = float64(config.X), float64(config.Y)
+	case 0x0215:
+		vx, vy := s.velocity()
+		binary.Write(buf, binary.BigEndian, []int16{
+			int16(s.x), int16(s.y), int16(vx), int16(vy), int16(math.Hypot(vx, vy)),
+		})
+	case 0x0220:
+		s.rgb = []uint8{body[0], body[1], body[2]}
+	case 0x0221:
+		s.backLED = body[0]
+	case 0x0222:
+		buf.Write(s.rgb)
+	case 0x0230:
+		s.speed = body[0]
+		s.heading = (uint16(body[1])<<8 | uint16(body[2])) % 360
+		if body[3] == 0x00 {
+			s.speed = 0
+		}
+	case 0x0233:
+		s.speed = 0
+	case 0x0235:
+		s.permanent = binary.BigEndian.Uint32(body)
+	case 0x0236:
+		binary.Write(buf, binary.BigEndian, s.permanent)
+	case 0x0237:
+		s.temporary = binary.BigEndian.Uint32(body)
+	case 0x0238:
+		binary.Write(buf, binary.BigEndian, s.temporary)
+	case 0x0255, 0x0256:
+		binary.Write(buf, binary.BigEndian, MacroStatus{})
+	}
+	return responseOK, buf.Bytes()
+}
+
+// respond sends the response to a packet, unless it does not ask for one
+func (s *SpheroSimulator) respond(answer bool, code uint8, seq uint8, data []uint8) {
+	if code != responseOK {
+		s.packetErrors++
+	}
+	if !answer {
+		return
+	}
+	packet := append([]uint8{0xFF, 0xFF, code, seq, uint8(len(data) + 1)}, data...)
+	s.send(append(packet, calculateChecksum(packet[2:])))
+}
+
+// sendAsync sends an asynchronous packet with the given ID code
+func (s *SpheroSimulator) sendAsync(id uint8, data []uint8) {
+	dlen := len(data) + 1
+	packet := append([]uint8{0xFF, 0xFE, id, uint8(dlen >> 8), uint8(dlen)}, data...)
+	s.send(append(packet, calculateChecksum(packet[2:])))
+}
+
+func (s *SpheroSimulator) send(packet []uint8) {
+	if s.closed {
+		return
+	}
+	s.out = append(s.out, packet...)
+	s.cond.Broadcast()
+}
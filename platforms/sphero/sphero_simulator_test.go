@@ -0,0 +1,186 @@
+package sphero
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot"
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+// initTestSimulator returns a connected simulator which is only advanced by
+// Step, and a driver writing packets to it
+func initTestSimulator() (*SpheroSimulator, *SpheroDriver, func()) {
+	clock := gobot.DefaultClock
+	gobot.DefaultClock = gobot.NewVirtualClock(time.Now())
+	sim := NewSpheroSimulator()
+	a := NewSimulatedSpheroAdaptor("bot", sim)
+	a.Connect()
+	gobot.DefaultClock = clock
+	return sim, NewSpheroDriver(a, "bot"), func() { a.Disconnect() }
+}
+
+func writePacket(sim *SpheroSimulator, p *packet) {
+	sim.Write(append(append(p.header, p.body...), p.checksum))
+}
+
+func readPacket(sim *SpheroSimulator) []uint8 {
+	header := make([]uint8, 5)
+	readFull(sim, header)
+	length := int(header[4])
+	if header[1] == 0xFE {
+		length += int(header[3]) << 8
+	}
+	body := make([]uint8, length)
+	readFull(sim, body)
+	return append(header, body...)
+}
+
+func readFull(sim *SpheroSimulator, b []uint8) {
+	for n := 0; n < len(b); {
+		i, _ := sim.Read(b[n:])
+		n += i
+	}
+}
+
+func TestSpheroSimulatorPackets(t *testing.T) {
+	sim, d, stop := initTestSimulator()
+	defer stop()
+
+	writePacket(sim, d.craftPacket([]uint8{1, 2, 3, 1}, 0x02, 0x20))
+	response := readPacket(sim)
+	gobottest.Assert(t, response, []uint8{0xFF, 0xFF, 0x00, 0x00, 0x01, 0xFE})
+	gobottest.Assert(t, sim.RGB(), []uint8{1, 2, 3})
+
+	// the response to GetRGB, written one byte at a time
+	p := d.craftPacket([]uint8{}, 0x02, 0x22)
+	for _, b := range append(append(p.header, p.body...), p.checksum) {
+		sim.Write([]uint8{b})
+	}
+	response = readPacket(sim)
+	gobottest.Assert(t, response[2:8], []uint8{0x00, 0x01, 0x04, 1, 2, 3})
+	gobottest.Assert(t, response[8], calculateChecksum(response[2:8]))
+
+	p = d.craftPacket([]uint8{}, 0x02, 0x22)
+	p.checksum++
+	writePacket(sim, p)
+	gobottest.Assert(t, readPacket(sim)[2], responseBadChecksum)
+
+	writePacket(sim, d.craftPacket([]uint8{}, 0x02, 0x99))
+	gobottest.Assert(t, readPacket(sim)[2], responseUnknown)
+
+	writePacket(sim, d.craftPacket([]uint8{1}, 0x02, 0x30))
+	gobottest.Assert(t, readPacket(sim)[2], responseBadData)
+	gobottest.Assert(t, sim.PacketErrors(), 3)
+}
+
+func TestSpheroSimulatorRoll(t *testing.T) {
+	sim, d, stop := initTestSimulator()
+	defer stop()
+
+	writePacket(sim, d.craftPacket([]uint8{255, 0x00, 90, 0x01}, 0x02, 0x30))
+	readPacket(sim)
+	gobottest.Assert(t, sim.Speed(), uint8(255))
+	gobottest.Assert(t, sim.Heading(), uint16(90))
+
+	sim.Step(100 * time.Millisecond)
+	x, y := sim.Position()
+	gobottest.Assert(t, int(x+0.5), 20)
+	gobottest.Assert(t, int(y+0.5), 0)
+
+	writePacket(sim, d.craftPacket([]uint8{}, 0x02, 0x15))
+	response := readPacket(sim)
+	gobottest.Assert(t, response[5:15], []uint8{0, 20, 0, 0, 0, 200, 0, 0, 0, 200})
+
+	// collision detection is disabled, the Sphero stops at the wall
+	sim.Step(time.Second)
+	x, _ = sim.Position()
+	gobottest.Assert(t, x, 100.0)
+	gobottest.Assert(t, sim.Speed(), uint8(0))
+	gobottest.Assert(t, sim.Collisions(), 1)
+}
+
+func TestSpheroSimulatorDriver(t *testing.T) {
+	sim, d, stop := initTestSimulator()
+	defer stop()
+
+	collisions := make(chan CollisionPacket, 1)
+	gobot.On(d.Event(Collision), func(data interface{}) {
+		collisions <- data.(CollisionPacket)
+	})
+	sensorData := make(chan DataStreamingPacket, 1)
+	gobot.On(d.Event(SensorData), func(data interface{}) {
+		sensorData <- data.(DataStreamingPacket)
+	})
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	d.SetRGB(10, 20, 30)
+	gobottest.Assert(t, d.GetRGB(), []uint8{10, 20, 30})
+
+	d.SetDataStreaming(DataStreamingConfig{N: 40, M: 1, Mask: 0xFFFFFFFF, Pcnt: 1, Mask2: 0xFFFFFFFF})
+	d.Roll(255, 180)
+	gobottest.Assert(t, d.Ping(), nil)
+
+	sim.Step(time.Second)
+	select {
+	case c := <-collisions:
+		gobottest.Assert(t, c.Axis, uint8(0x02))
+		gobottest.Assert(t, c.Y, int16(1000))
+		gobottest.Assert(t, c.Speed, uint8(255))
+	case <-time.After(time.Second):
+		t.Fatal("Collision was not published")
+	}
+	select {
+	case data := <-sensorData:
+		gobottest.Assert(t, data.OdomX, int16(0))
+		gobottest.Assert(t, data.OdomY, int16(-100))
+		gobottest.Assert(t, data.FiltYaw, int16(180))
+	case <-time.After(time.Second):
+		t.Fatal("Sensor data was not published")
+	}
+	gobottest.Assert(t, d.ReadLocator(), []int16{0, -100, 0, 0, 0})
+	gobottest.Assert(t, sim.PacketErrors(), 0)
+}
+
+// notifyingTransport is a Transport receiving the packets of a simulator in
+// notifications of at most 20 bytes, like a BLE characteristic
+type notifyingTransport struct {
+	*SpheroSimulator
+}
+
+func (notifyingTransport) Name() string      { return "ble" }
+func (notifyingTransport) Connect() []error  { return nil }
+func (notifyingTransport) Finalize() []error { return nil }
+func (notifyingTransport) Connected() bool   { return true }
+func (n notifyingTransport) Read(b []byte) (int, error) {
+	if len(b) > 20 {
+		b = b[:20]
+	}
+	return n.SpheroSimulator.Read(b)
+}
+
+func TestSpheroDriverTransport(t *testing.T) {
+	sim, _, stop := initTestSimulator()
+	defer stop()
+	d := NewSpheroDriver(notifyingTransport{sim}, "bot")
+
+	gobottest.Assert(t, len(d.Start()), 0)
+	gobottest.Assert(t, d.Connection().Name(), "ble")
+	d.SetRGB(1, 2, 3)
+	gobottest.Assert(t, d.GetRGB(), []uint8{1, 2, 3})
+
+	d.SetDataStreaming(DefaultDataStreamingConfig())
+	gobottest.Assert(t, d.Ping(), nil)
+
+	sensorData := make(chan DataStreamingPacket, 10)
+	gobot.On(d.Event(SensorData), func(data interface{}) {
+		sensorData <- data.(DataStreamingPacket)
+	})
+	sim.Step(100 * time.Millisecond)
+	select {
+	case data := <-sensorData:
+		gobottest.Assert(t, data.AccelOne, int16(1000))
+	case <-time.After(time.Second):
+		t.Fatal("Sensor data was not published")
+	}
+}
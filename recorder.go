@@ -0,0 +1,197 @@
+package gobot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Record is the data of an event written at a given time, as recorded by a
+// Recorder. Robot is empty for the events of the Gobot, and Device for the
+// events of a robot.
+type Record struct {
+	Time   time.Time       `json:"time"`
+	Robot  string          `json:"robot,omitempty"`
+	Device string          `json:"device,omitempty"`
+	Event  string          `json:"event"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Recorder writes the data of every event of a Gobot, its robots and their
+// devices to a writer, as lines of JSON Records timestamped by DefaultClock.
+type Recorder struct {
+	gobot        *Gobot
+	w            io.Writer
+	mutex        sync.Mutex
+	unsubscribes []func()
+	err          error
+}
+
+// NewRecorder returns a new Recorder of the events of g written to w
+func NewRecorder(g *Gobot, w io.Writer) *Recorder {
+	return &Recorder{gobot: g, w: w}
+}
+
+// Start subscribes to the events of the Gobot and of the robots and devices
+// it has, and stops recording when the Gobot stops.
+func (r *Recorder) Start() {
+	subscribe := func(robot string, device string, v interface{}) {
+		eventer, ok := v.(Eventer)
+		if !ok {
+			return
+		}
+		for _, name := range eventNames(eventer) {
+			name := name
+			unsubscribe, _ := Subscribe(eventer.Event(name), func(data interface{}) {
+				r.record(robot, device, name, data)
+			})
+			r.mutex.Lock()
+			r.unsubscribes = append(r.unsubscribes, unsubscribe)
+			r.mutex.Unlock()
+		}
+	}
+
+	subscribe("", "", r.gobot)
+	r.gobot.Robots().Each(func(robot *Robot) {
+		subscribe(robot.Name, "", robot)
+		robot.Devices().Each(func(device Device) {
+			subscribe(robot.Name, device.Name(), device)
+		})
+	})
+	r.gobot.AddStopHandler(r.Stop)
+}
+
+// Stop unsubscribes from the events, returning the first error which
+// prevented recording an event.
+func (r *Recorder) Stop() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, unsubscribe := range r.unsubscribes {
+		unsubscribe()
+	}
+	r.unsubscribes = nil
+	return r.err
+}
+
+func (r *Recorder) record(robot string, device string, event string, data interface{}) {
+	record := Record{Time: DefaultClock.Now(), Robot: robot, Device: device, Event: event}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	line, err := json.Marshal(data)
+	if err == nil {
+		record.Data = line
+		line, err = json.Marshal(record)
+	}
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("Recording %v: %v", record.path(), err)
+	}
+}
+
+// path returns the robot, device and event of the record joined by slashes
+func (r Record) path() string {
+	path := r.Event
+	if r.Device != "" {
+		path = r.Device + "/" + path
+	}
+	if r.Robot != "" {
+		path = r.Robot + "/" + path
+	}
+	return path
+}
+
+// Replayer writes the data of recorded events back to the events of a Gobot,
+// its robots and their devices, advancing its Clock to the time of each
+// record so the work of robots which uses Every and After is run as it was
+// when the events were recorded. Set DefaultClock to the Clock of the
+// Replayer before the work is started:
+//
+//	replayer := gobot.NewReplayer(gbot, file)
+//	gobot.DefaultClock = replayer.Clock
+//	robot.Work()
+//	err := replayer.Replay()
+type Replayer struct {
+	gobot *Gobot
+	r     io.Reader
+	// Clock is advanced to the time of each record, it starts at the time
+	// of the first record unless it is set to another time
+	Clock *VirtualClock
+	// Decode returns the data of a record written to its event, by default
+	// the JSON value of the record, such as a float64 or a
+	// map[string]interface{}. Set it to replay events whose handlers expect
+	// the data to be of a given type.
+	Decode func(record Record) (data interface{}, err error)
+}
+
+// NewReplayer returns a new Replayer of the records read from r to the events
+// of g
+func NewReplayer(g *Gobot, r io.Reader) *Replayer {
+	return &Replayer{
+		gobot: g,
+		r:     r,
+		Clock: NewVirtualClock(time.Time{}),
+		Decode: func(record Record) (data interface{}, err error) {
+			if len(record.Data) > 0 {
+				err = json.Unmarshal(record.Data, &data)
+			}
+			return
+		},
+	}
+}
+
+// Replay writes the data of every record to its event, in order, returning
+// when all of them are written or when one can not be replayed.
+func (p *Replayer) Replay() error {
+	decoder := json.NewDecoder(p.r)
+	for {
+		record := Record{}
+		if err := decoder.Decode(&record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		event := p.gobot.event(record.Robot, record.Device, record.Event)
+		if event == nil {
+			return fmt.Errorf("Replaying %v: %v", record.path(), ErrUnknownEvent)
+		}
+		data, err := p.Decode(record)
+		if err != nil {
+			return fmt.Errorf("Replaying %v: %v", record.path(), err)
+		}
+
+		if p.Clock.Now().IsZero() {
+			p.Clock.start(record.Time)
+		}
+		p.Clock.Set(record.Time)
+		event.Write(data)
+	}
+}
+
+// event returns the event of a device of a robot, of a robot when device is
+// empty, or of the Gobot when robot is empty
+func (g *Gobot) event(robot string, device string, name string) *Event {
+	var eventer Eventer = g
+	if robot != "" {
+		r := g.Robot(robot)
+		if r == nil {
+			return nil
+		}
+		eventer = r
+		if device != "" {
+			d, ok := r.Device(device).(Eventer)
+			if !ok {
+				return nil
+			}
+			eventer = d
+		}
+	}
+	return eventer.Event(name)
+}
@@ -0,0 +1,146 @@
+package gobot
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+type testEventDriver struct {
+	*testDriver
+	Eventer
+}
+
+func newTestRecorderGobot() *Gobot {
+	g := NewGobot()
+	g.AddEvent("started")
+	driver := &testEventDriver{
+		testDriver: newTestDriver(newTestAdaptor("Connection1", "/dev/null"), "Device1", "0"),
+		Eventer:    NewEventer(),
+	}
+	driver.AddEvent("data")
+	r := NewRobot("Robot1", []Connection{}, []Device{driver})
+	r.AddEvent("moved")
+	g.AddRobot(r)
+	return g
+}
+
+func TestRecorder(t *testing.T) {
+	g := newTestRecorderGobot()
+	c := NewVirtualClock(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC))
+	DefaultClock = c
+	defer func() { DefaultClock = systemClock{} }()
+
+	buf := &bytes.Buffer{}
+	recorder := NewRecorder(g, buf)
+	recorder.Start()
+
+	Publish(g.Robot("Robot1").Device("Device1").(Eventer).Event("data"), map[string]int{"x": 1})
+	<-time.After(10 * time.Millisecond)
+	c.Advance(time.Second)
+	Publish(g.Robot("Robot1").Event("moved"), 42)
+	<-time.After(10 * time.Millisecond)
+	c.Advance(time.Second)
+	Publish(g.Event("started"), nil)
+	<-time.After(10 * time.Millisecond)
+
+	gobottest.Assert(t, len(g.Stop()), 0)
+	Publish(g.Event("started"), nil)
+	<-time.After(10 * time.Millisecond)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	gobottest.Assert(t, lines, []string{
+		`{"time":"2016-01-01T00:00:00Z","robot":"Robot1","device":"Device1","event":"data","data":{"x":1}}`,
+		`{"time":"2016-01-01T00:00:01Z","robot":"Robot1","event":"moved","data":42}`,
+		`{"time":"2016-01-01T00:00:02Z","event":"started","data":null}`,
+	})
+}
+
+func TestRecorderError(t *testing.T) {
+	g := newTestRecorderGobot()
+	recorder := NewRecorder(g, &bytes.Buffer{})
+	recorder.Start()
+
+	Publish(g.Event("started"), make(chan bool))
+	<-time.After(10 * time.Millisecond)
+	err := recorder.Stop()
+	gobottest.Refute(t, err, nil)
+	gobottest.Assert(t, strings.HasPrefix(err.Error(), "Recording started: "), true)
+}
+
+func TestReplayer(t *testing.T) {
+	g := newTestRecorderGobot()
+	records := `{"time":"2016-01-01T00:00:00Z","robot":"Robot1","device":"Device1","event":"data","data":{"x":1}}
+{"time":"2016-01-01T00:00:01.5Z","robot":"Robot1","event":"moved","data":42}
+{"time":"2016-01-01T00:00:03Z","event":"started","data":null}
+`
+	replayer := NewReplayer(g, strings.NewReader(records))
+	DefaultClock = replayer.Clock
+	defer func() { DefaultClock = systemClock{} }()
+
+	data := make(chan interface{}, 1)
+	moved := make(chan interface{}, 1)
+	On(g.Robot("Robot1").Device("Device1").(Eventer).Event("data"), func(d interface{}) {
+		data <- d
+	})
+	On(g.Robot("Robot1").Event("moved"), func(d interface{}) {
+		moved <- d
+	})
+
+	// the work runs on the clock of the records
+	ticks := []time.Time{}
+	Every(time.Second, func() {})
+	After(time.Second, func() { ticks = append(ticks, DefaultClock.Now()) })
+	After(2*time.Second, func() { ticks = append(ticks, DefaultClock.Now()) })
+
+	gobottest.Assert(t, replayer.Replay(), nil)
+	gobottest.Assert(t, <-data, map[string]interface{}{"x": 1.0})
+	gobottest.Assert(t, <-moved, 42.0)
+	gobottest.Assert(t, ticks, []time.Time{
+		time.Date(2016, 1, 1, 0, 0, 1, 0, time.UTC),
+		time.Date(2016, 1, 1, 0, 0, 2, 0, time.UTC),
+	})
+	gobottest.Assert(t, replayer.Clock.Now(), time.Date(2016, 1, 1, 0, 0, 3, 0, time.UTC))
+}
+
+func TestReplayerDecode(t *testing.T) {
+	g := newTestRecorderGobot()
+	replayer := NewReplayer(g, strings.NewReader(
+		`{"time":"2016-01-01T00:00:00Z","robot":"Robot1","event":"moved","data":42}`))
+	replayer.Decode = func(record Record) (interface{}, error) {
+		var i int
+		err := json.Unmarshal(record.Data, &i)
+		return i, err
+	}
+
+	received := make(chan interface{}, 1)
+	On(g.Robot("Robot1").Event("moved"), func(data interface{}) {
+		received <- data
+	})
+	gobottest.Assert(t, replayer.Replay(), nil)
+	gobottest.Assert(t, <-received, 42)
+
+	replayer.Decode = func(record Record) (interface{}, error) {
+		return nil, errors.New("decode error")
+	}
+	replayer.r = strings.NewReader(`{"time":"2016-01-01T00:00:00Z","robot":"Robot1","event":"moved","data":42}`)
+	gobottest.Assert(t, replayer.Replay(), errors.New("Replaying Robot1/moved: decode error"))
+}
+
+func TestReplayerErrors(t *testing.T) {
+	g := newTestRecorderGobot()
+	for records, err := range map[string]string{
+		`{"time":"2016-01-01T00:00:00Z","robot":"Robot2","event":"moved"}`:                   "Replaying Robot2/moved: Event does not exist",
+		`{"time":"2016-01-01T00:00:00Z","robot":"Robot1","device":"Device2","event":"data"}`: "Replaying Robot1/Device2/data: Event does not exist",
+		`{"time":"2016-01-01T00:00:00Z","event":"stopped"}`:                                  "Replaying stopped: Event does not exist",
+	} {
+		gobottest.Assert(t, NewReplayer(g, strings.NewReader(records)).Replay().Error(), err)
+	}
+
+	gobottest.Refute(t, NewReplayer(g, strings.NewReader("not json")).Replay(), nil)
+}
@@ -2,13 +2,15 @@ package gobot
 
 import (
 	"fmt"
-	"log"
+	"sync"
 )
 
 // JSONRobot a JSON representation of a Robot.
 type JSONRobot struct {
 	Name        string            `json:"name"`
 	Commands    []string          `json:"commands"`
+	Schemas     []CommandSchema   `json:"schemas"`
+	Events      []string          `json:"events"`
 	Connections []*JSONConnection `json:"connections"`
 	Devices     []*JSONDevice     `json:"devices"`
 }
@@ -18,6 +20,8 @@ func NewJSONRobot(robot *Robot) *JSONRobot {
 	jsonRobot := &JSONRobot{
 		Name:        robot.Name,
 		Commands:    []string{},
+		Schemas:     robot.Schemas(),
+		Events:      eventNames(robot),
 		Connections: []*JSONConnection{},
 		Devices:     []*JSONDevice{},
 	}
@@ -26,10 +30,24 @@ func NewJSONRobot(robot *Robot) *JSONRobot {
 		jsonRobot.Commands = append(jsonRobot.Commands, command)
 	}
 
+	robot.Connections().Each(func(connection Connection) {
+		jsonConnection := NewJSONConnection(connection)
+		jsonConnection.Connected = robot.Connected(connection.Name())
+		jsonRobot.Connections = append(jsonRobot.Connections, jsonConnection)
+	})
+
 	robot.Devices().Each(func(device Device) {
 		jsonDevice := NewJSONDevice(device)
-		jsonRobot.Connections = append(jsonRobot.Connections, NewJSONConnection(robot.Connection(jsonDevice.Connection)))
 		jsonRobot.Devices = append(jsonRobot.Devices, jsonDevice)
+		pinner, ok := device.(Pinner)
+		if !ok {
+			return
+		}
+		for _, jsonConnection := range jsonRobot.Connections {
+			if jsonConnection.Name == jsonDevice.Connection {
+				jsonConnection.Pins = append(jsonConnection.Pins, pinner.Pin())
+			}
+		}
 	})
 	return jsonRobot
 }
@@ -42,6 +60,10 @@ type Robot struct {
 	Work        func()
 	connections *Connections
 	devices     *Devices
+	connected   map[string]bool
+	mutex       sync.Mutex
+	gobot       *Gobot
+	logger      Logger
 	Commander
 	Eventer
 }
@@ -102,26 +124,26 @@ func NewRobot(name string, v ...interface{}) *Robot {
 		Name:        name,
 		connections: &Connections{},
 		devices:     &Devices{},
+		connected:   make(map[string]bool),
 		Work:        nil,
 		Eventer:     NewEventer(),
 		Commander:   NewCommander(),
 	}
 
-	log.Println("Initializing Robot", r.Name, "...")
+	log := r.Logger()
+	log.Log(InfoLevel, "Initializing robot", Fields{"robot": r.Name})
 
 	for i := range v {
 		switch v[i].(type) {
 		case []Connection:
-			log.Println("Initializing connections...")
 			for _, connection := range v[i].([]Connection) {
 				c := r.AddConnection(connection)
-				log.Println("Initializing connection", c.Name(), "...")
+				log.Log(InfoLevel, "Initializing connection", Fields{"robot": r.Name, "connection": c.Name()})
 			}
 		case []Device:
-			log.Println("Initializing devices...")
 			for _, device := range v[i].([]Device) {
 				d := r.AddDevice(device)
-				log.Println("Initializing device", d.Name(), "...")
+				log.Log(InfoLevel, "Initializing device", deviceFields(r.Name, d))
 			}
 		case func():
 			r.Work = v[i].(func())
@@ -133,17 +155,20 @@ func NewRobot(name string, v ...interface{}) *Robot {
 
 // Start a Robot's Connections, Devices, and work.
 func (r *Robot) Start() (errs []error) {
-	log.Println("Starting Robot", r.Name, "...")
-	if cerrs := r.Connections().Start(); len(cerrs) > 0 {
+	log := r.Logger()
+	log.Log(InfoLevel, "Starting robot", Fields{"robot": r.Name})
+	if cerrs := r.Connections().start(r.Name, log, func(c Connection) {
+		r.setConnected(c.Name(), true)
+	}); len(cerrs) > 0 {
 		errs = append(errs, cerrs...)
 		return
 	}
-	if derrs := r.Devices().Start(); len(derrs) > 0 {
+	if derrs := r.Devices().start(r.Name, log); len(derrs) > 0 {
 		errs = append(errs, derrs...)
 		return
 	}
 	if r.Work != nil {
-		log.Println("Starting work...")
+		log.Log(InfoLevel, "Starting work", Fields{"robot": r.Name})
 		r.Work()
 	}
 	return
@@ -151,12 +176,50 @@ func (r *Robot) Start() (errs []error) {
 
 // Stop stops a Robot's connections and Devices
 func (r *Robot) Stop() (errs []error) {
-	log.Println("Stopping Robot", r.Name, "...")
-	errs = append(errs, r.Devices().Halt()...)
+	r.Logger().Log(InfoLevel, "Stopping robot", Fields{"robot": r.Name})
+	errs = append(errs, r.Devices().halt(r.Name)...)
 	errs = append(errs, r.Connections().Finalize()...)
+	r.Connections().Each(func(c Connection) {
+		r.setConnected(c.Name(), false)
+	})
 	return errs
 }
 
+// Logger returns the Logger of the robot, which is the Logger of the Gobot it
+// was added to unless it was set, or DefaultLogger.
+func (r *Robot) Logger() Logger {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	switch {
+	case r.logger != nil:
+		return r.logger
+	case r.gobot != nil:
+		return r.gobot.Logger()
+	}
+	return DefaultLogger
+}
+
+// SetLogger sets the Logger of the robot
+func (r *Robot) SetLogger(l Logger) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.logger = l
+}
+
+// Connected returns whether a connection of the robot given a name was
+// connected by Start, and not yet finalized by Stop.
+func (r *Robot) Connected(name string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.connected[name]
+}
+
+func (r *Robot) setConnected(name string, connected bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.connected[name] = connected
+}
+
 // Devices returns all devices associated with this Robot.
 func (r *Robot) Devices() *Devices {
 	return r.devices
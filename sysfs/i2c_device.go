@@ -6,6 +6,8 @@ import (
 	"os"
 	"syscall"
 	"unsafe"
+
+	"github.com/hybridgroup/gobot"
 )
 
 const (
@@ -66,7 +68,8 @@ func (d *i2cDevice) queryFunctionality() (err error) {
 	if errno != 0 {
 		err = fmt.Errorf("Querying functionality failed with syscall.Errno %v", errno)
 	}
-	fmt.Printf("Functionality: 0x%x\n", d.funcs)
+	gobot.DefaultLogger.Log(gobot.DebugLevel, "I2c adapter functionality",
+		gobot.Fields{"functionality": fmt.Sprintf("0x%x", d.funcs)})
 	return
 }
 
@@ -0,0 +1,77 @@
+package sysfs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// W1PATH default linux 1-wire path
+	W1PATH = "/sys/bus/w1/devices"
+	// W1MASTER default linux 1-wire bus master
+	W1MASTER = "w1_bus_master1"
+)
+
+// OneWireDevice is the interface for sysfs 1-wire slave interactions.
+// Each Write resets the bus, selects the slave and writes the data, each
+// Read reads from the bus without resetting it.
+type OneWireDevice interface {
+	io.ReadWriteCloser
+}
+
+type oneWireDevice struct {
+	file File
+}
+
+// NewOneWireDevice returns an io.ReadWriteCloser to the raw "rw" file of the
+// 1-wire slave id, eg. "28-0000054c2ec2". The raw file is only available when
+// no family driver (such as w1_therm) has claimed the slave.
+func NewOneWireDevice(id string) (d *oneWireDevice, err error) {
+	d = &oneWireDevice{}
+	d.file, err = OpenFile(fmt.Sprintf("%v/%v/rw", W1PATH, id), os.O_RDWR, 0644)
+	return
+}
+
+func (d *oneWireDevice) Read(b []byte) (n int, err error) {
+	return d.file.Read(b)
+}
+
+func (d *oneWireDevice) Write(b []byte) (n int, err error) {
+	return d.file.Write(b)
+}
+
+func (d *oneWireDevice) Close() (err error) {
+	return d.file.Close()
+}
+
+// OneWireSlaves returns the ids of the slaves found by the 1-wire bus master,
+// eg. "w1_bus_master1". An empty master defaults to W1MASTER.
+func OneWireSlaves(master string) (ids []string, err error) {
+	if master == "" {
+		master = W1MASTER
+	}
+	f, err := OpenFile(fmt.Sprintf("%v/%v/w1_master_slaves", W1PATH, master), os.O_RDONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+	n, err := f.Read(buf)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	ids = []string{}
+	for _, line := range strings.Split(string(buf[:n]), "\n") {
+		line = strings.TrimSpace(line)
+		// the kernel reports "not found." when the bus is empty
+		if line == "" || line == "not found." {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	return ids, nil
+}
@@ -0,0 +1,56 @@
+package sysfs
+
+import (
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+func TestNewOneWireDevice(t *testing.T) {
+	fs := NewMockFilesystem([]string{})
+	SetFilesystem(fs)
+
+	_, err := NewOneWireDevice("28-0000054c2ec2")
+	gobottest.Refute(t, err, nil)
+
+	fs = NewMockFilesystem([]string{
+		"/sys/bus/w1/devices/28-0000054c2ec2/rw",
+	})
+	SetFilesystem(fs)
+
+	d, err := NewOneWireDevice("28-0000054c2ec2")
+	var _ OneWireDevice = d
+	gobottest.Assert(t, err, nil)
+
+	n, err := d.Write([]byte{0xBE})
+	gobottest.Assert(t, n, 1)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, fs.Files["/sys/bus/w1/devices/28-0000054c2ec2/rw"].Contents, "\xBE")
+
+	buf := make([]byte, 1)
+	n, err = d.Read(buf)
+	gobottest.Assert(t, n, 1)
+	gobottest.Assert(t, buf, []byte{0xBE})
+
+	gobottest.Assert(t, d.Close(), nil)
+}
+
+func TestOneWireSlaves(t *testing.T) {
+	fs := NewMockFilesystem([]string{
+		"/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves",
+	})
+	SetFilesystem(fs)
+
+	fs.Files["/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves"].Contents = "28-0000054c2ec2\n28-00000574c8e1\n"
+	ids, err := OneWireSlaves(W1MASTER)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, ids, []string{"28-0000054c2ec2", "28-00000574c8e1"})
+
+	fs.Files["/sys/bus/w1/devices/w1_bus_master1/w1_master_slaves"].Contents = "not found.\n"
+	ids, err = OneWireSlaves("")
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, ids, []string{})
+
+	_, err = OneWireSlaves("w1_bus_master2")
+	gobottest.Refute(t, err, nil)
+}
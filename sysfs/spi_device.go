@@ -0,0 +1,96 @@
+package sysfs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	SPI_IOC_WR_MODE          = 0x40016B01
+	SPI_IOC_WR_BITS_PER_WORD = 0x40016B03
+	SPI_IOC_WR_MAX_SPEED_HZ  = 0x40046B04
+
+	// SPI_BUFFER_SIZE is the default spidev bufsiz, the largest single transfer
+	SPI_BUFFER_SIZE = 4096
+)
+
+// SpiDevice is the interface for spidev interactions
+type SpiDevice interface {
+	io.WriteCloser
+	SetSpeed(int) error
+}
+
+type spiDevice struct {
+	file File
+}
+
+// NewSpiDevice returns an io.WriteCloser with the proper ioctl given a spidev
+// location, eg. "/dev/spidev0.0", using SPI mode 0, 8 bits per word and speed
+// in hertz
+func NewSpiDevice(location string, speed int) (d *spiDevice, err error) {
+	d = &spiDevice{}
+
+	if d.file, err = OpenFile(location, os.O_RDWR, os.ModeExclusive); err != nil {
+		return
+	}
+	defer func() {
+		if err != nil {
+			d.file.Close()
+		}
+	}()
+
+	mode := uint8(0)
+	if err = d.ioctl(SPI_IOC_WR_MODE, uintptr(unsafe.Pointer(&mode))); err != nil {
+		return
+	}
+	bits := uint8(8)
+	if err = d.ioctl(SPI_IOC_WR_BITS_PER_WORD, uintptr(unsafe.Pointer(&bits))); err != nil {
+		return
+	}
+
+	err = d.SetSpeed(speed)
+	return
+}
+
+// SetSpeed sets the maximum clock speed in hertz
+func (d *spiDevice) SetSpeed(speed int) (err error) {
+	hz := uint32(speed)
+	return d.ioctl(SPI_IOC_WR_MAX_SPEED_HZ, uintptr(unsafe.Pointer(&hz)))
+}
+
+func (d *spiDevice) ioctl(request uintptr, arg uintptr) (err error) {
+	_, _, errno := Syscall(
+		syscall.SYS_IOCTL,
+		d.file.Fd(),
+		request,
+		arg,
+	)
+
+	if errno != 0 {
+		err = fmt.Errorf("SPI ioctl 0x%x failed with syscall.Errno %v", request, errno)
+	}
+	return
+}
+
+// Write transmits b, split into transfers of at most SPI_BUFFER_SIZE bytes
+func (d *spiDevice) Write(b []byte) (n int, err error) {
+	for n < len(b) {
+		end := n + SPI_BUFFER_SIZE
+		if end > len(b) {
+			end = len(b)
+		}
+		i, err := d.file.Write(b[n:end])
+		n += i
+		if err != nil {
+			return n, err
+		}
+	}
+	return
+}
+
+func (d *spiDevice) Close() (err error) {
+	return d.file.Close()
+}
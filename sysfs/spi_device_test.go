@@ -0,0 +1,84 @@
+package sysfs
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/hybridgroup/gobot/gobottest"
+)
+
+type failingSyscall struct{}
+
+func (sys *failingSyscall) Syscall(trap, a1, a2, a3 uintptr) (r1, r2 uintptr, err syscall.Errno) {
+	return 0, 0, syscall.EINVAL
+}
+
+// closingFilesystem records whether the files it opens are closed
+type closingFilesystem struct {
+	*MockFilesystem
+	closed bool
+}
+
+type closingFile struct {
+	*MockFile
+	fs *closingFilesystem
+}
+
+func (f *closingFile) Close() error {
+	f.fs.closed = true
+	return nil
+}
+
+func (fs *closingFilesystem) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	f, err := fs.MockFilesystem.OpenFile(name, flag, perm)
+	if err != nil {
+		return f, err
+	}
+	return &closingFile{MockFile: f.(*MockFile), fs: fs}, nil
+}
+
+func TestNewSpiDevice(t *testing.T) {
+	fs := NewMockFilesystem([]string{})
+	SetFilesystem(fs)
+
+	_, err := NewSpiDevice("/dev/spidev0.0", 1000000)
+	gobottest.Refute(t, err, nil)
+
+	fs = NewMockFilesystem([]string{
+		"/dev/spidev0.0",
+	})
+	SetFilesystem(fs)
+	SetSyscall(&MockSyscall{})
+
+	d, err := NewSpiDevice("/dev/spidev0.0", 1000000)
+	var _ SpiDevice = d
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, d.SetSpeed(2400000), nil)
+
+	n, err := d.Write([]byte{0x01, 0x02, 0x03})
+	gobottest.Assert(t, n, 3)
+	gobottest.Assert(t, err, nil)
+	gobottest.Assert(t, fs.Files["/dev/spidev0.0"].Contents, "\x01\x02\x03")
+
+	// transfers larger than the spidev buffer are split
+	n, err = d.Write([]byte(strings.Repeat("a", SPI_BUFFER_SIZE) + "b"))
+	gobottest.Assert(t, n, SPI_BUFFER_SIZE+1)
+	gobottest.Assert(t, fs.Files["/dev/spidev0.0"].Contents, "b")
+
+	gobottest.Assert(t, d.Close(), nil)
+}
+
+func TestNewSpiDeviceIoctlError(t *testing.T) {
+	fs := &closingFilesystem{MockFilesystem: NewMockFilesystem([]string{
+		"/dev/spidev0.0",
+	})}
+	SetFilesystem(fs)
+	SetSyscall(&failingSyscall{})
+	defer SetSyscall(&MockSyscall{})
+
+	_, err := NewSpiDevice("/dev/spidev0.0", 1000000)
+	gobottest.Refute(t, err, nil)
+	gobottest.Assert(t, fs.closed, true)
+}
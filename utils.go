@@ -3,7 +3,6 @@ package gobot
 import (
 	"crypto/rand"
 	"errors"
-	"log"
 	"math"
 	"math/big"
 	"time"
@@ -17,7 +16,7 @@ var (
 var eventError = func(e *Event) (err error) {
 	if e == nil {
 		err = ErrUnknownEvent
-		log.Println(err.Error())
+		DefaultLogger.Log(ErrorLevel, err.Error(), nil)
 		return
 	}
 	return
@@ -26,10 +25,10 @@ var eventError = func(e *Event) (err error) {
 // Every triggers f every t time until the end of days, or when a
 // bool value is sent to the channel returned by the Every function.
 // It does not wait for the previous execution of f to finish before
-// it fires the next f.
+// it fires the next f. The time is kept by DefaultClock.
 func Every(t time.Duration, f func()) chan bool {
 	done := make(chan bool)
-	c := time.Tick(t)
+	c := DefaultClock.Tick(t)
 
 	go func() {
 		for {
@@ -46,9 +45,9 @@ func Every(t time.Duration, f func()) chan bool {
 	return done
 }
 
-// After triggers f after t duration.
+// After triggers f after t duration, kept by DefaultClock.
 func After(t time.Duration, f func()) {
-	time.AfterFunc(t, f)
+	DefaultClock.AfterFunc(t, f)
 }
 
 // Publish emits val to all subscribers of e. Returns ErrUnknownEvent if Event
@@ -64,7 +63,7 @@ func Publish(e *Event, val interface{}) (err error) {
 // does not exist.
 func On(e *Event, f func(s interface{})) (err error) {
 	if err = eventError(e); err == nil {
-		e.Callbacks = append(e.Callbacks, callback{f, false})
+		e.add(f, false, false)
 	}
 	return
 }
@@ -73,7 +72,18 @@ func On(e *Event, f func(s interface{})) (err error) {
 //ErrUnknownEvent if Event does not exist.
 func Once(e *Event, f func(s interface{})) (err error) {
 	if err = eventError(e); err == nil {
-		e.Callbacks = append(e.Callbacks, callback{f, true})
+		e.add(f, true, false)
+	}
+	return
+}
+
+// Subscribe is similar to On except that it returns a function which stops
+// executing f, for subscribers which do not live as long as the Event.
+// Returns ErrUnknownEvent if Event does not exist.
+func Subscribe(e *Event, f func(s interface{})) (unsubscribe func(), err error) {
+	if err = eventError(e); err == nil {
+		id := e.add(f, false, true)
+		unsubscribe = func() { e.remove(id) }
 	}
 	return
 }
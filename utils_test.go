@@ -106,6 +106,28 @@ func TestOnce(t *testing.T) {
 	gobottest.Assert(t, err, ErrUnknownEvent)
 }
 
+func TestSubscribe(t *testing.T) {
+	c := make(chan interface{}, 10)
+	e := NewEvent()
+	unsubscribe, err := Subscribe(e, func(data interface{}) {
+		c <- data
+	})
+	gobottest.Assert(t, err, nil)
+	On(e, func(data interface{}) {})
+	Publish(e, 10)
+	gobottest.Assert(t, <-c, 10)
+
+	unsubscribe()
+	gobottest.Assert(t, len(e.Callbacks), 1)
+	Publish(e, 20)
+	<-time.After(1 * time.Millisecond)
+	gobottest.Assert(t, len(c), 0)
+
+	var e1 = (*Event)(nil)
+	_, err = Subscribe(e1, func(data interface{}) {})
+	gobottest.Assert(t, err, ErrUnknownEvent)
+}
+
 func TestFromScale(t *testing.T) {
 	gobottest.Assert(t, FromScale(5, 0, 10), 0.5)
 }